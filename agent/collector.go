@@ -2,10 +2,20 @@ package main
 
 import (
 	"bytes"
+	"compress/gzip"
+	"context"
+	crand "crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
 	"io"
 	"io/ioutil"
+	"math"
 	"math/rand"
 	"net/http"
+	"reflect"
+	"strconv"
+	"sync"
 	"sync/atomic"
 	"time"
 
@@ -17,9 +27,73 @@ import (
 	"github.com/DataDog/datadog-process-agent/statsd"
 )
 
+const (
+	// maxRetryAfter caps the delay the backend can ask for when throttling the agent.
+	maxRetryAfter = 5 * time.Minute
+	// maxThrottledAttempts is the number of times a throttled message is submitted before being
+	// handed back to the payload retries.
+	maxThrottledAttempts = 3
+	// retryDelay is how long to wait before submitting again after a failed submission.
+	retryDelay = 5 * time.Second
+	// apiKeyHeader is the header the other intake endpoints take the API key from, sent
+	// when api_key_in_header is set.
+	apiKeyHeader = "DD-API-KEY"
+)
+
+var (
+	errCheckTimeout  = errors.New("check run timed out")
+	errCheckInFlight = errors.New("previous check run is still in progress")
+	errStopping      = errors.New("the agent is stopping")
+)
+
 type checkPayload struct {
 	messages []model.MessageBody
+	// Whether the first message is the manifest of the others rather than collected data,
+	// see newManifest
+	hasManifest bool
+	endpoint    string
+	groupID     int32
+	// Sequence number of the first message, the others follow. 0 if the messages
+	// were not sequenced.
+	firstSeq uint64
+	// When the payload was queued, and how many times its submission failed so far.
+	created time.Time
+	retries int
+}
+
+// envelope identifies a submitted message so that the backend can tell a resend
+// from a fresh message. It is set when the message is queued and stays the same
+// across retries.
+type envelope struct {
+	seq uint64
+	id  string
+}
+
+// throttledError is returned when the backend asks the agent to slow down,
+// with the delay to wait before submitting again.
+type throttledError struct {
+	retryAfter time.Duration
+}
+
+func (e *throttledError) Error() string {
+	return fmt.Sprintf("throttled by the backend, retry after %s", e.retryAfter)
+}
+
+// submissionError is returned when a submission failed in a way worth retrying, e.g. on
+// a network error or a server error of the backend.
+type submissionError struct {
+	err error
+}
+
+func (e *submissionError) Error() string {
+	return e.err.Error()
+}
+
+// mirrorPayload is an encoded message to copy to the mirror endpoint.
+type mirrorPayload struct {
 	endpoint string
+	body     []byte
+	env      envelope
 }
 
 // Collector will collect metrics from the local system and ship to the backend.
@@ -32,6 +106,43 @@ type Collector struct {
 	runCounter    int64
 	enabledChecks []checks.Check
 
+	// Last sequence number given to a queued message, and the random ID of this
+	// agent run the message IDs are made of.
+	seq   uint64
+	runID string
+
+	// One slot per check, held while a run is in progress so that a check whose
+	// previous run timed out is never run concurrently with it.
+	inFlight map[string]chan struct{}
+
+	// Encoded messages to copy to the mirror endpoint, nil if mirroring is disabled.
+	mirror chan mirrorPayload
+
+	// Writes the collected payloads to the debug_output_file, nil if it isn't set.
+	debugOutput *debugOutput
+
+	// Closed once the containers can be accessed, nil unless the container checks wait
+	// for them up to containerGracePeriod, see deferContainerChecks.
+	containersReady      chan struct{}
+	containerGracePeriod time.Duration
+
+	// No submission is made before this time, set when the backend throttles us or
+	// a submission fails. Only accessed from the goroutine submitting the payloads.
+	retryAfter time.Time
+	// Delay before submitting again after a failed submission.
+	retryDelay time.Duration
+	// Closed once the agent is stopping, to give up on waiting for retryAfter.
+	stopping chan struct{}
+
+	// Set when no API key is configured, the payloads are discarded instead of submitted
+	// as the backend would reject them all, see checkAPIKey.
+	discard bool
+
+	// Number of payloads dropped without being fully submitted, and how many of them
+	// because they were older than max_payload_age.
+	dropped int64
+	expired int64
+
 	// Controls the real-time interval, can change live.
 	realTimeInterval time.Duration
 	// Set to 1 if enabled 0 is not. We're using an integer
@@ -47,20 +158,40 @@ func NewCollector(cfg *config.AgentConfig) (Collector, error) {
 	}
 
 	enabledChecks := make([]checks.Check, 0)
+	inFlight := make(map[string]chan struct{})
 	for _, c := range checks.All {
 		if cfg.CheckIsEnabled(c.Name()) {
 			c.Init(cfg, sysInfo)
 			enabledChecks = append(enabledChecks, c)
+			inFlight[c.Name()] = make(chan struct{}, 1)
+		}
+	}
+
+	var mirror chan mirrorPayload
+	if cfg.MirrorEndpoint != nil {
+		mirror = make(chan mirrorPayload, cfg.QueueSize)
+	}
+
+	var debugOut *debugOutput
+	if cfg.DebugOutputFile != "" {
+		if debugOut, err = newDebugOutput(cfg.DebugOutputFile); err != nil {
+			return Collector{}, fmt.Errorf("unable to open debug_output_file: %s", err)
 		}
 	}
 
 	return Collector{
 		send:          make(chan checkPayload, cfg.QueueSize),
+		mirror:        mirror,
+		debugOutput:   debugOut,
 		rtIntervalCh:  make(chan time.Duration),
 		cfg:           cfg,
-		groupID:       rand.Int31(),
-		httpClient:    http.Client{Transport: cfg.Transport},
+		groupID:       newGroupIDSeed(cfg)(time.Now()),
+		httpClient:    http.Client{Transport: cfg.Transport, Timeout: cfg.SubmissionTimeout},
 		enabledChecks: enabledChecks,
+		inFlight:      inFlight,
+		runID:         newRunID(),
+		retryDelay:    retryDelay,
+		discard:       cfg.APIKey == "",
 
 		// Defaults for real-time on start
 		realTimeInterval: 2 * time.Second,
@@ -68,16 +199,36 @@ func NewCollector(cfg *config.AgentConfig) (Collector, error) {
 	}, nil
 }
 
-func (l *Collector) runCheck(c checks.Check) {
+func (l *Collector) runCheck(ctx context.Context, c checks.Check) {
 	runCounter := atomic.AddInt64(&l.runCounter, 1)
 	s := time.Now()
 	// update the last collected timestamp for info
 	updateLastCollectTime(time.Now())
-	messages, err := c.Run(l.cfg, atomic.AddInt32(&l.groupID, 1))
+	groupID := atomic.AddInt32(&l.groupID, 1)
+	messages, err := l.runWithTimeout(ctx, c, groupID)
+	switch err {
+	case context.Canceled:
+		log.Infof("Check '%s' was stopped before completing", c.Name())
+		return
+	case errCheckTimeout:
+		log.Warnf("Check '%s' timed out after %s, discarding its results", c.Name(), l.cfg.CheckTimeout(c.Name()))
+		checks.RecordTimeout(c.Name(), time.Since(s))
+		return
+	case errCheckInFlight:
+		log.Warnf("Skipping check '%s', its previous run timed out and is still in progress", c.Name())
+		return
+	}
+	checks.RecordRun(c.Name(), time.Since(s), messages, err)
 	if err != nil {
 		log.Criticalf("Unable to run check '%s': %s", c.Name(), err)
 	} else {
-		l.send <- checkPayload{messages, c.Endpoint()}
+		payload := l.newPayload(messages, c.Endpoint(), groupID)
+		if l.debugOutput != nil {
+			if err := l.debugOutput.write(c.Name(), payload); err != nil {
+				log.Warnf("Unable to write the payload of check '%s' to the debug_output_file: %s", c.Name(), err)
+			}
+		}
+		l.send <- payload
 		// update proc and container count for info
 		updateProcContainerCount(messages)
 		if !c.RealTime() {
@@ -94,47 +245,130 @@ func (l *Collector) runCheck(c checks.Check) {
 	}
 }
 
+// runWithTimeout runs the check under its configured timeout. A run going over the
+// timeout is abandoned: its context is cancelled so it can return early, but the
+// collector doesn't wait for it and the check is skipped until it does return.
+func (l *Collector) runWithTimeout(ctx context.Context, c checks.Check, groupID int32) ([]model.MessageBody, error) {
+	timeout := l.cfg.CheckTimeout(c.Name())
+	if timeout <= 0 {
+		return c.Run(ctx, l.cfg, groupID)
+	}
+
+	slot := l.inFlight[c.Name()]
+	if slot != nil {
+		select {
+		case slot <- struct{}{}:
+		default:
+			return nil, errCheckInFlight
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	type result struct {
+		messages []model.MessageBody
+		err      error
+	}
+	done := make(chan result, 1)
+	go func() {
+		if slot != nil {
+			defer func() { <-slot }()
+		}
+		messages, err := c.Run(ctx, l.cfg, groupID)
+		done <- result{messages, err}
+	}()
+
+	select {
+	case r := <-done:
+		// Checks honoring the context report the timeout themselves
+		if r.err == context.DeadlineExceeded {
+			return nil, errCheckTimeout
+		}
+		return r.messages, r.err
+	case <-ctx.Done():
+		if ctx.Err() == context.DeadlineExceeded {
+			return nil, errCheckTimeout
+		}
+		return nil, ctx.Err()
+	}
+}
+
 func (l *Collector) run(exit chan bool) {
 	log.Infof("Starting process-agent for host=%s, endpoint=%s, enabled checks=%v", l.cfg.HostName, l.cfg.APIEndpoint, l.cfg.EnabledChecks)
-	go handleSignals(exit)
 	heartbeat := time.NewTicker(15 * time.Second)
 	queueSizeTicker := time.NewTicker(10 * time.Second)
+	stopSender := make(chan struct{})
+	senderDone := make(chan struct{})
+	l.stopping = make(chan struct{})
+	if l.mirror != nil {
+		log.Infof("Mirroring %.0f%% of the payloads to %s", l.cfg.MirrorSampleRate*100, l.cfg.MirrorEndpoint.Host)
+		go l.runMirror(stopSender)
+	}
 	go func() {
+		defer close(senderDone)
 		for {
 			select {
 			case payload := <-l.send:
 				if len(l.send) >= l.cfg.QueueSize {
-					log.Info("Expiring payload from in-memory queue.")
 					// Limit number of items kept in memory while we wait.
-					<-l.send
-				}
-				for _, m := range payload.messages {
-					l.postMessage(payload.endpoint, m)
+					l.dropPayload(<-l.send, "the in-memory queue is full")
 				}
+				l.postPayload(payload)
 			case <-heartbeat.C:
 				statsd.Client.Gauge("datadog.process.agent", 1, []string{"version:" + Version}, 1)
 			case <-queueSizeTicker.C:
 				updateQueueSize(l.send)
-			case <-exit:
+			case <-stopSender:
 				return
 			}
 		}
 	}()
 
+	ctx, cancel := context.WithCancel(context.Background())
+	var checksWG sync.WaitGroup
+	jitter := rand.New(rand.NewSource(time.Now().UnixNano()))
 	for _, c := range l.enabledChecks {
+		checksWG.Add(1)
+		delay := startDelay(jitter, l.cfg.CollectionJitter)
 		go func(c checks.Check) {
+			defer checksWG.Done()
+
+			if delay > 0 {
+				log.Debugf("Delaying the start of the %s check by %s", c.Name(), delay)
+				select {
+				case <-time.After(delay):
+				case <-ctx.Done():
+					return
+				}
+			}
+
+			// The container checks waiting for the containers start once they are ready
+			var ready chan struct{}
+			if l.waitsForContainers(c) {
+				ready = l.containersReady
+			}
+
 			// Run the check the first time to prime the caches.
-			if !c.RealTime() {
-				l.runCheck(c)
+			if ready == nil && !c.RealTime() {
+				l.runCheck(ctx, c)
 			}
 
 			ticker := time.NewTicker(l.cfg.CheckInterval(c.Name()))
 			for {
 				select {
+				case <-ready:
+					ready = nil
+					if !c.RealTime() {
+						l.runCheck(ctx, c)
+					}
 				case <-ticker.C:
+					if ready != nil {
+						continue
+					}
 					realTimeEnabled := atomic.LoadInt64(&l.realTimeEnabled) == 1
 					if !c.RealTime() || realTimeEnabled {
-						l.runCheck(c)
+						l.runCheck(ctx, c)
 					}
 				case d := <-l.rtIntervalCh:
 					// Live-update the ticker.
@@ -150,35 +384,388 @@ func (l *Collector) run(exit chan bool) {
 			}
 		}(c)
 	}
+	if l.containersReady != nil {
+		checksWG.Add(1)
+		go func() {
+			defer checksWG.Done()
+			retry := time.NewTicker(containerAccessRetryInterval)
+			defer retry.Stop()
+			expired := time.NewTimer(l.containerGracePeriod)
+			defer expired.Stop()
+			l.awaitContainers(ctx, retry.C, expired.C, canAccessContainers)
+		}()
+	}
 	<-exit
+	// Stop the in-flight check runs so we don't wait on them to shut down.
+	cancel()
+	close(l.stopping)
+
+	l.shutdown(&checksWG, stopSender, senderDone)
 }
 
-func (l *Collector) postMessage(endpoint string, m model.MessageBody) {
-	msgType, err := model.DetectMessageType(m)
-	if err != nil {
-		log.Errorf("Unable to detect message type: %s", err)
+// startDelay returns a random delay within [0, maxJitter) for the first run of a check.
+func startDelay(r *rand.Rand, maxJitter time.Duration) time.Duration {
+	if maxJitter <= 0 {
+		return 0
+	}
+	return time.Duration(r.Int63n(int64(maxJitter)))
+}
+
+// shutdown waits for in-flight check runs and submits the payloads left in the
+// queue, giving up once the drain timeout is reached.
+func (l *Collector) shutdown(checksWG *sync.WaitGroup, stopSender chan struct{}, senderDone chan struct{}) {
+	log.Infof("Stopping checks and flushing queued payloads, waiting up to %s", l.cfg.DrainTimeout)
+
+	flushed := make(chan struct{})
+	go func() {
+		defer close(flushed)
+		// Keep sending while the checks finish so they never block on a full queue.
+		checksWG.Wait()
+		close(stopSender)
+		<-senderDone
+		l.flush()
+	}()
+
+	select {
+	case <-flushed:
+		log.Info("Flushed all queued payloads")
+	case <-time.After(l.cfg.DrainTimeout):
+		log.Warnf("Timed out flushing queued payloads, dropping %d payloads", len(l.send))
+	}
+
+	checks.Connections.Close()
+	if l.debugOutput != nil {
+		l.debugOutput.Close()
+	}
+}
+
+// flush submits all the payloads remaining in the queue.
+func (l *Collector) flush() {
+	for {
+		select {
+		case payload := <-l.send:
+			l.postPayload(payload)
+		default:
+			return
+		}
+	}
+}
+
+// newRunID returns a random ID for this agent run, so that the message IDs of
+// different agents or of a restarted agent never collide.
+func newRunID() string {
+	b := make([]byte, 8)
+	if _, err := crand.Read(b); err != nil {
+		return strconv.FormatInt(time.Now().UnixNano(), 16)
+	}
+	return hex.EncodeToString(b)
+}
+
+// newPayload returns the payload to queue for the messages, giving them the next
+// sequence numbers. The messages are preceded by their manifest if batch_manifest is enabled.
+func (l *Collector) newPayload(messages []model.MessageBody, endpoint string, groupID int32) checkPayload {
+	hasManifest := l.cfg.BatchManifest && len(messages) > 0
+	if hasManifest {
+		messages = append([]model.MessageBody{newManifest(l.cfg.HostName, groupID, messages)}, messages...)
+	}
+	last := atomic.AddUint64(&l.seq, uint64(len(messages)))
+	return checkPayload{
+		messages:    messages,
+		hasManifest: hasManifest,
+		endpoint:    endpoint,
+		groupID:     groupID,
+		firstSeq:    last - uint64(len(messages)) + 1,
+		created:     time.Now(),
+	}
+}
+
+// manifest returns the manifest of the payload, nil if it has none.
+func (p checkPayload) manifest() model.MessageBody {
+	if !p.hasManifest {
+		return nil
+	}
+	return p.messages[0]
+}
+
+// data returns the messages of the payload holding collected data, i.e. all but the manifest.
+func (p checkPayload) data() []model.MessageBody {
+	if p.hasManifest {
+		return p.messages[1:]
+	}
+	return p.messages
+}
+
+// newManifest returns the manifest of the batches of a collection group.
+func newManifest(hostName string, groupID int32, batches []model.MessageBody) *model.CollectorManifest {
+	m := &model.CollectorManifest{
+		HostName:  hostName,
+		GroupId:   groupID,
+		GroupSize: int32(len(batches)),
+		ItemCount: int32(checks.CountItems(batches)),
+	}
+	if t, err := model.DetectMessageType(batches[0]); err == nil {
+		m.BatchType = int32(t)
+	}
+	return m
+}
+
+// envelope returns the envelope of the i-th message of the payload.
+func (l *Collector) envelope(payload checkPayload, i int) envelope {
+	if payload.firstSeq == 0 {
+		return envelope{}
+	}
+	seq := payload.firstSeq + uint64(i)
+	return envelope{seq: seq, id: fmt.Sprintf("%s-%d", l.runID, seq)}
+}
+
+// postPayload submits the messages of the payload, also copying them to the mirror
+// endpoint if their message group is sampled. If a message fails to be submitted, it
+// and the following messages are queued again to be retried later. Payloads older than
+// max_payload_age are dropped instead.
+func (l *Collector) postPayload(payload checkPayload) {
+	if l.discard {
+		log.Debugf("No API key configured, discarding %s payload", payloadType(payload))
+		return
+	}
+	if l.expiredPayload(payload) {
+		return
+	}
+	mirror := l.mirror != nil && sampleGroup(payload.groupID, l.cfg.MirrorSampleRate)
+	for i, m := range payload.messages {
+		body, err := encodeMessage(m, l.cfg.PayloadCompression)
+		if err != nil {
+			log.Errorf("Unable to encode message: %s", err)
+			continue
+		}
+		env := l.envelope(payload, i)
+		if err := l.submitMessage(payload.endpoint, body, env); err == errStopping {
+			l.dropPayload(payload.from(i), "the agent stopped while waiting to submit it")
+			return
+		} else if err != nil {
+			l.retryPayload(payload.from(i), err)
+			return
+		}
+
+		if mirror {
+			select {
+			case l.mirror <- mirrorPayload{payload.endpoint, body, env}:
+			default:
+				log.Debug("Mirror queue is full, dropping a payload copy")
+			}
+		}
+	}
+}
+
+// expiredPayload drops the payload and returns true if it was queued longer than
+// max_payload_age ago, e.g. during a long outage, as its data is too stale to be useful.
+func (l *Collector) expiredPayload(payload checkPayload) bool {
+	if l.cfg.MaxPayloadAge <= 0 || time.Since(payload.created) <= l.cfg.MaxPayloadAge {
+		return false
+	}
+	atomic.AddInt64(&l.expired, 1)
+	statsd.Client.Count("datadog.process.payloads.expired", 1, []string{"type:" + payloadType(payload)}, 1)
+	l.dropPayload(payload, fmt.Sprintf("older than max_payload_age (%s)", l.cfg.MaxPayloadAge))
+	return true
+}
+
+// submitMessage posts an encoded message, first waiting out any delay requested by
+// the backend. A throttled message is retried after the requested delay, up to
+// maxThrottledAttempts times, with the same envelope. An error is returned if the
+// message still wasn't submitted and should be retried later, or errStopping if the
+// agent stopped while waiting, in which case it is dropped.
+func (l *Collector) submitMessage(endpoint string, body []byte, env envelope) error {
+	for attempt := 1; ; attempt++ {
+		if wait := time.Until(l.retryAfter); wait > 0 {
+			timer := time.NewTimer(wait)
+			select {
+			case <-timer.C:
+			case <-l.stopping:
+				timer.Stop()
+				return errStopping
+			}
+		}
+
+		err := l.postMessage(endpoint, body, env)
+		switch e := err.(type) {
+		case nil:
+			return nil
+		case *throttledError:
+			l.retryAfter = time.Now().Add(e.retryAfter)
+			if attempt >= maxThrottledAttempts {
+				return fmt.Errorf("still %s after %d attempts", err, attempt)
+			}
+			log.Warnf("Payload was %s", err)
+		default:
+			l.retryAfter = time.Now().Add(l.retryDelay)
+			return err
+		}
+	}
+}
+
+// from returns the payload made of the messages from the i-th one on.
+func (p checkPayload) from(i int) checkPayload {
+	p.messages = p.messages[i:]
+	p.hasManifest = p.hasManifest && i == 0
+	if p.firstSeq != 0 {
+		p.firstSeq += uint64(i)
+	}
+	return p
+}
+
+// retryPayload queues again a payload that failed to be submitted, unless it already
+// used up its max_retries or the queue is full, in which case it is dropped.
+func (l *Collector) retryPayload(payload checkPayload, err error) {
+	payload.retries++
+	if payload.retries > l.cfg.MaxRetries {
+		l.dropPayload(payload, fmt.Sprintf("submission failed %d times, last error: %s", payload.retries, err))
 		return
 	}
+	log.Warnf("Unable to submit payload, retrying later (%d/%d): %s", payload.retries, l.cfg.MaxRetries, err)
+	select {
+	case l.send <- payload:
+	default:
+		l.dropPayload(payload, "the in-memory queue is full")
+	}
+}
+
+// dropPayload gives up on submitting a payload, reporting it so that the data loss
+// is visible.
+func (l *Collector) dropPayload(payload checkPayload, reason string) {
+	atomic.AddInt64(&l.dropped, 1)
+	msgType := payloadType(payload)
+	log.Warnf("Dropping %s payload queued %s ago: %s", msgType, time.Since(payload.created), reason)
+	statsd.Client.Count("datadog.process.payloads.dropped", 1, []string{"type:" + msgType}, 1)
+}
+
+// payloadType returns the name of the type of the messages of a payload, e.g. CollectorProc.
+// The manifest of the payload isn't taken into account.
+func payloadType(payload checkPayload) string {
+	data := payload.data()
+	if len(data) == 0 {
+		return "empty"
+	}
+	t := reflect.TypeOf(data[0])
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return t.Name()
+}
+
+// sampleGroup returns true if the messages of the given group are part of the
+// sample at the given rate. The decision is the same for all the messages of a group.
+func sampleGroup(groupID int32, rate float64) bool {
+	if rate <= 0 {
+		return false
+	}
+	if rate >= 1 {
+		return true
+	}
+	// Knuth's multiplicative hash spreads the sequential group IDs evenly over the uint32 range.
+	h := uint32(groupID) * 2654435761
+	return float64(h) < rate*math.MaxUint32
+}
 
+// encodeMessage encodes a message with the given payload compression. zstd is applied
+// to the message body only, as flagged by the message header, while gzip is applied
+// to the whole encoded message and must be flagged by the Content-Encoding header.
+func encodeMessage(m model.MessageBody, compression string) ([]byte, error) {
+	msgType, err := model.DetectMessageType(m)
+	if err != nil {
+		return nil, err
+	}
+	encoding := model.MessageEncodingProtobuf
+	if compression == config.PayloadCompressionZstd {
+		encoding = model.MessageEncodingZstdPB
+	}
 	body, err := model.EncodeMessage(model.Message{
 		Header: model.MessageHeader{
 			Version:  model.MessageV3,
-			Encoding: model.MessageEncodingZstdPB,
+			Encoding: encoding,
 			Type:     msgType,
 		}, Body: m})
+	if err != nil || compression != config.PayloadCompressionGzip {
+		return body, err
+	}
+
+	var b bytes.Buffer
+	w := gzip.NewWriter(&b)
+	if _, err := w.Write(body); err != nil {
+		return nil, err
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+	return b.Bytes(), nil
+}
+
+// addPayloadHeaders sets the headers of a submission of an encoded message.
+func (l *Collector) addPayloadHeaders(req *http.Request, apiKey string, env envelope) {
+	req.Header.Add("X-Dd-APIKey", apiKey)
+	if l.cfg.APIKeyInHeader {
+		req.Header.Add(apiKeyHeader, apiKey)
+	}
+	req.Header.Add("X-Dd-Hostname", l.cfg.HostName)
+	req.Header.Add("X-Dd-Processagentversion", Version)
+	if env.id != "" {
+		req.Header.Add("X-Dd-Message-Id", env.id)
+		req.Header.Add("X-Dd-Sequence", strconv.FormatUint(env.seq, 10))
+	}
+	if l.cfg.PayloadCompression == config.PayloadCompressionGzip {
+		req.Header.Add("Content-Encoding", "gzip")
+	}
+}
+
+// runMirror submits the payload copies to the mirror endpoint until stop is closed.
+// Copies left in the queue on shutdown are dropped.
+func (l *Collector) runMirror(stop <-chan struct{}) {
+	for {
+		select {
+		case p := <-l.mirror:
+			l.mirrorMessage(p.endpoint, p.body, p.env)
+		case <-stop:
+			return
+		}
+	}
+}
+
+// mirrorMessage submits a copy of an encoded message to the mirror endpoint. The
+// outcome is only logged and doesn't affect the primary submission in any way.
+func (l *Collector) mirrorMessage(endpoint string, body []byte, env envelope) {
+	u := *l.cfg.MirrorEndpoint
+	u.Path = endpoint
+	req, err := http.NewRequest("POST", u.String(), bytes.NewReader(body))
 	if err != nil {
-		log.Errorf("Unable to encode message: %s", err)
+		log.Debugf("could not create mirror request: %s", err)
+		return
 	}
+	l.addPayloadHeaders(req, l.cfg.MirrorAPIKey, env)
+
+	resp, err := l.httpClient.Do(req)
+	if err != nil {
+		log.Debugf("Error mirroring payload to %s: %s", u.Host, err)
+		return
+	}
+	defer resp.Body.Close()
+	io.Copy(ioutil.Discard, resp.Body)
+	if resp.StatusCode < 200 || resp.StatusCode > 300 {
+		log.Debugf("unexpected response from mirror %s. Status: %s", u.String(), resp.Status)
+	}
+}
+
+// postMessage submits an encoded message to the API endpoint. Failures worth retrying,
+// i.e. network errors and server errors, return a *submissionError and the backend
+// throttling the agent returns a *throttledError, so that the message can be retried
+// later, after the retryDelay when the backend doesn't tell when. The other failures are
+// logged.
+func (l *Collector) postMessage(endpoint string, body []byte, env envelope) error {
 	l.cfg.APIEndpoint.Path = endpoint
 	url := l.cfg.APIEndpoint.String()
 	req, err := http.NewRequest("POST", url, bytes.NewReader(body))
 	if err != nil {
 		log.Errorf("could not create request: %s", err)
-		return
+		return nil
 	}
-	req.Header.Add("X-Dd-APIKey", l.cfg.APIKey)
-	req.Header.Add("X-Dd-Hostname", l.cfg.HostName)
-	req.Header.Add("X-Dd-Processagentversion", Version)
+	l.addPayloadHeaders(req, l.cfg.APIKey, env)
 
 	resp, err := l.httpClient.Do(req)
 	if err != nil {
@@ -187,26 +774,44 @@ func (l *Collector) postMessage(endpoint string, m model.MessageBody) {
 		} else {
 			log.Errorf("Error submitting payload: %s", err)
 		}
-		return
+		return &submissionError{err}
 	}
 
 	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusTooManyRequests {
+		io.Copy(ioutil.Discard, resp.Body)
+		if d, ok := parseRetryAfter(resp.Header.Get("Retry-After"), time.Now()); ok {
+			if d > maxRetryAfter {
+				d = maxRetryAfter
+			}
+			return &throttledError{retryAfter: d}
+		}
+		log.Warnf("Missing or invalid Retry-After in the throttled response from %s, retrying after %s", url, l.retryDelay)
+		return &throttledError{retryAfter: l.retryDelay}
+	}
 	if resp.StatusCode < 200 || resp.StatusCode > 300 {
 		log.Errorf("unexpected response from %s. Status: %s", url, resp.Status)
 		io.Copy(ioutil.Discard, resp.Body)
-		return
+		if resp.StatusCode >= 500 {
+			return &submissionError{fmt.Errorf("unexpected response status %s", resp.Status)}
+		}
+		return nil
 	}
 
 	body, err = ioutil.ReadAll(resp.Body)
 	if err != nil {
-		log.Errorf("could not decode response body: %s", err)
-		return
+		if isHTTPTimeout(err) {
+			log.Errorf("Timeout detected reading the response body, %s", err)
+		} else {
+			log.Errorf("could not decode response body: %s", err)
+		}
+		return nil
 	}
 
 	r, err := model.DecodeMessage(body)
 	if err != nil {
 		log.Errorf("could not decode response, invalid format: %s", err)
-		return
+		return nil
 	}
 	switch r.Header.Type {
 	case model.TypeResCollector:
@@ -219,6 +824,7 @@ func (l *Collector) postMessage(endpoint string, m model.MessageBody) {
 	default:
 		log.Errorf("unexpected response type: %d", r.Header.Type)
 	}
+	return nil
 }
 
 func (l *Collector) updateStatus(s *model.CollectorStatus) {
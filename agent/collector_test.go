@@ -0,0 +1,745 @@
+package main
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math/rand"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/DataDog/datadog-process-agent/checks"
+	"github.com/DataDog/datadog-process-agent/config"
+	"github.com/DataDog/datadog-process-agent/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestCollector(t *testing.T, serverURL string) *Collector {
+	cfg := config.NewDefaultAgentConfig()
+	u, err := url.Parse(serverURL)
+	assert.NoError(t, err)
+	cfg.APIEndpoint = u
+	cfg.DrainTimeout = time.Second
+
+	return &Collector{
+		send: make(chan checkPayload, cfg.QueueSize),
+		cfg:  cfg,
+	}
+}
+
+func queuePayloads(l *Collector, n int) {
+	for i := 0; i < n; i++ {
+		l.send <- checkPayload{
+			messages: []model.MessageBody{&model.CollectorProc{}},
+			endpoint: "/api/v1/collector",
+			created:  time.Now(),
+		}
+	}
+}
+
+func TestCollectorDrainsQueueOnExit(t *testing.T) {
+	var posted int64
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt64(&posted, 1)
+	}))
+	defer server.Close()
+
+	l := newTestCollector(t, server.URL)
+	queuePayloads(l, 5)
+
+	exit := make(chan bool)
+	close(exit)
+	l.run(exit)
+
+	assert.Equal(t, int64(5), atomic.LoadInt64(&posted))
+	assert.Len(t, l.send, 0)
+}
+
+func TestCollectorDrainTimeout(t *testing.T) {
+	unblock := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-unblock
+	}))
+	defer server.Close()
+	defer close(unblock)
+
+	l := newTestCollector(t, server.URL)
+	l.cfg.DrainTimeout = 100 * time.Millisecond
+	queuePayloads(l, 5)
+
+	exit := make(chan bool)
+	close(exit)
+	start := time.Now()
+	l.run(exit)
+
+	assert.True(t, time.Since(start) < time.Second, "shutdown took %s", time.Since(start))
+}
+
+// stubCheck is a check returning a single empty message, blocking until release is
+// closed if it is set. Unless cooperative is set it ignores cancellation.
+type stubCheck struct {
+	name        string
+	release     chan struct{}
+	cooperative bool
+	err         error
+	runs        int32
+}
+
+func (c *stubCheck) Init(cfg *config.AgentConfig, info *model.SystemInfo) {}
+func (c *stubCheck) Name() string                                         { return c.name }
+func (c *stubCheck) Endpoint() string                                     { return "/api/v1/collector" }
+func (c *stubCheck) RealTime() bool                                       { return false }
+func (c *stubCheck) Run(ctx context.Context, cfg *config.AgentConfig, groupID int32) ([]model.MessageBody, error) {
+	atomic.AddInt32(&c.runs, 1)
+	if c.cooperative {
+		select {
+		case <-c.release:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	} else if c.release != nil {
+		<-c.release
+	}
+	if c.err != nil {
+		return nil, c.err
+	}
+	return []model.MessageBody{&model.CollectorProc{}}, nil
+}
+
+func TestCollectorCheckTimeout(t *testing.T) {
+	assert := assert.New(t)
+	l := newTestCollector(t, "http://localhost")
+	l.cfg.CheckTimeouts["test-slow"] = 50 * time.Millisecond
+	l.inFlight = map[string]chan struct{}{"test-slow": make(chan struct{}, 1)}
+
+	slow := &stubCheck{name: "test-slow", release: make(chan struct{})}
+	start := time.Now()
+	l.runCheck(context.Background(), slow)
+	assert.True(time.Since(start) < time.Second, "check run took %s", time.Since(start))
+	assert.Len(l.send, 0)
+	assert.Equal(int64(1), checks.Stats()["test-slow"].TimeoutCount)
+
+	// The timed out run is still going so the next one is skipped
+	l.runCheck(context.Background(), slow)
+	assert.Len(l.send, 0)
+	assert.Equal(int64(1), checks.Stats()["test-slow"].TimeoutCount)
+
+	// Once it returns the check runs again
+	close(slow.release)
+	for i := 0; i < 100 && len(l.inFlight["test-slow"]) > 0; i++ {
+		time.Sleep(10 * time.Millisecond)
+	}
+	l.runCheck(context.Background(), slow)
+	assert.Len(l.send, 1)
+
+	// Checks without a timeout are unaffected
+	l.runCheck(context.Background(), &stubCheck{name: "test-fast"})
+	assert.Len(l.send, 2)
+	assert.Equal(int64(0), checks.Stats()["test-fast"].TimeoutCount)
+}
+
+func TestCollectorRecordsCheckError(t *testing.T) {
+	assert := assert.New(t)
+	l := newTestCollector(t, "http://localhost")
+
+	l.runCheck(context.Background(), &stubCheck{name: "test-failing", err: fmt.Errorf("unable to read /proc")})
+	assert.Len(l.send, 0)
+	stats := checks.Stats()["test-failing"]
+	assert.Equal("unable to read /proc", stats.LastError)
+	assert.False(stats.LastErrorTime.IsZero())
+	assert.True(stats.LastSuccessTime.IsZero())
+
+	// A successful run keeps the last error around
+	l.runCheck(context.Background(), &stubCheck{name: "test-failing"})
+	assert.Len(l.send, 1)
+	stats = checks.Stats()["test-failing"]
+	assert.Equal("unable to read /proc", stats.LastError)
+	assert.False(stats.LastSuccessTime.IsZero())
+}
+
+func TestCollectorCancelsChecksOnExit(t *testing.T) {
+	l := newTestCollector(t, "http://localhost")
+	check := &stubCheck{name: "test-cancel", release: make(chan struct{}), cooperative: true}
+	defer close(check.release)
+	l.enabledChecks = []checks.Check{check}
+
+	exit := make(chan bool)
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		close(exit)
+	}()
+	start := time.Now()
+	l.run(exit)
+
+	assert.True(t, time.Since(start) < time.Second, "shutdown took %s", time.Since(start))
+	assert.Len(t, l.send, 0)
+}
+
+func TestSampleGroup(t *testing.T) {
+	assert := assert.New(t)
+	for _, rate := range []float64{0, 0.1, 0.25, 0.5, 0.9, 1} {
+		sampled := 0
+		for groupID := int32(0); groupID < 10000; groupID++ {
+			if sampleGroup(groupID, rate) {
+				sampled++
+			}
+		}
+		assert.InDelta(rate*10000, sampled, 200, "rate %v", rate)
+	}
+
+	// The decision is deterministic per group
+	for groupID := int32(-50); groupID < 50; groupID++ {
+		assert.Equal(sampleGroup(groupID, 0.5), sampleGroup(groupID, 0.5))
+	}
+	assert.False(sampleGroup(42, 0))
+	assert.True(sampleGroup(42, 1))
+}
+
+func TestStartDelay(t *testing.T) {
+	r := rand.New(rand.NewSource(42))
+	assert.Equal(t, time.Duration(0), startDelay(r, 0))
+	assert.Equal(t, time.Duration(0), startDelay(r, -time.Second))
+
+	maxJitter := 30 * time.Second
+	var spread bool
+	first := startDelay(r, maxJitter)
+	for i := 0; i < 1000; i++ {
+		d := startDelay(r, maxJitter)
+		assert.True(t, d >= 0 && d < maxJitter, "delay %s out of [0, %s)", d, maxJitter)
+		spread = spread || d != first
+	}
+	assert.True(t, spread)
+}
+
+func TestCollectorMirror(t *testing.T) {
+	var primaryPosts, mirrorPosts int64
+	primary := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt64(&primaryPosts, 1)
+		assert.Equal(t, "primary-key", r.Header.Get("X-Dd-APIKey"))
+	}))
+	defer primary.Close()
+	// The mirror failing doesn't affect the primary submission
+	mirror := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt64(&mirrorPosts, 1)
+		assert.Equal(t, "mirror-key", r.Header.Get("X-Dd-APIKey"))
+		assert.Equal(t, "/api/v1/collector", r.URL.Path)
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer mirror.Close()
+
+	l := newTestCollector(t, primary.URL)
+	l.cfg.APIKey = "primary-key"
+	l.cfg.MirrorEndpoint, _ = url.Parse(mirror.URL)
+	l.cfg.MirrorAPIKey = "mirror-key"
+	l.cfg.MirrorSampleRate = 0.5
+	l.mirror = make(chan mirrorPayload, 100)
+
+	expected := 0
+	for groupID := int32(0); groupID < 20; groupID++ {
+		if sampleGroup(groupID, l.cfg.MirrorSampleRate) {
+			expected += 2
+		}
+		l.postPayload(checkPayload{
+			messages: []model.MessageBody{&model.CollectorProc{}, &model.CollectorProc{}},
+			endpoint: "/api/v1/collector",
+			groupID:  groupID,
+		})
+	}
+	assert.Equal(t, int64(40), atomic.LoadInt64(&primaryPosts))
+	assert.Len(t, l.mirror, expected)
+
+	stop := make(chan struct{})
+	done := make(chan struct{})
+	go func() {
+		l.runMirror(stop)
+		close(done)
+	}()
+	for i := 0; i < 100 && len(l.mirror) > 0; i++ {
+		time.Sleep(10 * time.Millisecond)
+	}
+	close(stop)
+	<-done
+	assert.Equal(t, int64(expected), atomic.LoadInt64(&mirrorPosts))
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2018, 6, 1, 12, 0, 0, 0, time.UTC)
+	for _, tc := range []struct {
+		value    string
+		expected time.Duration
+		ok       bool
+	}{
+		{"120", 2 * time.Minute, true},
+		{" 0 ", 0, true},
+		{"Fri, 01 Jun 2018 12:00:30 GMT", 30 * time.Second, true},
+		{"Fri, 01 Jun 2018 11:00:00 GMT", 0, true},
+		{"", 0, false},
+		{"-1", 0, false},
+		{"soon", 0, false},
+	} {
+		d, ok := parseRetryAfter(tc.value, now)
+		assert.Equal(t, tc.ok, ok, tc.value)
+		assert.Equal(t, tc.expected, d, tc.value)
+	}
+}
+
+func TestCollectorRetryAfter(t *testing.T) {
+	var posts []time.Time
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		posts = append(posts, time.Now())
+		// Throttle the first submission only
+		if len(posts) == 1 {
+			w.Header().Set("Retry-After", "1")
+			w.WriteHeader(http.StatusTooManyRequests)
+		}
+	}))
+	defer server.Close()
+
+	l := newTestCollector(t, server.URL)
+	l.postPayload(checkPayload{
+		messages: []model.MessageBody{&model.CollectorProc{}, &model.CollectorProc{}},
+		endpoint: "/api/v1/collector",
+	})
+
+	// The throttled message is retried once the delay is over, then the next one is sent
+	assert.Len(t, posts, 3)
+	assert.True(t, posts[1].Sub(posts[0]) >= 900*time.Millisecond, "retried after %s", posts[1].Sub(posts[0]))
+	assert.True(t, posts[2].Sub(posts[1]) < 500*time.Millisecond)
+}
+
+func TestCollectorThrottledWithoutRetryAfter(t *testing.T) {
+	var posts int64
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt64(&posts, 1) == 1 {
+			w.WriteHeader(http.StatusTooManyRequests)
+		}
+	}))
+	defer server.Close()
+
+	// The message is retried after the default delay instead of being dropped
+	l := newTestCollector(t, server.URL)
+	l.retryDelay = 10 * time.Millisecond
+	l.postPayload(checkPayload{messages: []model.MessageBody{&model.CollectorProc{}}, endpoint: "/api/v1/collector"})
+	assert.Equal(t, int64(2), atomic.LoadInt64(&posts))
+	assert.Equal(t, int64(0), atomic.LoadInt64(&l.dropped))
+}
+
+func TestCollectorThrottledStopping(t *testing.T) {
+	var posts int64
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt64(&posts, 1)
+	}))
+	defer server.Close()
+
+	l := newTestCollector(t, server.URL)
+	l.retryAfter = time.Now().Add(maxRetryAfter)
+	l.stopping = make(chan struct{})
+	go func() {
+		time.Sleep(20 * time.Millisecond)
+		close(l.stopping)
+	}()
+
+	// The throttling delay isn't waited out once the agent stops, the payload is dropped
+	start := time.Now()
+	l.postPayload(checkPayload{messages: []model.MessageBody{&model.CollectorProc{}}, endpoint: "/api/v1/collector"})
+	assert.True(t, time.Since(start) < time.Second, "waited %s", time.Since(start))
+	assert.Equal(t, int64(0), atomic.LoadInt64(&posts))
+	assert.Equal(t, int64(1), atomic.LoadInt64(&l.dropped))
+	assert.Len(t, l.send, 0)
+}
+
+func TestCollectorRetryAfterDropsPayload(t *testing.T) {
+	var posts int64
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt64(&posts, 1)
+		w.Header().Set("Retry-After", "0")
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer server.Close()
+
+	l := newTestCollector(t, server.URL)
+	l.cfg.MaxRetries = 0
+	queuePayloads(l, 1)
+	l.postPayload(<-l.send)
+	assert.Equal(t, int64(maxThrottledAttempts), atomic.LoadInt64(&posts))
+	assert.Len(t, l.send, 0)
+	assert.Equal(t, int64(1), atomic.LoadInt64(&l.dropped))
+}
+
+func TestCollectorMaxRetries(t *testing.T) {
+	var posts []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		posts = append(posts, r.Header.Get("X-Dd-Sequence"))
+		// Accept the first message only, then fail persistently
+		if len(posts) > 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer server.Close()
+
+	l := newTestCollector(t, server.URL)
+	l.cfg.MaxRetries = 2
+	l.send <- l.newPayload([]model.MessageBody{&model.CollectorProc{}, &model.CollectorProc{}}, "/api/v1/collector", 1)
+	for i := 0; i < 3; i++ {
+		assert.Equal(t, int64(0), atomic.LoadInt64(&l.dropped))
+		assert.Len(t, l.send, 1)
+		l.postPayload(<-l.send)
+	}
+
+	// The failed message is retried with the same envelope until it runs out of retries
+	assert.Equal(t, []string{"1", "2", "2", "2"}, posts)
+	assert.Len(t, l.send, 0)
+	assert.Equal(t, int64(1), atomic.LoadInt64(&l.dropped))
+
+	// Network errors are retried too
+	server.Close()
+	l.cfg.MaxRetries = 1
+	queuePayloads(l, 1)
+	l.postPayload(<-l.send)
+	assert.Len(t, l.send, 1)
+	l.postPayload(<-l.send)
+	assert.Len(t, l.send, 0)
+	assert.Equal(t, int64(2), atomic.LoadInt64(&l.dropped))
+}
+
+func TestCollectorRetryQueueFull(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	l := newTestCollector(t, server.URL)
+	queuePayloads(l, l.cfg.QueueSize)
+
+	// A failed payload can't be queued again while the queue is full
+	l.postPayload(checkPayload{messages: []model.MessageBody{&model.CollectorProc{}}, endpoint: "/api/v1/collector"})
+	assert.Len(t, l.send, l.cfg.QueueSize)
+	assert.Equal(t, int64(1), atomic.LoadInt64(&l.dropped))
+}
+
+func TestPayloadType(t *testing.T) {
+	assert.Equal(t, "CollectorConnections", payloadType(checkPayload{messages: []model.MessageBody{&model.CollectorConnections{}}}))
+	assert.Equal(t, "empty", payloadType(checkPayload{}))
+}
+
+// slowBodyServer returns a server answering with a body trickling in a byte at a time,
+// until the client goes away.
+func slowBodyServer() *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		for i := 0; i < 100; i++ {
+			if _, err := w.Write([]byte{0}); err != nil {
+				return
+			}
+			w.(http.Flusher).Flush()
+			time.Sleep(50 * time.Millisecond)
+		}
+	}))
+}
+
+func TestCollectorSubmissionTimeout(t *testing.T) {
+	assert := assert.New(t)
+	server := slowBodyServer()
+	defer server.Close()
+
+	l := newTestCollector(t, server.URL)
+	l.httpClient = http.Client{Timeout: 200 * time.Millisecond}
+	start := time.Now()
+	assert.NoError(l.postMessage("/api/v1/collector", []byte("payload"), envelope{}))
+	assert.True(time.Since(start) < 2*time.Second, "submission took %s", time.Since(start))
+
+	// The deadline is hit while reading the body, after the headers were received
+	resp, err := l.httpClient.Post(server.URL, "application/x-protobuf", bytes.NewReader(nil))
+	assert.NoError(err)
+	defer resp.Body.Close()
+	_, err = ioutil.ReadAll(resp.Body)
+	assert.Error(err)
+	assert.True(isHTTPTimeout(err), "%s", err)
+}
+
+func TestIsHTTPTimeout(t *testing.T) {
+	assert := assert.New(t)
+	server := slowBodyServer()
+	defer server.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	req, err := http.NewRequest("POST", server.URL, nil)
+	assert.NoError(err)
+	resp, err := http.DefaultClient.Do(req.WithContext(ctx))
+	assert.NoError(err)
+	defer resp.Body.Close()
+	_, err = ioutil.ReadAll(resp.Body)
+	assert.True(isHTTPTimeout(err), "%s", err)
+
+	assert.True(isHTTPTimeout(&url.Error{Op: "Post", URL: server.URL, Err: context.DeadlineExceeded}))
+	assert.False(isHTTPTimeout(&url.Error{Op: "Post", URL: server.URL, Err: io.ErrUnexpectedEOF}))
+	assert.False(isHTTPTimeout(context.Canceled))
+}
+
+func TestEncodeMessageCompression(t *testing.T) {
+	assert := assert.New(t)
+	procs := make([]*model.Process, 0, 100)
+	for i := int32(1); i <= 100; i++ {
+		procs = append(procs, &model.Process{
+			Pid:     i,
+			Command: &model.Command{Args: []string{"java", "-Xmx2g", "-jar", "/opt/app/service.jar"}},
+		})
+	}
+	m := &model.CollectorProc{HostName: "host", Processes: procs}
+
+	sizes := make(map[string]int)
+	for _, compression := range []string{config.PayloadCompressionNone, config.PayloadCompressionGzip, config.PayloadCompressionZstd} {
+		body, err := encodeMessage(m, compression)
+		assert.NoError(err)
+		sizes[compression] = len(body)
+
+		if compression == config.PayloadCompressionGzip {
+			r, err := gzip.NewReader(bytes.NewReader(body))
+			assert.NoError(err)
+			body, err = ioutil.ReadAll(r)
+			assert.NoError(err)
+		}
+		decoded, err := model.DecodeMessage(body)
+		assert.NoError(err, compression)
+		assert.Equal(m, decoded.Body, compression)
+	}
+	assert.True(sizes[config.PayloadCompressionGzip] < sizes[config.PayloadCompressionNone]/4, "gzip: %v", sizes)
+	assert.True(sizes[config.PayloadCompressionZstd] < sizes[config.PayloadCompressionNone]/4, "zstd: %v", sizes)
+}
+
+func TestCollectorPayloadCompression(t *testing.T) {
+	for _, compression := range []string{config.PayloadCompressionNone, config.PayloadCompressionGzip, config.PayloadCompressionZstd} {
+		var encodings []string
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			encodings = append(encodings, r.Header.Get("Content-Encoding"))
+			body := io.Reader(r.Body)
+			if r.Header.Get("Content-Encoding") == "gzip" {
+				gz, err := gzip.NewReader(r.Body)
+				assert.NoError(t, err)
+				body = gz
+			}
+			b, err := ioutil.ReadAll(body)
+			assert.NoError(t, err)
+			decoded, err := model.DecodeMessage(b)
+			assert.NoError(t, err)
+			assert.Equal(t, "host", decoded.Body.(*model.CollectorProc).HostName)
+		}))
+
+		l := newTestCollector(t, server.URL)
+		l.cfg.PayloadCompression = compression
+		l.postPayload(checkPayload{
+			messages: []model.MessageBody{&model.CollectorProc{HostName: "host"}},
+			endpoint: "/api/v1/collector",
+		})
+		server.Close()
+
+		expected := ""
+		if compression == config.PayloadCompressionGzip {
+			expected = "gzip"
+		}
+		assert.Equal(t, []string{expected}, encodings, compression)
+	}
+}
+
+func TestCollectorSequence(t *testing.T) {
+	var seqs, ids []string
+	posts := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		seqs = append(seqs, r.Header.Get("X-Dd-Sequence"))
+		ids = append(ids, r.Header.Get("X-Dd-Message-Id"))
+		posts++
+		// Throttle the first submission only
+		if posts == 1 {
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusTooManyRequests)
+		}
+	}))
+	defer server.Close()
+
+	l := newTestCollector(t, server.URL)
+	l.runID = newRunID()
+	first := l.newPayload([]model.MessageBody{&model.CollectorProc{}, &model.CollectorProc{}}, "/api/v1/collector", 1)
+	second := l.newPayload([]model.MessageBody{&model.CollectorProc{}}, "/api/v1/collector", 2)
+	assert.Equal(t, uint64(1), first.firstSeq)
+	assert.Equal(t, uint64(3), second.firstSeq)
+	l.postPayload(first)
+	l.postPayload(second)
+
+	// The throttled message is resent with the same envelope
+	assert.Equal(t, []string{"1", "1", "2", "3"}, seqs)
+	assert.Len(t, ids, 4)
+	assert.Equal(t, l.runID+"-1", ids[0])
+	assert.Equal(t, ids[0], ids[1])
+	assert.NotEqual(t, ids[1], ids[2])
+	assert.NotEqual(t, ids[2], ids[3])
+
+	// Messages queued without an envelope are submitted without one
+	seqs, ids = nil, nil
+	queuePayloads(l, 1)
+	l.postPayload(<-l.send)
+	assert.Equal(t, []string{""}, seqs)
+	assert.NotEqual(t, newRunID(), l.runID)
+}
+
+func TestCollectorBatchManifest(t *testing.T) {
+	assert := assert.New(t)
+	var received []model.MessageBody
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := ioutil.ReadAll(r.Body)
+		assert.NoError(err)
+		m, err := model.DecodeMessage(body)
+		assert.NoError(err)
+		received = append(received, m.Body)
+	}))
+	defer server.Close()
+
+	batches := []model.MessageBody{
+		&model.CollectorConnections{GroupId: 7, GroupSize: 3, Connections: []*model.Connection{{Pid: 1}, {Pid: 2}}},
+		&model.CollectorConnections{GroupId: 7, GroupSize: 3, Connections: []*model.Connection{{Pid: 3}, {Pid: 4}}},
+		&model.CollectorConnections{GroupId: 7, GroupSize: 3, Connections: []*model.Connection{{Pid: 5}}},
+	}
+	l := newTestCollector(t, server.URL)
+	l.cfg.HostName = "web-1"
+
+	// No manifest by default
+	payload := l.newPayload(batches, "/api/v1/collector", 7)
+	assert.Len(payload.messages, 3)
+
+	l.cfg.BatchManifest = true
+	payload = l.newPayload(batches, "/api/v1/collector", 7)
+	assert.Len(payload.messages, 4)
+	// The manifest is told apart from the collected data
+	assert.IsType(&model.CollectorManifest{}, payload.manifest())
+	assert.Equal(batches, payload.data())
+	assert.Equal("CollectorConnections", payloadType(payload))
+	assert.Nil(payload.from(1).manifest())
+	assert.Equal(batches[1:], payload.from(2).data())
+	l.postPayload(payload)
+
+	// The manifest is sent first and matches the batches that follow it
+	assert.Len(received, 4)
+	manifest, ok := received[0].(*model.CollectorManifest)
+	assert.True(ok)
+	assert.Equal(&model.CollectorManifest{
+		HostName:  "web-1",
+		GroupId:   7,
+		GroupSize: 3,
+		ItemCount: 5,
+		BatchType: model.TypeCollectorConnections,
+	}, manifest)
+	for _, b := range received[1:] {
+		assert.Equal(manifest.GroupSize, b.(*model.CollectorConnections).GroupSize)
+	}
+	assert.Equal(int32(len(received)-1), manifest.GroupSize)
+
+	// Check runs without messages have no manifest
+	assert.Empty(l.newPayload(nil, "/api/v1/collector", 8).messages)
+}
+
+func TestCollectorMaxPayloadAge(t *testing.T) {
+	var posted int64
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt64(&posted, 1)
+	}))
+	defer server.Close()
+
+	l := newTestCollector(t, server.URL)
+	l.cfg.MaxPayloadAge = time.Minute
+	stale := l.newPayload([]model.MessageBody{&model.CollectorProc{}}, "/api/v1/collector", 1)
+	stale.created = time.Now().Add(-2 * time.Minute)
+	fresh := l.newPayload([]model.MessageBody{&model.CollectorProc{}}, "/api/v1/collector", 2)
+
+	l.postPayload(stale)
+	l.postPayload(fresh)
+	assert.Equal(t, int64(1), atomic.LoadInt64(&posted))
+	assert.Equal(t, int64(1), atomic.LoadInt64(&l.expired))
+	assert.Equal(t, int64(1), atomic.LoadInt64(&l.dropped))
+
+	// Payloads are submitted whatever their age by default
+	l.cfg.MaxPayloadAge = 0
+	l.postPayload(stale)
+	assert.Equal(t, int64(2), atomic.LoadInt64(&posted))
+	assert.Equal(t, int64(1), atomic.LoadInt64(&l.expired))
+}
+
+func TestCollectorAPIKeyInHeader(t *testing.T) {
+	var headers []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "apikey", r.Header.Get("X-Dd-APIKey"))
+		headers = append(headers, r.Header.Get("DD-API-KEY"))
+	}))
+	defer server.Close()
+
+	l := newTestCollector(t, server.URL)
+	l.cfg.APIKey = "apikey"
+	l.postPayload(l.newPayload([]model.MessageBody{&model.CollectorProc{}}, "/api/v1/collector", 1))
+	l.cfg.APIKeyInHeader = true
+	l.postPayload(l.newPayload([]model.MessageBody{&model.CollectorProc{}}, "/api/v1/collector", 2))
+
+	// The header is only sent when api_key_in_header is set
+	assert.Equal(t, []string{"", "apikey"}, headers)
+}
+
+func TestCollectorDiscardsWithoutAPIKey(t *testing.T) {
+	var posted int64
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt64(&posted, 1)
+	}))
+	defer server.Close()
+
+	l := newTestCollector(t, server.URL)
+	l.discard = true
+	l.postPayload(l.newPayload([]model.MessageBody{&model.CollectorProc{}}, "/api/v1/collector", 1))
+	assert.Equal(t, int64(0), atomic.LoadInt64(&posted))
+	assert.Equal(t, int64(0), atomic.LoadInt64(&l.dropped))
+}
+
+func TestCheckAPIKey(t *testing.T) {
+	cfg := config.NewDefaultAgentConfig()
+	cfg.Enabled = true
+	assert.Equal(t, config.ErrMissingAPIKey, checkAPIKey(cfg))
+
+	// The checks can run without submitting their results
+	cfg.RequireAPIKey = false
+	assert.NoError(t, checkAPIKey(cfg))
+
+	cfg.RequireAPIKey = true
+	cfg.APIKey = "apikey"
+	assert.NoError(t, checkAPIKey(cfg))
+
+	// A disabled agent doesn't need one
+	cfg.APIKey = ""
+	cfg.Enabled = false
+	assert.NoError(t, checkAPIKey(cfg))
+}
+
+func TestCollectorForwarder(t *testing.T) {
+	var paths []string
+	forwarder := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "apikey", r.Header.Get("X-Dd-APIKey"))
+		paths = append(paths, r.URL.Path)
+	}))
+	defer forwarder.Close()
+
+	ddy := &config.YamlAgentConfig{APIKey: "apikey"}
+	ddy.Process.UseForwarder = true
+	ddy.Process.ForwarderAddress = forwarder.Listener.Addr().String()
+	cfg, err := config.NewAgentConfig(nil, ddy)
+	assert.NoError(t, err)
+
+	l := &Collector{
+		send:       make(chan checkPayload, cfg.QueueSize),
+		cfg:        cfg,
+		httpClient: http.Client{Transport: cfg.Transport, Timeout: cfg.SubmissionTimeout},
+	}
+	l.postPayload(l.newPayload([]model.MessageBody{&model.CollectorProc{}}, "/api/v1/collector", 1))
+	assert.Equal(t, []string{"/api/v1/collector"}, paths)
+}
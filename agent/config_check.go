@@ -0,0 +1,29 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"sort"
+
+	"github.com/DataDog/datadog-process-agent/config"
+)
+
+// printConfigSources lists the settings not using their default along with the source
+// of their value, e.g. to tell which of the config files or the environment set it.
+func printConfigSources(w io.Writer, cfg *config.AgentConfig) {
+	names := make([]string, 0, len(cfg.ConfigSources))
+	for name, source := range cfg.ConfigSources {
+		if source != config.SourceDefault {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	fmt.Fprintln(w, "Settings not using their default:")
+	if len(names) == 0 {
+		fmt.Fprintln(w, "  none")
+	}
+	for _, name := range names {
+		fmt.Fprintf(w, "  %s: %s\n", name, cfg.ConfigSources[name])
+	}
+}
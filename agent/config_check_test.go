@@ -0,0 +1,26 @@
+package main
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/DataDog/datadog-process-agent/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPrintConfigSources(t *testing.T) {
+	cfg := config.NewDefaultAgentConfig()
+	var b bytes.Buffer
+	printConfigSources(&b, cfg)
+	assert.Equal(t, "Settings not using their default:\n  none\n", b.String())
+
+	cfg.ConfigSources = map[string]string{
+		"QueueSize":  config.SourceYAML,
+		"APIKey":     config.SourceEnv,
+		"MaxProcFDs": config.SourceDefault,
+		"HostName":   config.SourceDerived,
+	}
+	b.Reset()
+	printConfigSources(&b, cfg)
+	assert.Equal(t, "Settings not using their default:\n  APIKey: env\n  HostName: derived\n  QueueSize: yaml\n", b.String())
+}
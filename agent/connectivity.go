@@ -0,0 +1,109 @@
+package main
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/DataDog/datadog-process-agent/config"
+)
+
+const (
+	// connectivityPath is the endpoint of the API validating the API key of a request.
+	connectivityPath = "/api/v1/validate"
+	// connectivityTimeout bounds the whole connectivity check request.
+	connectivityTimeout = 20 * time.Second
+)
+
+// connectivityResult is the outcome of a connectivity check.
+type connectivityResult struct {
+	url        string
+	statusCode int
+	status     string
+	latency    time.Duration
+	err        error
+	tlsErr     bool
+}
+
+// checkConnectivity makes a single authenticated request to the API endpoint, through
+// the same transport and proxy as the submissions of the collector.
+func checkConnectivity(cfg *config.AgentConfig) connectivityResult {
+	u := *cfg.APIEndpoint
+	u.Path = cfg.CollectorPath + connectivityPath
+	r := connectivityResult{url: u.String()}
+
+	req, err := http.NewRequest("GET", r.url, nil)
+	if err != nil {
+		r.err = err
+		return r
+	}
+	req.Header.Add("X-Dd-APIKey", cfg.APIKey)
+	if cfg.APIKeyInHeader {
+		req.Header.Add(apiKeyHeader, cfg.APIKey)
+	}
+	req.Header.Add("X-Dd-Hostname", cfg.HostName)
+	req.Header.Add("X-Dd-Processagentversion", Version)
+
+	client := http.Client{Transport: cfg.Transport, Timeout: connectivityTimeout}
+	start := time.Now()
+	resp, err := client.Do(req)
+	r.latency = time.Since(start)
+	if err != nil {
+		r.err = err
+		r.tlsErr = isTLSError(err)
+		return r
+	}
+	defer resp.Body.Close()
+	io.Copy(ioutil.Discard, resp.Body)
+	r.statusCode = resp.StatusCode
+	r.status = resp.Status
+	return r
+}
+
+// isTLSError returns true if the request failed during the TLS handshake.
+func isTLSError(err error) bool {
+	for err != nil {
+		switch e := err.(type) {
+		case x509.UnknownAuthorityError, x509.HostnameError, x509.CertificateInvalidError,
+			x509.SystemRootsError, tls.RecordHeaderError:
+			return true
+		case *url.Error:
+			err = e.Err
+		case interface{ Unwrap() error }:
+			err = e.Unwrap()
+		default:
+			return false
+		}
+	}
+	return false
+}
+
+// printConnectivity reports the result of a connectivity check, returning an error if
+// the check failed.
+func printConnectivity(w io.Writer, r connectivityResult) error {
+	fmt.Fprintf(w, "Checking connectivity to %s\n", r.url)
+	if r.err != nil {
+		if r.tlsErr {
+			fmt.Fprintf(w, "  TLS error: %s\n", r.err)
+		} else {
+			fmt.Fprintf(w, "  Error: %s\n", r.err)
+		}
+		return fmt.Errorf("connectivity check failed: %s", r.err)
+	}
+
+	fmt.Fprintf(w, "  Status: %s\n", r.status)
+	fmt.Fprintf(w, "  Latency: %s\n", r.latency)
+	switch {
+	case r.statusCode == http.StatusForbidden || r.statusCode == http.StatusUnauthorized:
+		return fmt.Errorf("connectivity check failed: the API key was rejected")
+	case r.statusCode < 200 || r.statusCode > 300:
+		return fmt.Errorf("connectivity check failed: unexpected status %s", r.status)
+	}
+	fmt.Fprintln(w, "  OK")
+	return nil
+}
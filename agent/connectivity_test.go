@@ -0,0 +1,62 @@
+package main
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/DataDog/datadog-process-agent/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckConnectivity(t *testing.T) {
+	assert := assert.New(t)
+	var paths []string
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.URL.Path)
+		if r.Header.Get("X-Dd-APIKey") != "good" {
+			w.WriteHeader(http.StatusForbidden)
+		}
+	})
+	server := httptest.NewServer(handler)
+	defer server.Close()
+
+	cfg := config.NewDefaultAgentConfig()
+	cfg.APIEndpoint, _ = url.Parse(server.URL)
+	cfg.APIKey = "good"
+	var out bytes.Buffer
+	r := checkConnectivity(cfg)
+	assert.NoError(printConnectivity(&out, r))
+	assert.Equal(http.StatusOK, r.statusCode)
+	assert.True(r.latency > 0)
+	assert.Contains(out.String(), "200 OK")
+	assert.Equal([]string{"/api/v1/validate"}, paths)
+
+	cfg.APIKey = "bad"
+	cfg.CollectorPath = "/intake"
+	out.Reset()
+	r = checkConnectivity(cfg)
+	assert.Error(printConnectivity(&out, r))
+	assert.Equal(http.StatusForbidden, r.statusCode)
+	assert.Contains(out.String(), "403 Forbidden")
+	assert.Equal("/intake/api/v1/validate", paths[1])
+
+	// The certificate of the test server isn't trusted
+	tlsServer := httptest.NewTLSServer(handler)
+	defer tlsServer.Close()
+	cfg.APIEndpoint, _ = url.Parse(tlsServer.URL)
+	out.Reset()
+	r = checkConnectivity(cfg)
+	assert.Error(printConnectivity(&out, r))
+	assert.True(r.tlsErr)
+	assert.Contains(out.String(), "TLS error")
+
+	// Nothing listening
+	cfg.APIEndpoint, _ = url.Parse("http://127.0.0.1:1")
+	out.Reset()
+	r = checkConnectivity(cfg)
+	assert.Error(printConnectivity(&out, r))
+	assert.False(r.tlsErr)
+}
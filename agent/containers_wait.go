@@ -0,0 +1,56 @@
+package main
+
+import (
+	"context"
+	"time"
+
+	log "github.com/cihub/seelog"
+
+	"github.com/DataDog/datadog-process-agent/checks"
+	"github.com/DataDog/datadog-process-agent/util/container"
+)
+
+// containerAccessRetryInterval is how often the access to the containers is retried
+// during the grace period.
+const containerAccessRetryInterval = 5 * time.Second
+
+// canAccessContainers returns true if the containers can be listed from the runtime.
+func canAccessContainers() bool {
+	_, err := container.GetContainers()
+	return err == nil
+}
+
+// deferContainerChecks holds back the container checks until the containers can be
+// accessed, e.g. while a slow container runtime starts, retrying in the background for
+// up to the grace period. The other checks start right away.
+func (l *Collector) deferContainerChecks(grace time.Duration) {
+	l.containersReady = make(chan struct{})
+	l.containerGracePeriod = grace
+}
+
+// waitsForContainers returns true if the check doesn't run until the containers are ready.
+func (l *Collector) waitsForContainers(c checks.Check) bool {
+	return l.containersReady != nil && (c.Name() == checks.Container.Name() || c.Name() == checks.RTContainer.Name())
+}
+
+// awaitContainers retries to access the containers on every tick, closing containersReady
+// once it succeeds. It gives up when expired fires or ctx is done, the container checks
+// then never run. It returns whether the containers are ready.
+func (l *Collector) awaitContainers(ctx context.Context, tick, expired <-chan time.Time, canAccess func() bool) bool {
+	log.Infof("Unable to access the containers, retrying for up to %s before disabling the container checks", l.containerGracePeriod)
+	for {
+		select {
+		case <-tick:
+			if canAccess() {
+				log.Info("The containers are accessible, starting the container checks")
+				close(l.containersReady)
+				return true
+			}
+		case <-expired:
+			log.Warnf("The containers are still not accessible after %s, the container checks are disabled", l.containerGracePeriod)
+			return false
+		case <-ctx.Done():
+			return false
+		}
+	}
+}
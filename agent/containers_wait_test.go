@@ -0,0 +1,98 @@
+package main
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/DataDog/datadog-process-agent/checks"
+)
+
+func isClosed(ch chan struct{}) bool {
+	select {
+	case <-ch:
+		return true
+	default:
+		return false
+	}
+}
+
+func TestAwaitContainers(t *testing.T) {
+	assert := assert.New(t)
+
+	// The runtime becomes available on the third retry
+	l := newTestCollector(t, "http://localhost")
+	l.deferContainerChecks(time.Minute)
+	tick := make(chan time.Time, 3)
+	for i := 0; i < 3; i++ {
+		tick <- time.Now()
+	}
+	attempts := 0
+	delayed := func() bool { attempts++; return attempts == 3 }
+	assert.True(l.awaitContainers(context.Background(), tick, nil, delayed))
+	assert.Equal(3, attempts)
+	assert.True(isClosed(l.containersReady))
+
+	// The runtime never becomes available
+	l.deferContainerChecks(time.Minute)
+	tick = make(chan time.Time, 2)
+	tick <- time.Now()
+	tick <- time.Now()
+	expired := make(chan time.Time, 1)
+	attempts = 0
+	unavailable := func() bool { attempts++; return false }
+	done := make(chan bool)
+	go func() { done <- l.awaitContainers(context.Background(), tick, expired, unavailable) }()
+	for len(tick) > 0 {
+		time.Sleep(time.Millisecond)
+	}
+	expired <- time.Now()
+	assert.False(<-done)
+	assert.Equal(2, attempts)
+	assert.False(isClosed(l.containersReady))
+
+	// The agent exits while waiting
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	assert.False(l.awaitContainers(ctx, nil, nil, unavailable))
+	assert.False(isClosed(l.containersReady))
+}
+
+func TestCollectorDefersContainerChecks(t *testing.T) {
+	assert := assert.New(t)
+	l := newTestCollector(t, "http://localhost")
+	process := &stubCheck{name: checks.Process.Name()}
+	ctr := &stubCheck{name: checks.Container.Name()}
+	l.enabledChecks = []checks.Check{process, ctr}
+	assert.False(l.waitsForContainers(ctr))
+
+	l.deferContainerChecks(time.Hour)
+	assert.True(l.waitsForContainers(ctr))
+	assert.True(l.waitsForContainers(&stubCheck{name: checks.RTContainer.Name()}))
+	assert.False(l.waitsForContainers(process))
+
+	exit := make(chan bool)
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		l.run(exit)
+	}()
+
+	// The other checks start right away, the container ones once the containers are ready
+	waitFor := func(c *stubCheck) bool {
+		for i := 0; i < 200 && atomic.LoadInt32(&c.runs) == 0; i++ {
+			time.Sleep(5 * time.Millisecond)
+		}
+		return atomic.LoadInt32(&c.runs) > 0
+	}
+	assert.True(waitFor(process))
+	assert.Equal(int32(0), atomic.LoadInt32(&ctr.runs))
+	close(l.containersReady)
+	assert.True(waitFor(ctr))
+
+	close(exit)
+	<-stopped
+}
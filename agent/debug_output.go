@@ -0,0 +1,62 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"sync"
+	"time"
+
+	log "github.com/cihub/seelog"
+
+	"github.com/DataDog/datadog-process-agent/model"
+)
+
+// debugOutput writes the collected payloads to a file as newline-delimited JSON, one line
+// per payload, to inspect what is collected without a backend. It is meant for debugging
+// only: the file is never rotated and writing it slows down the checks.
+type debugOutput struct {
+	mu  sync.Mutex
+	f   *os.File
+	enc *json.Encoder
+}
+
+// debugOutputLine is a payload written by debugOutput.
+type debugOutputLine struct {
+	Time     time.Time           `json:"time"`
+	Check    string              `json:"check"`
+	Endpoint string              `json:"endpoint"`
+	GroupID  int32               `json:"group_id"`
+	Manifest model.MessageBody   `json:"manifest,omitempty"`
+	Messages []model.MessageBody `json:"messages"`
+}
+
+// newDebugOutput opens the file the payloads are appended to, creating it if needed.
+func newDebugOutput(path string) (*debugOutput, error) {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		return nil, err
+	}
+	log.Warnf("Writing the collected payloads to %s, debug_output_file is meant for debugging only", path)
+	return &debugOutput{f: f, enc: json.NewEncoder(f)}, nil
+}
+
+// write appends the payload of a run of the given check as a single JSON line.
+func (d *debugOutput) write(check string, p checkPayload) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.enc.Encode(debugOutputLine{
+		Time:     p.created,
+		Check:    check,
+		Endpoint: p.endpoint,
+		GroupID:  p.groupID,
+		Manifest: p.manifest(),
+		Messages: p.data(),
+	})
+}
+
+// Close closes the file.
+func (d *debugOutput) Close() error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.f.Close()
+}
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/DataDog/datadog-process-agent/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCollectorDebugOutput(t *testing.T) {
+	assert := assert.New(t)
+	dir, err := ioutil.TempDir("", "process-agent-debug-output")
+	assert.NoError(err)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "payloads.ndjson")
+
+	l := newTestCollector(t, "http://localhost")
+	l.debugOutput, err = newDebugOutput(path)
+	assert.NoError(err)
+	l.runCheck(context.Background(), &stubCheck{name: "test-debug"})
+	l.runCheck(context.Background(), &stubCheck{name: "test-debug"})
+	assert.NoError(l.debugOutput.Close())
+	// The payloads are still queued for submission
+	assert.Len(l.send, 2)
+
+	b, err := ioutil.ReadFile(path)
+	assert.NoError(err)
+	lines := strings.Split(strings.TrimSuffix(string(b), "\n"), "\n")
+	assert.Len(lines, 2)
+	var groupIDs []int32
+	for _, line := range lines {
+		var payload struct {
+			Check    string            `json:"check"`
+			Endpoint string            `json:"endpoint"`
+			GroupID  int32             `json:"group_id"`
+			Messages []json.RawMessage `json:"messages"`
+		}
+		assert.NoError(json.Unmarshal([]byte(line), &payload))
+		assert.Equal("test-debug", payload.Check)
+		assert.Equal("/api/v1/collector", payload.Endpoint)
+		assert.Len(payload.Messages, 1)
+		groupIDs = append(groupIDs, payload.GroupID)
+	}
+	assert.Equal([]int32{1, 2}, groupIDs)
+}
+
+func TestCollectorDebugOutputManifest(t *testing.T) {
+	assert := assert.New(t)
+	dir, err := ioutil.TempDir("", "process-agent-debug-output")
+	assert.NoError(err)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "payloads.ndjson")
+
+	l := newTestCollector(t, "http://localhost")
+	l.cfg.BatchManifest = true
+	l.debugOutput, err = newDebugOutput(path)
+	assert.NoError(err)
+	l.runCheck(context.Background(), &stubCheck{name: "test-debug"})
+	assert.NoError(l.debugOutput.Close())
+
+	// The manifest is written apart from the collected messages
+	b, err := ioutil.ReadFile(path)
+	assert.NoError(err)
+	var payload struct {
+		Manifest *model.CollectorManifest `json:"manifest"`
+		Messages []json.RawMessage        `json:"messages"`
+	}
+	assert.NoError(json.Unmarshal(b, &payload))
+	assert.Len(payload.Messages, 1)
+	if assert.NotNil(payload.Manifest) {
+		assert.Equal(int32(1), payload.Manifest.GroupSize)
+	}
+}
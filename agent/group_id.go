@@ -0,0 +1,47 @@
+package main
+
+import (
+	crand "crypto/rand"
+	"encoding/binary"
+	"time"
+
+	"github.com/DataDog/datadog-process-agent/config"
+)
+
+// startTimeGroupIDsPerSecond is how many group IDs a second of uptime is given by the
+// start time seed. Restarting the agent skips the group IDs of the downtime.
+const startTimeGroupIDsPerSecond = 16
+
+// groupIDSeed returns the group ID preceding the first check run of an agent started at
+// the given time. The group ID is incremented for each run.
+type groupIDSeed func(start time.Time) int32
+
+// groupIDSeeds maps the seeds of config.AgentConfig.GroupIDSeed to their implementation.
+var groupIDSeeds = map[string]groupIDSeed{
+	config.GroupIDSeedRandom:    randomGroupIDSeed,
+	config.GroupIDSeedStartTime: startTimeGroupIDSeed,
+}
+
+// newGroupIDSeed returns the group ID seed of the config, defaulting to random.
+func newGroupIDSeed(cfg *config.AgentConfig) groupIDSeed {
+	if seed, ok := groupIDSeeds[cfg.GroupIDSeed]; ok {
+		return seed
+	}
+	return randomGroupIDSeed
+}
+
+// randomGroupIDSeed returns a random seed. math/rand isn't seeded, so would give the same
+// group IDs on every start.
+func randomGroupIDSeed(start time.Time) int32 {
+	b := make([]byte, 4)
+	if _, err := crand.Read(b); err != nil {
+		return int32(start.UnixNano())
+	}
+	return int32(binary.LittleEndian.Uint32(b))
+}
+
+// startTimeGroupIDSeed returns a seed increasing with the start time, wrapping around
+// every 8 years.
+func startTimeGroupIDSeed(start time.Time) int32 {
+	return int32(uint32(start.Unix()) * startTimeGroupIDsPerSecond)
+}
@@ -0,0 +1,55 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/DataDog/datadog-process-agent/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStartTimeGroupIDSeed(t *testing.T) {
+	assert := assert.New(t)
+
+	// Agents restarted after running checks at the highest supported rate, including one
+	// restarting right away, never reuse a group ID
+	start := time.Date(2018, 6, 1, 12, 0, 0, 0, time.UTC)
+	seen := make(map[int32]int)
+	for restart, uptime := range []time.Duration{time.Minute, 0, time.Second, time.Hour, 10 * time.Second} {
+		groupID := startTimeGroupIDSeed(start)
+		runs := int(uptime.Seconds()) * startTimeGroupIDsPerSecond
+		for i := 0; i < runs; i++ {
+			groupID++
+			if prev, ok := seen[groupID]; ok {
+				assert.Fail("group ID reused", "group ID %d of restart %d was used by restart %d", groupID, restart, prev)
+			}
+			seen[groupID] = restart
+		}
+		// The next start is at least a second later
+		start = start.Add(uptime + time.Second)
+	}
+
+	assert.Equal(startTimeGroupIDSeed(start)+startTimeGroupIDsPerSecond, startTimeGroupIDSeed(start.Add(time.Second)))
+}
+
+func TestRandomGroupIDSeed(t *testing.T) {
+	start := time.Now()
+	seeds := make(map[int32]struct{})
+	for i := 0; i < 10; i++ {
+		seeds[randomGroupIDSeed(start)] = struct{}{}
+	}
+	assert.True(t, len(seeds) > 1, "the random seeds don't change across restarts")
+}
+
+func TestNewGroupIDSeed(t *testing.T) {
+	assert := assert.New(t)
+	cfg := config.NewDefaultAgentConfig()
+	start := time.Date(2018, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	cfg.GroupIDSeed = config.GroupIDSeedStartTime
+	assert.Equal(startTimeGroupIDSeed(start), newGroupIDSeed(cfg)(start))
+
+	// Unknown seeds default to random
+	cfg.GroupIDSeed = "unknown"
+	assert.NotEqual(newGroupIDSeed(cfg)(start), newGroupIDSeed(cfg)(start))
+}
@@ -13,6 +13,7 @@ import (
 	"sync"
 	"time"
 
+	"github.com/DataDog/datadog-process-agent/checks"
 	"github.com/DataDog/datadog-process-agent/config"
 	"github.com/DataDog/datadog-process-agent/model"
 	"github.com/DataDog/datadog-process-agent/util"
@@ -46,7 +47,8 @@ const (
   Docker socket: {{.Status.DockerSocket}}{{end}}
   Number of processes: {{.Status.ProcessCount}}
   Number of containers: {{.Status.ContainerCount}}
-  Queue length: {{.Status.QueueSize}}
+  Queue length: {{.Status.QueueSize}}{{range $name, $s := .Status.CheckStats}}
+  Check {{$name}}: last success {{formatTime $s.LastSuccessTime}}{{if $s.LastError}}, last error {{formatTime $s.LastErrorTime}}: {{$s.LastError}}{{end}}{{end}}
 
   Logs: {{.Status.Config.LogFile}}{{if .Status.ProxyURL}}
   HttpProxy: {{.Status.ProxyURL}}{{end}}{{if ne .Status.ContainerID ""}}
@@ -148,6 +150,10 @@ func publishQueueSize() interface{} {
 	return infoQueueSize
 }
 
+func publishCheckStats() interface{} {
+	return checks.Stats()
+}
+
 func publishContainerID() interface{} {
 	cgroupFile := "/proc/self/cgroup"
 	if !util.PathExists(cgroupFile) {
@@ -212,6 +218,8 @@ type StatusInfo struct {
 	QueueSize       int                    `json:"queue_size"`
 	ContainerID     string                 `json:"container_id"`
 	ProxyURL        string                 `json:"proxy_url"`
+
+	CheckStats map[string]checks.CheckStats `json:"check_stats"`
 }
 
 func initInfo(conf *config.AgentConfig) error {
@@ -224,6 +232,12 @@ func initInfo(conf *config.AgentConfig) error {
 		"percent": func(v float64) string {
 			return fmt.Sprintf("%02.1f", v*100)
 		},
+		"formatTime": func(t time.Time) string {
+			if t.IsZero() {
+				return "never"
+			}
+			return t.Format("2006-01-02 15:04:05")
+		},
 	}
 	infoOnce.Do(func() {
 		expvar.NewInt("pid").Set(int64(os.Getpid()))
@@ -235,6 +249,7 @@ func initInfo(conf *config.AgentConfig) error {
 		expvar.Publish("container_count", expvar.Func(publishContainerCount))
 		expvar.Publish("queue_size", expvar.Func(publishQueueSize))
 		expvar.Publish("container_id", expvar.Func(publishContainerID))
+		expvar.Publish("check_stats", expvar.Func(publishCheckStats))
 		c := *conf
 		var buf []byte
 		buf, err = json.Marshal(&c)
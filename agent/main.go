@@ -5,17 +5,22 @@ package main
 import (
 	"flag"
 	_ "net/http/pprof"
+	"os"
+
+	log "github.com/cihub/seelog"
 
 	"github.com/DataDog/datadog-process-agent/config"
 )
 
 func main() {
-	flag.StringVar(&opts.configPath, "config", "/etc/datadog-agent/datadog.yaml", "Path to datadog.yaml config")
+	flag.StringVar(&opts.configPath, "config", "/etc/datadog-agent/datadog.yaml", "Path to datadog.yaml config, or to a directory of *.yaml files merged in lexical order")
 	flag.StringVar(&opts.ddConfigPath, "ddconfig", "/etc/dd-agent/datadog.conf", "Path to dd-agent config")
 	flag.StringVar(&opts.pidfilePath, "pid", "", "Path to set pidfile for process")
 	flag.BoolVar(&opts.info, "info", false, "Show info about running process agent and exit")
 	flag.BoolVar(&opts.version, "version", false, "Print the version and exit")
 	flag.StringVar(&opts.check, "check", "", "Run a specific check and print the results. Choose from: process, connections, realtime")
+	flag.BoolVar(&opts.checkConnectivity, "check-connectivity", false, "Make a single request to the configured endpoint to verify connectivity and exit")
+	flag.BoolVar(&opts.checkConfig, "check-config", false, "Load the config, print the source of the settings not using their default and exit")
 	flag.Parse()
 
 	// Set up a default config before parsing config so we log errors nicely.
@@ -28,4 +33,11 @@ func main() {
 
 	// Invoke the Agent
 	runAgent(exit)
+
+	if stop.reload {
+		if err := restartAgent(); err != nil {
+			log.Criticalf("Unable to reload the agent: %s", err)
+			os.Exit(1)
+		}
+	}
 }
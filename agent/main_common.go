@@ -2,6 +2,7 @@ package main
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"net/http"
@@ -20,13 +21,15 @@ import (
 )
 
 var opts struct {
-	configPath   string
-	ddConfigPath string
-	pidfilePath  string
-	debug        bool
-	version      bool
-	check        string
-	info         bool
+	configPath        string
+	ddConfigPath      string
+	pidfilePath       string
+	debug             bool
+	version           bool
+	check             string
+	info              bool
+	checkConnectivity bool
+	checkConfig       bool
 }
 
 // version info sourced from build flags
@@ -82,7 +85,7 @@ func runAgent(exit chan bool) {
 		os.Exit(0)
 	}
 
-	if opts.check == "" && !opts.info && opts.pidfilePath != "" {
+	if opts.check == "" && !opts.info && !opts.checkConnectivity && !opts.checkConfig && opts.pidfilePath != "" {
 		err := pidfile.WritePID(opts.pidfilePath)
 		if err != nil {
 			log.Errorf("Error while writing PID file, exiting: %v", err)
@@ -102,13 +105,20 @@ func runAgent(exit chan bool) {
 		os.Exit(1)
 	}
 
-	yamlConf, err := config.NewYamlIfExists(opts.configPath)
+	var yamlConf *config.YamlAgentConfig
+	if fi, statErr := os.Stat(opts.configPath); statErr == nil && fi.IsDir() {
+		yamlConf, err = config.NewYamlFromDir(opts.configPath)
+	} else {
+		yamlConf, err = config.NewYamlIfExists(opts.configPath)
+	}
 	if err != nil {
 		log.Criticalf("Error reading datadog.yaml: %s", err)
 		os.Exit(1)
 	}
 	if yamlConf != nil {
-		config.SetupDDAgentConfig(opts.configPath)
+		if err := config.SetupDDAgentConfig(yamlConf); err != nil {
+			log.Errorf("Error setting up the datadog-agent config: %s", err)
+		}
 	}
 
 	if err := tagger.Init(); err == nil {
@@ -118,10 +128,24 @@ func runAgent(exit chan bool) {
 	}
 
 	cfg, err := config.NewAgentConfig(agentConf, yamlConf)
-	if err != nil {
-		log.Criticalf("Error parsing config: %s", err)
+	switch err.(type) {
+	case nil:
+	case *config.InvalidIntervalError:
+		// The default interval is used instead, no need to stop the agent
+		log.Warnf("Error parsing config: %s", err)
+	default:
+		if err == config.ErrMissingAPIKey {
+			log.Critical("No API key configured, set api_key in the agent configuration or the DD_API_KEY environment variable")
+		} else {
+			log.Criticalf("Error parsing config: %s", err)
+		}
 		os.Exit(1)
 	}
+	if opts.checkConfig {
+		printConfigSources(os.Stdout, cfg)
+		os.Exit(0)
+	}
+
 	err = initInfo(cfg)
 	if err != nil {
 		log.Criticalf("Error initializing info: %s", err)
@@ -132,6 +156,20 @@ func runAgent(exit chan bool) {
 		os.Exit(1)
 	}
 
+	if opts.checkConnectivity {
+		if err := printConnectivity(os.Stdout, checkConnectivity(cfg)); err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+		os.Exit(0)
+	}
+
+	// Wait for a slow container runtime in the background instead of deciding once at startup
+	awaitContainers := cfg.AwaitContainers() && opts.check == ""
+	if awaitContainers {
+		cfg.Enabled = true
+	}
+
 	// Exit if agent is not enabled and we're not debugging a check.
 	if !cfg.Enabled && opts.check == "" {
 		if yamlConf != nil {
@@ -186,18 +224,59 @@ func runAgent(exit chan bool) {
 		http.ListenAndServe("localhost:6062", nil)
 	}()
 
+	if err := checkAPIKey(cfg); err != nil {
+		log.Critical("No API key configured, set api_key in the agent configuration or the DD_API_KEY environment variable, " +
+			"or set require_api_key to false to run the checks without submitting their results")
+		os.Exit(1)
+	}
+
 	cl, err := NewCollector(cfg)
 	if err != nil {
 		log.Criticalf("Error creating collector: %s", err)
 		os.Exit(1)
 		return
 	}
+	if awaitContainers {
+		cl.deferContainerChecks(cfg.ContainerAccessGracePeriod)
+	}
+
+	if cfg.WatchConfig {
+		if !canReload {
+			log.Warn("watch_config is not supported on this platform, the config files aren't watched")
+		} else if w, err := watchConfig(func() { reloadAgent(exit) }, opts.ddConfigPath, opts.configPath); err != nil {
+			log.Errorf("Unable to watch the config files: %s", err)
+		} else {
+			defer w.Close()
+		}
+	}
+	if cfg.BlacklistFile != nil {
+		if err := cfg.BlacklistFile.Watch(); err != nil {
+			log.Errorf("Unable to watch the blacklist file %s: %s", cfg.BlacklistFile.Path, err)
+		} else {
+			defer cfg.BlacklistFile.Close()
+		}
+	}
+	go handleSignals(exit)
 	cl.run(exit)
 	for range exit {
 
 	}
 }
 
+// checkAPIKey reports an enabled agent without API key, whose submissions would all be
+// rejected. It returns config.ErrMissingAPIKey if require_api_key is set, otherwise the
+// agent runs the checks without submitting their results.
+func checkAPIKey(cfg *config.AgentConfig) error {
+	if cfg.APIKey != "" || !cfg.Enabled {
+		return nil
+	}
+	if cfg.RequireAPIKey {
+		return config.ErrMissingAPIKey
+	}
+	log.Error("No API key configured, the check results won't be submitted until api_key or DD_API_KEY is set")
+	return nil
+}
+
 func debugCheckResults(cfg *config.AgentConfig, check string) error {
 	sysInfo, err := checks.CollectSystemInfo(cfg)
 	if err != nil {
@@ -207,7 +286,8 @@ func debugCheckResults(cfg *config.AgentConfig, check string) error {
 	if check == checks.Connections.Name() {
 		// Connections check requires process-check to have occurred first (for process creation ts)
 		checks.Process.Init(cfg, sysInfo)
-		checks.Process.Run(cfg, 0)
+		checks.Process.Run(context.Background(), cfg, 0)
+		defer checks.Connections.Close()
 	}
 
 	names := make([]string, 0, len(checks.All))
@@ -223,7 +303,7 @@ func debugCheckResults(cfg *config.AgentConfig, check string) error {
 
 func printResults(cfg *config.AgentConfig, ch checks.Check) error {
 	// Run the check once to prime the cache.
-	if _, err := ch.Run(cfg, 0); err != nil {
+	if _, err := ch.Run(context.Background(), cfg, 0); err != nil {
 		return fmt.Errorf("collection error: %s", err)
 	}
 
@@ -238,7 +318,7 @@ func printResults(cfg *config.AgentConfig, ch checks.Check) error {
 	fmt.Printf("\nResults for check %s\n", ch.Name())
 	fmt.Printf("-----------------------------\n\n")
 
-	msgs, err := ch.Run(cfg, 1)
+	msgs, err := ch.Run(context.Background(), cfg, 1)
 	if err != nil {
 		return fmt.Errorf("collection error: %s", err)
 	}
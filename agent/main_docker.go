@@ -26,8 +26,18 @@ func handleSignals(exit chan bool) {
 	for sig := range sigIn {
 		switch sig {
 		case syscall.SIGINT, syscall.SIGTERM:
+			select {
+			case <-exit:
+				// We're already shutting down, don't wait for the queue to be flushed.
+				log.Criticalf("Caught signal '%s' while shutting down; exiting now.", sig)
+				os.Exit(1)
+			default:
+			}
 			log.Criticalf("Caught signal '%s'; terminating.", sig)
-			close(exit)
+			stopAgent(exit)
+		case syscall.SIGHUP:
+			log.Infof("Caught signal '%s'; reloading.", sig)
+			reloadAgent(exit)
 		case syscall.SIGCHLD:
 			// Running docker.GetDockerStat() spins up / kills a new process
 			continue
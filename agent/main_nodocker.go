@@ -22,8 +22,18 @@ func handleSignals(exit chan bool) {
 	for sig := range sigIn {
 		switch sig {
 		case syscall.SIGINT, syscall.SIGTERM:
+			select {
+			case <-exit:
+				// We're already shutting down, don't wait for the queue to be flushed.
+				log.Criticalf("Caught signal '%s' while shutting down; exiting now.", sig)
+				os.Exit(1)
+			default:
+			}
 			log.Criticalf("Caught signal '%s'; terminating.", sig)
-			close(exit)
+			stopAgent(exit)
+		case syscall.SIGHUP:
+			log.Infof("Caught signal '%s'; reloading.", sig)
+			reloadAgent(exit)
 		default:
 			log.Warnf("Caught signal %s; continuing/ignoring.", sig)
 		}
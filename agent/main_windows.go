@@ -61,7 +61,7 @@ func (m *myservice) Execute(args []string, r <-chan svc.ChangeRequest, changes c
 					changes <- svc.Status{State: svc.StopPending}
 					///// FIXME:  Need a way to indicate to rest of service to shut
 					////  down
-					close(exit)
+					stopAgent(exit)
 					break
 				default:
 					elog.Warning(0xc000000A, string(c.Cmd))
@@ -114,11 +114,13 @@ func EnableLoggingToFile() {
 
 // main is the main application entry point
 func main() {
-	flag.StringVar(&opts.configPath, "config", defaultConfigPath, "Path to datadog.yaml config")
+	flag.StringVar(&opts.configPath, "config", defaultConfigPath, "Path to datadog.yaml config, or to a directory of *.yaml files merged in lexical order")
 	flag.StringVar(&opts.ddConfigPath, "ddconfig", defaultOldConfigPath, "Path to dd-agent config")
 	flag.BoolVar(&opts.info, "info", false, "Show info about running process agent and exit")
 	flag.BoolVar(&opts.version, "version", false, "Print the version and exit")
 	flag.StringVar(&opts.check, "check", "", "Run a specific check and print the results. Choose from: process, connections, realtime")
+	flag.BoolVar(&opts.checkConnectivity, "check-connectivity", false, "Make a single request to the configured endpoint to verify connectivity and exit")
+	flag.BoolVar(&opts.checkConfig, "check-config", false, "Load the config, print the source of the settings not using their default and exit")
 
 	// windows-specific options for installing the service, uninstalling the service, etc.
 	flag.BoolVar(&winopts.installService, "install-service", false, "Install the trace agent to the Service Control Manager")
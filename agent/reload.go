@@ -0,0 +1,50 @@
+package main
+
+import (
+	"sync"
+	"time"
+
+	log "github.com/cihub/seelog"
+
+	"github.com/DataDog/datadog-process-agent/config"
+)
+
+var (
+	// configWatchDebounce is how long the config files must be left unchanged before the
+	// agent reloads, so that a config being written in several steps reloads it once.
+	configWatchDebounce = 2 * time.Second
+
+	stop = &agentStop{}
+)
+
+// agentStop records how the agent was stopped, by a signal or a reload.
+type agentStop struct {
+	// Guards the closing of the exit channel
+	once sync.Once
+	// Set when the agent is stopped to be started again with a fresh config,
+	// read once runAgent has returned.
+	reload bool
+}
+
+// stopAgent stops the agent, flushing the queued payloads.
+func stopAgent(exit chan bool) {
+	stop.once.Do(func() { close(exit) })
+}
+
+// reloadAgent stops the agent like stopAgent, to be restarted with the config files read
+// again. Nothing is done if the agent is already stopping.
+func reloadAgent(exit chan bool) {
+	s := stop
+	s.once.Do(func() {
+		s.reload = true
+		close(exit)
+	})
+}
+
+// watchConfig calls reload when any of the config files changes.
+func watchConfig(reload func(), paths ...string) (*config.FileWatcher, error) {
+	return config.NewFileWatcher(paths, configWatchDebounce, func() {
+		log.Info("The config files changed, reloading")
+		reload()
+	})
+}
@@ -0,0 +1,30 @@
+// +build !windows
+
+package main
+
+import (
+	"os"
+	"syscall"
+
+	log "github.com/cihub/seelog"
+)
+
+// canReload is true if the agent can restart itself with restartAgent.
+const canReload = true
+
+// startupEnv is the environment the agent was started with. The config exports some of
+// its settings to the environment, e.g. HOST_PROC, which would otherwise override the
+// edited config files after a restart.
+var startupEnv = os.Environ()
+
+// restartAgent replaces the agent with a new instance started with the same arguments
+// and environment, which reads the config files again. It only returns on failure.
+func restartAgent() error {
+	exe, err := os.Executable()
+	if err != nil {
+		return err
+	}
+	log.Infof("Restarting %s", exe)
+	log.Flush()
+	return syscall.Exec(exe, os.Args, startupEnv)
+}
@@ -0,0 +1,25 @@
+// +build !windows
+
+package main
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/DataDog/datadog-process-agent/config"
+)
+
+func TestRestartEnvIgnoresExportedConfig(t *testing.T) {
+	defer os.Unsetenv("HOST_PROC")
+
+	// Loading the config exports host_proc, which the restarted agent must not inherit
+	var ddy config.YamlAgentConfig
+	ddy.APIKey = "apikey_20"
+	ddy.Process.HostProc = "/host/proc"
+	_, err := config.NewAgentConfig(nil, &ddy)
+	assert.NoError(t, err)
+	assert.Equal(t, "/host/proc", os.Getenv("HOST_PROC"))
+	assert.NotContains(t, startupEnv, "HOST_PROC=/host/proc")
+}
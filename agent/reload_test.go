@@ -0,0 +1,58 @@
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWatchConfig(t *testing.T) {
+	assert := assert.New(t)
+	defer func(d time.Duration) { configWatchDebounce = d }(configWatchDebounce)
+	configWatchDebounce = 50 * time.Millisecond
+
+	dir, err := ioutil.TempDir("", "process-agent-reload")
+	assert.NoError(err)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "datadog.yaml")
+	assert.NoError(ioutil.WriteFile(path, []byte("api_key: apikey_20\n"), 0644))
+
+	var reloads int64
+	w, err := watchConfig(func() { atomic.AddInt64(&reloads, 1) }, filepath.Join(dir, "datadog.conf"), path)
+	assert.NoError(err)
+	defer w.Close()
+
+	// A config written in several steps reloads the agent once
+	for i := 0; i < 3; i++ {
+		assert.NoError(ioutil.WriteFile(path, []byte("api_key: apikey_21\n"), 0644))
+	}
+	time.Sleep(300 * time.Millisecond)
+	assert.Equal(int64(1), atomic.LoadInt64(&reloads))
+}
+
+func TestReloadAgent(t *testing.T) {
+	assert := assert.New(t)
+	defer func(s *agentStop) { stop = s }(stop)
+
+	stop = &agentStop{}
+	exit := make(chan bool)
+	reloadAgent(exit)
+	_, open := <-exit
+	assert.False(open)
+	assert.True(stop.reload)
+	// Stopping again is a no-op
+	stopAgent(exit)
+	reloadAgent(exit)
+
+	// No reload once the agent is stopping
+	stop = &agentStop{}
+	exit = make(chan bool)
+	stopAgent(exit)
+	reloadAgent(exit)
+	assert.False(stop.reload)
+}
@@ -0,0 +1,12 @@
+// +build windows
+
+package main
+
+import "errors"
+
+// canReload is false as the service can't replace itself, it must be restarted instead.
+const canReload = false
+
+func restartAgent() error {
+	return errors.New("reloading the agent is not supported on Windows, restart the service instead")
+}
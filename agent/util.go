@@ -1,17 +1,57 @@
 package main
 
 import (
+	"context"
+	"net/http"
+	"net/url"
+	"strconv"
 	"strings"
+	"time"
 )
 
-// IsTimeout returns true if the error is due to reaching the timeout limit on the http.client
+// IsTimeout returns true if the error is due to reaching the timeout limit on the http.client,
+// or the deadline of the context of the request, including while reading the response body.
 func isHTTPTimeout(err error) bool {
-	if netErr, ok := err.(interface {
-		Timeout() bool
-	}); ok && netErr.Timeout() {
-		return true
-	} else if strings.Contains(err.Error(), "use of closed network connection") { //To deprecate when using GO > 1.5
-		return true
+	for e := err; e != nil; {
+		if e == context.DeadlineExceeded {
+			return true
+		}
+		if netErr, ok := e.(interface {
+			Timeout() bool
+		}); ok && netErr.Timeout() {
+			return true
+		}
+		switch w := e.(type) {
+		case *url.Error:
+			e = w.Err
+		case interface{ Unwrap() error }:
+			e = w.Unwrap()
+		default:
+			e = nil
+		}
 	}
-	return false
+	return strings.Contains(err.Error(), "use of closed network connection") //To deprecate when using GO > 1.5
+}
+
+// parseRetryAfter returns the delay requested by a Retry-After header, which is
+// either a number of seconds or an HTTP-date. Dates in the past yield a zero delay.
+func parseRetryAfter(value string, now time.Time) (time.Duration, bool) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0, false
+	}
+	if secs, err := strconv.Atoi(value); err == nil {
+		if secs < 0 {
+			return 0, false
+		}
+		return time.Duration(secs) * time.Second, true
+	}
+	t, err := http.ParseTime(value)
+	if err != nil {
+		return 0, false
+	}
+	if d := t.Sub(now); d > 0 {
+		return d, true
+	}
+	return 0, true
 }
@@ -146,10 +146,12 @@ func getAllProcesses(cfg *config.AgentConfig) (map[int32]*process.FilledProcess,
 		interval = -1
 	}
 
+	rebuilt := false
 	if interval != -1 {
 		if checkCount%interval == 0 {
 			log.Debugf("Rebuilding process table")
 			rebuildProcessMapFromWMI()
+			rebuilt = true
 		}
 		if checkCount == 0 {
 			log.Infof("windows process arg tracking enabled, will be refreshed every %d checks", interval)
@@ -163,8 +165,10 @@ func getAllProcesses(cfg *config.AgentConfig) (map[int32]*process.FilledProcess,
 		log.Warnf("process arguments disabled; processes will be reported without arguments")
 	}
 
+	run := checkCount
 	checkCount++
 	knownPids := makePidSet()
+	now := time.Now()
 
 	for success := w32.Process32First(allProcsSnap, &pe32); success; success = w32.Process32Next(allProcsSnap, &pe32) {
 		pid := pe32.Th32ProcessID
@@ -234,6 +238,19 @@ func getAllProcesses(cfg *config.AgentConfig) (map[int32]*process.FilledProcess,
 		}
 		ctime := CPU.CreationTime.Nanoseconds() / 1000000
 
+		// Between the rebuilds of the table, refresh the args of the processes young enough
+		// to be in a bucket with a shorter interval
+		if ok && interval != -1 && !rebuilt {
+			age := now.Sub(time.Unix(0, CPU.CreationTime.Nanoseconds()))
+			if refresh := cfg.Windows.ArgsRefreshIntervalFor(age); refresh > 0 && run%refresh == 0 {
+				if err := cp.refreshArgs(pid); err != nil {
+					log.Debugf("could not refresh args for pid %v %v", pid, err)
+				} else {
+					cachedProcesses[pid] = cp
+				}
+			}
+		}
+
 		utime := float64((int64(CPU.UserTime.HighDateTime) << 32) | int64(CPU.UserTime.LowDateTime))
 		stime := float64((int64(CPU.KernelTime.HighDateTime) << 32) | int64(CPU.KernelTime.LowDateTime))
 
@@ -253,7 +270,7 @@ func getAllProcesses(cfg *config.AgentConfig) (map[int32]*process.FilledProcess,
 			NumThreads:  int32(pe32.CntThreads),
 			CtxSwitches: &process.NumCtxSwitchesStat{},
 			MemInfo: &process.MemoryInfoStat{
-				RSS:  pmemcounter.WorkingSetSize,
+				RSS:  memoryUsage(cfg, &pmemcounter),
 				VMS:  pmemcounter.QuotaPagedPoolUsage,
 				Swap: 0,
 			},
@@ -277,6 +294,15 @@ func getAllProcesses(cfg *config.AgentConfig) (map[int32]*process.FilledProcess,
 	return procs, nil
 }
 
+// memoryUsage returns the memory of the process selected by the memory metric of the config.
+// The page file usage of a process is its private bytes, its committed memory.
+func memoryUsage(cfg *config.AgentConfig, mem *process.PROCESS_MEMORY_COUNTERS) uint64 {
+	if cfg.Windows.MemoryMetric == config.WindowsMemoryPrivateBytes {
+		return mem.PagefileUsage
+	}
+	return mem.WorkingSetSize
+}
+
 func getUsernameForProcess(h syscall.Handle) (name string, err error) {
 	name = ""
 	err = nil
@@ -395,16 +421,25 @@ func (cp *cachedProcess) fill(proc *Win32_Process) (err error) {
 	}
 	cp.executablePath = *proc.ExecutablePath
 	cp.commandLine = *proc.CommandLine
-	var parsedargs []string
-	if len(cp.commandLine) == 0 {
-		parsedargs = append(parsedargs, cp.executablePath)
-	} else {
-		parsedargs = parseCmdLineArgs(cp.commandLine)
-	}
-	cp.parsedArgs = parsedargs
+	cp.parseArgs()
 	return
 }
 
+// refreshArgs reads the command line of the process from WMI again.
+func (cp *cachedProcess) refreshArgs(pid uint32) error {
+	proc, err := getWin32Proc(pid)
+	if err != nil {
+		return err
+	}
+	if proc.ExecutablePath == nil || proc.CommandLine == nil {
+		return fmt.Errorf("no command line for pid %d", pid)
+	}
+	cp.executablePath = *proc.ExecutablePath
+	cp.commandLine = *proc.CommandLine
+	cp.parseArgs()
+	return nil
+}
+
 func (cp *cachedProcess) fillFromProcEntry(pe32 *w32.PROCESSENTRY32) (err error) {
 	// 0x1000 is PROCESS_QUERY_LIMITED_INFORMATION, but that constant isn't
 	// defined in syscall
@@ -420,6 +455,11 @@ func (cp *cachedProcess) fillFromProcEntry(pe32 *w32.PROCESSENTRY32) (err error)
 	}
 	cp.commandLine = convertWindowsString(pe32.SzExeFile[:])
 	cp.executablePath = cp.commandLine
+	cp.parseArgs()
+	return
+}
+
+func (cp *cachedProcess) parseArgs() {
 	var parsedargs []string
 	if len(cp.commandLine) == 0 {
 		parsedargs = append(parsedargs, cp.executablePath)
@@ -427,7 +467,6 @@ func (cp *cachedProcess) fillFromProcEntry(pe32 *w32.PROCESSENTRY32) (err error)
 		parsedargs = parseCmdLineArgs(cp.commandLine)
 	}
 	cp.parsedArgs = parsedargs
-	return
 }
 
 func (cp *cachedProcess) close() {
@@ -5,7 +5,10 @@ package checks
 import (
 	"testing"
 
+	"github.com/DataDog/gopsutil/process"
 	"github.com/stretchr/testify/assert"
+
+	"github.com/DataDog/datadog-process-agent/config"
 )
 
 func TestCommandLineSplitting(t *testing.T) {
@@ -15,7 +18,7 @@ func TestCommandLineSplitting(t *testing.T) {
 	}{
 		{
 			input: "\"C:\\Users\\db\\AppData\\Local\\slack\app-3.1.1\\slack.exe\" --type=gpu-process --no-sandbox --supports-dual-gpus=false --gpu-driver-bug-workarounds=7,10,20,21,24,43,76 --disable-gl-extensions=\"GL_KHR_blend_equation_advanced GL_KHR_blend_equation_advanced_coherent\" --gpu-vendor-id=0x10de --gpu-device-id=0x13b2 --gpu-driver-vendor=NVIDIA --gpu-driver-version=22.21.13.8205 --gpu-driver-date=5-1-2017 --gpu-secondary-vendor-ids=0x8086 --gpu-secondary-device-ids=0x191b --service-request-channel-token=2EADF7A9FD7CB01C6A780DE1F8FEF0BB --mojo-platform-channel-handle=1708 /prefetch:2",
-			expected: {
+			expected: []string{
 				"\"C:\\Users\\db\\AppData\\Local\\slack\app-3.1.1\\slack.exe\"",
 				"--type=gpu-process",
 				"--no-sandbox",
@@ -36,7 +39,7 @@ func TestCommandLineSplitting(t *testing.T) {
 		},
 		{
 			input: "\"C:\\Program Files (x86)\\Google\\Chrome\\Application\\chrome.exe\" --type=renderer --field-trial-handle=1592,5674313428440474125,10112982115004747190,131072 --service-pipe-token=E553C13F2DAFB1BDFD9B6F4F2B98B2ED --lang=en-US --enable-offline-auto-reload --enable-offline-auto-reload-visible-only --device-scale-factor=1 --num-raster-threads=4 --enable-main-frame-before-activation --enable-compositor-image-animations --service-request-channel-token=E553C13F2DAFB1BDFD9B6F4F2B98B2ED --renderer-client-id=1103 --mojo-platform-channel-handle=13292 /prefetch:1",
-			expected: {"\"C:\\Program Files (x86)\\Google\\Chrome\\Application\\chrome.exe\"",
+			expected: []string{"\"C:\\Program Files (x86)\\Google\\Chrome\\Application\\chrome.exe\"",
 				"--type=renderer",
 				"--field-trial-handle=1592,5674313428440474125,10112982115004747190,131072",
 				"--service-pipe-token=E553C13F2DAFB1BDFD9B6F4F2B98B2ED",
@@ -78,3 +81,15 @@ func TestWindowsStringConversion(t *testing.T) {
 	}
 
 }
+
+func TestMemoryUsage(t *testing.T) {
+	cfg := config.NewDefaultAgentConfig()
+	mem := &process.PROCESS_MEMORY_COUNTERS{WorkingSetSize: 100, PagefileUsage: 200}
+	assert.Equal(t, uint64(100), memoryUsage(cfg, mem))
+
+	cfg.Windows.MemoryMetric = config.WindowsMemoryPrivateBytes
+	assert.Equal(t, uint64(200), memoryUsage(cfg, mem))
+
+	cfg.Windows.MemoryMetric = config.WindowsMemoryWorkingSet
+	assert.Equal(t, uint64(100), memoryUsage(cfg, mem))
+}
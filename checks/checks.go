@@ -1,6 +1,8 @@
 package checks
 
 import (
+	"context"
+
 	"github.com/DataDog/datadog-process-agent/config"
 	"github.com/DataDog/datadog-process-agent/model"
 )
@@ -9,12 +11,14 @@ import (
 // a specific MessageBody type that will be published to the intake endpoint or
 // processed in another way (e.g. printed for debugging).
 // Before checks are used you must called Init.
+// Run is given a context that is cancelled when the run times out or the agent
+// stops, long running checks should return early once it is done.
 type Check interface {
 	Init(cfg *config.AgentConfig, info *model.SystemInfo)
 	Name() string
 	Endpoint() string
 	RealTime() bool
-	Run(cfg *config.AgentConfig, groupID int32) ([]model.MessageBody, error)
+	Run(ctx context.Context, cfg *config.AgentConfig, groupID int32) ([]model.MessageBody, error)
 }
 
 // All is all the singleton check instances.
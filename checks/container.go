@@ -3,6 +3,7 @@
 package checks
 
 import (
+	"context"
 	"runtime"
 	"time"
 
@@ -22,12 +23,14 @@ var Container = &ContainerCheck{}
 // ContainerCheck is a check that returns container metadata and stats.
 type ContainerCheck struct {
 	sysInfo        *model.SystemInfo
+	endpointPrefix string
 	lastContainers []*docker.Container
 	lastRun        time.Time
 }
 
 // Init initializes a ContainerCheck instance.
 func (c *ContainerCheck) Init(cfg *config.AgentConfig, info *model.SystemInfo) {
+	c.endpointPrefix = cfg.CollectorPath
 	c.sysInfo = info
 }
 
@@ -35,19 +38,20 @@ func (c *ContainerCheck) Init(cfg *config.AgentConfig, info *model.SystemInfo) {
 func (c *ContainerCheck) Name() string { return "container" }
 
 // Endpoint returns the endpoint where this check is submitted.
-func (c *ContainerCheck) Endpoint() string { return "/api/v1/container" }
+func (c *ContainerCheck) Endpoint() string { return c.endpointPrefix + "/api/v1/container" }
 
 // RealTime indicates if this check only runs in real-time mode.
 func (c *ContainerCheck) RealTime() bool { return false }
 
 // Run runs the ContainerCheck to collect a list of running containers and the
 // stats for each container.
-func (c *ContainerCheck) Run(cfg *config.AgentConfig, groupID int32) ([]model.MessageBody, error) {
+func (c *ContainerCheck) Run(ctx context.Context, cfg *config.AgentConfig, groupID int32) ([]model.MessageBody, error) {
 	start := time.Now()
 	containers, err := container.GetContainers()
 	if err != nil {
 		return nil, err
 	}
+	containers = cfg.ContainerFilter.Filter(containers)
 
 	// End check early if this is our first run.
 	if c.lastContainers == nil {
@@ -60,7 +64,7 @@ func (c *ContainerCheck) Run(cfg *config.AgentConfig, groupID int32) ([]model.Me
 	if len(containers) != cfg.MaxPerMessage {
 		groupSize++
 	}
-	chunked := fmtContainers(containers, c.lastContainers, c.lastRun, groupSize)
+	chunked := fmtContainers(containers, c.lastContainers, container.GetLifecycles(containers), container.GetCPUThrottling(containers), container.GetLabelTags(containers, cfg.ContainerLabelTags, cfg.ContainerLabelTagsMax), c.lastRun, groupSize)
 	messages := make([]model.MessageBody, 0, groupSize)
 	totalContainers := float64(0)
 	for i := 0; i < groupSize; i++ {
@@ -71,6 +75,7 @@ func (c *ContainerCheck) Run(cfg *config.AgentConfig, groupID int32) ([]model.Me
 			Containers: chunked[i],
 			GroupId:    groupID,
 			GroupSize:  int32(groupSize),
+			HostTags:   cfg.Tags,
 		})
 	}
 
@@ -86,6 +91,9 @@ func (c *ContainerCheck) Run(cfg *config.AgentConfig, groupID int32) ([]model.Me
 // number of chunks. len(result) MUST EQUAL chunks.
 func fmtContainers(
 	containers, lastContainers []*docker.Container,
+	lifecycles map[string]container.Lifecycle,
+	throttling map[string]container.CPUThrottling,
+	labelTags map[string][]string,
 	lastRun time.Time,
 	chunks int,
 ) [][]*model.Container {
@@ -117,28 +125,37 @@ func fmtContainers(
 			log.Errorf("unable to retrieve tags for container: %s", err)
 			tags = []string{}
 		}
+		tags = append(tags, labelTags[ctr.ID]...)
+		created, started := container.Timestamps(ctr)
 
 		chunk = append(chunk, &model.Container{
-			Id:          ctr.ID,
-			Type:        ctr.Type,
-			CpuLimit:    float32(ctr.CPULimit),
-			UserPct:     calculateCtrPct(ctr.CPU.User, lastCtr.CPU.User, sys2, sys1, cpus, lastRun),
-			SystemPct:   calculateCtrPct(ctr.CPU.System, lastCtr.CPU.System, sys2, sys1, cpus, lastRun),
-			TotalPct:    calculateCtrPct(ctr.CPU.User+ctr.CPU.System, lastCtr.CPU.User+lastCtr.CPU.System, sys2, sys1, cpus, lastRun),
-			MemoryLimit: ctr.MemLimit,
-			MemRss:      ctr.Memory.RSS,
-			MemCache:    ctr.Memory.Cache,
-			Created:     ctr.Created,
-			State:       model.ContainerState(model.ContainerState_value[ctr.State]),
-			Health:      model.ContainerHealth(model.ContainerHealth_value[ctr.Health]),
-			Rbps:        calculateRate(ctr.IO.ReadBytes, lastCtr.IO.ReadBytes, lastRun),
-			Wbps:        calculateRate(ctr.IO.WriteBytes, lastCtr.IO.WriteBytes, lastRun),
-			NetRcvdPs:   calculateRate(ifStats.PacketsRcvd, lastIfStats.PacketsRcvd, lastRun),
-			NetSentPs:   calculateRate(ifStats.PacketsSent, lastIfStats.PacketsSent, lastRun),
-			NetRcvdBps:  calculateRate(ifStats.BytesRcvd, lastIfStats.BytesRcvd, lastRun),
-			NetSentBps:  calculateRate(ifStats.BytesSent, lastIfStats.BytesSent, lastRun),
-			Started:     ctr.StartedAt,
-			Tags:        tags,
+			Id:           ctr.ID,
+			Type:         ctr.Type,
+			ImageDigest:  container.ImageDigest(ctr),
+			CpuLimit:     float32(ctr.CPULimit),
+			UserPct:      calculateCtrPct(ctr.CPU.User, lastCtr.CPU.User, sys2, sys1, cpus, lastRun),
+			SystemPct:    calculateCtrPct(ctr.CPU.System, lastCtr.CPU.System, sys2, sys1, cpus, lastRun),
+			TotalPct:     calculateCtrPct(ctr.CPU.User+ctr.CPU.System, lastCtr.CPU.User+lastCtr.CPU.System, sys2, sys1, cpus, lastRun),
+			MemoryLimit:  ctr.MemLimit,
+			MemRss:       ctr.Memory.RSS,
+			MemCache:     ctr.Memory.Cache,
+			Created:      created,
+			State:        model.ContainerState(model.ContainerState_value[ctr.State]),
+			Health:       model.ContainerHealth(model.ContainerHealth_value[ctr.Health]),
+			Rbps:         calculateRate(ctr.IO.ReadBytes, lastCtr.IO.ReadBytes, lastRun),
+			Wbps:         calculateRate(ctr.IO.WriteBytes, lastCtr.IO.WriteBytes, lastRun),
+			NetRcvdPs:    calculateRate(ifStats.PacketsRcvd, lastIfStats.PacketsRcvd, lastRun),
+			NetSentPs:    calculateRate(ifStats.PacketsSent, lastIfStats.PacketsSent, lastRun),
+			NetRcvdBps:   calculateRate(ifStats.BytesRcvd, lastIfStats.BytesRcvd, lastRun),
+			NetSentBps:   calculateRate(ifStats.BytesSent, lastIfStats.BytesSent, lastRun),
+			Started:      started,
+			Tags:         tags,
+			RestartCount: lifecycles[ctr.ID].RestartCount,
+			OomKilled:    lifecycles[ctr.ID].OOMKilled,
+
+			CpuNrPeriods:     throttling[ctr.ID].NrPeriods,
+			CpuNrThrottled:   throttling[ctr.ID].NrThrottled,
+			CpuThrottledTime: throttling[ctr.ID].ThrottledTime,
 		})
 
 		if len(chunk) == perChunk {
@@ -0,0 +1,113 @@
+// +build docker
+
+package checks
+
+import (
+	"testing"
+	"time"
+
+	"github.com/DataDog/datadog-agent/pkg/util/docker"
+	"github.com/DataDog/datadog-process-agent/model"
+	"github.com/DataDog/datadog-process-agent/util/container"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestContainerLifecycle(t *testing.T) {
+	ctrs := []*docker.Container{
+		makeContainer("foo"),
+		makeContainer("bar"),
+	}
+	lifecycles := map[string]container.Lifecycle{
+		"foo": {RestartCount: 4, OOMKilled: true},
+	}
+
+	chunked := fmtContainers(ctrs, ctrs, lifecycles, nil, nil, time.Now().Add(-5*time.Second), 1)
+	assert.Len(t, chunked[0], 2)
+	assert.Equal(t, int32(4), chunked[0][0].RestartCount)
+	assert.True(t, chunked[0][0].OomKilled)
+
+	// Containers without lifecycle signals keep the defaults
+	assert.Equal(t, int32(0), chunked[0][1].RestartCount)
+	assert.False(t, chunked[0][1].OomKilled)
+}
+
+func TestContainerCPUThrottling(t *testing.T) {
+	ctrs := []*docker.Container{
+		makeContainer("foo"),
+		makeContainer("bar"),
+	}
+	throttling := map[string]container.CPUThrottling{
+		"foo": {NrPeriods: 1200, NrThrottled: 300, ThrottledTime: 45000000000},
+	}
+
+	chunked := fmtContainers(ctrs, ctrs, nil, throttling, nil, time.Now().Add(-5*time.Second), 1)
+	assert.Len(t, chunked[0], 2)
+	assert.Equal(t, uint64(1200), chunked[0][0].CpuNrPeriods)
+	assert.Equal(t, uint64(300), chunked[0][0].CpuNrThrottled)
+	assert.Equal(t, uint64(45000000000), chunked[0][0].CpuThrottledTime)
+
+	// Containers without throttling stats report none
+	assert.Equal(t, uint64(0), chunked[0][1].CpuNrPeriods)
+	assert.Equal(t, uint64(0), chunked[0][1].CpuThrottledTime)
+}
+
+func TestContainerImageDigest(t *testing.T) {
+	digest := "sha256:4f5e6a2d1c8b9a0f7e3d2c1b0a9f8e7d6c5b4a3f2e1d0c9b8a7f6e5d4c3b2a10"
+	pinned := makeContainer("foo")
+	pinned.ImageID = digest
+	unknown := makeContainer("bar")
+
+	chunked := fmtContainers([]*docker.Container{pinned, unknown}, nil, nil, nil, nil, time.Now().Add(-5*time.Second), 1)
+	assert.Equal(t, digest, chunked[0][0].ImageDigest)
+	assert.Equal(t, "", chunked[0][1].ImageDigest)
+}
+
+func TestContainerLabelTags(t *testing.T) {
+	ctrs := []*docker.Container{
+		makeContainer("foo"),
+		makeContainer("bar"),
+	}
+	labelTags := map[string][]string{
+		"foo": {"team:core", "version:1.2"},
+	}
+
+	chunked := fmtContainers(ctrs, ctrs, nil, nil, labelTags, time.Now().Add(-5*time.Second), 1)
+	assert.Subset(t, chunked[0][0].Tags, []string{"team:core", "version:1.2"})
+	assert.NotContains(t, chunked[0][1].Tags, "team:core")
+}
+
+func TestContainerTimestamps(t *testing.T) {
+	// Runtimes report the times in other units than seconds, or only the start time
+	nanos := makeContainer("foo")
+	nanos.Created, nanos.StartedAt = 1536000000000000000, 1536000060000000000
+	startedOnly := makeContainer("bar")
+	startedOnly.Created, startedOnly.StartedAt = 0, 1536000060
+
+	chunked := fmtContainers([]*docker.Container{nanos, startedOnly}, nil, nil, nil, nil, time.Now().Add(-5*time.Second), 1)
+	assert.Equal(t, int64(1536000000), chunked[0][0].Created)
+	assert.Equal(t, int64(1536000060), chunked[0][0].Started)
+	assert.Equal(t, int64(1536000060), chunked[0][1].Created)
+	assert.Equal(t, int64(1536000060), chunked[0][1].Started)
+}
+
+func TestContainerNetworkStats(t *testing.T) {
+	withNetwork := func(id string, bytes uint64) *docker.Container {
+		ctr := makeContainer(id)
+		ctr.Network = docker.ContainerNetStats{{NetworkName: "bridge", BytesSent: bytes, BytesRcvd: bytes, PacketsSent: bytes, PacketsRcvd: bytes}}
+		return ctr
+	}
+	lastRun := time.Now().Add(-5 * time.Second)
+	report := func(cur, last []*docker.Container) (*model.Container, *model.ContainerStat) {
+		return fmtContainers(cur, last, nil, nil, nil, lastRun, 1)[0][0], fmtContainerStats(cur, last, lastRun, 1)[0][0]
+	}
+
+	ctr, stat := report([]*docker.Container{withNetwork("foo", 5000)}, []*docker.Container{withNetwork("foo", 1000)})
+	assert.True(t, ctr.NetSentBps > 0)
+	assert.True(t, stat.NetRcvdPs > 0)
+
+	// Without collect_docker_network the containers are listed without network stats
+	ctr, stat = report([]*docker.Container{makeContainer("foo")}, []*docker.Container{makeContainer("foo")})
+	for _, rate := range []float32{ctr.NetRcvdPs, ctr.NetSentPs, ctr.NetRcvdBps, ctr.NetSentBps, stat.NetRcvdPs, stat.NetSentPs, stat.NetRcvdBps, stat.NetSentBps} {
+		assert.Equal(t, float32(0), rate)
+	}
+}
@@ -3,11 +3,13 @@
 package checks
 
 import (
+	"context"
 	"time"
 
 	"github.com/DataDog/datadog-agent/pkg/util/docker"
 	"github.com/DataDog/datadog-process-agent/config"
 	"github.com/DataDog/datadog-process-agent/model"
+	"github.com/DataDog/datadog-process-agent/util/container"
 )
 
 // Container is a singleton ContainerCheck.
@@ -16,12 +18,14 @@ var Container = &ContainerCheck{}
 // ContainerCheck is a check that returns container metadata and stats.
 type ContainerCheck struct {
 	sysInfo        *model.SystemInfo
+	endpointPrefix string
 	lastContainers []*docker.Container
 	lastRun        time.Time
 }
 
 // Init initializes a ContainerCheck instance.
 func (c *ContainerCheck) Init(cfg *config.AgentConfig, info *model.SystemInfo) {
+	c.endpointPrefix = cfg.CollectorPath
 	c.sysInfo = info
 }
 
@@ -29,14 +33,14 @@ func (c *ContainerCheck) Init(cfg *config.AgentConfig, info *model.SystemInfo) {
 func (c *ContainerCheck) Name() string { return "container" }
 
 // Endpoint returns the endpoint where this check is submitted.
-func (c *ContainerCheck) Endpoint() string { return "/api/v1/container" }
+func (c *ContainerCheck) Endpoint() string { return c.endpointPrefix + "/api/v1/container" }
 
 // RealTime indicates if this check only runs in real-time mode.
 func (c *ContainerCheck) RealTime() bool { return false }
 
 // Run runs the ContainerCheck to collect a list of running containers and the
 // stats for each container.
-func (c *ContainerCheck) Run(cfg *config.AgentConfig, groupID int32) ([]model.MessageBody, error) {
+func (c *ContainerCheck) Run(ctx context.Context, cfg *config.AgentConfig, groupID int32) ([]model.MessageBody, error) {
 
 	return nil, nil
 }
@@ -45,6 +49,9 @@ func (c *ContainerCheck) Run(cfg *config.AgentConfig, groupID int32) ([]model.Me
 // number of chunks. len(result) MUST EQUAL chunks.
 func fmtContainers(
 	containers, lastContainers []*docker.Container,
+	lifecycles map[string]container.Lifecycle,
+	throttling map[string]container.CPUThrottling,
+	labelTags map[string][]string,
 	lastRun time.Time,
 	chunks int,
 ) [][]*model.Container {
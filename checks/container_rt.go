@@ -3,6 +3,7 @@
 package checks
 
 import (
+	"context"
 	"runtime"
 	"time"
 
@@ -18,12 +19,14 @@ var RTContainer = &RTContainerCheck{}
 // RTContainerCheck collects numeric statistics about live containers.
 type RTContainerCheck struct {
 	sysInfo        *model.SystemInfo
+	endpointPrefix string
 	lastContainers []*docker.Container
 	lastRun        time.Time
 }
 
 // Init initializes a RTContainerCheck instance.
 func (r *RTContainerCheck) Init(cfg *config.AgentConfig, sysInfo *model.SystemInfo) {
+	r.endpointPrefix = cfg.CollectorPath
 	r.sysInfo = sysInfo
 }
 
@@ -31,17 +34,18 @@ func (r *RTContainerCheck) Init(cfg *config.AgentConfig, sysInfo *model.SystemIn
 func (r *RTContainerCheck) Name() string { return "rtcontainer" }
 
 // Endpoint returns the endpoint where this check is submitted.
-func (r *RTContainerCheck) Endpoint() string { return "/api/v1/container" }
+func (r *RTContainerCheck) Endpoint() string { return r.endpointPrefix + "/api/v1/container" }
 
 // RealTime indicates if this check only runs in real-time mode.
 func (r *RTContainerCheck) RealTime() bool { return true }
 
 // Run runs the real-time container check getting container-level stats from the Cgroups and Docker APIs.
-func (r *RTContainerCheck) Run(cfg *config.AgentConfig, groupID int32) ([]model.MessageBody, error) {
+func (r *RTContainerCheck) Run(ctx context.Context, cfg *config.AgentConfig, groupID int32) ([]model.MessageBody, error) {
 	containers, err := container.GetContainers()
 	if err != nil {
 		return nil, err
 	}
+	containers = cfg.ContainerFilter.Filter(containers)
 
 	// End check early if this is our first run.
 	if r.lastContainers == nil {
@@ -100,6 +104,7 @@ func fmtContainerStats(
 		lastIfStats := lastCtr.Network.SumInterfaces()
 		cpus := runtime.NumCPU()
 		sys2, sys1 := ctr.CPU.SystemUsage, lastCtr.CPU.SystemUsage
+		_, started := container.Timestamps(ctr)
 		chunk = append(chunk, &model.ContainerStat{
 			Id:         ctr.ID,
 			UserPct:    calculateCtrPct(ctr.CPU.User, lastCtr.CPU.User, sys2, sys1, cpus, lastRun),
@@ -117,7 +122,7 @@ func fmtContainerStats(
 			NetSentBps: calculateRate(ifStats.BytesSent, lastIfStats.BytesSent, lastRun),
 			State:      model.ContainerState(model.ContainerState_value[ctr.State]),
 			Health:     model.ContainerHealth(model.ContainerHealth_value[ctr.Health]),
-			Started:    ctr.StartedAt,
+			Started:    started,
 		})
 		if len(chunk) == perChunk {
 			chunked[i] = chunk
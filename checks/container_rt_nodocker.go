@@ -3,6 +3,7 @@
 package checks
 
 import (
+	"context"
 	"time"
 
 	"github.com/DataDog/datadog-agent/pkg/util/docker"
@@ -16,12 +17,14 @@ var RTContainer = &RTContainerCheck{}
 // RTContainerCheck collects numeric statistics about live containers.
 type RTContainerCheck struct {
 	sysInfo        *model.SystemInfo
+	endpointPrefix string
 	lastContainers []*docker.Container
 	lastRun        time.Time
 }
 
 // Init initializes a RTContainerCheck instance.
 func (r *RTContainerCheck) Init(cfg *config.AgentConfig, sysInfo *model.SystemInfo) {
+	r.endpointPrefix = cfg.CollectorPath
 	r.sysInfo = sysInfo
 }
 
@@ -29,13 +32,13 @@ func (r *RTContainerCheck) Init(cfg *config.AgentConfig, sysInfo *model.SystemIn
 func (r *RTContainerCheck) Name() string { return "rtcontainer" }
 
 // Endpoint returns the endpoint where this check is submitted.
-func (r *RTContainerCheck) Endpoint() string { return "/api/v1/container" }
+func (r *RTContainerCheck) Endpoint() string { return r.endpointPrefix + "/api/v1/container" }
 
 // RealTime indicates if this check only runs in real-time mode.
 func (r *RTContainerCheck) RealTime() bool { return true }
 
 // Run runs the real-time container check getting container-level stats from the Cgroups and Docker APIs.
-func (r *RTContainerCheck) Run(cfg *config.AgentConfig, groupID int32) ([]model.MessageBody, error) {
+func (r *RTContainerCheck) Run(ctx context.Context, cfg *config.AgentConfig, groupID int32) ([]model.MessageBody, error) {
 	return nil, nil
 }
 
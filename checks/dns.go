@@ -0,0 +1,147 @@
+package checks
+
+import (
+	"context"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	log "github.com/cihub/seelog"
+
+	"github.com/DataDog/datadog-process-agent/util/cache"
+)
+
+const (
+	// dnsLookupTimeout bounds a single reverse lookup.
+	dnsLookupTimeout = 250 * time.Millisecond
+	// dnsResolveBudget bounds the total time spent resolving addresses during a check run.
+	dnsResolveBudget = time.Second
+	// dnsMaxConcurrentLookups is the number of lookups in flight at once.
+	dnsMaxConcurrentLookups = 8
+)
+
+// lookupAddrFunc returns the names mapping to the given address, like net.Resolver.LookupAddr.
+type lookupAddrFunc func(ctx context.Context, addr string) ([]string, error)
+
+// reverseDNSResolver resolves IP addresses to hostnames. Results, including failed
+// lookups, are kept in an LRU cache bounded by cache_max_entries so each address is only
+// looked up once.
+type reverseDNSResolver struct {
+	lookup  lookupAddrFunc
+	timeout time.Duration
+	budget  time.Duration
+	cache   *cache.LRU // The hostname of each address, empty for a failed lookup
+}
+
+func newReverseDNSResolver(maxEntries int) *reverseDNSResolver {
+	return &reverseDNSResolver{
+		lookup:  net.DefaultResolver.LookupAddr,
+		timeout: dnsLookupTimeout,
+		budget:  dnsResolveBudget,
+		cache:   cache.NewLRU(maxEntries, 0),
+	}
+}
+
+// Resolve returns the hostnames of the given IPs. Addresses that could not be resolved
+// within the budget are missing from the result and will be retried on the next call.
+func (r *reverseDNSResolver) Resolve(ips []string) map[string]string {
+	names := make(map[string]string, len(ips))
+	seen := make(map[string]struct{}, len(ips))
+	pending := make([]string, 0)
+	now := time.Now()
+	for _, ip := range ips {
+		if _, ok := seen[ip]; ok {
+			continue
+		}
+		seen[ip] = struct{}{}
+
+		if name, ok := r.cache.Get(ip, now); ok {
+			if name != "" {
+				names[ip] = name.(string)
+			}
+			continue
+		}
+		if shouldResolve(ip) {
+			pending = append(pending, ip)
+		}
+	}
+	if len(pending) == 0 {
+		return names
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), r.budget)
+	defer cancel()
+
+	var (
+		mu  sync.Mutex
+		wg  sync.WaitGroup
+		sem = make(chan struct{}, dnsMaxConcurrentLookups)
+	)
+	results := make(map[string]string, len(pending))
+loop:
+	for _, ip := range pending {
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+			break loop
+		}
+		wg.Add(1)
+		go func(ip string) {
+			defer func() { <-sem; wg.Done() }()
+			name, ok := r.lookupOne(ctx, ip)
+			if !ok {
+				return
+			}
+			mu.Lock()
+			results[ip] = name
+			mu.Unlock()
+		}(ip)
+	}
+
+	// Lookups are bounded by the context so waiting here never exceeds the budget
+	// by more than the time it takes for the resolver to notice the cancellation.
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-ctx.Done():
+		log.Debugf("reverse DNS budget of %s exhausted, %d addresses left unresolved", r.budget, len(pending)-len(results))
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	for ip, name := range results {
+		r.cache.Add(ip, name, now)
+		if name != "" {
+			names[ip] = name
+		}
+	}
+	return names
+}
+
+// lookupOne resolves a single address. The returned bool is false if the lookup did not
+// complete in time, in which case the result should not be cached.
+func (r *reverseDNSResolver) lookupOne(ctx context.Context, ip string) (string, bool) {
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	names, err := r.lookup(ctx, ip)
+	if ctx.Err() != nil {
+		return "", false
+	}
+	if err != nil || len(names) == 0 {
+		// Cache the failure so we don't keep looking up addresses without a PTR record
+		return "", true
+	}
+	return strings.TrimSuffix(names[0], "."), true
+}
+
+// shouldResolve returns false for addresses that have no meaningful hostname.
+func shouldResolve(ip string) bool {
+	addr := net.ParseIP(ip)
+	return addr != nil && !addr.IsLoopback() && !addr.IsUnspecified()
+}
@@ -0,0 +1,130 @@
+package checks
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/DataDog/datadog-process-agent/model"
+	"github.com/DataDog/datadog-process-agent/util/cache"
+	"github.com/stretchr/testify/assert"
+)
+
+// stubLookup answers reverse lookups from a fixed table and counts the calls per address.
+// Addresses mapped to "slow" block until the lookup is cancelled.
+type stubLookup struct {
+	sync.Mutex
+	names map[string]string
+	calls map[string]int
+}
+
+func newStubLookup(names map[string]string) *stubLookup {
+	return &stubLookup{names: names, calls: make(map[string]int)}
+}
+
+func (s *stubLookup) LookupAddr(ctx context.Context, addr string) ([]string, error) {
+	s.Lock()
+	s.calls[addr]++
+	name, ok := s.names[addr]
+	s.Unlock()
+
+	if name == "slow" {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}
+	if !ok {
+		return nil, fmt.Errorf("no PTR record for %s", addr)
+	}
+	return []string{name}, nil
+}
+
+func (s *stubLookup) Calls(addr string) int {
+	s.Lock()
+	defer s.Unlock()
+	return s.calls[addr]
+}
+
+func newTestResolver(lookup *stubLookup) *reverseDNSResolver {
+	return &reverseDNSResolver{
+		lookup:  lookup.LookupAddr,
+		timeout: 50 * time.Millisecond,
+		budget:  200 * time.Millisecond,
+		cache:   cache.NewLRU(10, 0),
+	}
+}
+
+func TestReverseDNSResolve(t *testing.T) {
+	assert := assert.New(t)
+	lookup := newStubLookup(map[string]string{
+		"10.0.0.1": "db.example.com.",
+		"10.0.0.2": "web.example.com",
+		"10.0.0.4": "slow",
+	})
+	r := newTestResolver(lookup)
+
+	names := r.Resolve([]string{"10.0.0.1", "10.0.0.2", "10.0.0.1", "10.0.0.3", "10.0.0.4", "127.0.0.1", "0.0.0.0"})
+	assert.Equal(map[string]string{
+		"10.0.0.1": "db.example.com",
+		"10.0.0.2": "web.example.com",
+	}, names)
+
+	// Loopback and unspecified addresses are never looked up
+	assert.Equal(0, lookup.Calls("127.0.0.1"))
+	assert.Equal(0, lookup.Calls("0.0.0.0"))
+
+	// Resolved and failed addresses are cached, timed out ones are retried
+	r.Resolve([]string{"10.0.0.1", "10.0.0.2", "10.0.0.3", "10.0.0.4"})
+	assert.Equal(1, lookup.Calls("10.0.0.1"))
+	assert.Equal(1, lookup.Calls("10.0.0.2"))
+	assert.Equal(1, lookup.Calls("10.0.0.3"))
+	assert.Equal(2, lookup.Calls("10.0.0.4"))
+	assert.Equal(3, r.cache.Len())
+}
+
+func TestReverseDNSResolveBudget(t *testing.T) {
+	ips := make([]string, 0, 50)
+	names := make(map[string]string)
+	for i := 0; i < 50; i++ {
+		ip := fmt.Sprintf("10.0.1.%d", i)
+		ips = append(ips, ip)
+		names[ip] = "slow"
+	}
+	r := newTestResolver(newStubLookup(names))
+	r.timeout = time.Second
+
+	start := time.Now()
+	assert.Empty(t, r.Resolve(ips))
+	assert.True(t, time.Since(start) < 500*time.Millisecond, "resolving took %s", time.Since(start))
+	assert.Equal(t, 0, r.cache.Len())
+}
+
+func TestReverseDNSCacheMaxEntries(t *testing.T) {
+	assert := assert.New(t)
+	lookup := newStubLookup(map[string]string{"10.0.0.1": "a", "10.0.0.2": "b", "10.0.0.3": "c"})
+	r := newTestResolver(lookup)
+	r.cache = cache.NewLRU(2, 0)
+
+	r.Resolve([]string{"10.0.0.1", "10.0.0.2"})
+	r.Resolve([]string{"10.0.0.1", "10.0.0.3"}) // 10.0.0.2 is the least recently used
+	assert.Equal(2, r.cache.Len())
+	assert.Equal(map[string]string{"10.0.0.1": "a", "10.0.0.2": "b"}, r.Resolve([]string{"10.0.0.1", "10.0.0.2"}))
+	assert.Equal(1, lookup.Calls("10.0.0.1"))
+	assert.Equal(2, lookup.Calls("10.0.0.2"))
+}
+
+func TestResolveRemoteHosts(t *testing.T) {
+	c := &ConnectionsCheck{resolver: newTestResolver(newStubLookup(map[string]string{
+		"10.0.0.1": "db.example.com",
+	}))}
+	cxs := []*model.Connection{
+		{Raddr: &model.Addr{Ip: "10.0.0.1", Port: 5432}},
+		{Raddr: &model.Addr{Ip: "10.0.0.2", Port: 80}},
+	}
+	c.resolveRemoteHosts(cxs)
+
+	assert.Equal(t, "db.example.com", cxs[0].RaddrHost)
+	assert.Equal(t, "", cxs[1].RaddrHost)
+	assert.Equal(t, "10.0.0.2", cxs[1].Raddr.Ip)
+}
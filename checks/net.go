@@ -2,32 +2,75 @@ package checks
 
 import (
 	"bytes"
+	"context"
+	"math/rand"
+	"net"
+	"sort"
+	"sync"
 	"time"
 
 	log "github.com/cihub/seelog"
 
 	"github.com/DataDog/datadog-process-agent/config"
 	"github.com/DataDog/datadog-process-agent/model"
+	"github.com/DataDog/datadog-process-agent/util"
 	"github.com/DataDog/tcptracer-bpf/pkg/tracer"
 )
 
 // Connections is a singleton ConnectionsCheck.
 var Connections = &ConnectionsCheck{}
 
+// connectionTracer is the subset of the network tracer used by the ConnectionsCheck.
+type connectionTracer interface {
+	Start()
+	Stop()
+	GetActiveConnections() ([]tracer.ConnectionStats, error)
+}
+
 // ConnectionsCheck collects statistics about live TCP and UDP connections.
 type ConnectionsCheck struct {
-	tracer    *tracer.Tracer
+	// Held by the runs and by Close, so that a run abandoned after its timeout never
+	// uses the tracer or the previous connections while they are released
+	mu sync.Mutex
+	// Tracks the tracer calls still in flight, e.g. of a run given up on its context
+	tracerCalls sync.WaitGroup
+
+	tracer    connectionTracer
 	supported bool
 
+	// Most connections read from the tracer, see config.AgentConfig.ConnectionsMaxTracked
+	maxTracked int
+
 	prevCheckConns []tracer.ConnectionStats
 	prevCheckTime  time.Time
 
+	// Resolves remote addresses to hostnames, nil unless enabled in the config
+	resolver *reverseDNSResolver
+
+	// Looks up the processes of the connections, the ones cached by the process check if nil
+	processSource func(pids []uint32) map[uint32]connectionProcess
+
+	// Prepended to the submission path, see config.AgentConfig.CollectorPath
+	endpointPrefix string
+
+	// Connections sampled from the tracer since the last run, keyed by their byte key.
+	// Only used when the collection interval is shorter than the flush interval.
+	sampleMu      sync.Mutex
+	sampled       map[string]tracer.ConnectionStats
+	sampleDropped int // Connections left out by connections_max_tracked, counted once per sample
+	stopSampling  chan struct{}
+	samplingDone  chan struct{}
+
 	buf *bytes.Buffer // Internal buffer
+
+	// Location of procfs to read the TCP states from, defaults to HOST_PROC
+	hostProc string
 }
 
 // Init initializes a ConnectionsCheck instance.
 func (c *ConnectionsCheck) Init(cfg *config.AgentConfig, sysInfo *model.SystemInfo) {
 	var err error
+	c.endpointPrefix = cfg.CollectorPath
 
 	// Checking whether the current kernel version is supported by the tracer
 	if c.supported, err = tracer.IsTracerSupportedByOS(); err != nil {
@@ -36,22 +79,79 @@ func (c *ConnectionsCheck) Init(cfg *config.AgentConfig, sysInfo *model.SystemIn
 		return
 	}
 
-	t, err := tracer.NewTracer()
-	if err != nil {
+	if err := c.startTracer(cfg); err != nil {
 		log.Errorf("failed to create network tracer: %s", err)
 		return
 	}
+	c.buf = new(bytes.Buffer)
+
+	if cfg.ConnectionsResolveDNS {
+		c.resolver = newReverseDNSResolver(cfg.CacheMaxEntries)
+	}
+
+	if interval := cfg.ConnectionsCollectionInterval; interval > 0 && interval < cfg.CheckInterval(c.Name()) {
+		log.Infof("Sampling connections every %s, flushing every %s", interval, cfg.CheckInterval(c.Name()))
+		c.startSampling(interval)
+	}
+}
+
+// tracerConfig holds the settings the network tracer is created with.
+type tracerConfig struct {
+	maxTrackedConnections int
+}
+
+// newTracer creates the network tracer, replaced in tests. The pinned tracer sizes its
+// eBPF maps when its program is compiled, so the connections beyond a different size are
+// dropped by the check instead, see trackedConnections.
+var newTracer = func(tc tracerConfig) (connectionTracer, error) {
+	if tc.maxTrackedConnections > config.DefaultConnectionsMaxTracked {
+		log.Warnf("connections_max_tracked is set to %d but the network tracer doesn't support resizing its maps yet, at most %d connections are tracked",
+			tc.maxTrackedConnections, config.DefaultConnectionsMaxTracked)
+	}
+	t, err := tracer.NewTracer()
+	if err != nil {
+		return nil, err
+	}
+	return t, nil
+}
 
+// startTracer creates the network tracer from the config and starts it.
+func (c *ConnectionsCheck) startTracer(cfg *config.AgentConfig) error {
+	t, err := newTracer(tracerConfig{maxTrackedConnections: cfg.ConnectionsMaxTracked})
+	if err != nil {
+		return err
+	}
 	c.tracer = t
 	c.tracer.Start()
-	c.buf = new(bytes.Buffer)
+	c.maxTracked = cfg.ConnectionsMaxTracked
+	return nil
+}
+
+// Close stops the network tracer and releases its resources. It is safe to call
+// when the tracer was never started, e.g. on an unsupported OS, and more than once.
+// It waits for the run and the tracer calls in flight, so that the tracer isn't
+// stopped while it is read.
+func (c *ConnectionsCheck) Close() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.tracerCalls.Wait()
+	if c.stopSampling != nil {
+		close(c.stopSampling)
+		<-c.samplingDone
+		c.stopSampling = nil
+	}
+	if c.tracer != nil {
+		c.tracer.Stop()
+		c.tracer = nil
+	}
+	c.prevCheckConns = nil
 }
 
 // Name returns the name of the ConnectionsCheck.
 func (c *ConnectionsCheck) Name() string { return "connections" }
 
 // Endpoint returns the endpoint where this check is submitted.
-func (c *ConnectionsCheck) Endpoint() string { return "/api/v1/collector" }
+func (c *ConnectionsCheck) Endpoint() string { return c.endpointPrefix + "/api/v1/collector" }
 
 // RealTime indicates if this check only runs in real-time mode.
 func (c *ConnectionsCheck) RealTime() bool { return false }
@@ -61,22 +161,47 @@ func (c *ConnectionsCheck) RealTime() bool { return false }
 // this information. For each connection we'll return a `model.Connection`
 // that will be bundled up into a `CollectorConnections`.
 // See agent.proto for the schema of the message and models.
-func (c *ConnectionsCheck) Run(cfg *config.AgentConfig, groupID int32) ([]model.MessageBody, error) {
+func (c *ConnectionsCheck) Run(ctx context.Context, cfg *config.AgentConfig, groupID int32) ([]model.MessageBody, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
 	if !c.supported || c.tracer == nil {
+		if cfg.ConnectionsDebug {
+			log.Debugf("connections check diagnostics: tracer unavailable, supported=%t", c.supported)
+		}
 		return nil, nil
 	}
 
-	start := time.Now()
+	diag := newConnectionsDiagnostics(cfg.ConnectionsDebug)
+	defer diag.log()
 
-	conns, err := c.tracer.GetActiveConnections()
+	start := time.Now()
+	conns, err := c.getActiveConnections(ctx)
+	diag.tracerCall(time.Since(start), len(conns), err)
 	if err != nil {
 		if err == tracer.ErrNotImplemented {
 			return nil, nil
 		}
 		return nil, err
 	}
+	conns, dropped := c.trackedConnections(conns)
+	if c.stopSampling != nil {
+		active := len(conns)
+		var sampleDropped int
+		conns, sampleDropped = c.withSamples(conns)
+		if diag != nil {
+			diag.sampled = len(conns) - active
+		}
+		dropped += sampleDropped
+	}
+	if dropped > 0 {
+		log.Infof("Reached connections_max_tracked, leaving out %d connections", dropped)
+	}
+	diag.drop(dropMaxTracked, dropped)
 
 	if c.prevCheckConns == nil { // End check early if this is our first run.
+		if diag != nil {
+			diag.firstRun = true
+		}
 		c.prevCheckConns = conns
 		c.prevCheckTime = time.Now()
 		return nil, nil
@@ -92,50 +217,319 @@ func (c *ConnectionsCheck) Run(cfg *config.AgentConfig, groupID int32) ([]model.
 		}
 	}
 
-	log.Infof("collected connections in %s", time.Since(start))
-	return batchConnections(cfg, groupID, c.formatConnections(conns, lastConnByKey, c.prevCheckTime)), nil
+	limited, truncated := limitConnections(cfg, conns, lastConnByKey, c.buf, rand.Intn)
+	if truncated > 0 {
+		log.Infof("Reached connections_max, leaving out %d connections", truncated)
+	}
+	diag.drop(dropMaxConnections, truncated)
+	cxs := c.formatConnections(cfg, limited, lastConnByKey, c.prevCheckTime, diag)
+	if cfg.ConnectionsListening {
+		listening := c.formatListeningSockets(cfg, readListeningSockets(c.procRoot()))
+		if diag != nil {
+			diag.listening = len(listening)
+		}
+		cxs = append(cxs, listening...)
+	}
+	if diag != nil {
+		diag.reported = len(cxs)
+	}
+	// All the connections are kept for the next run to tell the long-lived ones apart
+	c.prevCheckConns = conns
+	c.prevCheckTime = time.Now()
+	return batchConnections(cfg, groupID, cxs, truncated), nil
+}
+
+// getActiveConnections returns the connections from the tracer, giving up early
+// with the context error if the context is done before the tracer returns.
+func (c *ConnectionsCheck) getActiveConnections(ctx context.Context) ([]tracer.ConnectionStats, error) {
+	type result struct {
+		conns []tracer.ConnectionStats
+		err   error
+	}
+	done := make(chan result, 1)
+	c.tracerCalls.Add(1)
+	go func(t connectionTracer) {
+		defer c.tracerCalls.Done()
+		conns, err := t.GetActiveConnections()
+		done <- result{conns, err}
+	}(c.tracer)
+
+	select {
+	case r := <-done:
+		return r.conns, r.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// trackedConnections returns the connections within connections_max_tracked and the
+// number of connections dropped beyond it. The tracer returns the connections in no
+// particular order, so the ones that sent and received the most bytes are kept.
+func (c *ConnectionsCheck) trackedConnections(conns []tracer.ConnectionStats) ([]tracer.ConnectionStats, int) {
+	if c.maxTracked <= 0 || len(conns) <= c.maxTracked {
+		return conns, 0
+	}
+	sort.SliceStable(conns, func(i, j int) bool {
+		return conns[i].SendBytes+conns[i].RecvBytes > conns[j].SendBytes+conns[j].RecvBytes
+	})
+	return conns[:c.maxTracked], len(conns) - c.maxTracked
+}
+
+// startSampling samples the connections from the tracer at the given interval until
+// the check is closed, so that the connections closed between two runs are still reported.
+func (c *ConnectionsCheck) startSampling(interval time.Duration) {
+	c.sampled = make(map[string]tracer.ConnectionStats)
+	c.stopSampling = make(chan struct{})
+	c.samplingDone = make(chan struct{})
+	go c.runSampling(c.tracer, interval, c.stopSampling, c.samplingDone)
+}
+
+func (c *ConnectionsCheck) runSampling(t connectionTracer, interval time.Duration, stop <-chan struct{}, done chan<- struct{}) {
+	defer close(done)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	// The check's buffer is used by the runs, so sampling needs its own
+	buf := new(bytes.Buffer)
+	for {
+		select {
+		case <-ticker.C:
+			conns, err := t.GetActiveConnections()
+			if err != nil {
+				log.Debugf("failed to sample connections: %s", err)
+				continue
+			}
+			conns, dropped := c.trackedConnections(conns)
+			c.addSample(conns, buf)
+			c.sampleMu.Lock()
+			c.sampleDropped += dropped
+			c.sampleMu.Unlock()
+		case <-stop:
+			return
+		}
+	}
+}
+
+// addSample records the latest stats of the given connections. No new connection is
+// recorded once connections_max_tracked of them are.
+func (c *ConnectionsCheck) addSample(conns []tracer.ConnectionStats, buf *bytes.Buffer) {
+	c.sampleMu.Lock()
+	defer c.sampleMu.Unlock()
+	for _, conn := range conns {
+		b, err := conn.ByteKey(buf)
+		if err != nil {
+			log.Debugf("failed to create connection byte key: %s", err)
+			continue
+		}
+		if _, ok := c.sampled[string(b)]; !ok && c.maxTracked > 0 && len(c.sampled) >= c.maxTracked {
+			c.sampleDropped++
+			continue
+		}
+		c.sampled[string(b)] = conn
+	}
+}
+
+// withSamples returns the given active connections along with the connections sampled
+// since the last run that are no longer active, and the number of connections left out of
+// the samples. It starts a new sampling period.
+func (c *ConnectionsCheck) withSamples(conns []tracer.ConnectionStats) ([]tracer.ConnectionStats, int) {
+	c.sampleMu.Lock()
+	defer c.sampleMu.Unlock()
+	for _, conn := range conns {
+		if b, err := conn.ByteKey(c.buf); err == nil {
+			delete(c.sampled, string(b))
+		}
+	}
+	for _, conn := range c.sampled {
+		conns = append(conns, conn)
+	}
+	dropped := c.sampleDropped
+	c.sampled = make(map[string]tracer.ConnectionStats)
+	c.sampleDropped = 0
+	return conns, dropped
 }
 
 // Connections are split up into a chunks of at most 100 connections per message to
 // limit the message size on intake.
-func (c *ConnectionsCheck) formatConnections(conns []tracer.ConnectionStats, lastConns map[string]tracer.ConnectionStats, lastCheckTime time.Time) []*model.Connection {
-	// Process create-times required to construct unique process hash keys on the backend
-	createTimeForPID := Process.createTimesforPIDs(connectionPIDs(conns))
+// The connections left out are recorded in diag, which may be nil.
+func (c *ConnectionsCheck) formatConnections(cfg *config.AgentConfig, conns []tracer.ConnectionStats, lastConns map[string]tracer.ConnectionStats, lastCheckTime time.Time, diag *connectionsDiagnostics) []*model.Connection {
+	now := time.Now()
+
+	// Process create-times required to construct unique process hash keys on the backend.
+	// Blacklisted processes have no create-time so their connections are dropped as well.
+	pids := connectionPIDs(conns)
+	createTimeForPID := Process.createTimesforPIDs(cfg, pids)
+	var processes map[uint32]connectionProcess
+	if cfg.ConnectionsProcessName {
+		processes = c.connectionProcesses(pids)
+	}
+
+	// The network namespaces let the connections be joined with the processes per namespace
+	netNs := readNetNamespaces(c.procRoot(), pids)
+	states := readTCPStates(c.procRoot(), conns, netNs)
+
+	var elapsed time.Duration
+	if !lastCheckTime.IsZero() {
+		elapsed = now.Sub(lastCheckTime)
+	}
 
 	cxs := make([]*model.Connection, 0, len(conns))
 	for _, conn := range conns {
 		b, err := conn.ByteKey(c.buf)
 		if err != nil {
 			log.Debugf("failed to create connection byte key: %s", err)
+			diag.drop(dropByteKey, 1)
 			continue
 		}
 
 		if _, ok := createTimeForPID[conn.Pid]; !ok {
+			diag.drop(dropUnknownProcess, 1)
+			continue
+		}
+
+		laddr, raddr := normalizeIP(conn.Source), normalizeIP(conn.Dest)
+		if laddr == "" || raddr == "" {
+			log.Debugf("dropping connection with invalid address %s -> %s", conn.Source, conn.Dest)
+			diag.drop(dropInvalidAddress, 1)
+			continue
+		}
+		if !cfg.ConnectionsReportLoopback && isLoopback(raddr) {
+			diag.drop(dropLoopback, 1)
+			continue
+		}
+		if excludedConnection(cfg, laddr, raddr) {
+			diag.drop(dropExcludedCIDR, 1)
 			continue
 		}
 
-		key := string(b)
-		cxs = append(cxs, &model.Connection{
+		last, hasLast := lastConns[string(b)]
+		sent, recv := connectionRates(conn, last, hasLast, elapsed)
+		cx := &model.Connection{
 			Pid:           int32(conn.Pid),
 			PidCreateTime: createTimeForPID[conn.Pid],
 			Family:        formatFamily(conn.Family),
 			Type:          formatType(conn.Type),
 			Laddr: &model.Addr{
-				Ip:   conn.Source,
+				Ip:   laddr,
 				Port: int32(conn.SPort),
 			},
 			Raddr: &model.Addr{
-				Ip:   conn.Dest,
+				Ip:   raddr,
 				Port: int32(conn.DPort),
 			},
-			BytesSent:     calculateRate(conn.SendBytes, lastConns[key].SendBytes, lastCheckTime),
-			BytesRecieved: calculateRate(conn.RecvBytes, lastConns[key].RecvBytes, lastCheckTime),
-		})
+			BytesSent:     sent,
+			BytesRecieved: recv,
+
+			TotalBytesSent:     conn.SendBytes,
+			TotalBytesReceived: conn.RecvBytes,
+
+			NetNs: netNs[conn.Pid],
+		}
+		if conn.Type == tracer.TCP {
+			cx.TcpState = states.state(conn, laddr, raddr)
+		}
+		if cp, ok := processes[conn.Pid]; ok {
+			cx.ProcessName = cp.name
+			cx.ProcessCmdline = cp.cmdline
+		}
+		cxs = append(cxs, cx)
+	}
+
+	if cfg.ConnectionsAggregate {
+		n := len(cxs)
+		cxs = aggregateConnections(cxs)
+		if diag != nil {
+			diag.aggregated = n - len(cxs)
+		}
+	}
+	if c.resolver != nil {
+		c.resolveRemoteHosts(cxs)
 	}
-	c.prevCheckConns = conns
 	return cxs
 }
 
+// connectionProcesses returns the processes of the given pids.
+func (c *ConnectionsCheck) connectionProcesses(pids []uint32) map[uint32]connectionProcess {
+	if c.processSource != nil {
+		return c.processSource(pids)
+	}
+	return Process.processesForPIDs(pids)
+}
+
+func (c *ConnectionsCheck) procRoot() string {
+	if c.hostProc != "" {
+		return c.hostProc
+	}
+	return util.HostProc()
+}
+
+// connectionRates returns the bytes sent and received per second by a connection since
+// its last sample. There is no rate for connections without a previous sample, nor for
+// those whose counters went down, which happens when a closed socket's 4-tuple is
+// reused by a new one in the same process: the new counters become the baseline.
+func connectionRates(conn, last tracer.ConnectionStats, hasLast bool, elapsed time.Duration) (sent, recv float32) {
+	if !hasLast || elapsed <= 0 || conn.SendBytes < last.SendBytes || conn.RecvBytes < last.RecvBytes {
+		return 0, 0
+	}
+	secs := elapsed.Seconds()
+	return float32(float64(conn.SendBytes-last.SendBytes) / secs), float32(float64(conn.RecvBytes-last.RecvBytes) / secs)
+}
+
+// resolveRemoteHosts sets the hostname of the remote address of the given connections.
+// Addresses that could not be resolved are left as IPs.
+func (c *ConnectionsCheck) resolveRemoteHosts(cxs []*model.Connection) {
+	ips := make([]string, 0, len(cxs))
+	for _, cx := range cxs {
+		ips = append(ips, cx.Raddr.Ip)
+	}
+	names := c.resolver.Resolve(ips)
+	for _, cx := range cxs {
+		cx.RaddrHost = names[cx.Raddr.Ip]
+	}
+}
+
+// normalizeIP returns the canonical form of an IP, so that IPv6 addresses are always
+// compressed and IPv4-mapped IPv6 addresses are reported as IPv4. It returns an empty
+// string if the IP is invalid.
+func normalizeIP(ip string) string {
+	addr := net.ParseIP(ip)
+	if addr == nil {
+		return ""
+	}
+	return addr.String()
+}
+
+// isLoopback returns true if addr is a loopback address, including the IPv4-mapped ones.
+func isLoopback(addr string) bool {
+	ip := net.ParseIP(addr)
+	return ip != nil && ip.IsLoopback()
+}
+
+// excludedConnection returns true if the remote address of a connection, or its local
+// address when connections_exclude_local is set, is in connections_exclude_cidrs.
+func excludedConnection(cfg *config.AgentConfig, laddr, raddr string) bool {
+	if len(cfg.ConnectionsExcludeCIDRs) == 0 {
+		return false
+	}
+	if inCIDRs(cfg.ConnectionsExcludeCIDRs, raddr) {
+		return true
+	}
+	return cfg.ConnectionsExcludeLocal && inCIDRs(cfg.ConnectionsExcludeCIDRs, laddr)
+}
+
+func inCIDRs(nets []*net.IPNet, addr string) bool {
+	ip := net.ParseIP(addr)
+	if ip == nil {
+		return false
+	}
+	for _, n := range nets {
+		if n.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
 func formatFamily(f tracer.ConnectionFamily) model.ConnectionFamily {
 	switch f {
 	case tracer.AF_INET:
@@ -158,23 +552,43 @@ func formatType(f tracer.ConnectionType) model.ConnectionType {
 	}
 }
 
-func batchConnections(cfg *config.AgentConfig, groupID int32, cxs []*model.Connection) []model.MessageBody {
-	groupSize := groupSize(len(cxs), cfg.MaxPerMessage)
-	batches := make([]model.MessageBody, 0, groupSize)
-
+func batchConnections(cfg *config.AgentConfig, groupID int32, cxs []*model.Connection, truncated int) []model.MessageBody {
+	// Batches are bounded by both the item count and the serialized size.
+	chunks := make([][]*model.Connection, 0, groupSize(len(cxs), cfg.MaxPerMessage))
 	for len(cxs) > 0 {
-		batchSize := min(cfg.MaxPerMessage, len(cxs))
+		batchSize, batchBytes := 0, 0
+		for _, cx := range cxs[:min(cfg.MaxPerMessage, len(cxs))] {
+			size := cx.Size()
+			if batchSize > 0 && exceedsMessageBytes(cfg, batchBytes+size) {
+				break
+			}
+			batchSize++
+			batchBytes += size
+		}
+		chunks = append(chunks, cxs[:batchSize])
+		cxs = cxs[batchSize:]
+	}
+
+	groupSize := int32(len(chunks))
+	batches := make([]model.MessageBody, 0, groupSize)
+	for _, chunk := range chunks {
 		batches = append(batches, &model.CollectorConnections{
-			HostName:    cfg.HostName,
-			Connections: cxs[:batchSize],
-			GroupId:     groupID,
-			GroupSize:   groupSize,
+			HostName:             cfg.HostName,
+			Connections:          chunk,
+			GroupId:              groupID,
+			GroupSize:            groupSize,
+			TruncatedConnections: int32(truncated),
 		})
-		cxs = cxs[batchSize:]
 	}
 	return batches
 }
 
+// exceedsMessageBytes returns true if a message holding the given number of
+// serialized bytes of items would go over the configured limit.
+func exceedsMessageBytes(cfg *config.AgentConfig, size int) bool {
+	return cfg.MaxMessageBytes > 0 && size > cfg.MaxMessageBytes
+}
+
 func min(a, b int) int {
 	if a < b {
 		return a
@@ -0,0 +1,56 @@
+package checks
+
+import (
+	"github.com/DataDog/datadog-process-agent/model"
+)
+
+// connectionAggregationKey identifies the connections aggregated together: the ones of
+// a process to the same remote endpoint.
+type connectionAggregationKey struct {
+	pid    int32
+	netNs  uint32
+	family model.ConnectionFamily
+	ctype  model.ConnectionType
+	raddr  string
+	rport  int32
+}
+
+// aggregateConnections merges the connections of each process to the same remote address
+// and port into a single connection, summing their bytes and counting their sockets. The
+// local address is the one of the first socket, without the port unless all the sockets
+// share it, and so is the TCP state. The connections are kept in the order of their first socket.
+func aggregateConnections(cxs []*model.Connection) []*model.Connection {
+	aggregated := make([]*model.Connection, 0, len(cxs))
+	byKey := make(map[connectionAggregationKey]*model.Connection, len(cxs))
+	for _, cx := range cxs {
+		key := connectionAggregationKey{
+			pid:    cx.Pid,
+			netNs:  cx.NetNs,
+			family: cx.Family,
+			ctype:  cx.Type,
+			raddr:  cx.Raddr.Ip,
+			rport:  cx.Raddr.Port,
+		}
+		agg, ok := byKey[key]
+		if !ok {
+			agg = cx
+			agg.ConnectionCount = 1
+			byKey[key] = agg
+			aggregated = append(aggregated, agg)
+			continue
+		}
+
+		agg.ConnectionCount++
+		agg.BytesSent += cx.BytesSent
+		agg.BytesRecieved += cx.BytesRecieved
+		agg.TotalBytesSent += cx.TotalBytesSent
+		agg.TotalBytesReceived += cx.TotalBytesReceived
+		if agg.Laddr.Port != cx.Laddr.Port {
+			agg.Laddr.Port = 0
+		}
+		if agg.TcpState != cx.TcpState {
+			agg.TcpState = model.TCPState_unknownTCPState
+		}
+	}
+	return aggregated
+}
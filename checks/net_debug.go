@@ -0,0 +1,98 @@
+package checks
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	log "github.com/cihub/seelog"
+)
+
+// Reasons for leaving out a connection, counted by connectionsDiagnostics.
+const (
+	dropUnknownProcess = "unknown_process" // exited, blacklisted or not seen by the process check
+	dropInvalidAddress = "invalid_address"
+	dropByteKey        = "byte_key"
+	dropMaxConnections = "connections_max"
+	dropMaxTracked     = "connections_max_tracked"
+	dropExcludedCIDR   = "excluded_cidr"
+	dropLoopback       = "loopback"
+)
+
+// connectionsDiagnostics records what happened to the connections during a run of the
+// connections check, logged when connections_debug is enabled to tell why a run reports
+// fewer connections than expected. All its methods are no-ops on a nil *connectionsDiagnostics
+// so that the check doesn't need to test whether debugging is enabled.
+type connectionsDiagnostics struct {
+	tracerTime time.Duration
+	tracerErr  error
+	active     int // Connections returned by the tracer
+	sampled    int // Closed connections added from the samples
+	firstRun   bool
+
+	dropped     map[string]int
+	droppedKeys []string // Order the reasons were first seen in, for stable logs
+
+	aggregated int // Connections merged into others by connections_aggregate
+	listening  int
+	reported   int
+}
+
+// newConnectionsDiagnostics returns the diagnostics of a run, nil if they are not enabled.
+func newConnectionsDiagnostics(enabled bool) *connectionsDiagnostics {
+	if !enabled {
+		return nil
+	}
+	return &connectionsDiagnostics{dropped: make(map[string]int)}
+}
+
+// tracerCall records a call to GetActiveConnections.
+func (d *connectionsDiagnostics) tracerCall(took time.Duration, active int, err error) {
+	if d == nil {
+		return
+	}
+	d.tracerTime, d.active, d.tracerErr = took, active, err
+}
+
+// drop records n connections left out for the given reason.
+func (d *connectionsDiagnostics) drop(reason string, n int) {
+	if d == nil || n <= 0 {
+		return
+	}
+	if _, ok := d.dropped[reason]; !ok {
+		d.droppedKeys = append(d.droppedKeys, reason)
+	}
+	d.dropped[reason] += n
+}
+
+// String formats the diagnostics as space-separated key=value pairs.
+func (d *connectionsDiagnostics) String() string {
+	fields := []string{
+		fmt.Sprintf("tracer_time=%s", d.tracerTime),
+		fmt.Sprintf("active=%d", d.active),
+		fmt.Sprintf("sampled=%d", d.sampled),
+	}
+	if d.tracerErr != nil {
+		fields = append(fields, fmt.Sprintf("tracer_error=%q", d.tracerErr.Error()))
+	}
+	if d.firstRun {
+		fields = append(fields, "first_run=true")
+	}
+	for _, reason := range d.droppedKeys {
+		fields = append(fields, fmt.Sprintf("dropped_%s=%d", reason, d.dropped[reason]))
+	}
+	fields = append(fields,
+		fmt.Sprintf("aggregated=%d", d.aggregated),
+		fmt.Sprintf("listening=%d", d.listening),
+		fmt.Sprintf("reported=%d", d.reported),
+	)
+	return strings.Join(fields, " ")
+}
+
+// log logs the diagnostics of the run at the debug level.
+func (d *connectionsDiagnostics) log() {
+	if d == nil {
+		return
+	}
+	log.Debugf("connections check diagnostics: %s", d)
+}
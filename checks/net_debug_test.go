@@ -0,0 +1,80 @@
+package checks
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/DataDog/datadog-process-agent/config"
+	"github.com/DataDog/gopsutil/process"
+	"github.com/DataDog/tcptracer-bpf/pkg/tracer"
+	log "github.com/cihub/seelog"
+	"github.com/stretchr/testify/assert"
+)
+
+// captureLogs returns a buffer receiving the logs of all levels until the returned
+// function is called.
+func captureLogs(t *testing.T) (*bytes.Buffer, func()) {
+	var buf bytes.Buffer
+	logger, err := log.LoggerFromWriterWithMinLevelAndFormat(&buf, log.DebugLvl, "%Msg%n")
+	assert.NoError(t, err)
+	previous := log.Current
+	assert.NoError(t, log.UseLogger(logger))
+	return &buf, func() { log.UseLogger(previous) }
+}
+
+func TestConnectionsDebug(t *testing.T) {
+	assert := assert.New(t)
+	cfg := config.NewDefaultAgentConfig()
+
+	lastProcs := Process.lastProcs
+	defer func() { Process.lastProcs = lastProcs }()
+	Process.lastProcs = map[int32]*process.FilledProcess{
+		1: makeProcess(1, "nginx -g daemon off;"),
+	}
+
+	st := &scriptedTracer{}
+	st.set(
+		tracer.ConnectionStats{Pid: 1, Source: "10.0.0.1", SPort: 80, Dest: "10.0.0.2", DPort: 50000},
+		tracer.ConnectionStats{Pid: 2, Source: "10.0.0.1", SPort: 22, Dest: "10.0.0.3", DPort: 50001},
+		tracer.ConnectionStats{Pid: 1, Source: "10.0.0.1", SPort: 80, Dest: "not-an-ip", DPort: 50002},
+	)
+	c := &ConnectionsCheck{tracer: st, supported: true, buf: new(bytes.Buffer)}
+
+	logs, restore := captureLogs(t)
+	defer restore()
+	run := func() string {
+		logs.Reset()
+		_, err := c.Run(context.Background(), cfg, 0)
+		assert.NoError(err)
+		return logs.String()
+	}
+
+	// Nothing is logged by default
+	assert.NotContains(run(), "connections check diagnostics")
+
+	cfg.ConnectionsDebug = true
+	c.prevCheckConns = nil
+	out := run()
+	assert.Contains(out, "connections check diagnostics: tracer_time=")
+	assert.Contains(out, "active=3 sampled=0 first_run=true")
+
+	out = run()
+	assert.Contains(out, "active=3 sampled=0 dropped_unknown_process=1 dropped_invalid_address=1 aggregated=0 listening=0 reported=1")
+	assert.NotContains(out, "first_run")
+
+	cfg.MaxConnections = 2
+	assert.Contains(run(), "dropped_connections_max=1")
+
+	c.supported = false
+	assert.Contains(run(), "tracer unavailable")
+}
+
+func TestConnectionsDiagnosticsNil(t *testing.T) {
+	var d *connectionsDiagnostics
+	assert.Nil(t, newConnectionsDiagnostics(false))
+	// All the methods are safe to call when diagnostics are disabled
+	d.tracerCall(0, 1, nil)
+	d.drop(dropByteKey, 1)
+	d.log()
+}
@@ -0,0 +1,60 @@
+package checks
+
+import (
+	"bytes"
+	"sort"
+
+	"github.com/DataDog/tcptracer-bpf/pkg/tracer"
+
+	"github.com/DataDog/datadog-process-agent/config"
+)
+
+// limitConnections caps the connections to report to cfg.MaxConnections. Half of the
+// cap goes to the long-lived connections, the ones already active on the last run,
+// transferring the most bytes. The rest is a random sample of the other connections,
+// so that the short-lived ones are still represented. intn returns a random number
+// in [0, n). It returns the connections to report and the number of connections left out.
+func limitConnections(
+	cfg *config.AgentConfig,
+	conns []tracer.ConnectionStats,
+	lastConns map[string]tracer.ConnectionStats,
+	buf *bytes.Buffer,
+	intn func(n int) int,
+) ([]tracer.ConnectionStats, int) {
+	if cfg.MaxConnections <= 0 || len(conns) <= cfg.MaxConnections {
+		return conns, 0
+	}
+
+	longLived := make([]bool, len(conns))
+	for i, conn := range conns {
+		if b, err := conn.ByteKey(buf); err == nil {
+			_, longLived[i] = lastConns[string(b)]
+		}
+	}
+	sorted := make([]int, len(conns))
+	for i := range sorted {
+		sorted[i] = i
+	}
+	sort.SliceStable(sorted, func(i, j int) bool {
+		ci, cj := sorted[i], sorted[j]
+		if longLived[ci] != longLived[cj] {
+			return longLived[ci]
+		}
+		return conns[ci].SendBytes+conns[ci].RecvBytes > conns[cj].SendBytes+conns[cj].RecvBytes
+	})
+
+	prioritized := (cfg.MaxConnections + 1) / 2
+	limited := make([]tracer.ConnectionStats, 0, cfg.MaxConnections)
+	for _, i := range sorted[:prioritized] {
+		limited = append(limited, conns[i])
+	}
+
+	// Partial Fisher-Yates shuffle of the remaining connections
+	rest := sorted[prioritized:]
+	for i := 0; len(limited) < cfg.MaxConnections; i++ {
+		j := i + intn(len(rest)-i)
+		rest[i], rest[j] = rest[j], rest[i]
+		limited = append(limited, conns[rest[i]])
+	}
+	return limited, len(conns) - cfg.MaxConnections
+}
@@ -0,0 +1,150 @@
+package checks
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"sort"
+	"strconv"
+
+	log "github.com/cihub/seelog"
+
+	"github.com/DataDog/datadog-process-agent/config"
+	"github.com/DataDog/datadog-process-agent/model"
+	"github.com/DataDog/datadog-process-agent/util"
+)
+
+// listeningSocket is a listening TCP socket along with the process owning it.
+type listeningSocket struct {
+	util.ListeningSocket
+	family model.ConnectionFamily
+	pid    uint32
+	netNs  uint32
+}
+
+// readListeningSockets returns the listening TCP sockets of all the processes under
+// procRoot. The tracer only reports the sockets transferring data, so they are read from
+// procfs: the sockets of each network namespace are matched with the socket inodes of the
+// file descriptors of the processes. A socket shared by several processes, e.g. the workers
+// forked by a server, is reported once for the process with the lowest pid.
+func readListeningSockets(procRoot string) []listeningSocket {
+	pids, err := listPIDs(procRoot)
+	if err != nil {
+		log.Debugf("unable to list the processes of %s: %s", procRoot, err)
+		return nil
+	}
+
+	byNamespace := make(map[uint32]map[uint64]listeningSocket)
+	seen := make(map[uint32]map[uint64]struct{})
+	var socks []listeningSocket
+	for _, pid := range pids {
+		ns, err := util.ReadNetNamespace(procRoot, int32(pid))
+		if err != nil {
+			continue
+		}
+		inodes, err := util.ReadSocketInodes(procRoot, int32(pid))
+		if err != nil || len(inodes) == 0 {
+			continue
+		}
+
+		listening, ok := byNamespace[ns]
+		if !ok {
+			listening = readNamespaceListeningSockets(procRoot, pid)
+			byNamespace[ns] = listening
+			seen[ns] = make(map[uint64]struct{})
+		}
+		for _, inode := range inodes {
+			sock, ok := listening[inode]
+			if !ok {
+				continue
+			}
+			if _, ok := seen[ns][inode]; ok {
+				continue
+			}
+			seen[ns][inode] = struct{}{}
+			sock.pid, sock.netNs = pid, ns
+			socks = append(socks, sock)
+		}
+	}
+	return socks
+}
+
+// readNamespaceListeningSockets returns the listening TCP sockets of the network namespace
+// of the given process, keyed by inode.
+func readNamespaceListeningSockets(procRoot string, pid uint32) map[uint64]listeningSocket {
+	pidDir := filepath.Join(procRoot, strconv.Itoa(int(pid)))
+	listening := make(map[uint64]listeningSocket)
+	for f, family := range map[string]model.ConnectionFamily{"tcp": model.ConnectionFamily_v4, "tcp6": model.ConnectionFamily_v6} {
+		socks, err := util.ReadListeningSockets(filepath.Join(pidDir, "net", f))
+		if err != nil {
+			log.Debugf("unable to read listening sockets of pid %d: %s", pid, err)
+			continue
+		}
+		for _, s := range socks {
+			listening[s.Inode] = listeningSocket{ListeningSocket: s, family: family}
+		}
+	}
+	return listening
+}
+
+// listPIDs returns the pids of the processes under procRoot, in ascending order.
+func listPIDs(procRoot string) ([]uint32, error) {
+	entries, err := ioutil.ReadDir(procRoot)
+	if err != nil {
+		return nil, err
+	}
+	pids := make([]uint32, 0, len(entries))
+	for _, e := range entries {
+		if !e.IsDir() {
+			continue
+		}
+		pid, err := strconv.ParseUint(e.Name(), 10, 32)
+		if err != nil {
+			continue
+		}
+		pids = append(pids, uint32(pid))
+	}
+	sort.Slice(pids, func(i, j int) bool { return pids[i] < pids[j] })
+	return pids, nil
+}
+
+// formatListeningSockets returns the listening sockets as connections flagged as listening,
+// without remote address. Like for the other connections, the sockets of the processes
+// unknown to the process check or blacklisted are left out.
+func (c *ConnectionsCheck) formatListeningSockets(cfg *config.AgentConfig, socks []listeningSocket) []*model.Connection {
+	pids := make([]uint32, 0, len(socks))
+	for _, s := range socks {
+		pids = append(pids, s.pid)
+	}
+	createTimeForPID := Process.createTimesforPIDs(cfg, pids)
+	var processes map[uint32]connectionProcess
+	if cfg.ConnectionsProcessName {
+		processes = c.connectionProcesses(pids)
+	}
+
+	cxs := make([]*model.Connection, 0, len(socks))
+	for _, s := range socks {
+		createTime, ok := createTimeForPID[s.pid]
+		if !ok {
+			continue
+		}
+		cx := &model.Connection{
+			Pid:           int32(s.pid),
+			PidCreateTime: createTime,
+			Family:        s.family,
+			Type:          model.ConnectionType_tcp,
+			Laddr: &model.Addr{
+				Ip:   s.IP,
+				Port: int32(s.Port),
+			},
+			TcpState:  model.TCPState_listen,
+			NetNs:     s.netNs,
+			Listening: true,
+		}
+		if cp, ok := processes[s.pid]; ok {
+			cx.ProcessName = cp.name
+			cx.ProcessCmdline = cp.cmdline
+		}
+		cxs = append(cxs, cx)
+	}
+	return cxs
+}
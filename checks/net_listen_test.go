@@ -0,0 +1,91 @@
+package checks
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/DataDog/datadog-process-agent/config"
+	"github.com/DataDog/datadog-process-agent/model"
+	"github.com/DataDog/gopsutil/process"
+	"github.com/stretchr/testify/assert"
+)
+
+// writeSocketFixture creates a file descriptor link of a process to the socket with the given inode.
+func writeSocketFixture(t *testing.T, procRoot, pid, fd, inode string) {
+	dir := filepath.Join(procRoot, pid, "fd")
+	assert.NoError(t, os.MkdirAll(dir, 0755))
+	assert.NoError(t, os.Symlink("socket:["+inode+"]", filepath.Join(dir, fd)))
+}
+
+func TestListeningSockets(t *testing.T) {
+	assert := assert.New(t)
+	procRoot, err := ioutil.TempDir("", "proc")
+	assert.NoError(err)
+	defer os.RemoveAll(procRoot)
+
+	// pids 1 and 2 share a namespace: 0.0.0.0:80 listening, 10.0.0.1:80 -> 10.0.0.2:50000
+	// established, and [::]:443 listening
+	writeNetNsFixture(t, procRoot, "1", "net:[4026531992]")
+	writeNetNsFixture(t, procRoot, "2", "net:[4026531992]")
+	writeProcFixture(t, procRoot, "1/net/tcp", procNetTCPHeader+
+		"   0: 00000000:0050 00000000:0000 0A 00000000:00000000 00:00000000 00000000     0        0 1000 1 0 20 4 30 10 -1\n"+
+		"   1: 0100000A:0050 0200000A:C350 01 00000000:00000000 00:00000000 00000000     0        0 1001 1 0 20 4 30 10 -1\n")
+	writeProcFixture(t, procRoot, "1/net/tcp6", procNetTCPHeader+
+		"   0: 00000000000000000000000000000000:01BB 00000000000000000000000000000000:0000 0A 00000000:00000000 00:00000000 00000000     0        0 1002 1 0 20 4 30 10 -1\n")
+	// The master process 1 and its worker 2 share the listening sockets
+	writeSocketFixture(t, procRoot, "1", "3", "1000")
+	writeSocketFixture(t, procRoot, "1", "4", "1002")
+	writeSocketFixture(t, procRoot, "2", "3", "1000")
+	writeSocketFixture(t, procRoot, "2", "4", "1002")
+	writeSocketFixture(t, procRoot, "2", "5", "1001")
+	assert.NoError(ioutil.WriteFile(filepath.Join(procRoot, "2", "fd", "6"), nil, 0644))
+
+	// pid 3 listens on 127.0.0.1:6379 in another namespace, pid 4 isn't known to the process check
+	writeNetNsFixture(t, procRoot, "3", "net:[4026532281]")
+	writeProcFixture(t, procRoot, "3/net/tcp", procNetTCPHeader+
+		"   0: 0100007F:18EB 00000000:0000 0A 00000000:00000000 00:00000000 00000000     0        0 1000 1 0 20 4 30 10 -1\n")
+	writeSocketFixture(t, procRoot, "3", "3", "1000")
+	writeNetNsFixture(t, procRoot, "4", "net:[4026532281]")
+	writeSocketFixture(t, procRoot, "4", "3", "1000")
+
+	socks := readListeningSockets(procRoot)
+	assert.Len(socks, 3)
+
+	lastProcs := Process.lastProcs
+	defer func() { Process.lastProcs = lastProcs }()
+	Process.lastProcs = map[int32]*process.FilledProcess{
+		1: makeProcess(1, "nginx: master process"),
+		2: makeProcess(2, "nginx: worker process"),
+		3: makeProcess(3, "redis-server"),
+	}
+
+	c := &ConnectionsCheck{}
+	cxs := c.formatListeningSockets(config.NewDefaultAgentConfig(), socks)
+	assert.Len(cxs, 3)
+	for _, cx := range cxs {
+		assert.True(cx.Listening)
+		assert.Equal(model.TCPState_listen, cx.TcpState)
+		assert.Equal(model.ConnectionType_tcp, cx.Type)
+		assert.Nil(cx.Raddr)
+	}
+
+	byPort := make(map[int32]*model.Connection)
+	for _, cx := range cxs {
+		byPort[cx.Laddr.Port] = cx
+	}
+	assert.Equal(int32(1), byPort[80].Pid)
+	assert.Equal("0.0.0.0", byPort[80].Laddr.Ip)
+	assert.Equal(model.ConnectionFamily_v4, byPort[80].Family)
+	assert.Equal(uint32(4026531992), byPort[80].NetNs)
+	assert.Equal(int32(1), byPort[443].Pid)
+	assert.Equal("::", byPort[443].Laddr.Ip)
+	assert.Equal(model.ConnectionFamily_v6, byPort[443].Family)
+	assert.Equal(int32(3), byPort[6379].Pid)
+	assert.Equal("127.0.0.1", byPort[6379].Laddr.Ip)
+	assert.Equal(uint32(4026532281), byPort[6379].NetNs)
+
+	// Nothing is listening without procfs
+	assert.Empty(readListeningSockets(filepath.Join(procRoot, "missing")))
+}
@@ -0,0 +1,65 @@
+package checks
+
+import (
+	"time"
+
+	"github.com/DataDog/gopsutil/process"
+	log "github.com/cihub/seelog"
+
+	"github.com/DataDog/datadog-process-agent/config"
+	"github.com/DataDog/datadog-process-agent/util/cache"
+)
+
+// connectionProcessRuns is how many runs of the process check the process of a pid is
+// kept for once it is no longer seen, e.g. as it exited.
+const connectionProcessRuns = 3
+
+// connectionProcess is the process reported with its connections.
+type connectionProcess struct {
+	name    string
+	cmdline []string
+}
+
+// updateConnectionProcesses caches the names and scrubbed command lines of the processes
+// for the connections check. Scrubbing is done here as the scrubber is only used from the
+// process check. The cache is bounded by cache_max_entries, and the processes not seen for
+// a few runs are swept. Must be called with the lock held.
+func (p *ProcessCheck) updateConnectionProcesses(cfg *config.AgentConfig, procs map[int32]*process.FilledProcess, now time.Time) {
+	if !cfg.ConnectionsProcessName {
+		p.connProcesses = nil
+		return
+	}
+	if p.connProcesses == nil {
+		p.connProcesses = cache.NewLRU(cfg.CacheMaxEntries, connectionProcessRuns*cfg.CheckInterval(p.Name()))
+	}
+
+	withCmdline := cfg.CollectsProcessField(config.ProcessFieldCmdline)
+	for pid, fp := range procs {
+		cp := connectionProcess{name: fp.Name}
+		if withCmdline {
+			cp.cmdline = cfg.Scrubber.TruncateCommand(cfg.Scrubber.ScrubProcessCommand(fp))
+		}
+		p.connProcesses.Add(pid, cp, now)
+	}
+	if n := p.connProcesses.Sweep(now); n > 0 {
+		log.Debugf("Removed %d exited processes from the connection processes", n)
+	}
+}
+
+// processesForPIDs returns the cached processes of the given pids.
+func (p *ProcessCheck) processesForPIDs(pids []uint32) map[uint32]connectionProcess {
+	p.Lock()
+	defer p.Unlock()
+
+	processes := make(map[uint32]connectionProcess, len(pids))
+	if p.connProcesses == nil {
+		return processes
+	}
+	now := time.Now()
+	for _, pid := range pids {
+		if cp, ok := p.connProcesses.Get(int32(pid), now); ok {
+			processes[pid] = cp.(connectionProcess)
+		}
+	}
+	return processes
+}
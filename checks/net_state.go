@@ -0,0 +1,96 @@
+package checks
+
+import (
+	"path/filepath"
+	"strconv"
+
+	log "github.com/cihub/seelog"
+
+	"github.com/DataDog/datadog-process-agent/model"
+	"github.com/DataDog/datadog-process-agent/util"
+	"github.com/DataDog/tcptracer-bpf/pkg/tracer"
+)
+
+// tcpStates holds the state of the TCP sockets of the network namespaces of the
+// connection PIDs. The tracer doesn't report it, so it is read from procfs.
+type tcpStates struct {
+	procRoot string
+	byPid    map[uint32]map[util.TCPSocketKey]uint8
+}
+
+// readNetNamespaces returns the inode of the network namespace of each of the given pids,
+// leaving out those that couldn't be read, e.g. as the process exited.
+func readNetNamespaces(procRoot string, pids []uint32) map[uint32]uint32 {
+	netNs := make(map[uint32]uint32, len(pids))
+	for _, pid := range pids {
+		ino, err := util.ReadNetNamespace(procRoot, int32(pid))
+		if err != nil {
+			log.Debugf("unable to read network namespace of pid %d: %s", pid, err)
+			continue
+		}
+		netNs[pid] = ino
+	}
+	return netNs
+}
+
+// readTCPStates reads the TCP socket states for the PIDs of the TCP connections.
+// Each network namespace is only read once.
+func readTCPStates(procRoot string, conns []tracer.ConnectionStats, netNs map[uint32]uint32) *tcpStates {
+	s := &tcpStates{procRoot: procRoot, byPid: make(map[uint32]map[util.TCPSocketKey]uint8)}
+	byNamespace := make(map[uint32]map[util.TCPSocketKey]uint8)
+	for _, conn := range conns {
+		if conn.Type != tracer.TCP {
+			continue
+		}
+		if _, ok := s.byPid[conn.Pid]; ok {
+			continue
+		}
+
+		ns, hasNs := netNs[conn.Pid]
+		if states, ok := byNamespace[ns]; ok && hasNs {
+			s.byPid[conn.Pid] = states
+			continue
+		}
+
+		pidDir := filepath.Join(procRoot, strconv.Itoa(int(conn.Pid)))
+		states := make(map[util.TCPSocketKey]uint8)
+		for _, f := range []string{"tcp", "tcp6"} {
+			fileStates, err := util.ReadTCPStates(filepath.Join(pidDir, "net", f))
+			if err != nil {
+				log.Debugf("unable to read TCP states of pid %d: %s", conn.Pid, err)
+				continue
+			}
+			for k, st := range fileStates {
+				states[k] = st
+			}
+		}
+		s.byPid[conn.Pid] = states
+		if hasNs {
+			byNamespace[ns] = states
+		}
+	}
+	return s
+}
+
+// state returns the state of a TCP connection with the given normalized IPs,
+// or unknown if it wasn't found.
+func (s *tcpStates) state(conn tracer.ConnectionStats, laddr, raddr string) model.TCPState {
+	st, ok := s.byPid[conn.Pid][util.TCPSocketKey{
+		LocalIP:    laddr,
+		LocalPort:  conn.SPort,
+		RemoteIP:   raddr,
+		RemotePort: conn.DPort,
+	}]
+	if !ok {
+		return model.TCPState_unknownTCPState
+	}
+	return formatTCPState(st)
+}
+
+// formatTCPState maps the kernel TCP state numbers to the model enum.
+func formatTCPState(st uint8) model.TCPState {
+	if _, ok := model.TCPState_name[int32(st)]; !ok {
+		return model.TCPState_unknownTCPState
+	}
+	return model.TCPState(st)
+}
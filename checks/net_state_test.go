@@ -0,0 +1,147 @@
+package checks
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/DataDog/datadog-process-agent/config"
+	"github.com/DataDog/datadog-process-agent/model"
+	"github.com/DataDog/gopsutil/process"
+	"github.com/DataDog/tcptracer-bpf/pkg/tracer"
+	"github.com/stretchr/testify/assert"
+)
+
+const procNetTCPHeader = "  sl  local_address rem_address   st tx_queue rx_queue tr tm->when retrnsmt   uid  timeout inode\n"
+
+func TestFormatTCPState(t *testing.T) {
+	for st, expected := range map[uint8]model.TCPState{
+		0x01: model.TCPState_established,
+		0x02: model.TCPState_synSent,
+		0x03: model.TCPState_synRecv,
+		0x04: model.TCPState_finWait1,
+		0x05: model.TCPState_finWait2,
+		0x06: model.TCPState_timeWait,
+		0x07: model.TCPState_close,
+		0x08: model.TCPState_closeWait,
+		0x09: model.TCPState_lastAck,
+		0x0A: model.TCPState_listen,
+		0x0B: model.TCPState_closing,
+		0x00: model.TCPState_unknownTCPState,
+		0x0C: model.TCPState_unknownTCPState, // TCP_NEW_SYN_RECV is never reported for connections
+	} {
+		assert.Equal(t, expected, formatTCPState(st), "state %d", st)
+	}
+}
+
+func TestFormatConnectionsTCPState(t *testing.T) {
+	procRoot, err := ioutil.TempDir("", "proc")
+	assert.NoError(t, err)
+	defer os.RemoveAll(procRoot)
+
+	// 10.0.0.1:80 -> 10.0.0.2:50000 established, 10.0.0.1:80 -> 10.0.0.3:50001 in time wait
+	writeProcFixture(t, procRoot, "1/net/tcp", procNetTCPHeader+
+		"   0: 0100000A:0050 0200000A:C350 01 00000000:00000000 00:00000000 00000000     0        0 1000 1 0 20 4 30 10 -1\n"+
+		"   1: 0100000A:0050 0300000A:C351 06 00000000:00000000 00:00000000 00000000     0        0 0 1 0 20 4 30 10 -1\n")
+	// [::1]:8080 -> [::1]:50002 in close wait
+	writeProcFixture(t, procRoot, "1/net/tcp6", procNetTCPHeader+
+		"   0: 00000000000000000000000001000000:1F90 00000000000000000000000001000000:C352 08 00000000:00000000 00:00000000 00000000     0        0 1001 1 0 20 4 30 10 -1\n")
+
+	lastProcs := Process.lastProcs
+	defer func() { Process.lastProcs = lastProcs }()
+	Process.lastProcs = map[int32]*process.FilledProcess{
+		1: makeProcess(1, "nginx -g daemon off;"),
+		2: makeProcess(2, "dnsmasq"),
+	}
+
+	conns := []tracer.ConnectionStats{
+		{Pid: 1, Type: tracer.TCP, Family: tracer.AF_INET, Source: "10.0.0.1", SPort: 80, Dest: "10.0.0.2", DPort: 50000},
+		{Pid: 1, Type: tracer.TCP, Family: tracer.AF_INET, Source: "10.0.0.1", SPort: 80, Dest: "10.0.0.3", DPort: 50001},
+		{Pid: 1, Type: tracer.TCP, Family: tracer.AF_INET6, Source: "::1", SPort: 8080, Dest: "::1", DPort: 50002},
+		{Pid: 1, Type: tracer.TCP, Family: tracer.AF_INET, Source: "10.0.0.1", SPort: 80, Dest: "10.0.0.4", DPort: 50003},
+		{Pid: 2, Type: tracer.UDP, Family: tracer.AF_INET, Source: "10.0.0.1", SPort: 53, Dest: "10.0.0.2", DPort: 50004},
+	}
+
+	cfg := config.NewDefaultAgentConfig()
+	cfg.ConnectionsReportLoopback = true
+	c := &ConnectionsCheck{buf: new(bytes.Buffer), hostProc: procRoot}
+	cxs := c.formatConnections(cfg, conns, map[string]tracer.ConnectionStats{}, time.Now(), nil)
+	states := make([]model.TCPState, 0, len(cxs))
+	for _, cx := range cxs {
+		states = append(states, cx.TcpState)
+	}
+	assert.Equal(t, []model.TCPState{
+		model.TCPState_established,
+		model.TCPState_timeWait,
+		model.TCPState_closeWait,
+		model.TCPState_unknownTCPState, // Not found
+		model.TCPState_unknownTCPState, // UDP
+	}, states)
+}
+
+// writeNetNsFixture creates the ns/net link of a process to the given namespace link target.
+func writeNetNsFixture(t *testing.T, procRoot, pid, target string) {
+	dir := filepath.Join(procRoot, pid, "ns")
+	assert.NoError(t, os.MkdirAll(dir, 0755))
+	assert.NoError(t, os.Symlink(target, filepath.Join(dir, "net")))
+}
+
+func TestReadNetNamespaces(t *testing.T) {
+	procRoot, err := ioutil.TempDir("", "proc")
+	assert.NoError(t, err)
+	defer os.RemoveAll(procRoot)
+
+	writeNetNsFixture(t, procRoot, "1", "net:[4026531992]")
+	writeNetNsFixture(t, procRoot, "2", "net:[4026531992]")
+	writeNetNsFixture(t, procRoot, "3", "net:[4026532281]")
+	writeNetNsFixture(t, procRoot, "4", "mnt:[4026531840]")
+
+	assert.Equal(t, map[uint32]uint32{
+		1: 4026531992,
+		2: 4026531992,
+		3: 4026532281,
+	}, readNetNamespaces(procRoot, []uint32{1, 2, 3, 4, 5}))
+}
+
+func TestFormatConnectionsNetNs(t *testing.T) {
+	procRoot, err := ioutil.TempDir("", "proc")
+	assert.NoError(t, err)
+	defer os.RemoveAll(procRoot)
+
+	// pids 1 and 2 share a namespace, whose sockets are only listed for pid 1
+	writeNetNsFixture(t, procRoot, "1", "net:[4026531992]")
+	writeNetNsFixture(t, procRoot, "2", "net:[4026531992]")
+	writeNetNsFixture(t, procRoot, "3", "net:[4026532281]")
+	writeProcFixture(t, procRoot, "1/net/tcp", procNetTCPHeader+
+		"   0: 0100000A:0050 0200000A:C350 01 00000000:00000000 00:00000000 00000000     0        0 1000 1 0 20 4 30 10 -1\n"+
+		"   1: 0100000A:0051 0200000A:C351 0A 00000000:00000000 00:00000000 00000000     0        0 1001 1 0 20 4 30 10 -1\n")
+
+	lastProcs := Process.lastProcs
+	defer func() { Process.lastProcs = lastProcs }()
+	Process.lastProcs = map[int32]*process.FilledProcess{
+		1: makeProcess(1, "nginx -g daemon off;"),
+		2: makeProcess(2, "nginx -g daemon off;"),
+		3: makeProcess(3, "dnsmasq"),
+		4: makeProcess(4, "exited"),
+	}
+
+	conns := []tracer.ConnectionStats{
+		{Pid: 1, Type: tracer.TCP, Family: tracer.AF_INET, Source: "10.0.0.1", SPort: 80, Dest: "10.0.0.2", DPort: 50000},
+		{Pid: 2, Type: tracer.TCP, Family: tracer.AF_INET, Source: "10.0.0.1", SPort: 81, Dest: "10.0.0.2", DPort: 50001},
+		{Pid: 3, Type: tracer.UDP, Family: tracer.AF_INET, Source: "10.0.0.3", SPort: 53, Dest: "10.0.0.2", DPort: 50002},
+		{Pid: 4, Type: tracer.UDP, Family: tracer.AF_INET, Source: "10.0.0.4", SPort: 53, Dest: "10.0.0.2", DPort: 50003},
+	}
+
+	c := &ConnectionsCheck{buf: new(bytes.Buffer), hostProc: procRoot}
+	cxs := c.formatConnections(config.NewDefaultAgentConfig(), conns, map[string]tracer.ConnectionStats{}, time.Now(), nil)
+	netNs := make([]uint32, 0, len(cxs))
+	for _, cx := range cxs {
+		netNs = append(netNs, cx.NetNs)
+	}
+	assert.Equal(t, []uint32{4026531992, 4026531992, 4026532281, 0}, netNs)
+	assert.Equal(t, model.TCPState_established, cxs[0].TcpState)
+	assert.Equal(t, model.TCPState_listen, cxs[1].TcpState)
+}
@@ -1,10 +1,20 @@
 package checks
 
 import (
+	"bytes"
+	"context"
+	"math/rand"
+	"net"
+	"regexp"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/DataDog/datadog-process-agent/config"
 	"github.com/DataDog/datadog-process-agent/model"
+	"github.com/DataDog/gopsutil/process"
+	"github.com/DataDog/tcptracer-bpf/pkg/tracer"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -60,7 +70,7 @@ func TestNetworkConnectionBatching(t *testing.T) {
 		},
 	} {
 		cfg.MaxPerMessage = tc.maxSize
-		chunks := batchConnections(cfg, 0, tc.cur)
+		chunks := batchConnections(cfg, 0, tc.cur, 0)
 
 		assert.Len(t, chunks, tc.expectedChunks, "len %d", i)
 		total := 0
@@ -72,3 +82,730 @@ func TestNetworkConnectionBatching(t *testing.T) {
 		assert.Equal(t, tc.expectedTotal, total, "total test %d", i)
 	}
 }
+
+func TestNetworkConnectionBatchingBytes(t *testing.T) {
+	cfg := config.NewDefaultAgentConfig()
+	cfg.MaxPerMessage = 100
+
+	// Each connection has a 1KB address, so 5 of them fit in a 5.5KB message
+	cxs := make([]*model.Connection, 0, 12)
+	for i := 0; i < 12; i++ {
+		cxs = append(cxs, &model.Connection{
+			Pid:   int32(i),
+			Laddr: &model.Addr{Ip: strings.Repeat("a", 1024)},
+		})
+	}
+	cfg.MaxMessageBytes = 5*cxs[0].Size() + 512
+
+	chunks := batchConnections(cfg, 0, cxs, 0)
+	assert.Len(t, chunks, 3)
+	total := 0
+	for _, c := range chunks {
+		connections := c.(*model.CollectorConnections)
+		total += len(connections.Connections)
+		assert.True(t, connections.Size() <= cfg.MaxMessageBytes+64, "message is too large: %d", connections.Size())
+		assert.Equal(t, int32(3), connections.GroupSize)
+	}
+	assert.Equal(t, 12, total)
+
+	// A single item over the limit is still sent on its own
+	cfg.MaxMessageBytes = 10
+	chunks = batchConnections(cfg, 0, cxs[:2], 0)
+	assert.Len(t, chunks, 2)
+}
+
+func TestConnectionsCheckClose(t *testing.T) {
+	c := &ConnectionsCheck{}
+	// Closing before Init is a no-op
+	c.Close()
+
+	c.Init(config.NewDefaultAgentConfig(), &model.SystemInfo{})
+	if !c.supported {
+		assert.Nil(t, c.tracer)
+	}
+	c.Close()
+	assert.Nil(t, c.tracer)
+
+	// Run after Close doesn't collect anything
+	msgs, err := c.Run(context.Background(), config.NewDefaultAgentConfig(), 0)
+	assert.NoError(t, err)
+	assert.Nil(t, msgs)
+
+	// Closing twice is safe
+	c.Close()
+}
+
+func TestFormatConnectionsBlacklist(t *testing.T) {
+	cfg := config.NewDefaultAgentConfig()
+	cfg.Blacklist = []*regexp.Regexp{regexp.MustCompile("^mysqld")}
+
+	// The process check's last run provides the cmdlines of the connection PIDs
+	lastProcs := Process.lastProcs
+	defer func() { Process.lastProcs = lastProcs }()
+	Process.lastProcs = map[int32]*process.FilledProcess{
+		1: makeProcess(1, "mysqld --port 3306"),
+		2: makeProcess(2, "nginx -g daemon off;"),
+	}
+
+	conns := []tracer.ConnectionStats{
+		{Pid: 1, Source: "10.0.0.1", SPort: 3306, Dest: "10.0.0.2", DPort: 50000},
+		{Pid: 2, Source: "10.0.0.1", SPort: 80, Dest: "10.0.0.3", DPort: 50001},
+		{Pid: 1, Source: "10.0.0.1", SPort: 3306, Dest: "10.0.0.4", DPort: 50002},
+		{Pid: 3, Source: "10.0.0.1", SPort: 22, Dest: "10.0.0.5", DPort: 50003},
+	}
+
+	c := &ConnectionsCheck{buf: new(bytes.Buffer)}
+	cxs := c.formatConnections(cfg, conns, map[string]tracer.ConnectionStats{}, time.Now().Add(-time.Second), nil)
+	assert.Len(t, cxs, 1)
+	assert.Equal(t, int32(2), cxs[0].Pid)
+}
+
+func TestConnectionsCheckStartTracer(t *testing.T) {
+	assert := assert.New(t)
+	defer func(f func(tracerConfig) (connectionTracer, error)) { newTracer = f }(newTracer)
+
+	var created []tracerConfig
+	st := &scriptedTracer{}
+	newTracer = func(tc tracerConfig) (connectionTracer, error) {
+		created = append(created, tc)
+		return st, nil
+	}
+
+	cfg := config.NewDefaultAgentConfig()
+	c := &ConnectionsCheck{}
+	assert.NoError(c.startTracer(cfg))
+	assert.Equal(st, c.tracer)
+	assert.Equal([]tracerConfig{{maxTrackedConnections: config.DefaultConnectionsMaxTracked}}, created)
+
+	cfg.ConnectionsMaxTracked = 200000
+	c = &ConnectionsCheck{}
+	assert.NoError(c.startTracer(cfg))
+	assert.Equal(200000, created[1].maxTrackedConnections)
+
+	// The tracer is left unset when it can't be created
+	newTracer = func(tc tracerConfig) (connectionTracer, error) {
+		return nil, tracer.ErrNotImplemented
+	}
+	c = &ConnectionsCheck{}
+	assert.Equal(tracer.ErrNotImplemented, c.startTracer(cfg))
+	assert.Nil(c.tracer)
+}
+
+func TestConnectionsCheckMaxTracked(t *testing.T) {
+	assert := assert.New(t)
+	defer func(f func(tracerConfig) (connectionTracer, error)) { newTracer = f }(newTracer)
+
+	st := &scriptedTracer{}
+	newTracer = func(tc tracerConfig) (connectionTracer, error) { return st, nil }
+	var conns []tracer.ConnectionStats
+	for i := 0; i < 5; i++ {
+		conns = append(conns, tracer.ConnectionStats{Pid: 1, Source: "10.0.0.1", SPort: 80, Dest: "10.0.0.2", DPort: uint16(50000 + i), SendBytes: uint64(i)})
+	}
+	st.set(conns[2], conns[0], conns[4], conns[1], conns[3])
+
+	cfg := config.NewDefaultAgentConfig()
+	cfg.ConnectionsMaxTracked = 3
+	c := &ConnectionsCheck{sampled: make(map[string]tracer.ConnectionStats)}
+	assert.NoError(c.startTracer(cfg))
+
+	// The connections beyond the limit that sent and received the fewest bytes are dropped
+	active, err := c.getActiveConnections(context.Background())
+	assert.NoError(err)
+	tracked, dropped := c.trackedConnections(active)
+	assert.Equal([]tracer.ConnectionStats{conns[4], conns[3], conns[2]}, tracked)
+	assert.Equal(2, dropped)
+
+	// The samples are bounded too, the ones already recorded are still updated
+	buf := new(bytes.Buffer)
+	c.addSample(conns[1:], buf)
+	updated := conns[1]
+	updated.SendBytes = 10
+	c.addSample([]tracer.ConnectionStats{updated, conns[0]}, buf)
+	assert.Len(c.sampled, 3)
+	b, _ := updated.ByteKey(buf)
+	assert.Equal(updated, c.sampled[string(b)])
+	b, _ = conns[0].ByteKey(buf)
+	assert.NotContains(c.sampled, string(b))
+	// conns[4] and then conns[0] are left out
+	c.buf = buf
+	_, dropped = c.withSamples(nil)
+	assert.Equal(2, dropped)
+	_, dropped = c.withSamples(nil)
+	assert.Equal(0, dropped)
+
+	// Below the limit all the connections are kept
+	c.maxTracked = config.DefaultConnectionsMaxTracked
+	tracked, dropped = c.trackedConnections(conns)
+	assert.Equal(conns, tracked)
+	assert.Equal(0, dropped)
+}
+
+// blockingTracer is a connectionTracer whose GetActiveConnections blocks until release is closed.
+type blockingTracer struct {
+	release chan struct{}
+}
+
+func (t *blockingTracer) Start() {}
+func (t *blockingTracer) Stop()  {}
+func (t *blockingTracer) GetActiveConnections() ([]tracer.ConnectionStats, error) {
+	<-t.release
+	return nil, nil
+}
+
+func TestConnectionsCheckRunCancel(t *testing.T) {
+	bt := &blockingTracer{release: make(chan struct{})}
+	defer close(bt.release)
+	c := &ConnectionsCheck{tracer: bt, supported: true, buf: new(bytes.Buffer)}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		cancel()
+	}()
+
+	start := time.Now()
+	msgs, err := c.Run(ctx, config.NewDefaultAgentConfig(), 0)
+	assert.Equal(t, context.Canceled, err)
+	assert.Nil(t, msgs)
+	assert.True(t, time.Since(start) < time.Second, "run took %s", time.Since(start))
+	assert.Nil(t, c.prevCheckConns)
+}
+
+// stoppedTracer is a blockingTracer recording whether it was stopped while a call was in flight.
+type stoppedTracer struct {
+	blockingTracer
+	mu       sync.Mutex
+	inFlight bool
+	stopped  bool
+	overlap  bool
+}
+
+func (t *stoppedTracer) Stop() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.stopped, t.overlap = true, t.inFlight
+}
+
+func (t *stoppedTracer) GetActiveConnections() ([]tracer.ConnectionStats, error) {
+	t.mu.Lock()
+	t.inFlight = true
+	t.mu.Unlock()
+	conns, err := t.blockingTracer.GetActiveConnections()
+	t.mu.Lock()
+	t.inFlight = false
+	t.mu.Unlock()
+	return conns, err
+}
+
+func TestConnectionsCheckCloseAbandonedRun(t *testing.T) {
+	assert := assert.New(t)
+	st := &stoppedTracer{blockingTracer: blockingTracer{release: make(chan struct{})}}
+	c := &ConnectionsCheck{tracer: st, supported: true, buf: new(bytes.Buffer)}
+
+	// The run gives up on the tracer, which is still being read
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err := c.Run(ctx, config.NewDefaultAgentConfig(), 0)
+	assert.Equal(context.Canceled, err)
+
+	closed := make(chan struct{})
+	go func() {
+		defer close(closed)
+		c.Close()
+	}()
+	select {
+	case <-closed:
+		t.Fatal("closed while the tracer was still read")
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	close(st.release)
+	<-closed
+	assert.True(st.stopped)
+	assert.False(st.overlap)
+	assert.Nil(c.tracer)
+}
+
+func TestFormatConnectionsNormalizesIPs(t *testing.T) {
+	cfg := config.NewDefaultAgentConfig()
+	cfg.ConnectionsReportLoopback = true
+
+	lastProcs := Process.lastProcs
+	defer func() { Process.lastProcs = lastProcs }()
+	Process.lastProcs = map[int32]*process.FilledProcess{
+		1: makeProcess(1, "nginx -g daemon off;"),
+	}
+
+	conns := []tracer.ConnectionStats{
+		{Pid: 1, Family: tracer.AF_INET, Source: "10.0.0.1", SPort: 80, Dest: "10.0.0.2", DPort: 50000},
+		{Pid: 1, Family: tracer.AF_INET6, Source: "2001:0db8:0000:0000:0000:0000:0000:0001", SPort: 80, Dest: "2001:DB8::2", DPort: 50001},
+		{Pid: 1, Family: tracer.AF_INET6, Source: "::ffff:10.0.0.1", SPort: 80, Dest: "::1", DPort: 50002},
+		{Pid: 1, Family: tracer.AF_INET, Source: "10.0.0.1", SPort: 80, Dest: "10.0.0.256", DPort: 50003},
+		{Pid: 1, Family: tracer.AF_INET6, Source: "2001:db8::g", SPort: 80, Dest: "2001:db8::2", DPort: 50004},
+		{Pid: 1, Family: tracer.AF_INET, Source: "", SPort: 80, Dest: "10.0.0.2", DPort: 50005},
+	}
+
+	c := &ConnectionsCheck{buf: new(bytes.Buffer)}
+	cxs := c.formatConnections(cfg, conns, map[string]tracer.ConnectionStats{}, time.Now().Add(-time.Second), nil)
+	assert.Len(t, cxs, 3)
+
+	addrs := make([][2]string, 0, len(cxs))
+	for _, cx := range cxs {
+		addrs = append(addrs, [2]string{cx.Laddr.Ip, cx.Raddr.Ip})
+	}
+	assert.Equal(t, [][2]string{
+		{"10.0.0.1", "10.0.0.2"},
+		{"2001:db8::1", "2001:db8::2"},
+		{"10.0.0.1", "::1"},
+	}, addrs)
+}
+
+func TestFormatConnectionsExcludeCIDRs(t *testing.T) {
+	cfg := config.NewDefaultAgentConfig()
+	cfg.ConnectionsReportLoopback = true
+	for _, cidr := range []string{"10.1.0.0/16", "fd00::/64", "127.0.0.0/8"} {
+		_, n, err := net.ParseCIDR(cidr)
+		assert.NoError(t, err)
+		cfg.ConnectionsExcludeCIDRs = append(cfg.ConnectionsExcludeCIDRs, n)
+	}
+
+	lastProcs := Process.lastProcs
+	defer func() { Process.lastProcs = lastProcs }()
+	Process.lastProcs = map[int32]*process.FilledProcess{
+		1: makeProcess(1, "nginx -g daemon off;"),
+	}
+
+	conns := []tracer.ConnectionStats{
+		// Boundaries of 10.1.0.0/16
+		{Pid: 1, Family: tracer.AF_INET, Source: "10.2.0.1", SPort: 80, Dest: "10.0.255.255", DPort: 50000},
+		{Pid: 1, Family: tracer.AF_INET, Source: "10.2.0.1", SPort: 80, Dest: "10.1.0.0", DPort: 50001},
+		{Pid: 1, Family: tracer.AF_INET, Source: "10.2.0.1", SPort: 80, Dest: "10.1.255.255", DPort: 50002},
+		{Pid: 1, Family: tracer.AF_INET, Source: "10.2.0.1", SPort: 80, Dest: "10.2.0.0", DPort: 50003},
+		// Boundaries of fd00::/64
+		{Pid: 1, Family: tracer.AF_INET6, Source: "fd01::1", SPort: 80, Dest: "fd00::", DPort: 50004},
+		{Pid: 1, Family: tracer.AF_INET6, Source: "fd01::1", SPort: 80, Dest: "fd00::ffff:ffff:ffff:ffff", DPort: 50005},
+		{Pid: 1, Family: tracer.AF_INET6, Source: "fd01::1", SPort: 80, Dest: "fd00:0:0:1::", DPort: 50006},
+		// IPv4-mapped IPv6 addresses match the IPv4 ranges
+		{Pid: 1, Family: tracer.AF_INET6, Source: "fd01::1", SPort: 80, Dest: "::ffff:127.0.0.1", DPort: 50007},
+		// The local address is only matched with connections_exclude_local
+		{Pid: 1, Family: tracer.AF_INET, Source: "10.1.0.1", SPort: 80, Dest: "10.2.0.1", DPort: 50008},
+	}
+
+	remotePorts := func() []int32 {
+		c := &ConnectionsCheck{buf: new(bytes.Buffer)}
+		cxs := c.formatConnections(cfg, conns, map[string]tracer.ConnectionStats{}, time.Now().Add(-time.Second), nil)
+		ports := make([]int32, 0, len(cxs))
+		for _, cx := range cxs {
+			ports = append(ports, cx.Raddr.Port)
+		}
+		return ports
+	}
+	assert.Equal(t, []int32{50000, 50003, 50006, 50008}, remotePorts())
+
+	cfg.ConnectionsExcludeLocal = true
+	assert.Equal(t, []int32{50000, 50003, 50006}, remotePorts())
+}
+
+func TestFormatConnectionsLoopback(t *testing.T) {
+	cfg := config.NewDefaultAgentConfig()
+
+	lastProcs := Process.lastProcs
+	defer func() { Process.lastProcs = lastProcs }()
+	Process.lastProcs = map[int32]*process.FilledProcess{
+		1: makeProcess(1, "nginx -g daemon off;"),
+	}
+
+	conns := []tracer.ConnectionStats{
+		{Pid: 1, Family: tracer.AF_INET, Source: "127.0.0.1", SPort: 80, Dest: "127.0.0.1", DPort: 50000},
+		{Pid: 1, Family: tracer.AF_INET, Source: "127.0.0.1", SPort: 80, Dest: "127.255.255.254", DPort: 50001},
+		{Pid: 1, Family: tracer.AF_INET6, Source: "::1", SPort: 80, Dest: "::1", DPort: 50002},
+		{Pid: 1, Family: tracer.AF_INET6, Source: "::ffff:127.0.0.1", SPort: 80, Dest: "::ffff:127.0.0.1", DPort: 50003},
+		{Pid: 1, Family: tracer.AF_INET, Source: "10.0.0.1", SPort: 80, Dest: "128.0.0.1", DPort: 50004},
+		{Pid: 1, Family: tracer.AF_INET6, Source: "2001:db8::1", SPort: 80, Dest: "::2", DPort: 50005},
+	}
+
+	remotePorts := func() []int32 {
+		c := &ConnectionsCheck{buf: new(bytes.Buffer)}
+		cxs := c.formatConnections(cfg, conns, map[string]tracer.ConnectionStats{}, time.Now().Add(-time.Second), nil)
+		ports := make([]int32, 0, len(cxs))
+		for _, cx := range cxs {
+			ports = append(ports, cx.Raddr.Port)
+		}
+		return ports
+	}
+	// Left out by default
+	assert.Equal(t, []int32{50004, 50005}, remotePorts())
+
+	cfg.ConnectionsReportLoopback = true
+	assert.Equal(t, []int32{50000, 50001, 50002, 50003, 50004, 50005}, remotePorts())
+}
+
+// scriptedTracer is a connectionTracer returning the current connections set by the test.
+type scriptedTracer struct {
+	sync.Mutex
+	conns []tracer.ConnectionStats
+	calls int
+}
+
+func (t *scriptedTracer) Start() {}
+func (t *scriptedTracer) Stop()  {}
+func (t *scriptedTracer) GetActiveConnections() ([]tracer.ConnectionStats, error) {
+	t.Lock()
+	defer t.Unlock()
+	t.calls++
+	return t.conns, nil
+}
+
+func (t *scriptedTracer) set(conns ...tracer.ConnectionStats) {
+	t.Lock()
+	defer t.Unlock()
+	t.conns = conns
+}
+
+func (t *scriptedTracer) Calls() int {
+	t.Lock()
+	defer t.Unlock()
+	return t.calls
+}
+
+func TestConnectionsCheckSampling(t *testing.T) {
+	cfg := config.NewDefaultAgentConfig()
+
+	lastProcs := Process.lastProcs
+	defer func() { Process.lastProcs = lastProcs }()
+	Process.lastProcs = map[int32]*process.FilledProcess{
+		1: makeProcess(1, "nginx -g daemon off;"),
+	}
+
+	long := tracer.ConnectionStats{Pid: 1, Source: "10.0.0.1", SPort: 80, Dest: "10.0.0.2", DPort: 50000}
+	short := tracer.ConnectionStats{Pid: 1, Source: "10.0.0.1", SPort: 80, Dest: "10.0.0.3", DPort: 50001, SendBytes: 10}
+	st := &scriptedTracer{}
+	st.set(long)
+	c := &ConnectionsCheck{tracer: st, supported: true, buf: new(bytes.Buffer)}
+	c.startSampling(10 * time.Millisecond)
+	defer c.Close()
+
+	// The first run only primes the previous connections
+	msgs, err := c.Run(context.Background(), cfg, 0)
+	assert.NoError(t, err)
+	assert.Nil(t, msgs)
+
+	// A connection opened and closed between two runs is reported once with its last stats
+	st.set(long, short)
+	for calls := st.Calls(); st.Calls() < calls+3; {
+		time.Sleep(5 * time.Millisecond)
+	}
+	short.SendBytes = 20
+	st.set(long, short)
+	for calls := st.Calls(); st.Calls() < calls+3; {
+		time.Sleep(5 * time.Millisecond)
+	}
+	st.set(long)
+	msgs, err = c.Run(context.Background(), cfg, 0)
+	assert.NoError(t, err)
+	assert.Len(t, msgs, 1)
+	cxs := msgs[0].(*model.CollectorConnections).Connections
+	assert.Len(t, cxs, 2)
+
+	raddrs := make(map[string]bool)
+	for _, cx := range cxs {
+		raddrs[cx.Raddr.Ip] = true
+	}
+	assert.Equal(t, map[string]bool{"10.0.0.2": true, "10.0.0.3": true}, raddrs)
+
+	// The samples are only reported once
+	msgs, err = c.Run(context.Background(), cfg, 0)
+	assert.NoError(t, err)
+	assert.Len(t, msgs[0].(*model.CollectorConnections).Connections, 1)
+
+	c.Close()
+	assert.Nil(t, c.stopSampling)
+}
+
+func TestConnectionsCheckNoSampling(t *testing.T) {
+	st := &scriptedTracer{}
+	c := &ConnectionsCheck{tracer: st, supported: true, buf: new(bytes.Buffer)}
+	_, err := c.Run(context.Background(), config.NewDefaultAgentConfig(), 0)
+	assert.NoError(t, err)
+	// Without sampling the tracer is only read by the runs
+	time.Sleep(20 * time.Millisecond)
+	assert.Equal(t, 1, st.Calls())
+}
+
+func TestConnectionsCheckRates(t *testing.T) {
+	assert := assert.New(t)
+	cfg := config.NewDefaultAgentConfig()
+
+	lastProcs := Process.lastProcs
+	defer func() { Process.lastProcs = lastProcs }()
+	Process.lastProcs = map[int32]*process.FilledProcess{
+		1: makeProcess(1, "nginx -g daemon off;"),
+	}
+
+	conn := func(dport uint16, sent, recv uint64) tracer.ConnectionStats {
+		return tracer.ConnectionStats{Pid: 1, Source: "10.0.0.1", SPort: 80, Dest: "10.0.0.2", DPort: dport, SendBytes: sent, RecvBytes: recv}
+	}
+	st := &scriptedTracer{}
+	c := &ConnectionsCheck{tracer: st, supported: true, buf: new(bytes.Buffer)}
+
+	run := func(elapsed time.Duration) map[int32]*model.Connection {
+		// Pretend the last run happened the given duration ago
+		c.prevCheckTime = time.Now().Add(-elapsed)
+		msgs, err := c.Run(context.Background(), cfg, 0)
+		assert.NoError(err)
+		byPort := make(map[int32]*model.Connection)
+		for _, m := range msgs {
+			for _, cx := range m.(*model.CollectorConnections).Connections {
+				byPort[cx.Raddr.Port] = cx
+			}
+		}
+		return byPort
+	}
+
+	st.set(conn(50000, 1000, 2000), conn(50001, 0, 0), conn(50002, 5000, 5000))
+	run(0)
+
+	st.set(conn(50000, 3000, 2500), conn(50001, 400, 0), conn(50002, 100, 200), conn(50003, 7000, 7000))
+	cxs := run(2 * time.Second)
+	assert.Len(cxs, 4)
+	assert.InDelta(1000, cxs[50000].BytesSent, 5)
+	assert.InDelta(250, cxs[50000].BytesRecieved, 5)
+	assert.Equal(uint64(3000), cxs[50000].TotalBytesSent)
+	assert.Equal(uint64(2500), cxs[50000].TotalBytesReceived)
+	// A previous sample with no traffic is still a baseline
+	assert.InDelta(200, cxs[50001].BytesSent, 5)
+	// The counters of a reused 4-tuple went down, and a new connection has no baseline
+	assert.Equal(float32(0), cxs[50002].BytesSent)
+	assert.Equal(uint64(100), cxs[50002].TotalBytesSent)
+	assert.Equal(float32(0), cxs[50003].BytesSent)
+
+	// Rates are computed against the last run, not the first one
+	st.set(conn(50002, 1100, 200))
+	cxs = run(time.Second)
+	assert.InDelta(1000, cxs[50002].BytesSent, 5)
+	assert.Equal(float32(0), cxs[50002].BytesRecieved)
+}
+
+func TestLimitConnections(t *testing.T) {
+	assert := assert.New(t)
+	cfg := config.NewDefaultAgentConfig()
+	buf := new(bytes.Buffer)
+	r := rand.New(rand.NewSource(1))
+
+	// 2 long-lived connections and 10 new ones, the new ones transferring more bytes
+	var conns []tracer.ConnectionStats
+	lastConns := map[string]tracer.ConnectionStats{}
+	for i := 0; i < 12; i++ {
+		conn := tracer.ConnectionStats{Pid: uint32(i), Source: "10.0.0.1", SPort: 80, Dest: "10.0.0.2", DPort: uint16(50000 + i), SendBytes: uint64(i * 100)}
+		if i < 2 {
+			b, err := conn.ByteKey(buf)
+			assert.NoError(err)
+			lastConns[string(b)] = conn
+		}
+		conns = append(conns, conn)
+	}
+
+	// No limit
+	limited, truncated := limitConnections(cfg, conns, lastConns, buf, r.Intn)
+	assert.Equal(conns, limited)
+	assert.Equal(0, truncated)
+	cfg.MaxConnections = 12
+	limited, truncated = limitConnections(cfg, conns, lastConns, buf, r.Intn)
+	assert.Len(limited, 12)
+	assert.Equal(0, truncated)
+
+	cfg.MaxConnections = 6
+	for run := 0; run < 10; run++ {
+		limited, truncated = limitConnections(cfg, conns, lastConns, buf, r.Intn)
+		assert.Len(limited, 6)
+		assert.Equal(6, truncated)
+
+		// The long-lived connections come first, then the new ones with the most bytes
+		pids := make([]uint32, 0, len(limited))
+		seen := map[uint32]bool{}
+		for _, conn := range limited {
+			pids = append(pids, conn.Pid)
+			assert.False(seen[conn.Pid], "duplicate connection %d", conn.Pid)
+			seen[conn.Pid] = true
+		}
+		assert.Equal([]uint32{1, 0, 11}, pids[:3])
+		// The rest is sampled from the remaining connections
+		for _, pid := range pids[3:] {
+			assert.True(pid >= 2 && pid <= 10, "unexpected connection %d", pid)
+		}
+	}
+
+	// The remaining connections are all eventually sampled
+	sampled := map[uint32]bool{}
+	for run := 0; run < 100; run++ {
+		limited, _ = limitConnections(cfg, conns, lastConns, buf, r.Intn)
+		for _, conn := range limited[3:] {
+			sampled[conn.Pid] = true
+		}
+	}
+	assert.Len(sampled, 9)
+
+	chunks := batchConnections(cfg, 0, []*model.Connection{makeConnection(1)}, truncated)
+	assert.Equal(int32(6), chunks[0].(*model.CollectorConnections).TruncatedConnections)
+}
+
+func TestAggregateConnections(t *testing.T) {
+	assert := assert.New(t)
+	cx := func(pid int32, lport int32, raddr string, rport int32, sent uint64, state model.TCPState) *model.Connection {
+		return &model.Connection{
+			Pid:                pid,
+			Type:               model.ConnectionType_tcp,
+			Laddr:              &model.Addr{Ip: "10.0.0.1", Port: lport},
+			Raddr:              &model.Addr{Ip: raddr, Port: rport},
+			BytesSent:          float32(sent),
+			TotalBytesSent:     sent,
+			TotalBytesReceived: 2 * sent,
+			TcpState:           state,
+		}
+	}
+	cxs := []*model.Connection{
+		cx(1, 50000, "10.0.0.2", 5432, 10, model.TCPState_established),
+		cx(1, 50001, "10.0.0.2", 5432, 20, model.TCPState_established),
+		cx(2, 50002, "10.0.0.2", 5432, 30, model.TCPState_established),
+		cx(1, 50003, "10.0.0.2", 6379, 40, model.TCPState_established),
+		cx(1, 50004, "10.0.0.3", 5432, 50, model.TCPState_established),
+		cx(1, 50005, "10.0.0.2", 5432, 60, model.TCPState_timeWait),
+	}
+	udp := cx(1, 50000, "10.0.0.2", 5432, 70, model.TCPState_unknownTCPState)
+	udp.Type = model.ConnectionType_udp
+	cxs = append(cxs, udp)
+
+	aggregated := aggregateConnections(cxs)
+	assert.Len(aggregated, 5)
+
+	// The sockets of pid 1 to 10.0.0.2:5432 over TCP
+	agg := aggregated[0]
+	assert.Equal(int32(1), agg.Pid)
+	assert.Equal(uint32(3), agg.ConnectionCount)
+	assert.Equal(float32(90), agg.BytesSent)
+	assert.Equal(uint64(90), agg.TotalBytesSent)
+	assert.Equal(uint64(180), agg.TotalBytesReceived)
+	assert.Equal(&model.Addr{Ip: "10.0.0.1"}, agg.Laddr)
+	assert.Equal(&model.Addr{Ip: "10.0.0.2", Port: 5432}, agg.Raddr)
+	assert.Equal(model.TCPState_unknownTCPState, agg.TcpState)
+
+	// Different pid, remote port, remote address or type
+	for i, expected := range []struct {
+		pid   int32
+		raddr string
+		rport int32
+	}{{2, "10.0.0.2", 5432}, {1, "10.0.0.2", 6379}, {1, "10.0.0.3", 5432}, {1, "10.0.0.2", 5432}} {
+		agg := aggregated[i+1]
+		assert.Equal(expected.pid, agg.Pid)
+		assert.Equal(expected.raddr, agg.Raddr.Ip)
+		assert.Equal(expected.rport, agg.Raddr.Port)
+		assert.Equal(uint32(1), agg.ConnectionCount)
+		assert.NotZero(agg.Laddr.Port)
+	}
+	assert.Equal(model.ConnectionType_udp, aggregated[4].Type)
+	assert.Equal(model.TCPState_established, aggregated[1].TcpState)
+}
+
+func TestConnectionsProcessName(t *testing.T) {
+	assert := assert.New(t)
+	cfg := config.NewDefaultAgentConfig()
+
+	lastProcs := Process.lastProcs
+	defer func() { Process.lastProcs = lastProcs }()
+	Process.lastProcs = map[int32]*process.FilledProcess{
+		1: makeProcess(1, "nginx -g daemon off;"),
+		2: makeProcess(2, "redis-server"),
+	}
+	conns := []tracer.ConnectionStats{
+		{Pid: 1, Source: "10.0.0.1", SPort: 80, Dest: "10.0.0.2", DPort: 50000},
+		{Pid: 2, Source: "10.0.0.1", SPort: 6379, Dest: "10.0.0.2", DPort: 50001},
+	}
+
+	var lookups [][]uint32
+	c := &ConnectionsCheck{buf: new(bytes.Buffer), processSource: func(pids []uint32) map[uint32]connectionProcess {
+		lookups = append(lookups, pids)
+		return map[uint32]connectionProcess{
+			1: {name: "nginx", cmdline: []string{"nginx", "-g", "daemon off;"}},
+		}
+	}}
+
+	// Only the pids are reported by default
+	cxs := c.formatConnections(cfg, conns, map[string]tracer.ConnectionStats{}, time.Now(), nil)
+	assert.Len(cxs, 2)
+	for _, cx := range cxs {
+		assert.Empty(cx.ProcessName)
+		assert.Empty(cx.ProcessCmdline)
+	}
+	assert.Empty(lookups)
+
+	cfg.ConnectionsProcessName = true
+	cxs = c.formatConnections(cfg, conns, map[string]tracer.ConnectionStats{}, time.Now(), nil)
+	assert.Len(cxs, 2)
+	assert.Len(lookups, 1)
+	assert.ElementsMatch([]uint32{1, 2}, lookups[0])
+	assert.Equal("nginx", cxs[0].ProcessName)
+	assert.Equal([]string{"nginx", "-g", "daemon off;"}, cxs[0].ProcessCmdline)
+	// Processes unknown to the source have no name
+	assert.Equal(int32(2), cxs[1].Pid)
+	assert.Empty(cxs[1].ProcessName)
+}
+
+func TestConnectionProcessesCache(t *testing.T) {
+	assert := assert.New(t)
+	cfg := config.NewDefaultAgentConfig()
+	procs := map[int32]*process.FilledProcess{
+		1: makeProcess(1, "mysqld --password=secret"),
+		2: makeProcess(2, "nginx"),
+	}
+	procs[1].Name = "mysqld"
+	procs[2].Name = "nginx"
+
+	p := &ProcessCheck{}
+	p.updateConnectionProcesses(cfg, procs, time.Now())
+	assert.Empty(p.processesForPIDs([]uint32{1, 2}))
+
+	cfg.ConnectionsProcessName = true
+	p.updateConnectionProcesses(cfg, procs, time.Now())
+	assert.Equal(map[uint32]connectionProcess{
+		1: {name: "mysqld", cmdline: []string{"mysqld", "--password=********"}},
+	}, p.processesForPIDs([]uint32{1, 3}))
+	// The cached command line is not affected by the process check
+	assert.Equal([]string{"mysqld", "--password=secret"}, procs[1].Cmdline)
+
+	// No command line unless the command lines are collected
+	cfg.ProcessFields = map[string]bool{config.ProcessFieldMemory: true}
+	p.updateConnectionProcesses(cfg, procs, time.Now())
+	assert.Equal(map[uint32]connectionProcess{2: {name: "nginx"}}, p.processesForPIDs([]uint32{2}))
+}
+
+func TestConnectionProcessesExpiry(t *testing.T) {
+	assert := assert.New(t)
+	cfg := config.NewDefaultAgentConfig()
+	cfg.ConnectionsProcessName = true
+	cfg.CacheMaxEntries = 3
+	ttl := connectionProcessRuns * cfg.CheckInterval("process")
+	procs := map[int32]*process.FilledProcess{
+		1: makeProcess(1, "nginx"),
+		2: makeProcess(2, "redis-server"),
+	}
+
+	now := time.Now()
+	p := &ProcessCheck{}
+	p.updateConnectionProcesses(cfg, procs, now)
+	assert.Equal(2, p.connProcesses.Len())
+
+	// pid 1 exits, and is kept until it wasn't seen for a few runs
+	delete(procs, 1)
+	p.updateConnectionProcesses(cfg, procs, now.Add(ttl-time.Second))
+	assert.Len(p.processesForPIDs([]uint32{1, 2}), 2)
+	p.updateConnectionProcesses(cfg, procs, now.Add(ttl))
+	assert.Equal(1, p.connProcesses.Len())
+	assert.Len(p.processesForPIDs([]uint32{1, 2}), 1)
+
+	// The least recently seen processes are evicted once the cache is full
+	for pid := int32(3); pid <= 5; pid++ {
+		procs[pid] = makeProcess(pid, "worker")
+	}
+	p.updateConnectionProcesses(cfg, procs, now.Add(ttl))
+	assert.Equal(3, p.connProcesses.Len())
+}
@@ -1,7 +1,11 @@
 package checks
 
 import (
+	"context"
+	"sort"
+	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/DataDog/gopsutil/cpu"
@@ -12,12 +16,18 @@ import (
 	"github.com/DataDog/datadog-process-agent/config"
 	"github.com/DataDog/datadog-process-agent/model"
 	"github.com/DataDog/datadog-process-agent/statsd"
+	"github.com/DataDog/datadog-process-agent/util"
+	"github.com/DataDog/datadog-process-agent/util/cache"
 	"github.com/DataDog/datadog-process-agent/util/container"
 )
 
 // Process is a singleton ProcessCheck.
 var Process = &ProcessCheck{}
 
+// processSampleRun is the run of the process check whose process_sampling_rate sample is
+// also reported by the real-time process check, accessed atomically.
+var processSampleRun uint64
+
 // ProcessCheck collects full state, including cmdline args and related metadata,
 // for live and running processes. The instance will store some state between
 // checks that will be used for rates, cpu calculations, etc.
@@ -25,14 +35,21 @@ type ProcessCheck struct {
 	sync.Mutex
 
 	sysInfo        *model.SystemInfo
+	endpointPrefix string
 	lastCPUTime    cpu.TimesStat
 	lastProcs      map[int32]*process.FilledProcess
 	lastContainers []*docker.Container
 	lastRun        time.Time
+	// Number of runs so far, picking the processes sampled by process_sampling_rate
+	runs uint64
+
+	// Processes reported with their connections, when enabled, keyed by pid
+	connProcesses *cache.LRU
 }
 
 // Init initializes the singleton ProcessCheck.
 func (p *ProcessCheck) Init(cfg *config.AgentConfig, info *model.SystemInfo) {
+	p.endpointPrefix = cfg.CollectorPath
 	p.sysInfo = info
 }
 
@@ -40,7 +57,7 @@ func (p *ProcessCheck) Init(cfg *config.AgentConfig, info *model.SystemInfo) {
 func (p *ProcessCheck) Name() string { return "process" }
 
 // Endpoint returns the endpoint where this check is submitted.
-func (p *ProcessCheck) Endpoint() string { return "/api/v1/collector" }
+func (p *ProcessCheck) Endpoint() string { return p.endpointPrefix + "/api/v1/collector" }
 
 // RealTime indicates if this check only runs in real-time mode.
 func (p *ProcessCheck) RealTime() bool { return false }
@@ -52,7 +69,7 @@ func (p *ProcessCheck) RealTime() bool { return false }
 // Processes are split up into a chunks of at most 100 processes per message to
 // limit the message size on intake.
 // See agent.proto for the schema of the message and models used.
-func (p *ProcessCheck) Run(cfg *config.AgentConfig, groupID int32) ([]model.MessageBody, error) {
+func (p *ProcessCheck) Run(ctx context.Context, cfg *config.AgentConfig, groupID int32) ([]model.MessageBody, error) {
 	p.Lock()
 	defer p.Unlock()
 
@@ -65,6 +82,10 @@ func (p *ProcessCheck) Run(cfg *config.AgentConfig, groupID int32) ([]model.Mess
 	if err != nil {
 		return nil, err
 	}
+	startTimes.normalize(procs, time.Now())
+	fillProcessIO(cfg, procs, hostProcessIO)
+	fillProcessThreads(cfg, procs, hostProcessThreads)
+	p.updateConnectionProcesses(cfg, procs, start)
 	containers, _ := container.GetContainers()
 
 	// End check early if this is our first run.
@@ -76,14 +97,23 @@ func (p *ProcessCheck) Run(cfg *config.AgentConfig, groupID int32) ([]model.Mess
 		return nil, nil
 	}
 
-	chunkedProcs := fmtProcesses(cfg, procs, p.lastProcs,
-		containers, cpuTimes[0], p.lastCPUTime, p.lastRun)
+	ctrIDs := pidContainerIDs(cfg, procs, containers)
+	keptProcs, filtered := filterLowUsageProcesses(cfg, procs, p.lastProcs, ctrIDs, cpuTimes[0], p.lastCPUTime)
+	sampledProcs, sampledOut := sampleProcesses(cfg, keptProcs, p.lastProcs, ctrIDs, cpuTimes[0], p.lastCPUTime, p.runs)
+	atomic.StoreUint64(&processSampleRun, p.runs)
+	p.runs++
+	limitedProcs, truncated := limitProcesses(cfg, sampledProcs, p.lastProcs)
+	if truncated > 0 {
+		log.Infof("Reached max_processes, leaving out the %d processes using the least %s", truncated, cfg.MaxProcessesPriority)
+	}
+	chunkedProcs := fmtProcesses(cfg, limitedProcs, p.lastProcs,
+		ctrIDs, cpuTimes[0], p.lastCPUTime, p.lastRun)
 	// In case we skip every process..
 	if len(chunkedProcs) == 0 {
 		return nil, nil
 	}
 	groupSize := len(chunkedProcs)
-	chunkedContainers := fmtContainers(containers, p.lastContainers, p.lastRun, groupSize)
+	chunkedContainers := fmtContainers(containers, p.lastContainers, container.GetLifecycles(containers), container.GetCPUThrottling(containers), container.GetLabelTags(containers, cfg.ContainerLabelTags, cfg.ContainerLabelTagsMax), p.lastRun, groupSize)
 	messages := make([]model.MessageBody, 0, groupSize)
 	totalProcs, totalContainers := float64(0), float64(0)
 	for i := 0; i < groupSize; i++ {
@@ -96,6 +126,11 @@ func (p *ProcessCheck) Run(cfg *config.AgentConfig, groupID int32) ([]model.Mess
 			Containers: chunkedContainers[i],
 			GroupId:    groupID,
 			GroupSize:  int32(groupSize),
+			HostTags:   cfg.Tags,
+
+			TruncatedProcesses:  int32(truncated),
+			FilteredProcesses:   int32(filtered),
+			SampledOutProcesses: int32(sampledOut),
 		})
 	}
 
@@ -115,49 +150,82 @@ func (p *ProcessCheck) Run(cfg *config.AgentConfig, groupID int32) ([]model.Mess
 func fmtProcesses(
 	cfg *config.AgentConfig,
 	procs, lastProcs map[int32]*process.FilledProcess,
-	containers []*docker.Container,
+	ctrIDs map[int32]string,
 	syst2, syst1 cpu.TimesStat,
 	lastRun time.Time,
 ) [][]*model.Process {
-	ctrByPid := make(map[int32]*docker.Container, len(containers))
-	for _, c := range containers {
-		for _, p := range c.Pids {
-			ctrByPid[p] = c
-		}
-	}
-
 	chunked := make([][]*model.Process, 0)
 	chunk := make([]*model.Process, 0, cfg.MaxPerMessage)
+	chunkBytes := 0
+	services := processServices(cfg)
+	connections := newConnectionCounter(util.HostProc())
 	for _, fp := range procs {
 		if skipProcess(cfg, fp, lastProcs) {
 			continue
 		}
 
-		ctr, ok := ctrByPid[fp.Pid]
-		if !ok {
-			ctr = docker.NullContainer
+		proc := &model.Process{
+			Pid:         fp.Pid,
+			Command:     formatCommand(fp),
+			CreateTime:  fp.CreateTime,
+			State:       model.ProcessState(model.ProcessState_value[fp.Status]),
+			ContainerId: ctrIDs[fp.Pid],
+			Services:    services[fp.Pid],
+		}
+		if ns, err := util.ReadNetNamespace(util.HostProc(), fp.Pid); err == nil {
+			proc.NetNs = ns
+		}
+		if cfg.CollectsProcessField(config.ProcessFieldCmdline) {
+			// Hide blacklisted args if the Scrubber is enabled
+			fp.Cmdline = cfg.Scrubber.ScrubProcessCommand(fp)
+			proc.Command.Args = cfg.Scrubber.TruncateCommand(fp.Cmdline)
+		}
+		if cfg.CollectsProcessField(config.ProcessFieldUser) {
+			proc.User = formatUser(cfg, fp)
+			proc.User.Name = cfg.Scrubber.ScrubUsername(proc.User.Name)
+		}
+		if cfg.CollectsProcessField(config.ProcessFieldMemory) {
+			proc.Memory = formatMemory(fp)
+		}
+		if cfg.CollectsProcessField(config.ProcessFieldCPU) {
+			proc.Cpu = formatCPU(fp, fp.CpuTime, lastProcs[fp.Pid].CpuTime, syst2, syst1)
+		}
+		if cfg.CollectsProcessField(config.ProcessFieldIO) {
+			proc.IoStat = formatIO(fp, lastProcs[fp.Pid].IOStat, lastRun)
+		}
+		if cfg.CollectsProcessField(config.ProcessFieldFDs) {
+			proc.OpenFdCount = fp.OpenFdCount
+		}
+		if cfg.CollectsProcessField(config.ProcessFieldCtxSwitches) {
+			proc.VoluntaryCtxSwitches = uint64(fp.CtxSwitches.Voluntary)
+			proc.InvoluntaryCtxSwitches = uint64(fp.CtxSwitches.Involuntary)
+		}
+		if cfg.CollectsProcessField(config.ProcessFieldThreads) {
+			proc.Threads = fp.NumThreads
+		} else if proc.Cpu != nil {
+			proc.Cpu.NumThreads = 0
+		}
+		if cfg.CollectsProcessField(config.ProcessFieldConnections) {
+			proc.OpenConnections = connections.count(fp.Pid)
+		}
+		if cfg.CollectsProcessEnv(fp) {
+			proc.Env = formatEnv(cfg, fp)
 		}
 
-		// Hide blacklisted args if the Scrubber is enabled
-		fp.Cmdline = cfg.Scrubber.ScrubProcessCommand(fp)
-
-		chunk = append(chunk, &model.Process{
-			Pid:                    fp.Pid,
-			Command:                formatCommand(fp),
-			User:                   formatUser(fp),
-			Memory:                 formatMemory(fp),
-			Cpu:                    formatCPU(fp, fp.CpuTime, lastProcs[fp.Pid].CpuTime, syst2, syst1),
-			CreateTime:             fp.CreateTime,
-			OpenFdCount:            fp.OpenFdCount,
-			State:                  model.ProcessState(model.ProcessState_value[fp.Status]),
-			IoStat:                 formatIO(fp, lastProcs[fp.Pid].IOStat, lastRun),
-			VoluntaryCtxSwitches:   uint64(fp.CtxSwitches.Voluntary),
-			InvoluntaryCtxSwitches: uint64(fp.CtxSwitches.Involuntary),
-			ContainerId:            ctr.ID,
-		})
+		// Start a new chunk early if this process would push the message over the byte limit
+		size := proc.Size()
+		if len(chunk) > 0 && exceedsMessageBytes(cfg, chunkBytes+size) {
+			chunked = append(chunked, chunk)
+			chunk = make([]*model.Process, 0, cfg.MaxPerMessage)
+			chunkBytes = 0
+		}
+		chunk = append(chunk, proc)
+		chunkBytes += size
+
 		if len(chunk) == cfg.MaxPerMessage {
 			chunked = append(chunked, chunk)
 			chunk = make([]*model.Process, 0, cfg.MaxPerMessage)
+			chunkBytes = 0
 		}
 	}
 	if len(chunk) > 0 {
@@ -167,9 +235,35 @@ func fmtProcesses(
 	return chunked
 }
 
+// pidContainerIDs maps the pids of the processes to the ID of their container. The pids
+// listed by the container runtime are used first, the others are looked up from their
+// cgroup so processes of containers the runtime doesn't know about are associated too.
+func pidContainerIDs(
+	cfg *config.AgentConfig,
+	procs map[int32]*process.FilledProcess,
+	containers []*docker.Container,
+) map[int32]string {
+	ctrIDs := make(map[int32]string, len(procs))
+	for _, c := range containers {
+		for _, p := range c.Pids {
+			ctrIDs[p] = c.ID
+		}
+	}
+	for pid, fp := range procs {
+		if _, ok := ctrIDs[pid]; ok {
+			continue
+		}
+		if id := container.GetPidContainerID(pid, fp.CreateTime, cfg.ContainerCacheDuration, cfg.CacheMaxEntries); id != "" {
+			ctrIDs[pid] = id
+		}
+	}
+	return ctrIDs
+}
+
+// formatCommand returns the command of the process, without its arguments which are
+// only collected if the cmdline field is enabled.
 func formatCommand(fp *process.FilledProcess) *model.Command {
 	return &model.Command{
-		Args:   fp.Cmdline,
 		Cwd:    fp.Cwd,
 		Root:   "",    // TODO
 		OnDisk: false, // TODO
@@ -178,6 +272,23 @@ func formatCommand(fp *process.FilledProcess) *model.Command {
 	}
 }
 
+// formatEnv returns the scrubbed environment variables of the process as KEY=VALUE,
+// sorted by name.
+func formatEnv(cfg *config.AgentConfig, fp *process.FilledProcess) []string {
+	env, err := util.ReadProcessEnv(util.HostProc(), fp.Pid)
+	if err != nil {
+		log.Debugf("unable to read the environment of pid %d: %s", fp.Pid, err)
+		return nil
+	}
+	env = cfg.Scrubber.ScrubEnv(env)
+	formatted := make([]string, 0, len(env))
+	for k, v := range env {
+		formatted = append(formatted, k+"="+v)
+	}
+	sort.Strings(formatted)
+	return formatted
+}
+
 func formatIO(fp *process.FilledProcess, lastIO *process.IOCountersStat, before time.Time) *model.IOStat {
 	// This will be nill for Mac
 	if fp.IOStat == nil {
@@ -234,17 +345,33 @@ func formatMemory(fp *process.FilledProcess) *model.MemoryStat {
 	return ms
 }
 
-// skipProcess will skip a given process if it's blacklisted or hasn't existed
-// for multiple collections.
+// kthreadd is the pid of the parent of the kernel threads on Linux.
+const kthreadd = 2
+
+// isKernelThread returns true if the process is a kernel thread, which has no command line.
+// They are the children of kthreadd, or the processes without parent like kthreadd itself
+// and the System process on Windows, and are shown with their name in brackets by ps.
+func isKernelThread(fp *process.FilledProcess) bool {
+	if len(fp.Cmdline) > 0 {
+		return false
+	}
+	bracketed := strings.HasPrefix(fp.Name, "[") && strings.HasSuffix(fp.Name, "]")
+	return fp.Ppid == 0 || fp.Ppid == kthreadd || bracketed
+}
+
+// skipProcess will skip a given process if it isn't collected, e.g. blacklisted, or
+// hasn't existed for multiple collections. Kernel threads are skipped unless
+// collect_kernel_threads is set, but the user processes with an empty command line,
+// e.g. zombies, are kept.
 func skipProcess(
 	cfg *config.AgentConfig,
 	fp *process.FilledProcess,
 	lastProcs map[int32]*process.FilledProcess,
 ) bool {
-	if len(fp.Cmdline) == 0 {
+	if !cfg.CollectKernelThreads && isKernelThread(fp) {
 		return true
 	}
-	if config.IsBlacklisted(fp.Cmdline, cfg.Blacklist) {
+	if !cfg.ShouldCollectProcess(fp) {
 		return true
 	}
 	if _, ok := lastProcs[fp.Pid]; !ok {
@@ -255,13 +382,16 @@ func skipProcess(
 	return false
 }
 
-func (p *ProcessCheck) createTimesforPIDs(pids []uint32) map[uint32]int64 {
+// createTimesforPIDs returns the create time of each of the given pids seen in the
+// last run. The processes not collected, e.g. blacklisted, are left out, using the
+// cmdlines already collected by the last run so that no extra reads from procfs are needed.
+func (p *ProcessCheck) createTimesforPIDs(cfg *config.AgentConfig, pids []uint32) map[uint32]int64 {
 	p.Lock()
 	defer p.Unlock()
 
 	createTimeForPID := make(map[uint32]int64)
 	for _, pid := range pids {
-		if p, ok := p.lastProcs[int32(pid)]; ok {
+		if p, ok := p.lastProcs[int32(pid)]; ok && cfg.ShouldCollectProcess(p) {
 			createTimeForPID[pid] = p.CreateTime
 		}
 	}
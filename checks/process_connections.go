@@ -0,0 +1,66 @@
+package checks
+
+import (
+	"path/filepath"
+	"strconv"
+
+	log "github.com/cihub/seelog"
+
+	"github.com/DataDog/datadog-process-agent/util"
+)
+
+// connectionCounter counts the TCP and UDP connections opened by the processes from
+// procfs, without the connections check. The sockets of each network namespace are read
+// once per check run, from the first process seen in the namespace.
+type connectionCounter struct {
+	procRoot    string
+	byNamespace map[uint32]map[uint64]struct{}
+}
+
+func newConnectionCounter(procRoot string) *connectionCounter {
+	return &connectionCounter{procRoot: procRoot, byNamespace: make(map[uint32]map[uint64]struct{})}
+}
+
+// count returns the number of connections opened by the given process, the listening TCP
+// sockets left out. It returns 0 if the sockets of the process can't be read.
+func (c *connectionCounter) count(pid int32) int32 {
+	inodes, err := util.ReadSocketInodes(c.procRoot, pid)
+	if err != nil || len(inodes) == 0 {
+		return 0
+	}
+	ns, err := util.ReadNetNamespace(c.procRoot, pid)
+	if err != nil {
+		return 0
+	}
+	conns, ok := c.byNamespace[ns]
+	if !ok {
+		conns = readNamespaceConnections(c.procRoot, pid)
+		c.byNamespace[ns] = conns
+	}
+
+	n := int32(0)
+	for _, inode := range inodes {
+		if _, ok := conns[inode]; ok {
+			n++
+		}
+	}
+	return n
+}
+
+// readNamespaceConnections returns the inodes of the TCP and UDP connections of the network
+// namespace of the given process.
+func readNamespaceConnections(procRoot string, pid int32) map[uint64]struct{} {
+	netDir := filepath.Join(procRoot, strconv.Itoa(int(pid)), "net")
+	conns := make(map[uint64]struct{})
+	for _, f := range []string{"tcp", "tcp6", "udp", "udp6"} {
+		inodes, err := util.ReadConnectionInodes(filepath.Join(netDir, f))
+		if err != nil {
+			log.Debugf("unable to read the connections of pid %d: %s", pid, err)
+			continue
+		}
+		for _, inode := range inodes {
+			conns[inode] = struct{}{}
+		}
+	}
+	return conns
+}
@@ -0,0 +1,81 @@
+package checks
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DataDog/gopsutil/cpu"
+	"github.com/DataDog/gopsutil/process"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/DataDog/datadog-process-agent/config"
+)
+
+const procNetUDPHeader = "  sl  local_address rem_address   st tx_queue rx_queue tr tm->when retrnsmt   uid  timeout inode ref pointer drops\n"
+
+func TestConnectionCounter(t *testing.T) {
+	assert := assert.New(t)
+	procRoot, err := ioutil.TempDir("", "proc")
+	assert.NoError(err)
+	defer os.RemoveAll(procRoot)
+
+	// pids 1 and 2 share a namespace: 0.0.0.0:80 listening, two connections on :80 and a
+	// UDP socket sending to 10.0.0.53:53
+	writeNetNsFixture(t, procRoot, "1", "net:[4026531992]")
+	writeNetNsFixture(t, procRoot, "2", "net:[4026531992]")
+	writeProcFixture(t, procRoot, "1/net/tcp", procNetTCPHeader+
+		"   0: 00000000:0050 00000000:0000 0A 00000000:00000000 00:00000000 00000000     0        0 1000 1 0 20 4 30 10 -1\n"+
+		"   1: 0100000A:0050 0200000A:C350 01 00000000:00000000 00:00000000 00000000     0        0 1001 1 0 20 4 30 10 -1\n"+
+		"   2: 0100000A:0050 0300000A:C351 08 00000000:00000000 00:00000000 00000000     0        0 1002 1 0 20 4 30 10 -1\n")
+	writeProcFixture(t, procRoot, "1/net/udp", procNetUDPHeader+
+		"   0: 0100000A:A1B2 3500000A:0035 01 00000000:00000000 00:00000000 00000000     0        0 1003 2 0 0\n")
+	writeSocketFixture(t, procRoot, "1", "3", "1000")
+	writeSocketFixture(t, procRoot, "1", "4", "1003")
+	writeSocketFixture(t, procRoot, "2", "3", "1000")
+	writeSocketFixture(t, procRoot, "2", "4", "1001")
+	writeSocketFixture(t, procRoot, "2", "5", "1002")
+	// A socket of another family, e.g. a unix socket, isn't a connection
+	writeSocketFixture(t, procRoot, "2", "6", "2000")
+	assert.NoError(ioutil.WriteFile(filepath.Join(procRoot, "2", "fd", "7"), nil, 0644))
+
+	// pid 3 reuses inode 1001 in another namespace without sockets files
+	writeNetNsFixture(t, procRoot, "3", "net:[4026532281]")
+	writeSocketFixture(t, procRoot, "3", "3", "1001")
+
+	counter := newConnectionCounter(procRoot)
+	assert.Equal(int32(1), counter.count(1))
+	assert.Equal(int32(2), counter.count(2))
+	assert.Equal(int32(0), counter.count(3))
+	// Unknown processes have no connection
+	assert.Equal(int32(0), counter.count(4))
+	assert.Len(counter.byNamespace, 2)
+}
+
+func TestProcessOpenConnections(t *testing.T) {
+	procRoot, err := ioutil.TempDir("", "proc")
+	assert.NoError(t, err)
+	defer os.RemoveAll(procRoot)
+	defer os.Setenv("HOST_PROC", os.Getenv("HOST_PROC"))
+	os.Setenv("HOST_PROC", procRoot)
+
+	writeNetNsFixture(t, procRoot, "1", "net:[4026531992]")
+	writeProcFixture(t, procRoot, "1/net/tcp6", procNetTCPHeader+
+		"   0: 0000000000000000FFFF00000100000A:0050 0000000000000000FFFF00000200000A:C350 01 00000000:00000000 00:00000000 00000000     0        0 1001 1 0 20 4 30 10 -1\n")
+	writeSocketFixture(t, procRoot, "1", "3", "1001")
+	procs := map[int32]*process.FilledProcess{1: makeProcess(1, "nginx: worker process")}
+
+	cfg := config.NewDefaultAgentConfig()
+	cfg.Blacklist = []*regexp.Regexp{}
+	syst1, syst2 := cpu.TimesStat{}, cpu.TimesStat{}
+	chunked := fmtProcesses(cfg, procs, procs, nil, syst2, syst1, time.Now())
+	assert.Equal(t, int32(1), chunked[0][0].OpenConnections)
+
+	// The connections aren't counted unless selected with collect_fields
+	cfg.ProcessFields = map[string]bool{config.ProcessFieldCPU: true}
+	chunked = fmtProcesses(cfg, procs, procs, nil, syst2, syst1, time.Now())
+	assert.Equal(t, int32(0), chunked[0][0].OpenConnections)
+}
@@ -0,0 +1,121 @@
+package checks
+
+import (
+	"math"
+
+	"github.com/DataDog/gopsutil/process"
+
+	"github.com/DataDog/datadog-process-agent/model"
+)
+
+// processKey identifies a process across check runs, as pids can be reused.
+type processKey struct {
+	pid        int32
+	createTime int64
+}
+
+func statKey(s *model.ProcessStat) processKey {
+	return processKey{pid: s.Pid, createTime: s.CreateTime}
+}
+
+// processDelta keeps the process stats last reported by the real-time check so
+// that only the processes that are new, changed or exited since are reported.
+type processDelta struct {
+	// CPU usage change, in percentage points, above which a process is reported again
+	cpuThreshold float32
+	// RSS change, as a fraction of the last reported value, above which a process is reported again
+	memThreshold float64
+
+	reported map[processKey]*model.ProcessStat
+}
+
+func newProcessDelta(cpuThreshold float32, memThreshold float64) *processDelta {
+	return &processDelta{
+		cpuThreshold: cpuThreshold,
+		memThreshold: memThreshold,
+	}
+}
+
+// reset records a full snapshot as reported.
+func (d *processDelta) reset(stats []*model.ProcessStat) {
+	d.reported = make(map[processKey]*model.ProcessStat, len(stats))
+	for _, s := range stats {
+		d.reported[statKey(s)] = s
+	}
+}
+
+// diff returns the stats of the processes that are new or changed since they were
+// last reported, and the processes that exited since, with only their pid and
+// create time set. The changed stats are recorded as reported. Processes missing
+// from the stats but still live, e.g. left out by max_processes, didn't exit: they
+// keep their last reported stats.
+func (d *processDelta) diff(stats []*model.ProcessStat, live map[processKey]struct{}) (changed, exited []*model.ProcessStat) {
+	seen := make(map[processKey]struct{}, len(stats))
+	for _, s := range stats {
+		key := statKey(s)
+		seen[key] = struct{}{}
+		if prev, ok := d.reported[key]; ok && !d.hasChanged(prev, s) {
+			continue
+		}
+		d.reported[key] = s
+		changed = append(changed, s)
+	}
+
+	for key := range d.reported {
+		if _, ok := seen[key]; ok {
+			continue
+		}
+		if _, ok := live[key]; ok {
+			continue
+		}
+		delete(d.reported, key)
+		exited = append(exited, &model.ProcessStat{Pid: key.pid, CreateTime: key.createTime})
+	}
+	return changed, exited
+}
+
+// hasChanged compares the current stats of a process to the last reported ones.
+// Changes below the thresholds are ignored so that mostly idle processes aren't
+// reported on every run.
+func (d *processDelta) hasChanged(prev, cur *model.ProcessStat) bool {
+	if prev.ProcessState != cur.ProcessState || prev.Threads != cur.Threads {
+		return true
+	}
+	if prev.Cpu != nil && cur.Cpu != nil {
+		if math.Abs(float64(cur.Cpu.TotalPct-prev.Cpu.TotalPct)) >= float64(d.cpuThreshold) {
+			return true
+		}
+	}
+	if prev.Memory != nil && cur.Memory != nil && prev.Memory.Rss != cur.Memory.Rss {
+		if prev.Memory.Rss == 0 {
+			return true
+		}
+		diff := math.Abs(float64(cur.Memory.Rss) - float64(prev.Memory.Rss))
+		if diff/float64(prev.Memory.Rss) >= d.memThreshold {
+			return true
+		}
+	}
+	return false
+}
+
+// liveProcessKeys returns the keys of all the running processes, reported or not.
+func liveProcessKeys(procs map[int32]*process.FilledProcess) map[processKey]struct{} {
+	keys := make(map[processKey]struct{}, len(procs))
+	for _, fp := range procs {
+		keys[processKey{pid: fp.Pid, createTime: fp.CreateTime}] = struct{}{}
+	}
+	return keys
+}
+
+// chunkProcessStats splits the stats into chunks of at most perChunk stats.
+func chunkProcessStats(stats []*model.ProcessStat, perChunk int) [][]*model.ProcessStat {
+	chunked := make([][]*model.ProcessStat, 0, len(stats)/perChunk+1)
+	for len(stats) > perChunk {
+		chunked = append(chunked, stats[:perChunk])
+		stats = stats[perChunk:]
+	}
+	if len(stats) > 0 {
+		chunked = append(chunked, stats)
+	}
+	return chunked
+}
@@ -0,0 +1,210 @@
+package checks
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/DataDog/gopsutil/cpu"
+	"github.com/DataDog/gopsutil/process"
+
+	"github.com/DataDog/datadog-process-agent/config"
+	"github.com/DataDog/datadog-process-agent/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func makeProcessStat(pid int32, createTime int64, cpuPct float32, rss uint64) *model.ProcessStat {
+	return &model.ProcessStat{
+		Pid:          pid,
+		CreateTime:   createTime,
+		Threads:      1,
+		ProcessState: model.ProcessState_S,
+		Cpu:          &model.CPUStat{TotalPct: cpuPct},
+		Memory:       &model.MemoryStat{Rss: rss},
+	}
+}
+
+func TestProcessDelta(t *testing.T) {
+	assert := assert.New(t)
+	d := newProcessDelta(1, 0.05)
+	d.reset([]*model.ProcessStat{
+		makeProcessStat(1, 100, 10, 1000),
+		makeProcessStat(2, 100, 10, 1000),
+		makeProcessStat(3, 100, 10, 1000),
+		makeProcessStat(4, 100, 10, 1000),
+		makeProcessStat(5, 100, 10, 1000),
+	})
+
+	stopped := makeProcessStat(5, 100, 10, 1000)
+	stopped.ProcessState = model.ProcessState_T
+	changed, exited := d.diff([]*model.ProcessStat{
+		makeProcessStat(1, 100, 10.5, 1020), // below the thresholds
+		makeProcessStat(2, 100, 12, 1000),   // CPU changed
+		makeProcessStat(3, 100, 10, 1100),   // memory changed
+		makeProcessStat(4, 200, 10, 1000),   // pid reused by a new process
+		stopped,
+		makeProcessStat(6, 100, 0, 1000), // new process
+	}, nil)
+
+	var changedPids []int32
+	for _, s := range changed {
+		changedPids = append(changedPids, s.Pid)
+	}
+	assert.Equal([]int32{2, 3, 4, 5, 6}, changedPids)
+	assert.Equal(int64(200), changed[2].CreateTime)
+	assert.Equal([]*model.ProcessStat{{Pid: 4, CreateTime: 100}}, exited)
+
+	// Changes are compared to the last reported stats, so a slow drift is eventually reported
+	changed, exited = d.diff([]*model.ProcessStat{
+		makeProcessStat(1, 100, 11, 1000),
+		makeProcessStat(2, 100, 12, 1000),
+		makeProcessStat(3, 100, 10, 1100),
+		makeProcessStat(4, 200, 10, 1000),
+		stopped,
+		makeProcessStat(6, 100, 0, 1000),
+	}, nil)
+	assert.Len(changed, 1)
+	assert.Equal(int32(1), changed[0].Pid)
+	assert.Empty(exited)
+}
+
+func TestRTProcessApplyDelta(t *testing.T) {
+	assert := assert.New(t)
+	cfg := config.NewDefaultAgentConfig()
+	cfg.MaxPerMessage = 2
+	r := &RTProcessCheck{delta: newProcessDelta(1, 0.05)}
+
+	first := chunkProcessStats([]*model.ProcessStat{
+		makeProcessStat(1, 100, 10, 1000),
+		makeProcessStat(2, 100, 10, 1000),
+		makeProcessStat(3, 100, 10, 1000),
+	}, cfg.MaxPerMessage)
+	now := time.Now()
+
+	// The first run is a full snapshot
+	chunked, exited, delta := r.applyDelta(cfg, first, nil, now)
+	assert.False(delta)
+	assert.Equal(first, chunked)
+	assert.Empty(exited)
+
+	// Only the delta is reported until the process check interval is over
+	second := chunkProcessStats([]*model.ProcessStat{
+		makeProcessStat(1, 100, 10, 1000),
+		makeProcessStat(2, 100, 50, 1000),
+	}, cfg.MaxPerMessage)
+	chunked, exited, delta = r.applyDelta(cfg, second, nil, now.Add(2*time.Second))
+	assert.True(delta)
+	assert.Equal([][]*model.ProcessStat{{second[0][1]}}, chunked)
+	assert.Equal([]*model.ProcessStat{{Pid: 3, CreateTime: 100}}, exited)
+
+	// Nothing changed, there is still a message for the container stats
+	chunked, exited, delta = r.applyDelta(cfg, second, nil, now.Add(4*time.Second))
+	assert.True(delta)
+	assert.Equal([][]*model.ProcessStat{{}}, chunked)
+	assert.Empty(exited)
+
+	chunked, _, delta = r.applyDelta(cfg, second, nil, now.Add(cfg.CheckInterval("process")))
+	assert.False(delta)
+	assert.Equal(second, chunked)
+}
+
+func TestRTProcessDeltaMaxProcesses(t *testing.T) {
+	assert := assert.New(t)
+	cfg := config.NewDefaultAgentConfig()
+	cfg.MaxProcesses = 2
+	r := &RTProcessCheck{delta: newProcessDelta(1, 0.05)}
+
+	procs := make(map[int32]*process.FilledProcess)
+	for pid := int32(1); pid <= 3; pid++ {
+		fp := makeProcess(pid, "server")
+		fp.CreateTime = 100
+		procs[pid] = fp
+	}
+	lastProcs := make(map[int32]*process.FilledProcess)
+	for pid, fp := range procs {
+		last := *fp
+		lastProcs[pid] = &last
+	}
+	now := time.Now()
+	run := func(busiest ...int32) ([][]*model.ProcessStat, []*model.ProcessStat) {
+		for _, fp := range procs {
+			fp.CpuTime = cpu.TimesStat{}
+		}
+		for i, pid := range busiest {
+			procs[pid].CpuTime = cpu.TimesStat{User: float64(10 - i)}
+		}
+		limited, _ := limitProcesses(cfg, procs, lastProcs)
+		chunked := fmtProcessStats(cfg, limited, lastProcs, nil, cpu.TimesStat{}, cpu.TimesStat{}, now)
+		now = now.Add(2 * time.Second)
+		chunked, exited, _ := r.applyDelta(cfg, chunked, liveProcessKeys(procs), now)
+		return chunked, exited
+	}
+
+	run(1, 2)
+	// The live process 2 drops out of the top 2 without being reported as exited
+	chunked, exited := run(1, 3)
+	assert.Empty(exited)
+	assert.Len(chunked[0], 1)
+	assert.Equal(int32(3), chunked[0][0].Pid)
+
+	// Once it really exits it is
+	delete(procs, 2)
+	_, exited = run(1, 3)
+	assert.Equal([]*model.ProcessStat{{Pid: 2, CreateTime: 100}}, exited)
+}
+
+func TestRTProcessMinUsage(t *testing.T) {
+	assert := assert.New(t)
+	cfg := config.NewDefaultAgentConfig()
+	cfg.MinMemoryBytes = 1024 * 1024
+	r := &RTProcessCheck{delta: newProcessDelta(1, 0.05), lastProcs: make(map[int32]*process.FilledProcess)}
+
+	procs := make(map[int32]*process.FilledProcess)
+	for pid := int32(1); pid <= 2; pid++ {
+		fp := makeProcess(pid, "server")
+		fp.CreateTime = 100
+		fp.MemInfo = &process.MemoryInfoStat{RSS: 2 * 1024 * 1024}
+		procs[pid] = fp
+		last := *fp
+		r.lastProcs[pid] = &last
+	}
+	procs[2].MemInfo = &process.MemoryInfoStat{RSS: 1024}
+
+	// Like in the process check, the processes below the thresholds are left out
+	now := time.Now()
+	reported := r.reportedProcesses(cfg, procs, nil, cpu.TimesStat{})
+	assert.Len(reported, 1)
+	assert.Contains(reported, int32(1))
+
+	// Without being reported as exited
+	chunked := fmtProcessStats(cfg, reported, r.lastProcs, nil, cpu.TimesStat{}, cpu.TimesStat{}, now)
+	r.applyDelta(cfg, chunked, liveProcessKeys(procs), now)
+	_, exited, _ := r.applyDelta(cfg, chunked, liveProcessKeys(procs), now.Add(2*time.Second))
+	assert.Empty(exited)
+}
+
+func TestRTProcessSampling(t *testing.T) {
+	assert := assert.New(t)
+	cfg := config.NewDefaultAgentConfig()
+	cfg.ProcessSamplingRate = 0.5
+	r := &RTProcessCheck{lastProcs: make(map[int32]*process.FilledProcess)}
+	defer atomic.StoreUint64(&processSampleRun, 0)
+
+	procs := make(map[int32]*process.FilledProcess)
+	for pid := int32(1); pid <= 50; pid++ {
+		fp := makeProcess(pid, "server")
+		fp.CreateTime = 100
+		procs[pid] = fp
+		last := *fp
+		r.lastProcs[pid] = &last
+	}
+
+	// The real-time stats are reported for the sample of the last run of the process check
+	for run := uint64(0); run < 3; run++ {
+		atomic.StoreUint64(&processSampleRun, run)
+		sampled, _ := sampleProcesses(cfg, procs, r.lastProcs, nil, cpu.TimesStat{}, cpu.TimesStat{}, run)
+		reported := r.reportedProcesses(cfg, procs, nil, cpu.TimesStat{})
+		assert.Equal(sampled, reported)
+		assert.True(len(reported) < len(procs))
+	}
+}
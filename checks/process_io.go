@@ -0,0 +1,90 @@
+package checks
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/DataDog/gopsutil/process"
+	log "github.com/cihub/seelog"
+
+	"github.com/DataDog/datadog-process-agent/config"
+	"github.com/DataDog/datadog-process-agent/util"
+)
+
+// ioPermissionWarning logs once that the IO of some processes can't be read.
+var ioPermissionWarning sync.Once
+
+// fillProcessIO reads the IO counters of the processes that weren't collected with them
+// using readIO, or drops all of them if the collection is disabled. Processes whose
+// counters can't be read for lack of permissions get zero counters, which formatIO
+// reports as unknown rates.
+func fillProcessIO(cfg *config.AgentConfig, procs map[int32]*process.FilledProcess, readIO func(pid int32) (*process.IOCountersStat, error)) {
+	if !cfg.CollectProcessIO {
+		for _, fp := range procs {
+			fp.IOStat = nil
+		}
+		return
+	}
+
+	for pid, fp := range procs {
+		if fp.IOStat != nil {
+			continue
+		}
+		io, err := readIO(pid)
+		if os.IsPermission(err) {
+			ioPermissionWarning.Do(func() {
+				log.Infof("unable to read the IO stats of some processes, set collect_process_io to false to disable their collection: %s", err)
+			})
+			fp.IOStat = &process.IOCountersStat{}
+			continue
+		}
+		if err != nil {
+			// The process most likely exited
+			log.Debugf("unable to read IO stats of pid %d: %s", pid, err)
+			continue
+		}
+		fp.IOStat = io
+	}
+}
+
+// hostProcessIO reads the IO counters of a process from HOST_PROC.
+func hostProcessIO(pid int32) (*process.IOCountersStat, error) {
+	return readProcessIO(util.HostProc(), pid)
+}
+
+// readProcessIO parses <procRoot>/<pid>/io, which only the owner of the process and
+// root are allowed to read.
+func readProcessIO(procRoot string, pid int32) (*process.IOCountersStat, error) {
+	path := filepath.Join(procRoot, strconv.Itoa(int(pid)), "io")
+	lines, err := util.ReadLines(path)
+	if err != nil {
+		return nil, err
+	}
+
+	io := &process.IOCountersStat{}
+	for _, l := range lines {
+		fields := strings.Fields(l)
+		if len(fields) != 2 {
+			continue
+		}
+		v, err := strconv.ParseUint(fields[1], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s in %s: %s", fields[0], path, err)
+		}
+		switch fields[0] {
+		case "syscr:":
+			io.ReadCount = v
+		case "syscw:":
+			io.WriteCount = v
+		case "read_bytes:":
+			io.ReadBytes = v
+		case "write_bytes:":
+			io.WriteBytes = v
+		}
+	}
+	return io, nil
+}
@@ -0,0 +1,79 @@
+package checks
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+
+	"github.com/DataDog/gopsutil/process"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/DataDog/datadog-process-agent/config"
+)
+
+func TestReadProcessIO(t *testing.T) {
+	assert := assert.New(t)
+	procRoot, err := ioutil.TempDir("", "proc")
+	assert.NoError(err)
+	defer os.RemoveAll(procRoot)
+
+	assert.NoError(os.MkdirAll(filepath.Join(procRoot, "42"), 0755))
+	assert.NoError(ioutil.WriteFile(filepath.Join(procRoot, "42", "io"), []byte(
+		"rchar: 323934931\nwchar: 323929600\nsyscr: 632687\nsyscw: 632675\n"+
+			"read_bytes: 4096\nwrite_bytes: 323932160\ncancelled_write_bytes: 0\n"), 0400))
+
+	io, err := readProcessIO(procRoot, 42)
+	assert.NoError(err)
+	assert.Equal(&process.IOCountersStat{ReadCount: 632687, WriteCount: 632675, ReadBytes: 4096, WriteBytes: 323932160}, io)
+
+	_, err = readProcessIO(procRoot, 43)
+	assert.True(os.IsNotExist(err))
+
+	assert.NoError(os.MkdirAll(filepath.Join(procRoot, "44"), 0755))
+	assert.NoError(ioutil.WriteFile(filepath.Join(procRoot, "44", "io"), []byte("read_bytes: lots\n"), 0400))
+	_, err = readProcessIO(procRoot, 44)
+	assert.Error(err)
+}
+
+func TestFillProcessIO(t *testing.T) {
+	assert := assert.New(t)
+	collected := &process.IOCountersStat{ReadBytes: 1, WriteBytes: 2}
+	read := &process.IOCountersStat{ReadCount: 3, WriteCount: 4, ReadBytes: 5, WriteBytes: 6}
+	readIO := func(pid int32) (*process.IOCountersStat, error) {
+		switch pid {
+		case 2:
+			return read, nil
+		case 3:
+			// As returned when reading the io of a process of another user
+			return nil, &os.PathError{Op: "open", Path: "/proc/3/io", Err: syscall.EACCES}
+		}
+		return nil, errors.New("exited")
+	}
+	newProcs := func() map[int32]*process.FilledProcess {
+		return map[int32]*process.FilledProcess{
+			1: {Pid: 1, IOStat: collected},
+			2: {Pid: 2},
+			3: {Pid: 3},
+			4: {Pid: 4},
+		}
+	}
+
+	cfg := config.NewDefaultAgentConfig()
+	procs := newProcs()
+	fillProcessIO(cfg, procs, readIO)
+	assert.Equal(collected, procs[1].IOStat)
+	assert.Equal(read, procs[2].IOStat)
+	// Unreadable counters are reported as unknown rates
+	assert.Equal(&process.IOCountersStat{}, procs[3].IOStat)
+	assert.Nil(procs[4].IOStat)
+
+	cfg.CollectProcessIO = false
+	procs = newProcs()
+	fillProcessIO(cfg, procs, readIO)
+	for _, fp := range procs {
+		assert.Nil(fp.IOStat)
+	}
+}
@@ -0,0 +1,154 @@
+package checks
+
+import (
+	"math"
+	"sort"
+
+	"github.com/DataDog/gopsutil/cpu"
+	"github.com/DataDog/gopsutil/process"
+
+	"github.com/DataDog/datadog-process-agent/config"
+)
+
+// limitProcesses caps the processes to report to cfg.MaxProcesses, keeping the
+// ones using the most CPU since the last run or the most memory, depending on
+// cfg.MaxProcessesPriority. Processes that would be skipped anyway don't count
+// toward the limit. It returns the processes to format and the number of
+// processes left out.
+func limitProcesses(
+	cfg *config.AgentConfig,
+	procs, lastProcs map[int32]*process.FilledProcess,
+) (map[int32]*process.FilledProcess, int) {
+	if cfg.MaxProcesses <= 0 || len(procs) <= cfg.MaxProcesses {
+		return procs, 0
+	}
+
+	candidates := make([]*process.FilledProcess, 0, len(procs))
+	for _, fp := range procs {
+		if !skipProcess(cfg, fp, lastProcs) {
+			candidates = append(candidates, fp)
+		}
+	}
+	if len(candidates) <= cfg.MaxProcesses {
+		return procs, 0
+	}
+
+	var usage func(fp *process.FilledProcess) float64
+	if cfg.MaxProcessesPriority == config.ProcessFieldMemory {
+		usage = func(fp *process.FilledProcess) float64 {
+			if fp.MemInfo == nil {
+				return 0
+			}
+			return float64(fp.MemInfo.RSS)
+		}
+	} else {
+		usage = func(fp *process.FilledProcess) float64 {
+			last := lastProcs[fp.Pid].CpuTime
+			return (fp.CpuTime.User + fp.CpuTime.System) - (last.User + last.System)
+		}
+	}
+	sort.Slice(candidates, func(i, j int) bool {
+		ui, uj := usage(candidates[i]), usage(candidates[j])
+		if ui != uj {
+			return ui > uj
+		}
+		// Break ties on the pid so the same processes are kept from run to run
+		return candidates[i].Pid < candidates[j].Pid
+	})
+
+	limited := make(map[int32]*process.FilledProcess, cfg.MaxProcesses)
+	for _, fp := range candidates[:cfg.MaxProcesses] {
+		limited[fp.Pid] = fp
+	}
+	return limited, len(candidates) - cfg.MaxProcesses
+}
+
+// filterLowUsageProcesses leaves out the processes using less than both cfg.MinCPUPercent
+// and cfg.MinMemoryBytes, a threshold of 0 being unset. Processes running in a container
+// are kept when cfg.IncludeContainerProcesses is set. Processes that would be skipped
+// anyway aren't counted. It returns the processes to format and the number of processes
+// left out.
+func filterLowUsageProcesses(
+	cfg *config.AgentConfig,
+	procs, lastProcs map[int32]*process.FilledProcess,
+	ctrIDs map[int32]string,
+	syst2, syst1 cpu.TimesStat,
+) (map[int32]*process.FilledProcess, int) {
+	if cfg.MinCPUPercent <= 0 && cfg.MinMemoryBytes == 0 {
+		return procs, 0
+	}
+
+	kept := make(map[int32]*process.FilledProcess, len(procs))
+	filtered := 0
+	for pid, fp := range procs {
+		if skipProcess(cfg, fp, lastProcs) || (cfg.IncludeContainerProcesses && ctrIDs[pid] != "") {
+			kept[pid] = fp
+			continue
+		}
+		lowCPU := cfg.MinCPUPercent <= 0 ||
+			float64(formatCPU(fp, fp.CpuTime, lastProcs[pid].CpuTime, syst2, syst1).TotalPct) < cfg.MinCPUPercent
+		lowMemory := cfg.MinMemoryBytes == 0 || fp.MemInfo == nil || fp.MemInfo.RSS < cfg.MinMemoryBytes
+		if lowCPU && lowMemory {
+			filtered++
+			continue
+		}
+		kept[pid] = fp
+	}
+	return kept, filtered
+}
+
+// Processes using at least this much CPU since the last run, or this much memory, are
+// always collected whatever the process_sampling_rate.
+const (
+	sampledMinCPUPercent  = 5
+	sampledMinMemoryBytes = 512 * 1024 * 1024
+)
+
+// sampleProcesses keeps a cfg.ProcessSamplingRate fraction of the processes, picked from
+// their pid and create time along with the run number so that a different sample is
+// collected on each run and all the processes are eventually collected. Processes running
+// in a container or using at least sampledMinCPUPercent CPU or sampledMinMemoryBytes
+// memory are always kept. Processes that would be skipped anyway aren't counted. It
+// returns the processes to format and the number of processes left out.
+func sampleProcesses(
+	cfg *config.AgentConfig,
+	procs, lastProcs map[int32]*process.FilledProcess,
+	ctrIDs map[int32]string,
+	syst2, syst1 cpu.TimesStat,
+	run uint64,
+) (map[int32]*process.FilledProcess, int) {
+	if cfg.ProcessSamplingRate >= 1 {
+		return procs, 0
+	}
+
+	kept := make(map[int32]*process.FilledProcess, len(procs))
+	sampledOut := 0
+	for pid, fp := range procs {
+		if skipProcess(cfg, fp, lastProcs) || ctrIDs[pid] != "" || inSample(fp, run, cfg.ProcessSamplingRate) {
+			kept[pid] = fp
+			continue
+		}
+		highCPU := formatCPU(fp, fp.CpuTime, lastProcs[pid].CpuTime, syst2, syst1).TotalPct >= sampledMinCPUPercent
+		highMemory := fp.MemInfo != nil && fp.MemInfo.RSS >= sampledMinMemoryBytes
+		if highCPU || highMemory {
+			kept[pid] = fp
+			continue
+		}
+		sampledOut++
+	}
+	return kept, sampledOut
+}
+
+// inSample returns true if the process is part of the sample of the given run at the
+// given rate. The decision is the same for a process and a run on every call.
+func inSample(fp *process.FilledProcess, run uint64, rate float64) bool {
+	if rate <= 0 {
+		return false
+	}
+	// The splitmix64 finalizer spreads the sequential pids and runs evenly over the uint64 range
+	h := uint64(fp.Pid) ^ uint64(fp.CreateTime)*0x9e3779b97f4a7c15 ^ run*0xbf58476d1ce4e5b9
+	h = (h ^ (h >> 30)) * 0xbf58476d1ce4e5b9
+	h = (h ^ (h >> 27)) * 0x94d049bb133111eb
+	h ^= h >> 31
+	return float64(h) < rate*math.MaxUint64
+}
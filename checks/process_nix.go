@@ -3,23 +3,27 @@
 package checks
 
 import (
-	"os/user"
 	"runtime"
-	"strconv"
+	"time"
 
 	"github.com/DataDog/gopsutil/cpu"
 	"github.com/DataDog/gopsutil/process"
 
+	"github.com/DataDog/datadog-process-agent/config"
 	"github.com/DataDog/datadog-process-agent/model"
 )
 
-func formatUser(fp *process.FilledProcess) *model.ProcessUser {
+// formatUser returns the user of the process, only with its UID and GID if the user
+// names aren't resolved.
+func formatUser(cfg *config.AgentConfig, fp *process.FilledProcess) *model.ProcessUser {
 	var username string
 	var uid, gid int32
 	if len(fp.Uids) > 0 {
-		u, err := user.LookupId(strconv.Itoa(int(fp.Uids[0])))
-		if err == nil {
-			username = u.Username
+		if cfg.ResolveUserNames {
+			if userNames == nil {
+				userNames = newUserNameCache(cfg.CacheMaxEntries, userNameCacheTTL, lookupUserName)
+			}
+			username = userNames.name(uint32(fp.Uids[0]), time.Now())
 		}
 		uid = int32(fp.Uids[0])
 	}
@@ -34,6 +38,11 @@ func formatUser(fp *process.FilledProcess) *model.ProcessUser {
 	}
 }
 
+// processServices returns nil, services are only reported on Windows.
+func processServices(*config.AgentConfig) map[int32][]string {
+	return nil
+}
+
 func formatCPU(fp *process.FilledProcess, t2, t1, syst2, syst1 cpu.TimesStat) *model.CPUStat {
 	numCPU := float64(runtime.NumCPU())
 	deltaSys := syst2.Total() - syst1.Total()
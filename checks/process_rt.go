@@ -1,6 +1,8 @@
 package checks
 
 import (
+	"context"
+	"sync/atomic"
 	"time"
 
 	"github.com/DataDog/gopsutil/cpu"
@@ -19,22 +21,31 @@ var RTProcess = &RTProcessCheck{}
 // The instance stores state between checks for calculation of rates and CPU.
 type RTProcessCheck struct {
 	sysInfo        *model.SystemInfo
+	endpointPrefix string
 	lastCPUTime    cpu.TimesStat
 	lastProcs      map[int32]*process.FilledProcess
 	lastContainers []*docker.Container
 	lastRun        time.Time
+
+	// Set in delta mode, to only report the processes that changed between full snapshots
+	delta    *processDelta
+	lastFull time.Time
 }
 
 // Init initializes a new RTProcessCheck instance.
 func (r *RTProcessCheck) Init(cfg *config.AgentConfig, info *model.SystemInfo) {
+	r.endpointPrefix = cfg.CollectorPath
 	r.sysInfo = info
+	if cfg.RealTimeDelta {
+		r.delta = newProcessDelta(cfg.RealTimeDeltaCPUThreshold, cfg.RealTimeDeltaMemThreshold)
+	}
 }
 
 // Name returns the name of the RTProcessCheck.
 func (r *RTProcessCheck) Name() string { return "rtprocess" }
 
 // Endpoint returns the endpoint where this check is submitted.
-func (r *RTProcessCheck) Endpoint() string { return "/api/v1/collector" }
+func (r *RTProcessCheck) Endpoint() string { return r.endpointPrefix + "/api/v1/collector" }
 
 // RealTime indicates if this check only runs in real-time mode.
 func (r *RTProcessCheck) RealTime() bool { return true }
@@ -45,7 +56,7 @@ func (r *RTProcessCheck) RealTime() bool { return true }
 // Processes are split up into a chunks of at most 100 processes per message to
 // limit the message size on intake.
 // See agent.proto for the schema of the message and models used.
-func (r *RTProcessCheck) Run(cfg *config.AgentConfig, groupID int32) ([]model.MessageBody, error) {
+func (r *RTProcessCheck) Run(ctx context.Context, cfg *config.AgentConfig, groupID int32) ([]model.MessageBody, error) {
 	cpuTimes, err := cpu.Times(false)
 	if err != nil {
 		return nil, err
@@ -54,6 +65,9 @@ func (r *RTProcessCheck) Run(cfg *config.AgentConfig, groupID int32) ([]model.Me
 	if err != nil {
 		return nil, err
 	}
+	startTimes.normalize(procs, time.Now())
+	fillProcessIO(cfg, procs, hostProcessIO)
+	fillProcessThreads(cfg, procs, hostProcessThreads)
 	containers, _ := container.GetContainers()
 
 	// End check early if this is our first run.
@@ -65,13 +79,20 @@ func (r *RTProcessCheck) Run(cfg *config.AgentConfig, groupID int32) ([]model.Me
 		return nil, nil
 	}
 
-	chunkedStats := fmtProcessStats(cfg, procs, r.lastProcs,
-		containers, cpuTimes[0], r.lastCPUTime, r.lastRun)
+	ctrIDs := pidContainerIDs(cfg, procs, containers)
+	limitedProcs := r.reportedProcesses(cfg, procs, ctrIDs, cpuTimes[0])
+	chunkedStats := fmtProcessStats(cfg, limitedProcs, r.lastProcs,
+		ctrIDs, cpuTimes[0], r.lastCPUTime, r.lastRun)
+	var exited []*model.ProcessStat
+	var delta bool
+	if r.delta != nil {
+		chunkedStats, exited, delta = r.applyDelta(cfg, chunkedStats, liveProcessKeys(procs), time.Now())
+	}
 	groupSize := len(chunkedStats)
 	chunkedCtrStats := fmtContainerStats(containers, r.lastContainers, r.lastRun, groupSize)
 	messages := make([]model.MessageBody, 0, groupSize)
 	for i := 0; i < groupSize; i++ {
-		messages = append(messages, &model.CollectorRealTime{
+		m := &model.CollectorRealTime{
 			HostName:       cfg.HostName,
 			Stats:          chunkedStats[i],
 			ContainerStats: chunkedCtrStats[i],
@@ -79,7 +100,13 @@ func (r *RTProcessCheck) Run(cfg *config.AgentConfig, groupID int32) ([]model.Me
 			GroupSize:      int32(groupSize),
 			NumCpus:        int32(len(r.sysInfo.Cpus)),
 			TotalMemory:    r.sysInfo.TotalMemory,
-		})
+
+			Delta: delta,
+		}
+		if i == 0 {
+			m.Exited = exited
+		}
+		messages = append(messages, m)
 	}
 
 	// Store the last state for comparison on the next run.
@@ -92,21 +119,62 @@ func (r *RTProcessCheck) Run(cfg *config.AgentConfig, groupID int32) ([]model.Me
 	return messages, nil
 }
 
+// reportedProcesses returns the processes to report the stats of, left out like in the
+// process check by min_cpu_percent, min_memory_bytes and max_processes. The processes
+// of the process_sampling_rate sample of the last run of the process check are kept, so
+// that the stats are reported for the processes it reported. The processes left out are
+// still live for the delta, they aren't reported as exited.
+func (r *RTProcessCheck) reportedProcesses(
+	cfg *config.AgentConfig,
+	procs map[int32]*process.FilledProcess,
+	ctrIDs map[int32]string,
+	syst2 cpu.TimesStat,
+) map[int32]*process.FilledProcess {
+	keptProcs, _ := filterLowUsageProcesses(cfg, procs, r.lastProcs, ctrIDs, syst2, r.lastCPUTime)
+	sampledProcs, _ := sampleProcesses(cfg, keptProcs, r.lastProcs, ctrIDs, syst2, r.lastCPUTime, atomic.LoadUint64(&processSampleRun))
+	limitedProcs, _ := limitProcesses(cfg, sampledProcs, r.lastProcs)
+	return limitedProcs
+}
+
+// applyDelta replaces the chunked stats with the stats of the processes that are new
+// or changed since they were last reported, along with the processes that exited.
+// A full snapshot is still sent on every process check interval, in which case the
+// stats are returned as is and the returned bool is false. Only the processes that
+// are no longer live are reported as exited.
+func (r *RTProcessCheck) applyDelta(
+	cfg *config.AgentConfig,
+	chunked [][]*model.ProcessStat,
+	live map[processKey]struct{},
+	now time.Time,
+) ([][]*model.ProcessStat, []*model.ProcessStat, bool) {
+	stats := make([]*model.ProcessStat, 0, len(chunked)*cfg.MaxPerMessage)
+	for _, c := range chunked {
+		stats = append(stats, c...)
+	}
+
+	if now.Sub(r.lastFull) >= cfg.CheckInterval("process") {
+		r.delta.reset(stats)
+		r.lastFull = now
+		return chunked, nil, false
+	}
+
+	changed, exited := r.delta.diff(stats, live)
+	chunked = chunkProcessStats(changed, cfg.MaxPerMessage)
+	if len(chunked) == 0 {
+		// Always send a message for the exited processes and the container stats
+		chunked = [][]*model.ProcessStat{{}}
+	}
+	return chunked, exited, true
+}
+
 // fmtProcessStats formats and chunks a slice of ProcessStat into chunks.
 func fmtProcessStats(
 	cfg *config.AgentConfig,
 	procs, lastProcs map[int32]*process.FilledProcess,
-	containers []*docker.Container,
+	ctrIDs map[int32]string,
 	syst2, syst1 cpu.TimesStat,
 	lastRun time.Time,
 ) [][]*model.ProcessStat {
-	ctrByPid := make(map[int32]*docker.Container, len(containers))
-	for _, c := range containers {
-		for _, p := range c.Pids {
-			ctrByPid[p] = c
-		}
-	}
-
 	chunked := make([][]*model.ProcessStat, 0)
 	chunk := make([]*model.ProcessStat, 0, cfg.MaxPerMessage)
 	for _, fp := range procs {
@@ -114,25 +182,35 @@ func fmtProcessStats(
 			continue
 		}
 
-		ctr, ok := ctrByPid[fp.Pid]
-		if !ok {
-			ctr = docker.NullContainer
+		stat := &model.ProcessStat{
+			Pid:          fp.Pid,
+			CreateTime:   fp.CreateTime,
+			Nice:         fp.Nice,
+			ProcessState: model.ProcessState(model.ProcessState_value[fp.Status]),
+			ContainerId:  ctrIDs[fp.Pid],
 		}
-
-		chunk = append(chunk, &model.ProcessStat{
-			Pid:                    fp.Pid,
-			CreateTime:             fp.CreateTime,
-			Memory:                 formatMemory(fp),
-			Cpu:                    formatCPU(fp, fp.CpuTime, lastProcs[fp.Pid].CpuTime, syst2, syst1),
-			Nice:                   fp.Nice,
-			Threads:                fp.NumThreads,
-			OpenFdCount:            fp.OpenFdCount,
-			ProcessState:           model.ProcessState(model.ProcessState_value[fp.Status]),
-			IoStat:                 formatIO(fp, lastProcs[fp.Pid].IOStat, lastRun),
-			VoluntaryCtxSwitches:   uint64(fp.CtxSwitches.Voluntary),
-			InvoluntaryCtxSwitches: uint64(fp.CtxSwitches.Involuntary),
-			ContainerId:            ctr.ID,
-		})
+		if cfg.CollectsProcessField(config.ProcessFieldMemory) {
+			stat.Memory = formatMemory(fp)
+		}
+		if cfg.CollectsProcessField(config.ProcessFieldCPU) {
+			stat.Cpu = formatCPU(fp, fp.CpuTime, lastProcs[fp.Pid].CpuTime, syst2, syst1)
+		}
+		if cfg.CollectsProcessField(config.ProcessFieldIO) {
+			stat.IoStat = formatIO(fp, lastProcs[fp.Pid].IOStat, lastRun)
+		}
+		if cfg.CollectsProcessField(config.ProcessFieldFDs) {
+			stat.OpenFdCount = fp.OpenFdCount
+		}
+		if cfg.CollectsProcessField(config.ProcessFieldCtxSwitches) {
+			stat.VoluntaryCtxSwitches = uint64(fp.CtxSwitches.Voluntary)
+			stat.InvoluntaryCtxSwitches = uint64(fp.CtxSwitches.Involuntary)
+		}
+		if cfg.CollectsProcessField(config.ProcessFieldThreads) {
+			stat.Threads = fp.NumThreads
+		} else if stat.Cpu != nil {
+			stat.Cpu.NumThreads = 0
+		}
+		chunk = append(chunk, stat)
 		if len(chunk) == cfg.MaxPerMessage {
 			chunked = append(chunked, chunk)
 			chunk = make([]*model.ProcessStat, 0, cfg.MaxPerMessage)
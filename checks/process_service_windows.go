@@ -0,0 +1,133 @@
+// +build windows
+
+package checks
+
+import (
+	"sort"
+	"syscall"
+	"unsafe"
+
+	log "github.com/cihub/seelog"
+
+	"github.com/DataDog/datadog-process-agent/config"
+)
+
+const (
+	scManagerEnumerateService = 0x0004
+	scEnumProcessInfo         = 0
+	serviceWin32              = 0x30
+	serviceActive             = 0x1
+	errorMoreData             = syscall.Errno(234)
+)
+
+var (
+	modadvapi32                = syscall.NewLazyDLL("advapi32.dll")
+	procOpenSCManagerW         = modadvapi32.NewProc("OpenSCManagerW")
+	procCloseServiceHandle     = modadvapi32.NewProc("CloseServiceHandle")
+	procEnumServicesStatusExW  = modadvapi32.NewProc("EnumServicesStatusExW")
+	haveWarnedServicesDisabled = false
+)
+
+// SERVICE_STATUS_PROCESS
+type serviceStatusProcess struct {
+	ServiceType             uint32
+	CurrentState            uint32
+	ControlsAccepted        uint32
+	Win32ExitCode           uint32
+	ServiceSpecificExitCode uint32
+	CheckPoint              uint32
+	WaitHint                uint32
+	ProcessID               uint32
+	ServiceFlags            uint32
+}
+
+// ENUM_SERVICE_STATUS_PROCESSW
+type enumServiceStatusProcess struct {
+	ServiceName          *uint16
+	DisplayName          *uint16
+	ServiceStatusProcess serviceStatusProcess
+}
+
+// processServices returns the names of the running services by the pid of the process
+// running them, several services being able to share a process, e.g. svchost.exe.
+func processServices(cfg *config.AgentConfig) map[int32][]string {
+	if !cfg.Windows.CollectServices {
+		return nil
+	}
+	services, err := enumServices()
+	if err != nil {
+		if !haveWarnedServicesDisabled {
+			log.Warnf("unable to list the services from the service control manager, they won't be reported: %s", err)
+			haveWarnedServicesDisabled = true
+		}
+		return nil
+	}
+	return servicesByPid(services)
+}
+
+// servicesByPid groups the names of the services by the pid running them, sorted.
+func servicesByPid(services []enumServiceStatusProcess) map[int32][]string {
+	byPid := make(map[int32][]string)
+	for _, s := range services {
+		pid := int32(s.ServiceStatusProcess.ProcessID)
+		// Stopping services can have no process anymore
+		if pid == 0 || s.ServiceName == nil {
+			continue
+		}
+		byPid[pid] = append(byPid[pid], utf16PtrToString(s.ServiceName))
+	}
+	for _, names := range byPid {
+		sort.Strings(names)
+	}
+	return byPid
+}
+
+// enumServices lists the running Win32 services from the service control manager.
+func enumServices() ([]enumServiceStatusProcess, error) {
+	h, _, e1 := procOpenSCManagerW.Call(0, 0, scManagerEnumerateService)
+	if h == 0 {
+		return nil, e1
+	}
+	defer procCloseServiceHandle.Call(h)
+
+	var services []enumServiceStatusProcess
+	var resume uint32
+	buf := make([]byte, 64*1024)
+	for {
+		var needed, returned uint32
+		r1, _, e1 := procEnumServicesStatusExW.Call(h, scEnumProcessInfo, serviceWin32, serviceActive,
+			uintptr(unsafe.Pointer(&buf[0])), uintptr(len(buf)),
+			uintptr(unsafe.Pointer(&needed)), uintptr(unsafe.Pointer(&returned)),
+			uintptr(unsafe.Pointer(&resume)), 0)
+		if r1 == 0 && e1 != errorMoreData {
+			return nil, e1
+		}
+
+		entries := (*[1 << 16]enumServiceStatusProcess)(unsafe.Pointer(&buf[0]))[:returned:returned]
+		for _, e := range entries {
+			// The names point into buf, which is reused for the next batch
+			name := utf16PtrToString(e.ServiceName)
+			e.ServiceName = &syscall.StringToUTF16(name)[0]
+			e.DisplayName = nil
+			services = append(services, e)
+		}
+		if r1 != 0 {
+			return services, nil
+		}
+		if int(needed) > len(buf) {
+			buf = make([]byte, needed)
+		}
+	}
+}
+
+// utf16PtrToString converts a NUL-terminated UTF-16 string to a Go string.
+func utf16PtrToString(p *uint16) string {
+	if p == nil {
+		return ""
+	}
+	chars := make([]uint16, 0, 64)
+	for ptr := unsafe.Pointer(p); *(*uint16)(ptr) != 0; ptr = unsafe.Pointer(uintptr(ptr) + 2) {
+		chars = append(chars, *(*uint16)(ptr))
+	}
+	return syscall.UTF16ToString(chars)
+}
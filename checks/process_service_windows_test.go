@@ -0,0 +1,51 @@
+// +build windows
+
+package checks
+
+import (
+	"syscall"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/DataDog/datadog-process-agent/config"
+)
+
+func TestServicesByPid(t *testing.T) {
+	assert.Equal(t, map[int32][]string{
+		812:  {"Dhcp", "EventLog", "lmhosts"},
+		1020: {"Spooler"},
+	}, servicesByPid([]enumServiceStatusProcess{
+		testService("lmhosts", 812),
+		testService("Spooler", 1020),
+		testService("EventLog", 812),
+		testService("wuauserv", 0),
+		testService("Dhcp", 812),
+	}))
+}
+
+func testService(name string, pid uint32) enumServiceStatusProcess {
+	s := enumServiceStatusProcess{
+		ServiceName: syscall.StringToUTF16Ptr(name),
+		DisplayName: syscall.StringToUTF16Ptr(name),
+	}
+	s.ServiceStatusProcess.ProcessID = pid
+	return s
+}
+
+func TestProcessServices(t *testing.T) {
+	assert := assert.New(t)
+	cfg := config.NewDefaultAgentConfig()
+
+	// The service control manager always runs some services, e.g. the event log
+	services, err := enumServices()
+	assert.NoError(err)
+	assert.NotEmpty(services)
+	for _, s := range services {
+		assert.NotEmpty(utf16PtrToString(s.ServiceName))
+	}
+	assert.NotEmpty(processServices(cfg))
+
+	cfg.Windows.CollectServices = false
+	assert.Nil(processServices(cfg))
+}
@@ -0,0 +1,84 @@
+package checks
+
+import (
+	"sync"
+	"time"
+
+	"github.com/DataDog/gopsutil/process"
+	log "github.com/cihub/seelog"
+
+	"github.com/DataDog/datadog-process-agent/util"
+)
+
+// startTimeTolerance is how far the create time reported for a pid can move between
+// runs while still being considered the same process rather than a reused pid.
+const startTimeTolerance = 2 * time.Second
+
+// startTimes is shared by the process checks so that they report the same start times.
+var startTimes = &processStartTimes{}
+
+// processStartTimes computes the start times of processes from the host boot time and
+// their start offset in procfs, both read from HOST_PROC. The boot time is only read
+// once: the btime reported by the kernel moves whenever the clock is adjusted, which
+// would change the start time of a process, and so its identity, between runs.
+type processStartTimes struct {
+	sync.Mutex
+
+	procRoot string // Defaults to HOST_PROC
+	bootTime int64  // In milliseconds since the epoch, 0 until read
+	byPid    map[int32]processStartTime
+}
+
+type processStartTime struct {
+	reported   int64 // Create time collected with the process
+	normalized int64
+}
+
+// normalize replaces the create time of the processes with their normalized start
+// time, in milliseconds since the epoch. Processes whose start time can't be read from
+// procfs, e.g. on platforms without it, keep their create time.
+func (s *processStartTimes) normalize(procs map[int32]*process.FilledProcess, now time.Time) {
+	s.Lock()
+	defer s.Unlock()
+
+	procRoot := s.procRoot
+	if procRoot == "" {
+		procRoot = util.HostProc()
+	}
+	if s.bootTime == 0 {
+		bootTime, err := util.ReadBootTime(procRoot)
+		if err != nil {
+			log.Debugf("unable to read boot time, not normalizing process start times: %s", err)
+			return
+		}
+		s.bootTime = bootTime * 1000
+	}
+
+	nowMs := now.UnixNano() / int64(time.Millisecond)
+	tolerance := int64(startTimeTolerance / time.Millisecond)
+	byPid := make(map[int32]processStartTime, len(procs))
+	for pid, fp := range procs {
+		st, ok := s.byPid[pid]
+		if !ok || abs(st.reported-fp.CreateTime) > tolerance {
+			st = processStartTime{reported: fp.CreateTime, normalized: fp.CreateTime}
+			if offset, err := util.ReadProcessStartOffset(procRoot, pid); err == nil {
+				st.normalized = s.bootTime + int64(offset/time.Millisecond)
+				// Processes can't start in the future, this comes from the clock of a
+				// container drifting from the host's.
+				if st.normalized > nowMs {
+					st.normalized = nowMs
+				}
+			}
+		}
+		byPid[pid] = st
+		fp.CreateTime = st.normalized
+	}
+	s.byPid = byPid
+}
+
+func abs(v int64) int64 {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
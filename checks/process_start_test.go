@@ -0,0 +1,77 @@
+package checks
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/DataDog/gopsutil/process"
+	"github.com/stretchr/testify/assert"
+)
+
+func writeProcFixture(t *testing.T, procRoot, path, contents string) {
+	p := filepath.Join(procRoot, path)
+	assert.NoError(t, os.MkdirAll(filepath.Dir(p), 0755))
+	assert.NoError(t, ioutil.WriteFile(p, []byte(contents), 0644))
+}
+
+// procStat returns the contents of /proc/<pid>/stat with the given comm and starttime in ticks.
+func procStat(pid, comm, starttime string) string {
+	return pid + " (" + comm + ") S 1 1 1 0 -1 4194560 1000 0 0 0 10 5 0 0 20 0 1 0 " + starttime +
+		" 12345678 100 18446744073709551615 1 1 0 0 0 0 0 4096 0 0 0 0 17 0 0 0 0 0 0\n"
+}
+
+func TestProcessStartTimes(t *testing.T) {
+	assert := assert.New(t)
+	procRoot, err := ioutil.TempDir("", "proc")
+	assert.NoError(err)
+	defer os.RemoveAll(procRoot)
+
+	bootTime := int64(1500000000)
+	writeProcFixture(t, procRoot, "stat", "cpu  1 2 3 4\nintr 1234\nbtime 1500000000\nprocesses 42\n")
+	writeProcFixture(t, procRoot, "1/stat", procStat("1", "init", "250"))
+	// The command name can contain spaces and parentheses
+	writeProcFixture(t, procRoot, "2/stat", procStat("2", "my (weird) cmd", "360000"))
+	// A start time far in the future, as seen with a container clock behind the host's
+	writeProcFixture(t, procRoot, "3/stat", procStat("3", "skewed", "99999999999"))
+
+	now := time.Unix(bootTime+7200, 0)
+	procs := map[int32]*process.FilledProcess{
+		1: {Pid: 1, CreateTime: bootTime*1000 + 2000},
+		2: {Pid: 2, CreateTime: bootTime*1000 + 3601000},
+		3: {Pid: 3, CreateTime: 123},
+		4: {Pid: 4, CreateTime: 456}, // No stat, e.g. the process exited
+	}
+	s := &processStartTimes{procRoot: procRoot}
+	s.normalize(procs, now)
+
+	assert.Equal(bootTime*1000+2500, procs[1].CreateTime)
+	assert.Equal(bootTime*1000+3600000, procs[2].CreateTime)
+	assert.Equal(now.Unix()*1000, procs[3].CreateTime)
+	assert.Equal(int64(456), procs[4].CreateTime)
+
+	// The kernel btime moving doesn't change the start times
+	writeProcFixture(t, procRoot, "stat", "btime 1500000001\n")
+	procs = map[int32]*process.FilledProcess{
+		1: {Pid: 1, CreateTime: bootTime*1000 + 3000},
+	}
+	s.normalize(procs, now)
+	assert.Equal(bootTime*1000+2500, procs[1].CreateTime)
+
+	// A reused pid gets its new start time
+	writeProcFixture(t, procRoot, "1/stat", procStat("1", "other", "500000"))
+	procs = map[int32]*process.FilledProcess{
+		1: {Pid: 1, CreateTime: bootTime*1000 + 5000000},
+	}
+	s.normalize(procs, now)
+	assert.Equal(bootTime*1000+5000000, procs[1].CreateTime)
+}
+
+func TestProcessStartTimesNoProcfs(t *testing.T) {
+	s := &processStartTimes{procRoot: "/does/not/exist"}
+	procs := map[int32]*process.FilledProcess{1: {Pid: 1, CreateTime: 1234}}
+	s.normalize(procs, time.Now())
+	assert.Equal(t, int64(1234), procs[1].CreateTime)
+}
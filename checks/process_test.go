@@ -1,14 +1,17 @@
 package checks
 
 import (
+	"io/ioutil"
+	"os"
 	"regexp"
 	"runtime"
+	"sort"
 	"strings"
 	"testing"
 	"time"
 
-	"github.com/DataDog/datadog-agent/pkg/util/docker"
 	"github.com/DataDog/datadog-process-agent/config"
+	"github.com/DataDog/datadog-process-agent/model"
 	"github.com/DataDog/gopsutil/cpu"
 	"github.com/DataDog/gopsutil/process"
 	"github.com/stretchr/testify/assert"
@@ -30,7 +33,6 @@ func TestProcessChunking(t *testing.T) {
 		makeProcess(3, "datadog-process-agent -ddconfig datadog.conf"),
 		makeProcess(4, "foo -bar -bim"),
 	}
-	containers := []*docker.Container{}
 	lastRun := time.Now().Add(-5 * time.Second)
 	syst1, syst2 := cpu.TimesStat{}, cpu.TimesStat{}
 	cfg := config.NewDefaultAgentConfig()
@@ -91,7 +93,7 @@ func TestProcessChunking(t *testing.T) {
 			last[c.Pid] = c
 		}
 
-		chunked := fmtProcesses(cfg, cur, last, containers, syst2, syst1, lastRun)
+		chunked := fmtProcesses(cfg, cur, last, nil, syst2, syst1, lastRun)
 		assert.Len(t, chunked, tc.expectedChunks, "len %d", i)
 		total := 0
 		for _, c := range chunked {
@@ -99,7 +101,7 @@ func TestProcessChunking(t *testing.T) {
 		}
 		assert.Equal(t, tc.expectedTotal, total, "total test %d", i)
 
-		chunkedStat := fmtProcessStats(cfg, cur, last, containers, syst2, syst1, lastRun)
+		chunkedStat := fmtProcessStats(cfg, cur, last, nil, syst2, syst1, lastRun)
 		assert.Len(t, chunkedStat, tc.expectedChunks, "len stat %d", i)
 		total = 0
 		for _, c := range chunkedStat {
@@ -110,6 +112,39 @@ func TestProcessChunking(t *testing.T) {
 	}
 }
 
+func TestProcessChunkingBytes(t *testing.T) {
+	lastRun := time.Now().Add(-5 * time.Second)
+	syst1, syst2 := cpu.TimesStat{}, cpu.TimesStat{}
+	cfg := config.NewDefaultAgentConfig()
+	cfg.Blacklist = []*regexp.Regexp{}
+	cfg.MaxPerMessage = 100
+
+	// Each process carries a 4KB cmdline so only a few fit under the byte limit
+	procs := make(map[int32]*process.FilledProcess)
+	for i := int32(1); i <= 10; i++ {
+		procs[i] = makeProcess(i, "java -Dlarge="+strings.Repeat("x", 4096))
+	}
+	cfg.MaxMessageBytes = 3 * 4200
+
+	chunked := fmtProcesses(cfg, procs, procs, nil, syst2, syst1, lastRun)
+	assert.Len(t, chunked, 4)
+	total := 0
+	for _, chunk := range chunked {
+		total += len(chunk)
+		size := 0
+		for _, p := range chunk {
+			size += p.Size()
+		}
+		assert.True(t, size <= cfg.MaxMessageBytes, "chunk is too large: %d", size)
+	}
+	assert.Equal(t, 10, total)
+
+	// Without a byte limit only the item count applies
+	cfg.MaxMessageBytes = 0
+	chunked = fmtProcesses(cfg, procs, procs, nil, syst2, syst1, lastRun)
+	assert.Len(t, chunked, 1)
+}
+
 func TestPercentCalculation(t *testing.T) {
 	// Capping at NUM CPU * 100 if we get odd values for delta-{Proc,Time}
 	assert.True(t, floatEquals(calculatePct(100, 50, 1), 100))
@@ -140,3 +175,344 @@ func floatEquals(a, b float32) bool {
 	var e float32 = 0.00000001 // Difference less than some epsilon
 	return a-b < e && b-a < e
 }
+
+func TestProcessCollectFields(t *testing.T) {
+	assert := assert.New(t)
+	lastRun := time.Now().Add(-5 * time.Second)
+	syst1, syst2 := cpu.TimesStat{}, cpu.TimesStat{}
+	cfg := config.NewDefaultAgentConfig()
+	cfg.Blacklist = []*regexp.Regexp{}
+	cfg.ProcessFields = map[string]bool{config.ProcessFieldCPU: true}
+
+	// Formatting the memory or the context switches of this process would panic
+	fp := makeProcess(1, "mysqld --password=secret")
+	fp.Exe = "/usr/sbin/mysqld"
+	fp.MemInfo = nil
+	fp.CtxSwitches = nil
+	fp.OpenFdCount = 10
+	fp.NumThreads = 12
+	procs := map[int32]*process.FilledProcess{1: fp}
+
+	chunked := fmtProcesses(cfg, procs, procs, nil, syst2, syst1, lastRun)
+	assert.Len(chunked, 1)
+	proc := chunked[0][0]
+	assert.NotNil(proc.Cpu)
+	assert.Equal("/usr/sbin/mysqld", proc.Command.Exe)
+	assert.Empty(proc.Command.Args)
+	assert.Nil(proc.User)
+	assert.Nil(proc.Memory)
+	assert.Nil(proc.IoStat)
+	assert.Equal(int32(0), proc.OpenFdCount)
+	assert.Equal(int32(0), proc.Threads)
+	assert.Equal(int32(0), proc.Cpu.NumThreads)
+	// The cmdline isn't scrubbed either
+	assert.Equal([]string{"mysqld", "--password=secret"}, fp.Cmdline)
+
+	stats := fmtProcessStats(cfg, procs, procs, nil, syst2, syst1, lastRun)
+	assert.Len(stats, 1)
+	assert.NotNil(stats[0][0].Cpu)
+	assert.Nil(stats[0][0].Memory)
+	assert.Nil(stats[0][0].IoStat)
+	assert.Equal(int32(0), stats[0][0].Threads)
+
+	// All the fields are collected by default
+	cfg.ProcessFields = nil
+	fp.MemInfo = &process.MemoryInfoStat{}
+	fp.CtxSwitches = &process.NumCtxSwitchesStat{}
+	chunked = fmtProcesses(cfg, procs, procs, nil, syst2, syst1, lastRun)
+	proc = chunked[0][0]
+	assert.NotNil(proc.User)
+	assert.NotNil(proc.Memory)
+	assert.Equal(int32(10), proc.OpenFdCount)
+	assert.Equal(int32(12), proc.Threads)
+	assert.Equal(int32(12), proc.Cpu.NumThreads)
+	assert.Equal([]string{"mysqld", "--password=********"}, proc.Command.Args)
+	stats = fmtProcessStats(cfg, procs, procs, nil, syst2, syst1, lastRun)
+	assert.Equal(int32(12), stats[0][0].Threads)
+}
+
+func TestLimitProcesses(t *testing.T) {
+	assert := assert.New(t)
+	cfg := config.NewDefaultAgentConfig()
+
+	lastProcs := make(map[int32]*process.FilledProcess)
+	procs := make(map[int32]*process.FilledProcess)
+	for pid, usage := range map[int32]struct {
+		cpu float64
+		rss uint64
+	}{
+		1: {cpu: 5, rss: 100},
+		2: {cpu: 50, rss: 10},
+		3: {cpu: 1, rss: 500},
+		4: {cpu: 20, rss: 300},
+		5: {cpu: 20, rss: 50},
+	} {
+		last := makeProcess(pid, "foo")
+		last.CpuTime = cpu.TimesStat{User: 100, System: 100}
+		lastProcs[pid] = last
+
+		p := makeProcess(pid, "foo")
+		p.CpuTime = cpu.TimesStat{User: 100 + usage.cpu, System: 100}
+		p.MemInfo = &process.MemoryInfoStat{RSS: usage.rss}
+		procs[pid] = p
+	}
+	// Blacklisted and new processes don't count toward the limit
+	procs[6] = makeProcess(6, "foo")
+	procs[7] = makeProcess(7, "mysqld")
+	lastProcs[7] = procs[7]
+	cfg.Blacklist = []*regexp.Regexp{regexp.MustCompile("^mysqld")}
+
+	pids := func(procs map[int32]*process.FilledProcess) []int32 {
+		out := make([]int32, 0, len(procs))
+		for pid := range procs {
+			out = append(out, pid)
+		}
+		sort.Slice(out, func(i, j int) bool { return out[i] < out[j] })
+		return out
+	}
+
+	// No limit by default
+	limited, truncated := limitProcesses(cfg, procs, lastProcs)
+	assert.Len(limited, 7)
+	assert.Equal(0, truncated)
+
+	cfg.MaxProcesses = 5
+	limited, truncated = limitProcesses(cfg, procs, lastProcs)
+	assert.Len(limited, 7)
+	assert.Equal(0, truncated)
+
+	// Ties are broken on the pid
+	cfg.MaxProcesses = 3
+	limited, truncated = limitProcesses(cfg, procs, lastProcs)
+	assert.Equal([]int32{2, 4, 5}, pids(limited))
+	assert.Equal(2, truncated)
+
+	cfg.MaxProcessesPriority = config.ProcessFieldMemory
+	limited, truncated = limitProcesses(cfg, procs, lastProcs)
+	assert.Equal([]int32{1, 3, 4}, pids(limited))
+	assert.Equal(2, truncated)
+
+	// Only the limited processes are formatted
+	chunks := fmtProcesses(cfg, limited, lastProcs, nil, cpu.TimesStat{}, cpu.TimesStat{}, time.Now().Add(-time.Second))
+	assert.Len(chunks, 1)
+	assert.Len(chunks[0], 3)
+}
+
+func TestFilterLowUsageProcesses(t *testing.T) {
+	assert := assert.New(t)
+	cfg := config.NewDefaultAgentConfig()
+
+	lastProcs := make(map[int32]*process.FilledProcess)
+	procs := make(map[int32]*process.FilledProcess)
+	for pid, usage := range map[int32]struct {
+		cpu float64
+		rss uint64
+	}{
+		1: {cpu: 0.5, rss: 1 << 20},
+		2: {cpu: 5, rss: 1 << 20},
+		3: {cpu: 0.1, rss: 50 << 20},
+		4: {cpu: 0.1, rss: 1 << 20},
+	} {
+		last := makeProcess(pid, "foo")
+		last.CpuTime = cpu.TimesStat{User: 100, System: 100}
+		lastProcs[pid] = last
+
+		p := makeProcess(pid, "foo")
+		p.CpuTime = cpu.TimesStat{User: 100 + usage.cpu, System: 100}
+		p.MemInfo = &process.MemoryInfoStat{RSS: usage.rss}
+		procs[pid] = p
+	}
+	// Blacklisted and new processes are left to skipProcess
+	procs[5] = makeProcess(5, "foo")
+	procs[6] = makeProcess(6, "mysqld")
+	lastProcs[6] = procs[6]
+	cfg.Blacklist = []*regexp.Regexp{regexp.MustCompile("^mysqld")}
+	ctrIDs := map[int32]string{4: "abc"}
+
+	// The CPU usage is the process time over the system time, scaled to the number of CPUs
+	syst1 := cpu.TimesStat{}
+	syst2 := cpu.TimesStat{User: float64(100 * runtime.NumCPU())}
+
+	pids := func(procs map[int32]*process.FilledProcess) []int32 {
+		out := make([]int32, 0, len(procs))
+		for pid := range procs {
+			out = append(out, pid)
+		}
+		sort.Slice(out, func(i, j int) bool { return out[i] < out[j] })
+		return out
+	}
+
+	// No thresholds by default
+	kept, filtered := filterLowUsageProcesses(cfg, procs, lastProcs, ctrIDs, syst2, syst1)
+	assert.Len(kept, 6)
+	assert.Equal(0, filtered)
+
+	// Processes are kept if they are above either threshold
+	cfg.MinCPUPercent = 1
+	cfg.MinMemoryBytes = 10 << 20
+	kept, filtered = filterLowUsageProcesses(cfg, procs, lastProcs, ctrIDs, syst2, syst1)
+	assert.Equal([]int32{2, 3, 5, 6}, pids(kept))
+	assert.Equal(2, filtered)
+
+	cfg.IncludeContainerProcesses = true
+	kept, filtered = filterLowUsageProcesses(cfg, procs, lastProcs, ctrIDs, syst2, syst1)
+	assert.Equal([]int32{2, 3, 4, 5, 6}, pids(kept))
+	assert.Equal(1, filtered)
+
+	// An unset threshold isn't a criterion
+	cfg.IncludeContainerProcesses = false
+	cfg.MinCPUPercent = 0
+	kept, filtered = filterLowUsageProcesses(cfg, procs, lastProcs, ctrIDs, syst2, syst1)
+	assert.Equal([]int32{3, 5, 6}, pids(kept))
+	assert.Equal(3, filtered)
+}
+
+func TestSampleProcesses(t *testing.T) {
+	assert := assert.New(t)
+	cfg := config.NewDefaultAgentConfig()
+	cfg.Blacklist = []*regexp.Regexp{}
+
+	lastProcs := make(map[int32]*process.FilledProcess)
+	procs := make(map[int32]*process.FilledProcess)
+	for pid := int32(1); pid <= 10000; pid++ {
+		last := makeProcess(pid, "foo")
+		last.CpuTime = cpu.TimesStat{User: 100, System: 100}
+		lastProcs[pid] = last
+
+		p := makeProcess(pid, "foo")
+		p.CpuTime = cpu.TimesStat{User: 100.1, System: 100}
+		p.MemInfo = &process.MemoryInfoStat{RSS: 1 << 20}
+		procs[pid] = p
+	}
+	// Busy, big and containerized processes are always collected
+	procs[1].CpuTime.User = 110
+	procs[2].MemInfo.RSS = 1 << 30
+	ctrIDs := map[int32]string{3: "abc"}
+	syst1 := cpu.TimesStat{}
+	syst2 := cpu.TimesStat{User: float64(100 * runtime.NumCPU())}
+
+	// All the processes are collected by default
+	kept, sampledOut := sampleProcesses(cfg, procs, lastProcs, ctrIDs, syst2, syst1, 0)
+	assert.Len(kept, 10000)
+	assert.Equal(0, sampledOut)
+
+	cfg.ProcessSamplingRate = 0.25
+	seen := make(map[int32]bool)
+	for run := uint64(0); run < 20; run++ {
+		kept, sampledOut = sampleProcesses(cfg, procs, lastProcs, ctrIDs, syst2, syst1, run)
+		assert.InDelta(2500, len(kept), 200, "run %d", run)
+		assert.Equal(10000, len(kept)+sampledOut)
+		for _, pid := range []int32{1, 2, 3} {
+			assert.Contains(kept, pid, "run %d", run)
+		}
+		for pid := range kept {
+			seen[pid] = true
+		}
+
+		// The sample of a run is deterministic
+		again, _ := sampleProcesses(cfg, procs, lastProcs, ctrIDs, syst2, syst1, run)
+		assert.Equal(kept, again)
+	}
+	// Every process is eventually collected, a process has a 0.75^20 chance to be left out of all the runs
+	assert.InDelta(10000, len(seen), 50)
+
+	// Only the processes always collected are left at a rate of 0
+	cfg.ProcessSamplingRate = 0
+	kept, sampledOut = sampleProcesses(cfg, procs, lastProcs, ctrIDs, syst2, syst1, 0)
+	assert.Len(kept, 3)
+	assert.Equal(9997, sampledOut)
+}
+
+func TestSkipKernelThreads(t *testing.T) {
+	assert := assert.New(t)
+	procs := map[int32]*process.FilledProcess{
+		1:   {Pid: 1, Ppid: 0, Name: "systemd", Cmdline: []string{"/sbin/init"}},
+		2:   {Pid: 2, Ppid: 0, Name: "kthreadd"},
+		50:  {Pid: 50, Ppid: kthreadd, Name: "kworker/0:1"},
+		60:  {Pid: 60, Ppid: 1, Name: "[watchdog]"},
+		100: {Pid: 100, Ppid: 1, Name: "app", Status: "Z"},
+		101: {Pid: 101, Ppid: 1, Name: "app"},
+	}
+	cfg := config.NewDefaultAgentConfig()
+
+	skipped := func() []int32 {
+		var pids []int32
+		for pid, fp := range procs {
+			if skipProcess(cfg, fp, procs) {
+				pids = append(pids, pid)
+			}
+		}
+		sort.Slice(pids, func(i, j int) bool { return pids[i] < pids[j] })
+		return pids
+	}
+
+	// The user processes without command line, e.g. zombies, are kept
+	assert.Equal([]int32{2, 50, 60}, skipped())
+	cfg.CollectKernelThreads = true
+	assert.Empty(skipped())
+}
+
+func TestCheckEndpoints(t *testing.T) {
+	assert := assert.New(t)
+	cfg := config.NewDefaultAgentConfig()
+	checks := []Check{&ProcessCheck{}, &RTProcessCheck{}, &ContainerCheck{}, &RTContainerCheck{}}
+	expected := []string{"/api/v1/collector", "/api/v1/collector", "/api/v1/container", "/api/v1/container"}
+
+	for i, c := range checks {
+		c.Init(cfg, &model.SystemInfo{})
+		assert.Equal(expected[i], c.Endpoint(), c.Name())
+	}
+	assert.Equal("/api/v1/collector", (&ConnectionsCheck{}).Endpoint())
+
+	cfg.CollectorPath = "/intake/process"
+	for i, c := range checks {
+		c.Init(cfg, &model.SystemInfo{})
+		assert.Equal("/intake/process"+expected[i], c.Endpoint(), c.Name())
+	}
+	assert.Equal("/intake/process/api/v1/collector", (&ConnectionsCheck{endpointPrefix: cfg.CollectorPath}).Endpoint())
+}
+
+func TestProcessEnvCollection(t *testing.T) {
+	procRoot, err := ioutil.TempDir("", "proc")
+	assert.NoError(t, err)
+	defer os.RemoveAll(procRoot)
+	defer os.Setenv("HOST_PROC", os.Getenv("HOST_PROC"))
+	os.Setenv("HOST_PROC", procRoot)
+
+	environ := "HOME=/home/app\x00DB_PASSWORD=hunter2\x00OPTS=a=b\x00"
+	for _, pid := range []string{"1", "2", "3"} {
+		writeProcFixture(t, procRoot, pid+"/environ", environ)
+	}
+	app := makeProcess(1, "server --port 80")
+	app.Exe = "/opt/app/bin/server"
+	daemon := makeProcess(2, "sshd -D")
+	daemon.Exe = "/usr/sbin/sshd"
+	// The executable of the processes of other users can't be read
+	other := makeProcess(3, "/opt/app/bin/worker")
+	procs := map[int32]*process.FilledProcess{1: app, 2: daemon, 3: other}
+
+	cfg := config.NewDefaultAgentConfig()
+	cfg.Blacklist = []*regexp.Regexp{}
+	syst1, syst2 := cpu.TimesStat{}, cpu.TimesStat{}
+	envs := func() map[int32][]string {
+		envs := make(map[int32][]string)
+		for _, chunk := range fmtProcesses(cfg, procs, procs, nil, syst2, syst1, time.Now()) {
+			for _, p := range chunk {
+				envs[p.Pid] = p.Env
+			}
+		}
+		return envs
+	}
+
+	// No environment is collected by default
+	for pid, env := range envs() {
+		assert.Empty(t, env, "pid %d", pid)
+	}
+
+	cfg.EnvCollectionExePatterns = []*regexp.Regexp{regexp.MustCompile("^/opt/app/")}
+	collected := envs()
+	expected := []string{"DB_PASSWORD=********", "HOME=/home/app", "OPTS=a=b"}
+	assert.Equal(t, expected, collected[1])
+	assert.Empty(t, collected[2])
+	assert.Equal(t, expected, collected[3])
+}
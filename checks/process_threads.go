@@ -0,0 +1,62 @@
+package checks
+
+import (
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/DataDog/gopsutil/process"
+	log "github.com/cihub/seelog"
+
+	"github.com/DataDog/datadog-process-agent/config"
+	"github.com/DataDog/datadog-process-agent/util"
+)
+
+// fillProcessThreads reads the thread count of the processes it wasn't collected for with
+// readThreads. Every process runs at least one thread, a count of 0 means it is unknown and
+// stays unreported if it can't be read either.
+func fillProcessThreads(cfg *config.AgentConfig, procs map[int32]*process.FilledProcess, readThreads func(pid int32) (int32, error)) {
+	if !cfg.CollectsProcessField(config.ProcessFieldThreads) {
+		return
+	}
+
+	for pid, fp := range procs {
+		if fp.NumThreads > 0 {
+			continue
+		}
+		threads, err := readThreads(pid)
+		if err != nil {
+			// The process most likely exited
+			log.Debugf("unable to read the thread count of pid %d: %s", pid, err)
+			continue
+		}
+		fp.NumThreads = threads
+	}
+}
+
+// hostProcessThreads reads the thread count of a process from HOST_PROC.
+func hostProcessThreads(pid int32) (int32, error) {
+	return readProcessThreads(util.HostProc(), pid)
+}
+
+// readProcessThreads parses the Threads line of <procRoot>/<pid>/status.
+func readProcessThreads(procRoot string, pid int32) (int32, error) {
+	path := filepath.Join(procRoot, strconv.Itoa(int(pid)), "status")
+	lines, err := util.ReadLines(path)
+	if err != nil {
+		return 0, err
+	}
+
+	for _, l := range lines {
+		if !strings.HasPrefix(l, "Threads:") {
+			continue
+		}
+		threads, err := strconv.ParseInt(strings.TrimSpace(strings.TrimPrefix(l, "Threads:")), 10, 32)
+		if err != nil {
+			return 0, fmt.Errorf("invalid Threads in %s: %s", path, err)
+		}
+		return int32(threads), nil
+	}
+	return 0, fmt.Errorf("no Threads in %s", path)
+}
@@ -0,0 +1,69 @@
+package checks
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/DataDog/gopsutil/process"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/DataDog/datadog-process-agent/config"
+)
+
+func TestReadProcessThreads(t *testing.T) {
+	assert := assert.New(t)
+	procRoot, err := ioutil.TempDir("", "proc")
+	assert.NoError(err)
+	defer os.RemoveAll(procRoot)
+
+	for pid, status := range map[string]string{
+		"42": "Name:\tpostgres\nUmask:\t0077\nState:\tS (sleeping)\nTgid:\t42\nPid:\t42\nPPid:\t1\n" +
+			"VmRSS:\t   24576 kB\nThreads:\t7\nSigQ:\t0/63448\nvoluntary_ctxt_switches:\t150\n",
+		"43": "Name:\tzombie\nState:\tZ (zombie)\n",
+		"44": "Name:\tbroken\nThreads:\tmany\n",
+	} {
+		assert.NoError(os.MkdirAll(filepath.Join(procRoot, pid), 0755))
+		assert.NoError(ioutil.WriteFile(filepath.Join(procRoot, pid, "status"), []byte(status), 0444))
+	}
+
+	threads, err := readProcessThreads(procRoot, 42)
+	assert.NoError(err)
+	assert.Equal(int32(7), threads)
+
+	for _, pid := range []int32{43, 44, 45} {
+		_, err = readProcessThreads(procRoot, pid)
+		assert.Error(err, "pid %d", pid)
+	}
+}
+
+func TestFillProcessThreads(t *testing.T) {
+	assert := assert.New(t)
+	readThreads := func(pid int32) (int32, error) {
+		if pid == 2 {
+			return 4, nil
+		}
+		return 0, errors.New("exited")
+	}
+	newProcs := func() map[int32]*process.FilledProcess {
+		return map[int32]*process.FilledProcess{
+			1: {Pid: 1, NumThreads: 9},
+			2: {Pid: 2},
+			3: {Pid: 3},
+		}
+	}
+
+	cfg := config.NewDefaultAgentConfig()
+	procs := newProcs()
+	fillProcessThreads(cfg, procs, readThreads)
+	assert.Equal(int32(9), procs[1].NumThreads)
+	assert.Equal(int32(4), procs[2].NumThreads)
+	assert.Equal(int32(0), procs[3].NumThreads)
+
+	cfg.ProcessFields = map[string]bool{config.ProcessFieldCPU: true}
+	procs = newProcs()
+	fillProcessThreads(cfg, procs, readThreads)
+	assert.Equal(int32(0), procs[2].NumThreads)
+}
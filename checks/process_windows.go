@@ -5,12 +5,13 @@ package checks
 import (
 	"runtime"
 
+	"github.com/DataDog/datadog-process-agent/config"
 	"github.com/DataDog/datadog-process-agent/model"
 	"github.com/DataDog/gopsutil/cpu"
 	"github.com/DataDog/gopsutil/process"
 )
 
-func formatUser(fp *process.FilledProcess) *model.ProcessUser {
+func formatUser(_ *config.AgentConfig, fp *process.FilledProcess) *model.ProcessUser {
 	return &model.ProcessUser{
 		Name: fp.Username,
 	}
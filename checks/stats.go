@@ -0,0 +1,97 @@
+package checks
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/DataDog/datadog-process-agent/model"
+)
+
+// CheckStats holds runtime statistics about a single check.
+type CheckStats struct {
+	// Duration of the most recent run
+	LastRunDuration time.Duration
+	// Number of items (processes, containers, connections...) collected in the most recent run
+	LastItemCount int
+	// Total number of failed runs
+	ErrorCount int64
+	// Total number of runs abandoned because they went over the check timeout
+	TimeoutCount int64
+	// Error of the most recent failed or timed out run, and when it ended
+	LastError     string
+	LastErrorTime time.Time
+	// When the most recent successful run ended
+	LastSuccessTime time.Time
+}
+
+var (
+	statsMutex sync.RWMutex
+	checkStats = make(map[string]CheckStats)
+
+	// statsNow returns the end time of the recorded runs, replaced in tests
+	statsNow = time.Now
+)
+
+// RecordRun stores the outcome of a check run in the stats registry.
+func RecordRun(name string, d time.Duration, msgs []model.MessageBody, err error) {
+	statsMutex.Lock()
+	defer statsMutex.Unlock()
+
+	s := checkStats[name]
+	s.LastRunDuration = d
+	if err != nil {
+		s.ErrorCount++
+		s.LastItemCount = 0
+		s.LastError, s.LastErrorTime = err.Error(), statsNow()
+	} else {
+		s.LastItemCount = CountItems(msgs)
+		s.LastSuccessTime = statsNow()
+	}
+	checkStats[name] = s
+}
+
+// RecordTimeout counts a check run that was abandoned after going over its timeout.
+func RecordTimeout(name string, d time.Duration) {
+	statsMutex.Lock()
+	defer statsMutex.Unlock()
+
+	s := checkStats[name]
+	s.LastRunDuration = d
+	s.LastItemCount = 0
+	s.TimeoutCount++
+	s.LastError, s.LastErrorTime = fmt.Sprintf("timed out after %s", d), statsNow()
+	checkStats[name] = s
+}
+
+// Stats returns a snapshot of the stats for every check that has run, keyed by check name.
+func Stats() map[string]CheckStats {
+	statsMutex.RLock()
+	defer statsMutex.RUnlock()
+
+	stats := make(map[string]CheckStats, len(checkStats))
+	for name, s := range checkStats {
+		stats[name] = s
+	}
+	return stats
+}
+
+// CountItems returns the number of top-level items contained in the messages.
+func CountItems(msgs []model.MessageBody) int {
+	var count int
+	for _, m := range msgs {
+		switch msg := m.(type) {
+		case *model.CollectorProc:
+			count += len(msg.Processes)
+		case *model.CollectorRealTime:
+			count += len(msg.Stats)
+		case *model.CollectorContainer:
+			count += len(msg.Containers)
+		case *model.CollectorContainerRealTime:
+			count += len(msg.Stats)
+		case *model.CollectorConnections:
+			count += len(msg.Connections)
+		}
+	}
+	return count
+}
@@ -0,0 +1,50 @@
+package checks
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/DataDog/datadog-process-agent/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRecordRun(t *testing.T) {
+	assert := assert.New(t)
+	end := time.Date(2018, 9, 1, 12, 0, 0, 0, time.UTC)
+	defer func() { statsNow = time.Now }()
+	statsNow = func() time.Time { return end }
+
+	msgs := []model.MessageBody{
+		&model.CollectorProc{Processes: make([]*model.Process, 3)},
+		&model.CollectorProc{Processes: make([]*model.Process, 2)},
+	}
+	RecordRun("test-process", time.Second, msgs, nil)
+	RecordRun("test-connections", 2*time.Second, []model.MessageBody{
+		&model.CollectorConnections{Connections: make([]*model.Connection, 4)},
+	}, nil)
+
+	stats := Stats()
+	assert.Equal(CheckStats{LastRunDuration: time.Second, LastItemCount: 5, LastSuccessTime: end}, stats["test-process"])
+	assert.Equal(CheckStats{LastRunDuration: 2 * time.Second, LastItemCount: 4, LastSuccessTime: end}, stats["test-connections"])
+
+	// The last error is kept along with the last success
+	failed := end.Add(time.Minute)
+	statsNow = func() time.Time { return failed }
+	RecordRun("test-process", 3*time.Second, nil, fmt.Errorf("failed"))
+	RecordRun("test-process", 4*time.Second, nil, fmt.Errorf("failed again"))
+	assert.Equal(CheckStats{
+		LastRunDuration: 4 * time.Second,
+		ErrorCount:      2,
+		LastError:       "failed again",
+		LastErrorTime:   failed,
+		LastSuccessTime: end,
+	}, Stats()["test-process"])
+
+	RecordTimeout("test-process", 5*time.Second)
+	assert.Equal("timed out after 5s", Stats()["test-process"].LastError)
+
+	// Snapshots must not be affected by later runs
+	RecordRun("test-connections", time.Second, nil, nil)
+	assert.Equal(4, stats["test-connections"].LastItemCount)
+}
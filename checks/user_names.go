@@ -0,0 +1,56 @@
+// +build !windows
+
+package checks
+
+import (
+	"os/user"
+	"strconv"
+	"time"
+
+	"github.com/DataDog/datadog-process-agent/util/cache"
+)
+
+// userNameCacheTTL is how long the user name of a UID is cached, so that renamed users
+// are eventually picked up.
+const userNameCacheTTL = 10 * time.Minute
+
+// userNames caches the user names of the UIDs of the processes, as each lookup can go
+// through NSS and query the network, e.g. with LDAP backed users. It is created on first
+// use, bounded by cache_max_entries, and only used from the process check.
+var userNames *userNameCache
+
+// userNameCache resolves UIDs to user names, caching the result of each lookup,
+// including the failed ones, for ttl.
+type userNameCache struct {
+	lookup  func(uid uint32) (string, error)
+	entries *cache.LRU
+}
+
+func newUserNameCache(maxEntries int, ttl time.Duration, lookup func(uid uint32) (string, error)) *userNameCache {
+	return &userNameCache{
+		lookup:  lookup,
+		entries: cache.NewLRU(maxEntries, ttl),
+	}
+}
+
+// name returns the user name of the UID, empty if it can't be resolved.
+func (c *userNameCache) name(uid uint32, now time.Time) string {
+	if name, ok := c.entries.Get(uid, now); ok {
+		return name.(string)
+	}
+
+	name, err := c.lookup(uid)
+	if err != nil {
+		name = ""
+	}
+	c.entries.Add(uid, name, now)
+	return name
+}
+
+func lookupUserName(uid uint32) (string, error) {
+	u, err := user.LookupId(strconv.FormatUint(uint64(uid), 10))
+	if err != nil {
+		return "", err
+	}
+	return u.Username, nil
+}
@@ -0,0 +1,77 @@
+// +build !windows
+
+package checks
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DataDog/gopsutil/process"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/DataDog/datadog-process-agent/config"
+)
+
+func TestUserNameCache(t *testing.T) {
+	assert := assert.New(t)
+	lookups := map[uint32]int{}
+	names := map[uint32]string{0: "root", 1000: "alice"}
+	c := newUserNameCache(10, time.Minute, func(uid uint32) (string, error) {
+		lookups[uid]++
+		if name, ok := names[uid]; ok {
+			return name, nil
+		}
+		return "", errors.New("unknown user")
+	})
+
+	now := time.Now()
+	for i := 0; i < 3; i++ {
+		assert.Equal("root", c.name(0, now))
+		assert.Equal("alice", c.name(1000, now))
+		assert.Equal("", c.name(1001, now))
+	}
+	// Failed lookups are cached too
+	assert.Equal(map[uint32]int{0: 1, 1000: 1, 1001: 1}, lookups)
+
+	// Entries are looked up again once expired
+	names[1000] = "bob"
+	assert.Equal("alice", c.name(1000, now.Add(59*time.Second)))
+	assert.Equal("bob", c.name(1000, now.Add(time.Minute)))
+	assert.Equal(2, lookups[1000])
+
+	// At most cache_max_entries UIDs are cached
+	c = newUserNameCache(2, time.Minute, func(uid uint32) (string, error) { return "", nil })
+	for uid := uint32(0); uid < 5; uid++ {
+		c.name(uid, now)
+	}
+	assert.Equal(2, c.entries.Len())
+}
+
+func TestFormatUser(t *testing.T) {
+	assert := assert.New(t)
+	lookups := 0
+	defer func(c *userNameCache) { userNames = c }(userNames)
+	userNames = newUserNameCache(10, time.Minute, func(uid uint32) (string, error) {
+		lookups++
+		return "alice", nil
+	})
+
+	cfg := config.NewDefaultAgentConfig()
+	fp := &process.FilledProcess{Pid: 1, Uids: []int32{1000, 1000}, Gids: []int32{100, 100}}
+	for i := 0; i < 2; i++ {
+		u := formatUser(cfg, fp)
+		assert.Equal("alice", u.Name)
+		assert.Equal(int32(1000), u.Uid)
+		assert.Equal(int32(100), u.Gid)
+	}
+	assert.Equal(1, lookups)
+
+	// Only the IDs are reported when the names aren't resolved
+	cfg.ResolveUserNames = false
+	u := formatUser(cfg, fp)
+	assert.Equal("", u.Name)
+	assert.Equal(int32(1000), u.Uid)
+	assert.Equal(int32(100), u.Gid)
+	assert.Equal(1, lookups)
+}
@@ -0,0 +1,72 @@
+package config
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	log "github.com/cihub/seelog"
+)
+
+// ArgsRefreshBucket sets how often the arguments of the processes younger than MaxAge are
+// refreshed on Windows, recently started processes being more likely to be unknown to
+// the last refresh.
+type ArgsRefreshBucket struct {
+	MaxAge time.Duration
+	// Number of checks runs between refreshes
+	RefreshInterval int
+}
+
+// ArgsRefreshIntervalFor returns the number of check runs between refreshes of the arguments
+// of a process of the given age: that of the youngest bucket holding it, or ArgsRefreshInterval
+// for the processes older than all the buckets.
+func (w WindowsConfig) ArgsRefreshIntervalFor(age time.Duration) int {
+	for _, b := range w.ArgsRefreshAgeBuckets {
+		if age < b.MaxAge {
+			return b.RefreshInterval
+		}
+	}
+	return w.ArgsRefreshInterval
+}
+
+// sortArgsRefreshBuckets drops the invalid buckets and sorts the others by age.
+func sortArgsRefreshBuckets(buckets []ArgsRefreshBucket) []ArgsRefreshBucket {
+	valid := make([]ArgsRefreshBucket, 0, len(buckets))
+	for _, b := range buckets {
+		if b.MaxAge <= 0 || b.RefreshInterval <= 0 {
+			log.Warnf("Ignoring args refresh bucket with max age %s and refresh interval %d, both must be positive", b.MaxAge, b.RefreshInterval)
+			continue
+		}
+		valid = append(valid, b)
+	}
+	sort.Slice(valid, func(i, j int) bool { return valid[i].MaxAge < valid[j].MaxAge })
+	return valid
+}
+
+// parseArgsRefreshBuckets parses a comma-separated list of <max age in seconds>:<refresh interval>
+// buckets, e.g. 60:1,3600:5.
+func parseArgsRefreshBuckets(value string) ([]ArgsRefreshBucket, error) {
+	var buckets []ArgsRefreshBucket
+	for _, pair := range strings.Split(value, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+		parts := strings.Split(pair, ":")
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("invalid bucket '%s', expected <max age>:<refresh interval>", pair)
+		}
+		age, err := strconv.Atoi(strings.TrimSpace(parts[0]))
+		if err != nil {
+			return nil, fmt.Errorf("invalid max age in bucket '%s': %s", pair, err)
+		}
+		interval, err := strconv.Atoi(strings.TrimSpace(parts[1]))
+		if err != nil {
+			return nil, fmt.Errorf("invalid refresh interval in bucket '%s': %s", pair, err)
+		}
+		buckets = append(buckets, ArgsRefreshBucket{MaxAge: time.Duration(age) * time.Second, RefreshInterval: interval})
+	}
+	return sortArgsRefreshBuckets(buckets), nil
+}
@@ -0,0 +1,85 @@
+package config
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-ini/ini"
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/yaml.v2"
+)
+
+func TestArgsRefreshIntervalFor(t *testing.T) {
+	w := WindowsConfig{
+		ArgsRefreshInterval: 15,
+		ArgsRefreshAgeBuckets: []ArgsRefreshBucket{
+			{MaxAge: time.Minute, RefreshInterval: 1},
+			{MaxAge: time.Hour, RefreshInterval: 5},
+		},
+	}
+	for age, interval := range map[time.Duration]int{
+		0:                1,
+		59 * time.Second: 1,
+		time.Minute:      5,
+		30 * time.Minute: 5,
+		time.Hour:        15,
+		48 * time.Hour:   15,
+		-time.Second:     1, // Clock adjustments can put the start time in the future
+	} {
+		assert.Equal(t, interval, w.ArgsRefreshIntervalFor(age), age.String())
+	}
+
+	// Without buckets all the processes use the same interval
+	w.ArgsRefreshAgeBuckets = nil
+	assert.Equal(t, 15, w.ArgsRefreshIntervalFor(time.Second))
+}
+
+func TestParseArgsRefreshBuckets(t *testing.T) {
+	assert := assert.New(t)
+	buckets, err := parseArgsRefreshBuckets(" 3600:5, 60:1,0:2,120:-1")
+	assert.NoError(err)
+	// Invalid buckets are dropped and the others sorted by age
+	assert.Equal([]ArgsRefreshBucket{
+		{MaxAge: time.Minute, RefreshInterval: 1},
+		{MaxAge: time.Hour, RefreshInterval: 5},
+	}, buckets)
+
+	for _, value := range []string{"60", "60:1:2", "a minute:1", "60:often"} {
+		_, err = parseArgsRefreshBuckets(value)
+		assert.Error(err, value)
+	}
+}
+
+func TestArgsRefreshAgeBucketsConfig(t *testing.T) {
+	assert := assert.New(t)
+	expected := []ArgsRefreshBucket{
+		{MaxAge: time.Minute, RefreshInterval: 1},
+		{MaxAge: 10 * time.Minute, RefreshInterval: 3},
+	}
+
+	dd, _ := ini.Load([]byte(strings.Join([]string{
+		"[Main]",
+		"api_key = apikey_12",
+		"[process.config]",
+		"windows_args_refresh_age_buckets = 600:3,60:1",
+	}, "\n")))
+	agentConfig, err := NewAgentConfig(&File{instance: dd, Path: "whatever"}, nil)
+	assert.NoError(err)
+	assert.Equal(expected, agentConfig.Windows.ArgsRefreshAgeBuckets)
+
+	var ddy YamlAgentConfig
+	assert.NoError(yaml.Unmarshal([]byte(strings.Join([]string{
+		"api_key: apikey_20",
+		"process_config:",
+		"  windows:",
+		"    args_refresh_age_buckets:",
+		"      - max_age: 600",
+		"        refresh_interval: 3",
+		"      - max_age: 60",
+		"        refresh_interval: 1",
+	}, "\n")), &ddy))
+	agentConfig, err = NewAgentConfig(nil, &ddy)
+	assert.NoError(err)
+	assert.Equal(expected, agentConfig.Windows.ArgsRefreshAgeBuckets)
+}
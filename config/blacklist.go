@@ -0,0 +1,153 @@
+package config
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync/atomic"
+
+	log "github.com/cihub/seelog"
+	"github.com/fsnotify/fsnotify"
+
+	"github.com/DataDog/datadog-process-agent/util"
+)
+
+// blacklistExceptionPrefix marks the blacklist patterns of the processes that are never
+// blacklisted, even if they match other patterns. A pattern starting with a literal ! can
+// be written with an escaped \!.
+const blacklistExceptionPrefix = "!"
+
+// parseBlacklistPattern compiles a blacklist pattern, returning whether it is an exception.
+func parseBlacklistPattern(pattern string) (*regexp.Regexp, bool, error) {
+	exception := strings.HasPrefix(pattern, blacklistExceptionPrefix)
+	if exception {
+		pattern = strings.TrimPrefix(pattern, blacklistExceptionPrefix)
+	}
+	r, err := regexp.Compile(pattern)
+	return r, exception, err
+}
+
+// blacklistPatterns are the compiled patterns of a blacklist.
+type blacklistPatterns struct {
+	patterns   []*regexp.Regexp
+	exceptions []*regexp.Regexp
+}
+
+// BlacklistFile holds process blacklist patterns loaded from a file containing
+// one regex per line. Once watched, the patterns are reloaded when the file
+// changes so that the blacklist can be updated without restarting the agent.
+type BlacklistFile struct {
+	Path string
+
+	patterns atomic.Value // blacklistPatterns
+	watcher  *fsnotify.Watcher
+}
+
+// NewBlacklistFile loads the patterns from the given file. The file isn't watched
+// for changes until Watch is called.
+func NewBlacklistFile(path string) (*BlacklistFile, error) {
+	b := &BlacklistFile{Path: filepath.Clean(path)}
+	if err := b.reload(); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+// Watch starts reloading the patterns when the file changes, until Close is called.
+func (b *BlacklistFile) Watch() error {
+	if b.watcher != nil {
+		return nil
+	}
+	w, err := fsnotify.NewWatcher()
+	if err != nil {
+		return err
+	}
+	// Watch the parent directory so we still see the file if it is replaced
+	// through a rename, which is what most config management tools do.
+	if err := w.Add(filepath.Dir(b.Path)); err != nil {
+		w.Close()
+		return err
+	}
+	b.watcher = w
+	go b.watch(w)
+	return nil
+}
+
+// Patterns returns the patterns from the last successful load of the file.
+func (b *BlacklistFile) Patterns() []*regexp.Regexp {
+	patterns, _ := b.patterns.Load().(blacklistPatterns)
+	return patterns.patterns
+}
+
+// Exceptions returns the !-prefixed patterns from the last successful load of the file.
+func (b *BlacklistFile) Exceptions() []*regexp.Regexp {
+	patterns, _ := b.patterns.Load().(blacklistPatterns)
+	return patterns.exceptions
+}
+
+// Close stops watching the file for changes.
+func (b *BlacklistFile) Close() error {
+	if b == nil || b.watcher == nil {
+		return nil
+	}
+	w := b.watcher
+	b.watcher = nil
+	return w.Close()
+}
+
+func (b *BlacklistFile) watch(w *fsnotify.Watcher) {
+	for {
+		select {
+		case e, ok := <-w.Events:
+			if !ok {
+				return
+			}
+			// A removed file keeps the last known patterns until it is created again.
+			if filepath.Clean(e.Name) != b.Path || e.Op&(fsnotify.Write|fsnotify.Create) == 0 {
+				continue
+			}
+			if err := b.reload(); err != nil {
+				log.Warnf("unable to reload blacklist file %s: %s", b.Path, err)
+				continue
+			}
+			log.Infof("reloaded %d blacklist patterns from %s", len(b.Patterns()), b.Path)
+		case err, ok := <-w.Errors:
+			if !ok {
+				return
+			}
+			log.Warnf("error watching blacklist file %s: %s", b.Path, err)
+		}
+	}
+}
+
+func (b *BlacklistFile) reload() error {
+	if !util.PathExists(b.Path) {
+		return fmt.Errorf("%s does not exist", b.Path)
+	}
+	lines, err := util.ReadLines(b.Path)
+	if err != nil {
+		return err
+	}
+
+	var patterns blacklistPatterns
+	for _, l := range lines {
+		l = strings.TrimSpace(l)
+		// Skip blank lines and comments
+		if l == "" || strings.HasPrefix(l, "#") {
+			continue
+		}
+		r, exception, err := parseBlacklistPattern(l)
+		if err != nil {
+			log.Warnf("Invalid blacklist pattern in %s: %s", b.Path, l)
+			continue
+		}
+		if exception {
+			patterns.exceptions = append(patterns.exceptions, r)
+		} else {
+			patterns.patterns = append(patterns.patterns, r)
+		}
+	}
+	b.patterns.Store(patterns)
+	return nil
+}
@@ -0,0 +1,57 @@
+package config
+
+import (
+	"path/filepath"
+	"strings"
+
+	"github.com/DataDog/gopsutil/process"
+	log "github.com/cihub/seelog"
+)
+
+// Fields of the processes the blacklist patterns are matched against, see AgentConfig.BlacklistMatchField.
+const (
+	// BlacklistMatchCmdline matches the command line, its arguments joined by spaces.
+	BlacklistMatchCmdline = "cmdline"
+	// BlacklistMatchExe matches the path of the executable of the process.
+	BlacklistMatchExe = "exe"
+	// BlacklistMatchName matches the name of the process, e.g. the basename of the executable.
+	BlacklistMatchName = "name"
+)
+
+// parseBlacklistMatchField returns the blacklist match field matching the name, defaulting
+// to the command line for unknown names.
+func parseBlacklistMatchField(name string) string {
+	switch f := strings.ToLower(strings.TrimSpace(name)); f {
+	case BlacklistMatchCmdline, BlacklistMatchExe, BlacklistMatchName:
+		return f
+	default:
+		log.Warnf("Unknown blacklist_match_field '%s', choose from: %s, %s, %s. Defaulting to %s",
+			name, BlacklistMatchCmdline, BlacklistMatchExe, BlacklistMatchName, BlacklistMatchCmdline)
+		return BlacklistMatchCmdline
+	}
+}
+
+// blacklistMatchValue returns the field of the process the blacklist patterns are matched
+// against. The executable and name fall back to the first argument of the command line
+// when unknown, e.g. as the executable of the processes of other users can't be read.
+func blacklistMatchValue(field string, fp *process.FilledProcess) []string {
+	switch field {
+	case BlacklistMatchExe:
+		if fp.Exe != "" {
+			return []string{fp.Exe}
+		}
+		if len(fp.Cmdline) > 0 {
+			return fp.Cmdline[:1]
+		}
+	case BlacklistMatchName:
+		if fp.Name != "" {
+			return []string{fp.Name}
+		}
+		if len(fp.Cmdline) > 0 {
+			return []string{filepath.Base(fp.Cmdline[0])}
+		}
+	default:
+		return fp.Cmdline
+	}
+	return nil
+}
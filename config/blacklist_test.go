@@ -0,0 +1,209 @@
+package config
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DataDog/gopsutil/process"
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/yaml.v2"
+)
+
+func TestBlacklistFile(t *testing.T) {
+	assert := assert.New(t)
+	dir, err := ioutil.TempDir("", "blacklist")
+	assert.NoError(err)
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "blacklist.txt")
+	assert.NoError(ioutil.WriteFile(path, []byte(strings.Join([]string{
+		"# managed by config management",
+		"^/usr/sbin/sshd",
+		"",
+		"[invalid",
+		"mysql",
+	}, "\n")), 0644))
+
+	var ddy YamlAgentConfig
+	err = yaml.Unmarshal([]byte(strings.Join([]string{
+		"api_key: apikey_20",
+		"process_config:",
+		"  blacklist_patterns:",
+		"    - ^/bin/bash",
+		"  blacklist_file: " + path,
+	}, "\n")), &ddy)
+	assert.NoError(err)
+
+	agentConfig, err := NewAgentConfig(nil, &ddy)
+	assert.NoError(err)
+	defer agentConfig.BlacklistFile.Close()
+
+	// Invalid patterns are skipped
+	assert.Len(agentConfig.BlacklistFile.Patterns(), 2)
+	assert.True(agentConfig.IsProcessBlacklisted(&process.FilledProcess{Cmdline: []string{"/bin/bash", "-l"}}))
+	assert.True(agentConfig.IsProcessBlacklisted(&process.FilledProcess{Cmdline: []string{"/usr/sbin/sshd", "-D"}}))
+	assert.True(agentConfig.IsProcessBlacklisted(&process.FilledProcess{Cmdline: []string{"mysqld"}}))
+	assert.False(agentConfig.IsProcessBlacklisted(&process.FilledProcess{Cmdline: []string{"postgres"}}))
+
+	// The file is only reloaded once watched
+	assert.NoError(agentConfig.BlacklistFile.Watch())
+	assert.NoError(ioutil.WriteFile(path, []byte("postgres\n"), 0644))
+	deadline := time.Now().Add(5 * time.Second)
+	for !agentConfig.IsProcessBlacklisted(&process.FilledProcess{Cmdline: []string{"postgres"}}) && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	assert.True(agentConfig.IsProcessBlacklisted(&process.FilledProcess{Cmdline: []string{"postgres"}}))
+	assert.False(agentConfig.IsProcessBlacklisted(&process.FilledProcess{Cmdline: []string{"mysqld"}}))
+	assert.True(agentConfig.IsProcessBlacklisted(&process.FilledProcess{Cmdline: []string{"/bin/bash", "-l"}}))
+}
+
+func TestBlacklistFileClose(t *testing.T) {
+	assert := assert.New(t)
+	dir, err := ioutil.TempDir("", "blacklist")
+	assert.NoError(err)
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "blacklist.txt")
+	assert.NoError(ioutil.WriteFile(path, []byte("mysql\n"), 0644))
+	b, err := NewBlacklistFile(path)
+	assert.NoError(err)
+	assert.NoError(b.Watch())
+	assert.NoError(b.Close())
+	assert.NoError(b.Close())
+
+	// Changes made after Close are ignored
+	assert.NoError(ioutil.WriteFile(path, []byte("postgres\nredis\n"), 0644))
+	time.Sleep(100 * time.Millisecond)
+	assert.Len(b.Patterns(), 1)
+	assert.Equal("mysql", b.Patterns()[0].String())
+}
+
+func TestBlacklistFileMissing(t *testing.T) {
+	_, err := NewBlacklistFile("/does/not/exist")
+	assert.Error(t, err)
+}
+
+func TestYamlInvalidBlacklistPattern(t *testing.T) {
+	assert := assert.New(t)
+	var ddy YamlAgentConfig
+	assert.NoError(yaml.Unmarshal([]byte(strings.Join([]string{
+		"api_key: apikey_20",
+		"process_config:",
+		"  blacklist_patterns:",
+		"    - '[invalid'",
+		"    - ^/bin/bash",
+	}, "\n")), &ddy))
+
+	agentConfig, err := NewAgentConfig(nil, &ddy)
+	assert.NoError(err)
+	assert.Len(agentConfig.Blacklist, 1)
+	for _, r := range agentConfig.Blacklist {
+		assert.NotNil(r)
+	}
+	assert.NotPanics(func() {
+		assert.True(agentConfig.IsProcessBlacklisted(&process.FilledProcess{Cmdline: []string{"/bin/bash", "-l"}}))
+		assert.False(agentConfig.IsProcessBlacklisted(&process.FilledProcess{Cmdline: []string{"[invalid"}}))
+	})
+}
+
+func TestBlacklistMatchField(t *testing.T) {
+	assert := assert.New(t)
+	// The blacklisted term only appears in the arguments of the first process
+	ssh := &process.FilledProcess{Name: "ssh", Exe: "/usr/bin/ssh", Cmdline: []string{"ssh", "-J", "bastion", "python@host"}}
+	python := &process.FilledProcess{Name: "python3.6", Exe: "/usr/bin/python3.6", Cmdline: []string{"python", "app.py"}}
+
+	for field, expected := range map[string][2]bool{
+		"":        {true, true},
+		"cmdline": {true, true},
+		"exe":     {false, true},
+		" Name ":  {false, true},
+		"path":    {true, true},
+	} {
+		var ddy YamlAgentConfig
+		assert.NoError(yaml.Unmarshal([]byte(strings.Join([]string{
+			"api_key: apikey_20",
+			"process_config:",
+			"  blacklist_patterns:",
+			"    - python",
+			"  blacklist_match_field: '" + field + "'",
+		}, "\n")), &ddy))
+		agentConfig, err := NewAgentConfig(nil, &ddy)
+		assert.NoError(err, field)
+		assert.Equal(expected[0], agentConfig.IsProcessBlacklisted(ssh), field)
+		assert.Equal(expected[1], agentConfig.IsProcessBlacklisted(python), field)
+	}
+
+	// Anchored patterns match the whole exe or name
+	cfg := NewDefaultAgentConfig()
+	cfg.Blacklist = []*regexp.Regexp{regexp.MustCompile("^ssh$")}
+	assert.False(cfg.IsProcessBlacklisted(ssh))
+	cfg.BlacklistMatchField = BlacklistMatchName
+	assert.True(cfg.IsProcessBlacklisted(ssh))
+	cfg.BlacklistMatchField = BlacklistMatchExe
+	assert.False(cfg.IsProcessBlacklisted(ssh))
+
+	// The first argument is used when the exe or name are unknown
+	cfg.Blacklist = []*regexp.Regexp{regexp.MustCompile("^/usr/sbin/sshd$")}
+	sshd := &process.FilledProcess{Cmdline: []string{"/usr/sbin/sshd", "-D"}}
+	assert.True(cfg.IsProcessBlacklisted(sshd))
+	cfg.BlacklistMatchField = BlacklistMatchName
+	cfg.Blacklist = []*regexp.Regexp{regexp.MustCompile("^sshd$")}
+	assert.True(cfg.IsProcessBlacklisted(sshd))
+}
+
+func TestBlacklistExceptions(t *testing.T) {
+	assert := assert.New(t)
+	blacklist := []*regexp.Regexp{regexp.MustCompile("^/opt/vendor/")}
+	exceptions := []*regexp.Regexp{regexp.MustCompile("^/opt/vendor/bin/server ")}
+	assert.True(IsBlacklisted([]string{"/opt/vendor/bin/worker"}, blacklist, exceptions))
+	assert.False(IsBlacklisted([]string{"/opt/vendor/bin/server", "--port=80"}, blacklist, exceptions))
+	assert.False(IsBlacklisted([]string{"/usr/bin/server", "--port=80"}, blacklist, exceptions))
+
+	dir, err := ioutil.TempDir("", "blacklist")
+	assert.NoError(err)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "blacklist")
+	assert.NoError(ioutil.WriteFile(path, []byte("^sshd\n!^sshd: deploy\n\\!important\n"), 0644))
+
+	var ddy YamlAgentConfig
+	assert.NoError(yaml.Unmarshal([]byte(strings.Join([]string{
+		"api_key: apikey_20",
+		"process_config:",
+		"  blacklist_patterns:",
+		"    - ^/opt/vendor/",
+		"    - '!^/opt/vendor/bin/server '",
+		"  blacklist_file: " + path,
+	}, "\n")), &ddy))
+	agentConfig, err := NewAgentConfig(nil, &ddy)
+	assert.NoError(err)
+	defer agentConfig.BlacklistFile.Close()
+	assert.Len(agentConfig.Blacklist, 1)
+	assert.Len(agentConfig.BlacklistExceptions, 1)
+	assert.Len(agentConfig.BlacklistFile.Patterns(), 2)
+	assert.Len(agentConfig.BlacklistFile.Exceptions(), 1)
+
+	for cmdline, blacklisted := range map[string]bool{
+		"/opt/vendor/bin/worker":            true,
+		"/opt/vendor/bin/server --port=80":  false,
+		"sshd: root@pts/0":                  true,
+		"sshd: deploy@pts/1":                false,
+		"!important":                        true,
+		"/usr/bin/python app.py":            false,
+		"/opt/vendor/bin/server --port=443": false,
+	} {
+		fp := &process.FilledProcess{Cmdline: strings.Split(cmdline, " ")}
+		assert.Equal(blacklisted, agentConfig.IsProcessBlacklisted(fp), cmdline)
+	}
+
+	// The exceptions of the inline patterns apply to the patterns of the file, and vice versa
+	agentConfig.BlacklistExceptions = []*regexp.Regexp{regexp.MustCompile("root")}
+	agentConfig.Blacklist = []*regexp.Regexp{regexp.MustCompile("deploy")}
+	assert.False(agentConfig.IsProcessBlacklisted(&process.FilledProcess{Cmdline: []string{"sshd:", "root@pts/0"}}))
+	assert.False(agentConfig.IsProcessBlacklisted(&process.FilledProcess{Cmdline: []string{"sshd:", "deploy@pts/1"}}))
+	assert.True(agentConfig.IsProcessBlacklisted(&process.FilledProcess{Cmdline: []string{"deploy.sh"}}))
+}
@@ -0,0 +1,155 @@
+package config
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"time"
+
+	log "github.com/cihub/seelog"
+)
+
+var (
+	// cloudMetadataTimeout bounds the queries of the metadata endpoints, which don't answer
+	// at all outside of their cloud, so that they can't hold up the startup.
+	cloudMetadataTimeout = 300 * time.Millisecond
+
+	ec2MetadataURL   = "http://169.254.169.254/latest"
+	gceMetadataURL   = "http://metadata.google.internal/computeMetadata/v1"
+	azureMetadataURL = "http://169.254.169.254/metadata"
+
+	// Metadata endpoints are link-local, they must never be queried through a proxy.
+	cloudMetadataClient = &http.Client{Transport: &http.Transport{Proxy: nil}}
+)
+
+// ec2TokenTTL is the lifetime in seconds of the IMDSv2 session tokens we request.
+const ec2TokenTTL = "60"
+
+type cloudProvider struct {
+	name       string
+	instanceID func(ctx context.Context) (string, error)
+}
+
+// cloudProviders are queried in parallel, the first of them to return an instance ID wins.
+var cloudProviders = []cloudProvider{
+	{name: "ec2", instanceID: ec2InstanceID},
+	{name: "gce", instanceID: gceInstanceID},
+	{name: "azure", instanceID: azureInstanceID},
+}
+
+// cloudHostname returns the instance ID of the host from the metadata of its cloud provider
+// if use_cloud_hostname is enabled, and false if it is disabled or the host isn't in a
+// supported cloud.
+func cloudHostname(cfg *AgentConfig) (string, bool) {
+	if !cfg.UseCloudHostname {
+		return "", false
+	}
+	provider, id, err := getCloudInstanceID()
+	if err != nil {
+		log.Infof("unable to get the instance ID from the cloud metadata, falling back to the agent hostname: %s", err)
+		return "", false
+	}
+	log.Infof("using the %s instance ID as hostname", provider)
+	return id, true
+}
+
+// getCloudInstanceID queries the metadata endpoints of all the cloud providers at once and
+// returns the name of the provider and the instance ID from the first one that has it.
+func getCloudInstanceID() (string, string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), cloudMetadataTimeout)
+	defer cancel()
+
+	type result struct {
+		provider string
+		id       string
+		err      error
+	}
+	results := make(chan result, len(cloudProviders))
+	for _, p := range cloudProviders {
+		go func(p cloudProvider) {
+			id, err := p.instanceID(ctx)
+			results <- result{provider: p.name, id: id, err: err}
+		}(p)
+	}
+
+	errs := make([]string, 0, len(cloudProviders))
+	for range cloudProviders {
+		r := <-results
+		if r.err == nil {
+			return r.provider, r.id, nil
+		}
+		errs = append(errs, fmt.Sprintf("%s: %s", r.provider, r.err))
+	}
+	return "", "", errors.New(strings.Join(errs, ", "))
+}
+
+// ec2InstanceID reads the instance ID with an IMDSv2 session token, falling back to IMDSv1
+// on the instances that don't support tokens.
+func ec2InstanceID(ctx context.Context) (string, error) {
+	token, err := getMetadata(ctx, "PUT", ec2MetadataURL+"/api/token", map[string]string{
+		"X-aws-ec2-metadata-token-ttl-seconds": ec2TokenTTL,
+	})
+	if _, ok := err.(*metadataStatusError); err != nil && !ok {
+		return "", err
+	}
+	var headers map[string]string
+	if err == nil {
+		headers = map[string]string{"X-aws-ec2-metadata-token": token}
+	}
+	return getMetadata(ctx, "GET", ec2MetadataURL+"/meta-data/instance-id", headers)
+}
+
+func gceInstanceID(ctx context.Context) (string, error) {
+	return getMetadata(ctx, "GET", gceMetadataURL+"/instance/id", map[string]string{
+		"Metadata-Flavor": "Google",
+	})
+}
+
+func azureInstanceID(ctx context.Context) (string, error) {
+	return getMetadata(ctx, "GET", azureMetadataURL+"/instance/compute/vmId?api-version=2017-08-01&format=text", map[string]string{
+		"Metadata": "true",
+	})
+}
+
+// metadataStatusError is returned when a metadata endpoint answers with an unexpected status.
+type metadataStatusError struct {
+	status int
+}
+
+func (e *metadataStatusError) Error() string {
+	return fmt.Sprintf("unexpected status %d", e.status)
+}
+
+// getMetadata returns the trimmed body of a metadata query, which must be non-empty.
+func getMetadata(ctx context.Context, method, url string, headers map[string]string) (string, error) {
+	req, err := http.NewRequest(method, url, nil)
+	if err != nil {
+		return "", err
+	}
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+
+	resp, err := cloudMetadataClient.Do(req.WithContext(ctx))
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", &metadataStatusError{status: resp.StatusCode}
+	}
+
+	body, err := ioutil.ReadAll(io.LimitReader(resp.Body, 1024))
+	if err != nil {
+		return "", err
+	}
+	value := strings.TrimSpace(string(body))
+	if value == "" {
+		return "", errors.New("empty response")
+	}
+	return value, nil
+}
@@ -0,0 +1,148 @@
+package config
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/go-ini/ini"
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/yaml.v2"
+)
+
+// withMetadataServer points the metadata endpoints of all the providers to a stub server.
+func withMetadataServer(t *testing.T, handler http.HandlerFunc, test func()) {
+	srv := httptest.NewServer(handler)
+	defer srv.Close()
+
+	ec2, gce, azure := ec2MetadataURL, gceMetadataURL, azureMetadataURL
+	ec2MetadataURL = srv.URL + "/latest"
+	gceMetadataURL = srv.URL + "/computeMetadata/v1"
+	azureMetadataURL = srv.URL + "/metadata"
+	defer func() {
+		ec2MetadataURL, gceMetadataURL, azureMetadataURL = ec2, gce, azure
+	}()
+	test()
+}
+
+func TestCloudInstanceIDEC2(t *testing.T) {
+	assert := assert.New(t)
+	handler := func(imdsv2 bool) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			switch {
+			case r.Method == "PUT" && r.URL.Path == "/latest/api/token" && imdsv2:
+				assert.Equal(ec2TokenTTL, r.Header.Get("X-aws-ec2-metadata-token-ttl-seconds"))
+				w.Write([]byte("token-1234"))
+			case r.Method == "GET" && r.URL.Path == "/latest/meta-data/instance-id":
+				if imdsv2 && r.Header.Get("X-aws-ec2-metadata-token") != "token-1234" {
+					w.WriteHeader(http.StatusUnauthorized)
+					return
+				}
+				w.Write([]byte("i-0123456789abcdef0\n"))
+			default:
+				w.WriteHeader(http.StatusNotFound)
+			}
+		}
+	}
+
+	for _, imdsv2 := range []bool{true, false} {
+		withMetadataServer(t, handler(imdsv2), func() {
+			provider, id, err := getCloudInstanceID()
+			assert.NoError(err)
+			assert.Equal("ec2", provider)
+			assert.Equal("i-0123456789abcdef0", id)
+		})
+	}
+}
+
+func TestCloudInstanceIDGCEAndAzure(t *testing.T) {
+	assert := assert.New(t)
+	withMetadataServer(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/computeMetadata/v1/instance/id" && r.Header.Get("Metadata-Flavor") == "Google" {
+			w.Write([]byte("4520031799277581759"))
+			return
+		}
+		w.WriteHeader(http.StatusNotFound)
+	}, func() {
+		provider, id, err := getCloudInstanceID()
+		assert.NoError(err)
+		assert.Equal("gce", provider)
+		assert.Equal("4520031799277581759", id)
+	})
+
+	withMetadataServer(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/metadata/instance/compute/vmId" && r.Header.Get("Metadata") == "true" {
+			assert.Equal("text", r.URL.Query().Get("format"))
+			w.Write([]byte("02aab8a4-74ef-476e-8182-f6d2ba4166a6"))
+			return
+		}
+		w.WriteHeader(http.StatusNotFound)
+	}, func() {
+		provider, id, err := getCloudInstanceID()
+		assert.NoError(err)
+		assert.Equal("azure", provider)
+		assert.Equal("02aab8a4-74ef-476e-8182-f6d2ba4166a6", id)
+	})
+}
+
+func TestCloudHostnameFallback(t *testing.T) {
+	assert := assert.New(t)
+	cfg := NewDefaultAgentConfig()
+	cfg.UseCloudHostname = true
+
+	// Not in a cloud
+	withMetadataServer(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	}, func() {
+		_, ok := cloudHostname(cfg)
+		assert.False(ok)
+	})
+
+	// Slow metadata endpoints don't hold up the startup
+	done := make(chan struct{})
+	withMetadataServer(t, func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-done:
+		case <-r.Context().Done():
+		}
+	}, func() {
+		start := time.Now()
+		_, ok := cloudHostname(cfg)
+		assert.False(ok)
+		assert.True(time.Since(start) < cloudMetadataTimeout+time.Second)
+		close(done)
+	})
+
+	// Disabled
+	cfg.UseCloudHostname = false
+	withMetadataServer(t, func(w http.ResponseWriter, r *http.Request) {
+		t.Errorf("unexpected metadata query %s", r.URL)
+	}, func() {
+		_, ok := cloudHostname(cfg)
+		assert.False(ok)
+	})
+}
+
+func TestUseCloudHostname(t *testing.T) {
+	assert := assert.New(t)
+	withMetadataServer(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/computeMetadata/v1/instance/id" {
+			w.Write([]byte("4520031799277581759"))
+			return
+		}
+		w.WriteHeader(http.StatusNotFound)
+	}, func() {
+		dd, _ := ini.Load([]byte("[Main]\napi_key = apikey_12\n[process.config]\nuse_cloud_hostname = true"))
+		agentConfig, err := NewAgentConfig(&File{instance: dd, Path: "whatever"}, nil)
+		assert.NoError(err)
+		assert.True(agentConfig.UseCloudHostname)
+		assert.Equal("4520031799277581759", agentConfig.HostName)
+
+		var ddy YamlAgentConfig
+		assert.NoError(yaml.Unmarshal([]byte("api_key: apikey_20\nprocess_config:\n  use_cloud_hostname: true"), &ddy))
+		agentConfig, err = NewAgentConfig(nil, &ddy)
+		assert.NoError(err)
+		assert.Equal("4520031799277581759", agentConfig.HostName)
+	})
+}
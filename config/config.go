@@ -3,6 +3,7 @@ package config
 import (
 	"bytes"
 	"fmt"
+	"io/ioutil"
 	"net"
 	"net/http"
 	"net/url"
@@ -12,11 +13,13 @@ import (
 	"strconv"
 	"strings"
 	"time"
+	"unicode"
 
 	"github.com/DataDog/datadog-agent/pkg/util/docker"
 	ecsutil "github.com/DataDog/datadog-agent/pkg/util/ecs"
 	"github.com/DataDog/datadog-process-agent/util"
 	"github.com/DataDog/datadog-process-agent/util/container"
+	"github.com/DataDog/gopsutil/process"
 
 	log "github.com/cihub/seelog"
 	"github.com/go-ini/ini"
@@ -45,47 +48,224 @@ type WindowsConfig struct {
 	ArgsRefreshInterval int
 	// Controls getting process arguments immediately when a new process is discovered
 	AddNewArgs bool
+	// Controls reporting the names of the services run by the processes
+	CollectServices bool
+	// Refresh the arguments of recently started processes more often, sorted by age
+	ArgsRefreshAgeBuckets []ArgsRefreshBucket
+	// Memory figure reported as the RSS of the processes, the working set or the private bytes
+	MemoryMetric string
 }
 
 // AgentConfig is the global config for the process-agent. This information
 // is sourced from config files and the environment variables.
 type AgentConfig struct {
-	Enabled       bool
-	APIKey        string
-	HostName      string
-	APIEndpoint   *url.URL
-	LogFile       string
-	LogLevel      string
-	LogToConsole  bool
-	QueueSize     int
-	Blacklist     []*regexp.Regexp
-	Scrubber      *DataScrubber
-	MaxProcFDs    int
-	MaxPerMessage int
-	AllowRealTime bool
-	Transport     *http.Transport `json:"-"`
-	Logger        *LoggerConfig
-	DDAgentPy     string
-	DDAgentBin    string
-	DDAgentPyEnv  []string
-	StatsdHost    string
-	StatsdPort    int
+	Enabled         bool
+	APIKey          string
+	HostName        string
+	Tags            []string
+	APIEndpoint     *url.URL
+	CollectorPath   string // Prefix of the submission paths, e.g. when the API is mounted under a proxy path
+	LogFile         string
+	LogLevel        string
+	LogToConsole    bool
+	QueueSize       int
+	DrainTimeout    time.Duration
+	Blacklist       []*regexp.Regexp
+	BlacklistFile   *BlacklistFile `json:"-"`
+	Scrubber        *DataScrubber
+	MaxProcFDs      int
+	MaxPerMessage   int
+	MaxMessageBytes int
+	AllowRealTime   bool
+	Transport       *http.Transport `json:"-"`
+	Logger          *LoggerConfig
+	DDAgentPy       string
+	DDAgentBin      string
+	DDAgentPyEnv    []string
+	StatsdHost      string
+	StatsdPort      int
+
+	// Prepended to the names of the internal metrics, e.g. to tell apart the agents sharing a dogstatsd
+	StatsdPrefix string
+
+	// Field of the processes the blacklist patterns are matched against: cmdline, exe or name
+	BlacklistMatchField string
+	// Processes matching these are never blacklisted, from the !-prefixed blacklist patterns
+	BlacklistExceptions []*regexp.Regexp
+	// Only the processes matching these are collected in the whitelist collection mode
+	Whitelist []*regexp.Regexp
+	// Whether all the processes but the blacklisted ones are collected, or only the
+	// whitelisted ones: blacklist or whitelist
+	CollectionMode string
+
+	// The environment is only collected for the processes whose executable matches one of these
+	EnvCollectionExePatterns []*regexp.Regexp
+
+	// Use the instance ID from the cloud provider's metadata as hostname, if any
+	UseCloudHostname bool
+	// Accept localhost as hostname from the hostname providers, rejected by default as it
+	// is shared by all hosts
+	AllowLocalhostHostname bool
+
+	// Restart the agent with the new config when the config files change
+	WatchConfig bool
+
+	// Overall deadline of each submission, from connecting to reading the response body
+	SubmissionTimeout time.Duration
+
+	// Times a payload failing to be submitted is retried before being dropped
+	MaxRetries int
+	// Queued payloads older than this are dropped instead of submitted, 0 submits them whatever their age
+	MaxPayloadAge time.Duration
+	// Send a manifest with the number of batches and items of each collection group before its batches
+	BatchManifest bool
+	// Also send the API key in the DD-API-KEY header the other intake endpoints authenticate with
+	APIKeyInHeader bool
+	// Refuse to start without API key, rather than running the checks without submitting their results
+	RequireAPIKey bool
+	// Submit to the local forwarder of the infra agent listening on ForwarderAddress, e.g.
+	// localhost:5003, which takes care of the submissions to the intake
+	UseForwarder     bool
+	ForwarderAddress string
+
+	// Process attributes to collect, see CollectsProcessField. nil collects them all.
+	ProcessFields map[string]bool
+
+	// Read the IO counters of the processes, which can be denied for the processes of
+	// other users on hardened hosts.
+	CollectProcessIO bool
+
+	// Resolve the user names of the processes from their UID, false to only report the
+	// UID and GID when the lookups are slow, e.g. with LDAP backed users.
+	ResolveUserNames bool
+
+	// Maximum number of processes collected per run, 0 for no limit. The processes
+	// using the most of MaxProcessesPriority, either cpu or memory, are kept.
+	MaxProcesses         int
+	MaxProcessesPriority string
+
+	// Processes using less than both MinCPUPercent and MinMemoryBytes are not reported,
+	// unless they run in a container and IncludeContainerProcesses is set.
+	MinCPUPercent             float64
+	MinMemoryBytes            uint64
+	IncludeContainerProcesses bool
+
+	// Fraction, between 0 and 1, of the processes collected per run, a different sample on
+	// each run. The processes running in a container or using a lot of resources are always
+	// collected. The processes are sampled before max_processes caps their number.
+	ProcessSamplingRate float64
+
+	// Report the kernel threads, e.g. kworker, which are left out by default
+	CollectKernelThreads bool
 
 	// Check config
-	EnabledChecks  []string
-	CheckIntervals map[string]time.Duration
+	EnabledChecks       []string
+	CheckIntervals      map[string]time.Duration
+	CheckTimeouts       map[string]time.Duration
+	MinRealTimeInterval time.Duration
+	// Maximum random delay of the first run of each check, to spread the submissions of a fleet
+	CollectionJitter time.Duration
+
+	// Delta mode of the real-time process check, which only reports the processes
+	// that are new, exited or changed beyond the thresholds between full snapshots
+	RealTimeDelta             bool
+	RealTimeDeltaCPUThreshold float32 // in percentage points
+	RealTimeDeltaMemThreshold float64 // as a fraction of the RSS
+
+	// Locations of the host's procfs and sysfs, exported as HOST_PROC and HOST_SYS
+	HostProc string
+	HostSys  string
 
 	// Docker
-	ContainerBlacklist     []string
-	ContainerWhitelist     []string
+	ContainerBlacklist []string
+	ContainerWhitelist []string
+	ContainerFilter    *container.Filter `json:"-"` // Compiled from the blacklist and whitelist
+	// Collect the network stats of the containers. Without them the docker containers are
+	// listed with a single call to docker rather than inspected to find their networks.
 	CollectDockerNetwork   bool
 	ContainerCacheDuration time.Duration
+	// Runtime the containers are collected from, empty to detect the available ones
+	ContainerRuntime string
+	// Run the container checks, false when the containers are collected by something else
+	CollectContainers bool
+	// How long a default config without access to the containers at startup keeps retrying
+	// in the background before disabling the container checks, 0 to only try once
+	ContainerAccessGracePeriod time.Duration
+	// Container labels reported as tags, mapped to their tag key, and the maximum number of
+	// label tags per container
+	ContainerLabelTags    map[string]string
+	ContainerLabelTagsMax int
+
+	// Network
+	ConnectionsResolveDNS bool
+	// How often connections are sampled between two runs of the connections check,
+	// 0 to only collect them when the check runs
+	ConnectionsCollectionInterval time.Duration
+	// Maximum number of connections reported per run, 0 for no limit. The long-lived
+	// connections transferring the most bytes are kept along with a sample of the others.
+	MaxConnections int
+	// Report a single connection per process and remote endpoint, summing the bytes of
+	// its sockets, instead of one per socket
+	ConnectionsAggregate bool
+	// Report the name and scrubbed command line of the process of each connection
+	ConnectionsProcessName bool
+	// Report the listening TCP sockets along with the connections
+	ConnectionsListening bool
+	// Connections whose remote address, or local one too if ConnectionsExcludeLocal is set,
+	// is in one of these ranges are not reported
+	ConnectionsExcludeCIDRs []*net.IPNet
+	ConnectionsExcludeLocal bool
+	// Report the connections to loopback addresses, e.g. 127.0.0.1 or ::1
+	ConnectionsReportLoopback bool
+	// Log the diagnostics of each run of the connections check at the debug level, e.g. the
+	// connections seen by the tracer and why some were left out
+	ConnectionsDebug bool
+	// Maximum number of entries of each cache of the checks, e.g. keyed by pid, UID or
+	// address, the least recently used entries being evicted once reached
+	CacheMaxEntries int
+	// Most connections read from the network tracer, beyond which the connections that sent
+	// and received the fewest bytes are dropped. The tracer itself tracks at most
+	// DefaultConnectionsMaxTracked connections.
+	ConnectionsMaxTracked int
+
+	// Optional secondary endpoint receiving a copy of a sample of the payloads
+	MirrorEndpoint   *url.URL
+	MirrorAPIKey     string
+	MirrorSampleRate float64
+
+	// For debugging only: file the collected payloads are appended to as newline-delimited JSON
+	DebugOutputFile string
+
+	// How long the IPs of the endpoint are cached for, 0 to resolve it on every new connection
+	DNSCacheTTL time.Duration
+
+	// Compression of the submitted payloads: none, gzip or zstd
+	PayloadCompression string
+	// How the group IDs of the check runs are seeded: random or start_time
+	GroupIDSeed string
 
 	// Internal store of a proxy used for generating the Transport
 	proxy proxyFunc
+	// URL of a proxy auto-config file picking the proxy instead
+	proxyPACURL string
+	// Explicitly enable or disable HTTP/2 on the Transport, nil to let it negotiate
+	forceHTTP2 *bool
+
+	// Path of a file with additional blacklist patterns, loaded into BlacklistFile
+	blacklistPath string
+
+	// Whether APIEndpoint was set from an explicit URL, which takes precedence over the site
+	endpointOverridden bool
+
+	// Whether Enabled was set by the config rather than from the container access at startup
+	enabledSet bool
 
 	// Windows-specific config
 	Windows WindowsConfig
+
+	// Source of the final value of each setting, keyed by field name, e.g. MaxConnections: yaml.
+	// One of SourceDefault, SourceINI, SourceYAML, SourceEnv or SourceDerived.
+	ConfigSources map[string]string
 }
 
 // CheckIsEnabled returns a bool indicating if the given check name is enabled.
@@ -103,9 +283,31 @@ func (a AgentConfig) CheckInterval(checkName string) time.Duration {
 	return d
 }
 
+// CheckTimeout returns the maximum duration of a run of the given check name, 0 if it is unbounded.
+func (a AgentConfig) CheckTimeout(checkName string) time.Duration {
+	return a.CheckTimeouts[checkName]
+}
+
+// AwaitContainers returns true if the agent is only disabled as the containers couldn't be
+// accessed at startup, in which case it starts anyway and retries in the background for the
+// ContainerAccessGracePeriod before running the container checks.
+func (a AgentConfig) AwaitContainers() bool {
+	return !a.Enabled && !a.enabledSet && a.CollectContainers && a.ContainerAccessGracePeriod > 0
+}
+
 const (
 	defaultEndpoint = "https://process.datadoghq.com"
+	endpointPrefix  = "https://process."
 	maxMessageBatch = 100
+
+	defaultMaxMessageBytes = 1000000
+
+	// DefaultConnectionsMaxTracked is the number of connections the network tracer tracks
+	// by default, MinConnectionsMaxTracked and MaxConnectionsMaxTracked bound the
+	// connections_max_tracked setting to keep the kernel memory of its maps reasonable.
+	DefaultConnectionsMaxTracked = 65536
+	MinConnectionsMaxTracked     = 1024
+	MaxConnectionsMaxTracked     = 1 << 20
 )
 
 // NewDefaultAgentConfig returns an AgentConfig with defaults initialized
@@ -121,16 +323,18 @@ func NewDefaultAgentConfig() *AgentConfig {
 
 	ac := &AgentConfig{
 		// We'll always run inside of a container.
-		Enabled:       canAccessContainers,
-		APIEndpoint:   u,
-		LogFile:       defaultLogFilePath,
-		LogLevel:      "info",
-		LogToConsole:  false,
-		QueueSize:     20,
-		MaxProcFDs:    200,
-		MaxPerMessage: 100,
-		AllowRealTime: true,
-		HostName:      "",
+		Enabled:         canAccessContainers,
+		APIEndpoint:     u,
+		LogFile:         defaultLogFilePath,
+		LogLevel:        "info",
+		LogToConsole:    false,
+		QueueSize:       20,
+		DrainTimeout:    5 * time.Second,
+		MaxProcFDs:      200,
+		MaxPerMessage:   100,
+		MaxMessageBytes: defaultMaxMessageBytes,
+		AllowRealTime:   true,
+		HostName:        "",
 		Transport: &http.Transport{
 			MaxIdleConns:    5,
 			IdleConnTimeout: 90 * time.Second,
@@ -143,6 +347,40 @@ func NewDefaultAgentConfig() *AgentConfig {
 			ExpectContinueTimeout: 1 * time.Second,
 		},
 
+		// Mirror every message group once a mirror endpoint is set
+		MirrorSampleRate: 1,
+
+		CollectProcessIO:  true,
+		ResolveUserNames:  true,
+		CollectContainers: true,
+
+		ContainerLabelTagsMax: 20,
+
+		// Compress the message bodies with zstd
+		PayloadCompression: PayloadCompressionZstd,
+
+		GroupIDSeed: GroupIDSeedRandom,
+
+		// Submissions taking longer are abandoned, e.g. on a response trickling in
+		SubmissionTimeout: 30 * time.Second,
+
+		MaxRetries: 3,
+
+		RequireAPIKey: true,
+
+		BlacklistMatchField: BlacklistMatchCmdline,
+
+		CollectionMode: CollectionModeBlacklist,
+
+		CacheMaxEntries: 10000,
+
+		ConnectionsMaxTracked: DefaultConnectionsMaxTracked,
+
+		// Keep the busiest processes when max_processes is set
+		MaxProcessesPriority: ProcessFieldCPU,
+
+		ProcessSamplingRate: 1,
+
 		// Statsd for internal instrumentation
 		StatsdHost: "127.0.0.1",
 		StatsdPort: 8125,
@@ -160,6 +398,12 @@ func NewDefaultAgentConfig() *AgentConfig {
 			"rtcontainer": 2 * time.Second,
 			"connections": 10 * time.Second,
 		},
+		CheckTimeouts:       map[string]time.Duration{},
+		MinRealTimeInterval: time.Second,
+
+		// Real-time delta mode, disabled by default
+		RealTimeDeltaCPUThreshold: 1,
+		RealTimeDeltaMemThreshold: 0.05,
 
 		// Docker
 		ContainerCacheDuration: 10 * time.Second,
@@ -172,19 +416,17 @@ func NewDefaultAgentConfig() *AgentConfig {
 		Windows: WindowsConfig{
 			ArgsRefreshInterval: 15, // with default 20s check interval we refresh every 5m
 			AddNewArgs:          true,
+			CollectServices:     true,
+			MemoryMetric:        WindowsMemoryWorkingSet,
 		},
 	}
 
-	// Set default values for proc/sys paths if unset.
+	// Set default values for proc/sys paths, these are exported to the environment once the config is loaded.
 	// Don't set this is /host is not mounted to use context within container.
 	// Generally only applicable for container-only cases like Fargate.
 	if docker.IsContainerized() && util.PathExists("/host") {
-		if v := os.Getenv("HOST_PROC"); v == "" {
-			os.Setenv("HOST_PROC", "/host/proc")
-		}
-		if v := os.Getenv("HOST_SYS"); v == "" {
-			os.Setenv("HOST_SYS", "/host/sys")
-		}
+		ac.HostProc = "/host/proc"
+		ac.HostSys = "/host/sys"
 	}
 
 	if isRunningInKubernetes() {
@@ -200,9 +442,13 @@ func isRunningInKubernetes() bool {
 
 // NewAgentConfig returns an AgentConfig using a configuration file. It can be nil
 // if there is no file available. In this case we'll configure only via environment.
+// Errors are one of the types of errors.go when caused by an invalid setting. The config
+// is only usable with an *InvalidIntervalError, for which the default interval is kept.
 func NewAgentConfig(agentIni *File, agentYaml *YamlAgentConfig) (*AgentConfig, error) {
-	var err error
+	var err, intervalErr error
 	cfg := NewDefaultAgentConfig()
+	cfg.ConfigSources = defaultSources(cfg)
+	before := snapshotConfig(cfg)
 
 	var ns string
 	var section *ini.Section
@@ -212,66 +458,132 @@ func NewAgentConfig(agentIni *File, agentYaml *YamlAgentConfig) (*AgentConfig, e
 
 	// Pull from the ini Agent config by default.
 	if section != nil {
-		a, err := agentIni.Get("Main", "api_key")
-		if err != nil {
-			return nil, err
+		// All process-agent specific config lives under [process.config] section.
+		ns = "process.config"
+
+		// The api_key is only required when require_api_key isn't turned off
+		cfg.RequireAPIKey = agentIni.GetBool(ns, "require_api_key", cfg.RequireAPIKey)
+		if a, err := agentIni.Get("Main", "api_key"); err == nil {
+			cfg.APIKey = strings.Split(a, ",")[0]
+		} else if cfg.RequireAPIKey {
+			return nil, ErrMissingAPIKey
 		}
-		ak := strings.Split(a, ",")
-		cfg.APIKey = ak[0]
 		cfg.LogLevel = strings.ToLower(agentIni.GetDefault("Main", "log_level", "INFO"))
 		cfg.proxy, err = getProxySettings(section)
 		if err != nil {
 			log.Errorf("error parsing proxy settings, not using a proxy: %s", err)
 		}
 
-		v, _ := agentIni.Get("Main", "process_agent_enabled")
-		if enabled, err := isAffirmative(v); enabled {
-			cfg.Enabled = true
-			cfg.EnabledChecks = processChecks
-		} else if !enabled && err == nil { // Only want to disable the process agent if it's explicitly disabled
-			cfg.Enabled = false
+		// Only want to disable the process agent if it's explicitly disabled
+		if v, err := agentIni.Get("Main", "process_agent_enabled"); err == nil {
+			if enabled, err := parseBool(v); err != nil {
+				log.Warnf("Ignoring process_agent_enabled: %s", err)
+			} else if enabled {
+				cfg.Enabled, cfg.enabledSet = true, true
+				cfg.EnabledChecks = processChecks
+			} else {
+				cfg.Enabled, cfg.enabledSet = false, true
+			}
 		}
 
 		cfg.StatsdHost = agentIni.GetDefault("Main", "bind_host", cfg.StatsdHost)
 		// non_local_traffic is a shorthand in dd-agent configuration that is
 		// equivalent to setting `bind_host: 0.0.0.0`. Respect this flag
 		// since it defaults to true in Docker and saves us a command-line param
-		v, _ = agentIni.Get("Main", "non_local_traffic")
+		v, _ := agentIni.Get("Main", "non_local_traffic")
 		if enabled, _ := isAffirmative(v); enabled {
 			cfg.StatsdHost = "0.0.0.0"
 		}
 		cfg.StatsdPort = agentIni.GetIntDefault("Main", "dogstatsd_port", cfg.StatsdPort)
 
-		// All process-agent specific config lives under [process.config] section.
-		ns = "process.config"
+		cfg.StatsdPrefix = agentIni.GetDefault(ns, "statsd_prefix", cfg.StatsdPrefix)
 		e := agentIni.GetDefault(ns, "endpoint", defaultEndpoint)
-		u, err := url.Parse(e)
+		u, err := parseEndpoint("endpoint", e)
 		if err != nil {
-			return nil, fmt.Errorf("invalid endpoint URL: %s", err)
+			return nil, err
 		}
 		cfg.APIEndpoint = u
+		cfg.endpointOverridden = e != defaultEndpoint
+		cfg.CollectorPath = normalizeCollectorPath(agentIni.GetDefault(ns, "collector_path", cfg.CollectorPath))
 		cfg.QueueSize = agentIni.GetIntDefault(ns, "queue_size", cfg.QueueSize)
+		cfg.DrainTimeout = agentIni.GetDurationDefault(ns, "drain_timeout", time.Second, cfg.DrainTimeout)
+		cfg.SubmissionTimeout = agentIni.GetDurationDefault(ns, "submission_timeout", time.Second, cfg.SubmissionTimeout)
+		if v := agentIni.GetIntDefault(ns, "max_retries", cfg.MaxRetries); v >= 0 {
+			cfg.MaxRetries = v
+		}
+		cfg.MaxPayloadAge = agentIni.GetDurationDefault(ns, "max_payload_age", time.Second, cfg.MaxPayloadAge)
+		cfg.BatchManifest = agentIni.GetBool(ns, "batch_manifest", cfg.BatchManifest)
+		cfg.APIKeyInHeader = agentIni.GetBool(ns, "api_key_in_header", cfg.APIKeyInHeader)
+		cfg.UseForwarder = agentIni.GetBool(ns, "use_forwarder", cfg.UseForwarder)
+		cfg.ForwarderAddress = agentIni.GetDefault(ns, "forwarder_address", cfg.ForwarderAddress)
+		cfg.WatchConfig = agentIni.GetBool(ns, "watch_config", cfg.WatchConfig)
 		cfg.MaxProcFDs = agentIni.GetIntDefault(ns, "max_proc_fds", cfg.MaxProcFDs)
+		cfg.CollectProcessIO = agentIni.GetBool(ns, "collect_process_io", cfg.CollectProcessIO)
+		cfg.ResolveUserNames = agentIni.GetBool(ns, "resolve_user_names", cfg.ResolveUserNames)
+		cfg.proxyPACURL = agentIni.GetDefault(ns, "proxy_pac_url", cfg.proxyPACURL)
+		if v, err := agentIni.Get(ns, "force_http2"); err == nil {
+			if force, err := parseBool(v); err != nil {
+				log.Warnf("Ignoring force_http2: %s", err)
+			} else {
+				cfg.forceHTTP2 = &force
+			}
+		}
+		cfg.UseCloudHostname = agentIni.GetBool(ns, "use_cloud_hostname", cfg.UseCloudHostname)
+		cfg.AllowLocalhostHostname = agentIni.GetBool(ns, "allow_localhost_hostname", cfg.AllowLocalhostHostname)
 		cfg.AllowRealTime = agentIni.GetBool(ns, "allow_real_time", cfg.AllowRealTime)
 		cfg.LogFile = agentIni.GetDefault(ns, "log_file", cfg.LogFile)
+		cfg.Tags = parseTags(agentIni.GetDefault(ns, "tags", ""))
+		cfg.HostProc = agentIni.GetDefault(ns, "host_proc", cfg.HostProc)
+		cfg.HostSys = agentIni.GetDefault(ns, "host_sys", cfg.HostSys)
 		cfg.DDAgentPy = agentIni.GetDefault(ns, "dd_agent_py", cfg.DDAgentPy)
 		cfg.DDAgentPyEnv = agentIni.GetStrArrayDefault(ns, "dd_agent_py_env", ",", cfg.DDAgentPyEnv)
 
 		blacklistPats := agentIni.GetStrArrayDefault(ns, "blacklist", ",", []string{})
 		blacklist := make([]*regexp.Regexp, 0, len(blacklistPats))
+		var exceptions []*regexp.Regexp
 		for _, b := range blacklistPats {
-			r, err := regexp.Compile(b)
-			if err == nil {
+			r, exception, err := parseBlacklistPattern(b)
+			if err != nil {
+				continue
+			}
+			if exception {
+				exceptions = append(exceptions, r)
+			} else {
 				blacklist = append(blacklist, r)
 			}
 		}
 		cfg.Blacklist = blacklist
+		cfg.BlacklistExceptions = exceptions
+		cfg.blacklistPath = agentIni.GetDefault(ns, "blacklist_file", cfg.blacklistPath)
+		if f := agentIni.GetDefault(ns, "blacklist_match_field", ""); f != "" {
+			cfg.BlacklistMatchField = parseBlacklistMatchField(f)
+		}
+		if pats := agentIni.GetStrArrayDefault(ns, "whitelist", ",", nil); len(pats) > 0 {
+			cfg.Whitelist = compileWhitelist(pats)
+		}
+		if m := agentIni.GetDefault(ns, "collection_mode", ""); m != "" {
+			cfg.CollectionMode = parseCollectionMode(m)
+		}
+		if pats := agentIni.GetStrArrayDefault(ns, "env_collection_exe_patterns", ",", nil); len(pats) > 0 {
+			cfg.EnvCollectionExePatterns = compileEnvCollectionPatterns(pats)
+		}
 
 		// DataScrubber
-		cfg.Scrubber.Enabled = agentIni.GetBool(ns, "scrub_args", true)
+		if v, err := agentIni.Get(ns, "scrub_args"); err == nil {
+			if enabled, err := parseBool(v); err != nil {
+				log.Warnf("Ignoring scrub_args: %s", err)
+			} else {
+				cfg.Scrubber.Enabled = enabled
+			}
+		}
 		customSensitiveWords := agentIni.GetStrArrayDefault(ns, "custom_sensitive_words", ",", []string{})
 		cfg.Scrubber.AddCustomSensitiveWords(customSensitiveWords)
 		cfg.Scrubber.StripAllArguments = agentIni.GetBool(ns, "strip_proc_arguments", false)
+		cfg.Scrubber.HashUsernames = agentIni.GetBool(ns, "hash_usernames", false)
+		cfg.Scrubber.UsernameSalt = agentIni.GetDefault(ns, "username_hash_salt", "")
+		cfg.Scrubber.MaxCmdlineLength = agentIni.GetIntDefault(ns, "max_cmdline_length", cfg.Scrubber.MaxCmdlineLength)
+		cfg.Scrubber.EnvAllowlist = agentIni.GetStrArrayDefault(ns, "scrub_env_allowlist", ",", cfg.Scrubber.EnvAllowlist)
+		cfg.Scrubber.EnvDenylist = agentIni.GetStrArrayDefault(ns, "scrub_env_denylist", ",", cfg.Scrubber.EnvDenylist)
 
 		batchSize := agentIni.GetIntDefault(ns, "proc_limit", cfg.MaxPerMessage)
 		if batchSize <= maxMessageBatch {
@@ -280,11 +592,54 @@ func NewAgentConfig(agentIni *File, agentYaml *YamlAgentConfig) (*AgentConfig, e
 			log.Warn("Overriding the configured item count per message limit because it exceeds maximum")
 			cfg.MaxPerMessage = maxMessageBatch
 		}
+		cfg.MaxMessageBytes = agentIni.GetIntDefault(ns, "max_message_bytes", cfg.MaxMessageBytes)
+		if fields := agentIni.GetStrArrayDefault(ns, "collect_fields", ",", nil); len(fields) > 0 {
+			cfg.ProcessFields = parseProcessFields(fields)
+		}
+		cfg.MaxProcesses = agentIni.GetIntDefault(ns, "max_processes", cfg.MaxProcesses)
+		if p := agentIni.GetDefault(ns, "max_processes_priority", ""); p != "" {
+			cfg.MaxProcessesPriority = parseProcessPriority(p)
+		}
+		if pct, err := agentIni.GetFloat(ns, "min_cpu_percent"); err == nil {
+			cfg.MinCPUPercent = pct
+		}
+		cfg.MinMemoryBytes = uint64(agentIni.GetIntDefault(ns, "min_memory_bytes", int(cfg.MinMemoryBytes)))
+		cfg.IncludeContainerProcesses = agentIni.GetBool(ns, "include_container_processes", cfg.IncludeContainerProcesses)
+		if rate, err := agentIni.GetFloat(ns, "process_sampling_rate"); err == nil {
+			cfg.ProcessSamplingRate = parseProcessSamplingRate(rate, cfg.ProcessSamplingRate)
+		}
+		cfg.CollectKernelThreads = agentIni.GetBool(ns, "collect_kernel_threads", cfg.CollectKernelThreads)
+		cfg.DebugOutputFile = agentIni.GetDefault(ns, "debug_output_file", cfg.DebugOutputFile)
+
+		if c := agentIni.GetDefault(ns, "payload_compression", ""); c != "" {
+			cfg.PayloadCompression = parsePayloadCompression(c)
+		}
+		if n := agentIni.GetIntDefault(ns, "cache_max_entries", cfg.CacheMaxEntries); n > 0 {
+			cfg.CacheMaxEntries = n
+		}
+		if n := agentIni.GetIntDefault(ns, "connections_max_tracked", 0); n != 0 {
+			cfg.ConnectionsMaxTracked = parseConnectionsMaxTracked(n, cfg.ConnectionsMaxTracked)
+		}
+		if s := agentIni.GetDefault(ns, "group_id_seed", ""); s != "" {
+			cfg.GroupIDSeed = parseGroupIDSeed(s)
+		}
+
+		cfg.MinRealTimeInterval = agentIni.GetDurationDefault(ns, "min_realtime_interval", time.Second, cfg.MinRealTimeInterval)
+		cfg.CollectionJitter = agentIni.GetDurationDefault(ns, "collection_jitter", time.Second, cfg.CollectionJitter)
 
 		// Checks intervals can be overriden by configuration.
 		for checkName, defaultInterval := range cfg.CheckIntervals {
 			key := fmt.Sprintf("%s_interval", checkName)
-			interval := agentIni.GetDurationDefault(ns, key, time.Second, defaultInterval)
+			v, err := agentIni.Get(ns, key)
+			if err != nil {
+				continue
+			}
+			interval, err := agentIni.GetDuration(ns, key, time.Second)
+			if err != nil || interval < 0 {
+				log.Warnf("Ignoring %s, expected a positive number of seconds", key)
+				intervalErr = &InvalidIntervalError{Check: checkName, Value: v}
+				continue
+			}
 			if interval != defaultInterval {
 				log.Infof("Overriding check interval for %s to %s", checkName, interval)
 				cfg.CheckIntervals[checkName] = interval
@@ -295,24 +650,58 @@ func NewAgentConfig(agentIni *File, agentYaml *YamlAgentConfig) (*AgentConfig, e
 		cfg.CollectDockerNetwork = agentIni.GetBool(ns, "collect_docker_network", cfg.CollectDockerNetwork)
 		cfg.ContainerBlacklist = agentIni.GetStrArrayDefault(ns, "container_blacklist", ",", cfg.ContainerBlacklist)
 		cfg.ContainerWhitelist = agentIni.GetStrArrayDefault(ns, "container_whitelist", ",", cfg.ContainerWhitelist)
+		if labels := agentIni.GetStrArrayDefault(ns, "container_label_tags", ",", nil); len(labels) > 0 {
+			cfg.ContainerLabelTags = parseContainerLabelTags(labels)
+		}
+		cfg.ContainerLabelTagsMax = agentIni.GetIntDefault(ns, "container_label_tags_max", cfg.ContainerLabelTagsMax)
 		cfg.ContainerCacheDuration = agentIni.GetDurationDefault(ns, "container_cache_duration", time.Second, 30*time.Second)
+		cfg.ContainerRuntime = agentIni.GetDefault(ns, "container_runtime", cfg.ContainerRuntime)
+		cfg.CollectContainers = agentIni.GetBool(ns, "collect_containers", cfg.CollectContainers)
+		cfg.ContainerAccessGracePeriod = agentIni.GetDurationDefault(ns, "container_access_grace_period", time.Second, cfg.ContainerAccessGracePeriod)
 
 		// windows args config
 		cfg.Windows.ArgsRefreshInterval = agentIni.GetIntDefault(ns, "windows_args_refresh_interval", cfg.Windows.ArgsRefreshInterval)
 		cfg.Windows.AddNewArgs = agentIni.GetBool(ns, "windows_add_new_args", true)
+		cfg.Windows.CollectServices = agentIni.GetBool(ns, "windows_collect_services", cfg.Windows.CollectServices)
+		if v := agentIni.GetDefault(ns, "windows_memory_metric", ""); v != "" {
+			cfg.Windows.MemoryMetric = parseWindowsMemoryMetric(v)
+		}
+		if v := agentIni.GetDefault(ns, "windows_args_refresh_age_buckets", ""); v != "" {
+			if buckets, err := parseArgsRefreshBuckets(v); err != nil {
+				log.Warnf("Ignoring windows_args_refresh_age_buckets: %s", err)
+			} else {
+				cfg.Windows.ArgsRefreshAgeBuckets = buckets
+			}
+		}
 	}
 
+	recordSources(cfg, before, SourceINI)
+
 	// For Agents >= 6 we will have a YAML config file to use.
+	before = snapshotConfig(cfg)
 	if agentYaml != nil {
 		cfg, err = mergeYamlConfig(cfg, agentYaml)
-		if err != nil {
+		if _, ok := err.(*InvalidIntervalError); ok {
+			intervalErr = err
+		} else if err != nil {
 			return nil, err
 		}
 	}
+	recordSources(cfg, before, SourceYAML)
 
 	// Use environment to override any additional config.
+	before = snapshotConfig(cfg)
 	cfg = mergeEnvironmentVariables(cfg)
+	recordSources(cfg, before, SourceEnv)
+	before = snapshotConfig(cfg)
 
+	if cfg.blacklistPath != "" {
+		if cfg.BlacklistFile, err = NewBlacklistFile(cfg.blacklistPath); err != nil {
+			return nil, fmt.Errorf("unable to load blacklist_file: %s", err)
+		}
+	}
+
+	cfg.LogLevel = resolveLogLevel(cfg.LogLevel)
 	// Python-style log level has WARNING vs WARN
 	if strings.ToLower(cfg.LogLevel) == "warning" {
 		cfg.LogLevel = "warn"
@@ -323,6 +712,10 @@ func NewAgentConfig(agentIni *File, agentYaml *YamlAgentConfig) (*AgentConfig, e
 		return nil, err
 	}
 
+	if cfg.Scrubber.HashUsernames && cfg.Scrubber.UsernameSalt == "" {
+		log.Warn("hash_usernames is enabled without a username_hash_salt, hashed usernames can be reversed by hashing known usernames")
+	}
+
 	if cfg.HostName == "" {
 		if ecsutil.IsFargateInstance() {
 			// Fargate tasks should have no concept of host names, so we're using the task ARN.
@@ -331,14 +724,78 @@ func NewAgentConfig(agentIni *File, agentYaml *YamlAgentConfig) (*AgentConfig, e
 			} else {
 				log.Errorf("Failed to retrieve Fargate task metadata: %s", err)
 			}
-		} else if hostname, err := getHostname(cfg.DDAgentPy, cfg.DDAgentBin, cfg.DDAgentPyEnv); err == nil {
-			cfg.HostName = hostname
+		} else {
+			cfg.HostName = resolveHostname(cfg)
+		}
+	}
+
+	// Tag the submissions from an ECS container instance with the task of the agent
+	if !ecsutil.IsFargateInstance() {
+		cfg.Tags = append(cfg.Tags, ecsTags()...)
+	}
+
+	if !cfg.CollectContainers {
+		cfg.EnabledChecks = withoutChecks(cfg.EnabledChecks, containerChecks)
+		if len(cfg.EnabledChecks) == 0 {
+			log.Info("collect_containers is disabled and the process checks aren't enabled, no check left to run")
+			cfg.Enabled, cfg.enabledSet = false, true
+		}
+	}
+
+	// Collecting nothing is most likely a mistake
+	if cfg.CollectionMode == CollectionModeWhitelist && len(cfg.Whitelist) == 0 {
+		log.Warnf("collection_mode is %s but there are no whitelist patterns, collecting all the processes but the blacklisted ones", CollectionModeWhitelist)
+		cfg.CollectionMode = CollectionModeBlacklist
+	}
+
+	if len(cfg.ContainerBlacklist) > 0 {
+		cfg.ContainerFilter = container.NewFilter(cfg.ContainerBlacklist, cfg.ContainerWhitelist)
+	}
+
+	container.SetCollectNetwork(cfg.CollectDockerNetwork)
+	if cfg.ContainerRuntime != "" {
+		if err := container.SetContainerRuntime(cfg.ContainerRuntime); err != nil {
+			log.Warnf("Ignoring container_runtime: %s", err)
 		}
 	}
 
+	if cfg.proxyPACURL != "" {
+		cfg.proxy = newPACProxy(cfg.proxyPACURL)
+	}
 	if cfg.proxy != nil {
 		cfg.Transport.Proxy = cfg.proxy
 	}
+	if cfg.DNSCacheTTL > 0 {
+		cfg.Transport.DialContext = newCachingDialer(transportDialer(cfg.Transport), cfg.DNSCacheTTL).DialContext
+		cfg.Transport.Dial = nil
+	}
+	if cfg.forceHTTP2 != nil {
+		if err := configureHTTP2(cfg.Transport, *cfg.forceHTTP2); err != nil {
+			log.Warnf("Ignoring force_http2: %s", err)
+		}
+	}
+	if cfg.UseForwarder {
+		if err := cfg.useForwarder(); err != nil {
+			log.Warnf("Ignoring use_forwarder: %s", err)
+			cfg.UseForwarder = false
+		}
+	}
+
+	// gopsutil and our own utilities read the proc/sys locations from the environment.
+	if cfg.HostProc != "" {
+		os.Setenv("HOST_PROC", cfg.HostProc)
+	}
+	if cfg.HostSys != "" {
+		os.Setenv("HOST_SYS", cfg.HostSys)
+	}
+
+	// Bound the rate of the real-time checks to avoid CPU spikes from very low intervals.
+	for _, checkName := range []string{"rtprocess", "rtcontainer"} {
+		if interval := cfg.CheckIntervals[checkName]; interval < cfg.MinRealTimeInterval {
+			log.Warnf("%s interval of %s is below the minimum, using %s", checkName, interval, cfg.MinRealTimeInterval)
+			cfg.CheckIntervals[checkName] = cfg.MinRealTimeInterval
+		}
+	}
 
 	// sanity check. This element is used with the modulo operator (%), so it can't be zero.
 	// if it is, log the error, and assume the config was attempting to disable
@@ -347,31 +804,44 @@ func NewAgentConfig(agentIni *File, agentYaml *YamlAgentConfig) (*AgentConfig, e
 		cfg.Windows.ArgsRefreshInterval = -1
 	}
 
-	return cfg, nil
+	recordSources(cfg, before, SourceDerived)
+	return cfg, intervalErr
 }
 
 // mergeEnvironmentVariables applies overrides from environment variables to the process agent configuration
 func mergeEnvironmentVariables(c *AgentConfig) *AgentConfig {
 	var err error
-	if enabled, err := isAffirmative(os.Getenv("DD_PROCESS_AGENT_ENABLED")); enabled {
-		c.Enabled = true
-		c.EnabledChecks = processChecks
-	} else if !enabled && err == nil {
-		c.Enabled = false
+	if v := getEnv("DD_PROCESS_AGENT_ENABLED"); v != "" {
+		if enabled, err := parseBool(v); err != nil {
+			log.Warnf("Ignoring DD_PROCESS_AGENT_ENABLED: %s", err)
+		} else if enabled {
+			c.Enabled, c.enabledSet = true, true
+			c.EnabledChecks = processChecks
+		} else {
+			c.Enabled, c.enabledSet = false, true
+		}
 	}
 
-	if v := os.Getenv("DD_HOSTNAME"); v != "" {
+	if v := getEnv("DD_HOSTNAME"); v != "" {
 		log.Info("overriding hostname from env DD_HOSTNAME value")
 		c.HostName = v
 	}
 
+	// Support the shared DD_TAGS but prefer DD_PROCESS_AGENT_TAGS
+	if v := getEnv("DD_TAGS"); v != "" {
+		c.Tags = parseTags(v)
+	}
+	if v := getEnv("DD_PROCESS_AGENT_TAGS"); v != "" {
+		c.Tags = parseTags(v)
+	}
+
 	// Support API_KEY and DD_API_KEY but prefer DD_API_KEY.
 	var apiKey string
-	if v := os.Getenv("API_KEY"); v != "" {
+	if v := getEnv("API_KEY"); v != "" {
 		apiKey = v
 		log.Info("overriding API key from env API_KEY value")
 	}
-	if v := os.Getenv("DD_API_KEY"); v != "" {
+	if v := getEnv("DD_API_KEY"); v != "" {
 		apiKey = v
 		log.Info("overriding API key from env DD_API_KEY value")
 	}
@@ -384,16 +854,16 @@ func mergeEnvironmentVariables(c *AgentConfig) *AgentConfig {
 	}
 
 	// Support LOG_LEVEL and DD_LOG_LEVEL but prefer DD_LOG_LEVEL
-	if v := os.Getenv("LOG_LEVEL"); v != "" {
+	if v := getEnv("LOG_LEVEL"); v != "" {
 		c.LogLevel = v
 	}
-	if v := os.Getenv("DD_LOG_LEVEL"); v != "" {
+	if v := getEnv("DD_LOG_LEVEL"); v != "" {
 		c.LogLevel = v
 	}
-	if enabled, err := isAffirmative(os.Getenv("DD_LOGS_STDOUT")); err == nil {
+	if enabled, err := isAffirmative(getEnv("DD_LOGS_STDOUT")); err == nil {
 		c.LogToConsole = enabled
 	}
-	if enabled, err := isAffirmative(os.Getenv("LOG_TO_CONSOLE")); err == nil {
+	if enabled, err := isAffirmative(getEnv("LOG_TO_CONSOLE")); err == nil {
 		c.LogToConsole = enabled
 	}
 
@@ -402,38 +872,58 @@ func mergeEnvironmentVariables(c *AgentConfig) *AgentConfig {
 		c.proxy = nil
 	}
 
-	if v := os.Getenv("DD_PROCESS_AGENT_URL"); v != "" {
+	if v := getEnv("DD_PROCESS_AGENT_URL"); v != "" {
 		u, err := url.Parse(v)
 		if err != nil {
 			log.Warnf("DD_PROCESS_AGENT_URL is invalid: %s", err)
 		} else {
 			log.Infof("overriding API endpoint from env")
 			c.APIEndpoint = u
+			c.endpointOverridden = true
+		}
+	}
+	// DD_SITE only applies when no explicit endpoint URL was given
+	if v := getEnv("DD_SITE"); v != "" && !c.endpointOverridden {
+		u, err := siteEndpoint(v)
+		if err != nil {
+			log.Warnf("DD_SITE is invalid: %s", err)
+		} else {
+			log.Infof("overriding API endpoint from env DD_SITE value")
+			c.APIEndpoint = u
 		}
 	}
 
 	// Process Arguments Scrubbing
-	if enabled, err := isAffirmative(os.Getenv("DD_SCRUB_ARGS")); enabled {
-		c.Scrubber.Enabled = true
-	} else if !enabled && err == nil {
-		c.Scrubber.Enabled = false
+	if v := getEnv("DD_SCRUB_ARGS"); v != "" {
+		if enabled, err := parseBool(v); err != nil {
+			log.Warnf("Ignoring DD_SCRUB_ARGS: %s", err)
+		} else {
+			c.Scrubber.Enabled = enabled
+		}
 	}
 
-	if v := os.Getenv("DD_CUSTOM_SENSITIVE_WORDS"); v != "" {
+	if v := getEnv("DD_CUSTOM_SENSITIVE_WORDS"); v != "" {
 		c.Scrubber.AddCustomSensitiveWords(strings.Split(v, ","))
 	}
-	if ok, _ := isAffirmative(os.Getenv("DD_STRIP_PROCESS_ARGS")); ok {
+	if ok, _ := isAffirmative(getEnv("DD_STRIP_PROCESS_ARGS")); ok {
 		c.Scrubber.StripAllArguments = true
 	}
 
-	if v := os.Getenv("DD_AGENT_PY"); v != "" {
+	if ok, err := isAffirmative(getEnv("DD_PROCESS_AGENT_ALLOW_REAL_TIME")); err == nil {
+		c.AllowRealTime = ok
+	}
+	if ok, err := isAffirmative(getEnv("DD_CONNECTIONS_DEBUG")); err == nil {
+		c.ConnectionsDebug = ok
+	}
+
+	if v := getEnv("DD_AGENT_PY"); v != "" {
 		c.DDAgentPy = v
 	}
-	if v := os.Getenv("DD_AGENT_PY_ENV"); v != "" {
+	if v := getEnv("DD_AGENT_PY_ENV"); v != "" {
 		c.DDAgentPyEnv = strings.Split(v, ",")
 	}
 
-	if v := os.Getenv("DD_DOGSTATSD_PORT"); v != "" {
+	if v := getEnv("DD_DOGSTATSD_PORT"); v != "" {
 		port, err := strconv.Atoi(v)
 		if err != nil {
 			log.Info("Failed to parse DD_DOGSTATSD_PORT: it should be a port number")
@@ -442,50 +932,218 @@ func mergeEnvironmentVariables(c *AgentConfig) *AgentConfig {
 		}
 	}
 
-	if v := os.Getenv("DD_BIND_HOST"); v != "" {
+	if v := getEnv("DD_BIND_HOST"); v != "" {
 		c.StatsdHost = v
 	}
 
+	// Respect proc/sys locations that were already set in the environment
+	if v := getEnv("HOST_PROC"); v != "" {
+		c.HostProc = v
+	}
+	if v := getEnv("HOST_SYS"); v != "" {
+		c.HostSys = v
+	}
+
 	// Docker config
-	if v := os.Getenv("DD_COLLECT_DOCKER_NETWORK"); v == "false" {
+	if v := getEnv("DD_COLLECT_DOCKER_NETWORK"); v == "false" {
 		c.CollectDockerNetwork = false
 	}
-	if v := os.Getenv("DD_CONTAINER_BLACKLIST"); v != "" {
+	if v := getEnv("DD_CONTAINER_BLACKLIST"); v != "" {
 		c.ContainerBlacklist = strings.Split(v, ",")
 	}
-	if v := os.Getenv("DD_CONTAINER_WHITELIST"); v != "" {
+	if v := getEnv("DD_CONTAINER_WHITELIST"); v != "" {
 		c.ContainerWhitelist = strings.Split(v, ",")
 	}
-	if v := os.Getenv("DD_CONTAINER_CACHE_DURATION"); v != "" {
+	if v := getEnv("DD_CONTAINER_CACHE_DURATION"); v != "" {
 		durationS, _ := strconv.Atoi(v)
 		c.ContainerCacheDuration = time.Duration(durationS) * time.Second
 	}
 
 	// Note: this feature is in development and should not be used in production environments
-	if ok, _ := isAffirmative(os.Getenv("DD_CONNECTIONS_CHECK")); ok {
+	if ok, _ := isAffirmative(getEnv("DD_CONNECTIONS_CHECK")); ok {
 		c.EnabledChecks = append(c.EnabledChecks, "connections")
 	}
+	// An explicit list of checks replaces the process/container grouping
+	if v := getEnv("DD_PROCESS_AGENT_ENABLED_CHECKS"); v != "" {
+		if checks := c.validChecks(strings.Split(v, ",")); len(checks) > 0 {
+			log.Infof("overriding enabled checks from env DD_PROCESS_AGENT_ENABLED_CHECKS value")
+			c.EnabledChecks = checks
+		} else {
+			log.Warnf("Ignoring DD_PROCESS_AGENT_ENABLED_CHECKS, no known check in '%s'", v)
+		}
+	}
+	if ok, err := isAffirmative(getEnv("DD_CONNECTIONS_RESOLVE_DNS")); err == nil {
+		c.ConnectionsResolveDNS = ok
+	}
 
 	return c
 }
 
+// withoutChecks returns the checks that aren't in removed.
+func withoutChecks(checks, removed []string) []string {
+	kept := make([]string, 0, len(checks))
+	for _, c := range checks {
+		if !util.StringInSlice(removed, c) {
+			kept = append(kept, c)
+		}
+	}
+	return kept
+}
+
+// getEnv returns the value of the environment variable without its surrounding whitespace,
+// e.g. the trailing newline of a templated value.
+func getEnv(key string) string {
+	return strings.TrimSpace(os.Getenv(key))
+}
+
 // IsBlacklisted returns a boolean indicating if the given command is blacklisted by our config.
-func IsBlacklisted(cmdline []string, blacklist []*regexp.Regexp) bool {
+// Commands matching one of the exceptions are never blacklisted.
+func IsBlacklisted(cmdline []string, blacklist, exceptions []*regexp.Regexp) bool {
 	cmd := strings.Join(cmdline, " ")
-	for _, b := range blacklist {
-		if b.MatchString(cmd) {
+	return !matchesAny(cmd, exceptions) && matchesAny(cmd, blacklist)
+}
+
+func matchesAny(s string, patterns []*regexp.Regexp) bool {
+	for _, p := range patterns {
+		if p.MatchString(s) {
 			return true
 		}
 	}
 	return false
 }
 
+// parseContainerLabelTags parses the container labels to report as tags, as label:tag_key
+// entries, e.g. app.kubernetes.io/version:version. The tag key defaults to the label.
+func parseContainerLabelTags(entries []string) map[string]string {
+	labelTags := make(map[string]string, len(entries))
+	for _, e := range entries {
+		e = strings.TrimSpace(e)
+		if e == "" {
+			continue
+		}
+		label, tagKey := e, e
+		if i := strings.LastIndex(e, ":"); i >= 0 {
+			label, tagKey = strings.TrimSpace(e[:i]), strings.TrimSpace(e[i+1:])
+		}
+		if label == "" || tagKey == "" {
+			log.Warnf("Ignoring invalid container_label_tags entry '%s', expected label:tag_key", e)
+			continue
+		}
+		labelTags[label] = tagKey
+	}
+	return labelTags
+}
+
+// parseConnectionsMaxTracked returns the number of connections the network tracer is
+// sized for, or fallback if it is out of bounds.
+func parseConnectionsMaxTracked(n, fallback int) int {
+	if n < MinConnectionsMaxTracked || n > MaxConnectionsMaxTracked {
+		log.Warnf("Ignoring invalid connections_max_tracked %d, it must be between %d and %d",
+			n, MinConnectionsMaxTracked, MaxConnectionsMaxTracked)
+		return fallback
+	}
+	return n
+}
+
+// parseCIDRs parses the address ranges of the given setting, e.g. 10.0.0.0/8 or fd00::/8,
+// leaving out the invalid ones.
+func parseCIDRs(setting string, entries []string) []*net.IPNet {
+	var nets []*net.IPNet
+	for _, e := range entries {
+		e = strings.TrimSpace(e)
+		if e == "" {
+			continue
+		}
+		_, n, err := net.ParseCIDR(e)
+		if err != nil {
+			log.Warnf("Ignoring invalid %s entry '%s': %s", setting, e, err)
+			continue
+		}
+		nets = append(nets, n)
+	}
+	return nets
+}
+
+// parseTags splits a list of tags separated by commas or whitespace, e.g. "env:prod,role:db"
+func parseTags(value string) []string {
+	return strings.FieldsFunc(value, func(r rune) bool {
+		return r == ',' || unicode.IsSpace(r)
+	})
+}
+
+// normalizeCollectorPath returns the prefix of the submission paths with a leading and no
+// trailing slash, e.g. /intake/process, or "" for no prefix.
+func normalizeCollectorPath(path string) string {
+	path = strings.Trim(strings.TrimSpace(path), "/")
+	if path == "" {
+		return ""
+	}
+	return "/" + path
+}
+
+// siteEndpoint returns the process intake endpoint for the given Datadog site, e.g. datadoghq.eu
+func siteEndpoint(site string) (*url.URL, error) {
+	site = strings.TrimSpace(site)
+	if site == "" {
+		return nil, fmt.Errorf("site is empty")
+	}
+	return url.Parse(endpointPrefix + site)
+}
+
+// validChecks returns the known check names of names, in order and without duplicates.
+// Every check has a default interval, so CheckIntervals lists all the known checks.
+func (a *AgentConfig) validChecks(names []string) []string {
+	checks := make([]string, 0, len(names))
+	for _, name := range names {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "" || util.StringInSlice(checks, name) {
+			continue
+		}
+		if _, ok := a.CheckIntervals[name]; !ok {
+			log.Warnf("Ignoring unknown check '%s'", name)
+			continue
+		}
+		checks = append(checks, name)
+	}
+	return checks
+}
+
+// IsProcessBlacklisted returns a boolean indicating if the given process matches either
+// the inline blacklist patterns or the ones loaded from the blacklist file, and none of
+// the exceptions of either. The patterns are matched against the field of the process
+// set by BlacklistMatchField.
+func (a *AgentConfig) IsProcessBlacklisted(fp *process.FilledProcess) bool {
+	value := strings.Join(blacklistMatchValue(a.BlacklistMatchField, fp), " ")
+	if matchesAny(value, a.BlacklistExceptions) || (a.BlacklistFile != nil && matchesAny(value, a.BlacklistFile.Exceptions())) {
+		return false
+	}
+	return matchesAny(value, a.Blacklist) || (a.BlacklistFile != nil && matchesAny(value, a.BlacklistFile.Patterns()))
+}
+
+// isAffirmative returns whether value turns a setting on. Values other than the
+// affirmative ones turn it off, the error is only for empty (unset) values.
 func isAffirmative(value string) (bool, error) {
+	value = strings.TrimSpace(value)
 	if value == "" {
 		return false, fmt.Errorf("value is empty")
 	}
-	v := strings.ToLower(value)
-	return v == "true" || v == "yes" || v == "1", nil
+	// Unknown values are kept false for backwards compatibility
+	enabled, _ := parseBool(value)
+	return enabled, nil
+}
+
+// parseBool is the strict version of isAffirmative, failing on values that are neither
+// true nor false so that typos in critical toggles are reported instead of turning them off.
+func parseBool(value string) (bool, error) {
+	switch strings.ToLower(strings.TrimSpace(value)) {
+	case "true", "yes", "1", "on", "enabled":
+		return true, nil
+	case "false", "no", "0", "off", "disabled":
+		return false, nil
+	case "":
+		return false, fmt.Errorf("value is empty")
+	}
+	return false, fmt.Errorf("'%s' is not a boolean, expected true or false", value)
 }
 
 // getHostname shells out to obtain the hostname used by the infra agent
@@ -558,6 +1216,12 @@ func getProxySettings(m *ini.Section) (proxyFunc, error) {
 	if v := m.Key("proxy_password").MustString(""); v != "" {
 		password = v
 	}
+	if v := m.Key("proxy_password_file").MustString(""); v != "" {
+		var err error
+		if password, err = readProxyPassword(v); err != nil {
+			return nil, fmt.Errorf("invalid proxy_password_file: %s", err)
+		}
+	}
 	return constructProxy(host, scheme, port, user, password)
 }
 
@@ -594,10 +1258,30 @@ func proxyFromEnv(defaultVal proxyFunc) (proxyFunc, error) {
 	if v := os.Getenv("PROXY_PASSWORD"); v != "" {
 		password = v
 	}
+	if v := os.Getenv("PROXY_PASSWORD_FILE"); v != "" {
+		var err error
+		if password, err = readProxyPassword(v); err != nil {
+			return nil, fmt.Errorf("invalid PROXY_PASSWORD_FILE: %s", err)
+		}
+	}
 
 	return constructProxy(host, scheme, port, user, password)
 }
 
+// readProxyPassword reads the proxy password from a file holding only the password,
+// keeping it out of the config. A trailing newline is ignored.
+func readProxyPassword(path string) (string, error) {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("unable to read the proxy password: %s", err)
+	}
+	password := strings.TrimRight(string(b), "\r\n")
+	if password == "" {
+		return "", fmt.Errorf("the proxy password file %s is empty", path)
+	}
+	return password, nil
+}
+
 // constructProxy constructs a *url.Url for a proxy given the parts of a
 // Note that we assume we have at least a non-empty host for this call but
 // all other values can be their defaults (empty string or 0).
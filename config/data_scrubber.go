@@ -2,10 +2,14 @@ package config
 
 import (
 	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
 	"regexp"
 	"strconv"
 	"strings"
+	"unicode/utf8"
 
 	"github.com/DataDog/gopsutil/process"
 	log "github.com/cihub/seelog"
@@ -21,6 +25,14 @@ var (
 
 const (
 	defaultCacheMaxCycles = 25
+	// defaultMaxCmdlineLength only truncates the most unusual command lines
+	defaultMaxCmdlineLength = 32768
+	// maskedValue replaces the sensitive argument values
+	maskedValue = "********"
+	// cmdlineEllipsis marks a truncated command line
+	cmdlineEllipsis = "..."
+	// hashedUsernameLength is the number of hex characters kept from a username hash
+	hashedUsernameLength = 32
 )
 
 // DataScrubber allows the agent to blacklist cmdline arguments that match
@@ -33,6 +45,20 @@ type DataScrubber struct {
 	scrubbedCmdlines  map[string][]string
 	cacheCycles       uint32 // used to control the cache age
 	cacheMaxCycles    uint32 // number of cycles before resetting the cache content
+
+	// HashUsernames replaces process usernames with a salted hash of the name
+	HashUsernames bool
+	UsernameSalt  string
+
+	// MaxCmdlineLength is the maximum length of the command line joined by spaces, 0 for no limit
+	MaxCmdlineLength int
+
+	// Names of the environment variables whose values ScrubEnv always keeps, or always masks,
+	// regardless of the sensitive words
+	EnvAllowlist []string
+	EnvDenylist  []string
+	// Sensitive words matched anywhere in the names of the environment variables
+	sensitiveEnvKeys []*regexp.Regexp
 }
 
 // NewDefaultDataScrubber creates a DataScrubber with the default behavior: enabled
@@ -41,10 +67,12 @@ func NewDefaultDataScrubber() *DataScrubber {
 	newDataScrubber := &DataScrubber{
 		Enabled:           true,
 		SensitivePatterns: compileStringsToRegex(defaultSensitiveWords),
+		sensitiveEnvKeys:  compileEnvKeyPatterns(defaultSensitiveWords),
 		seenProcess:       make(map[string]struct{}),
 		scrubbedCmdlines:  make(map[string][]string),
 		cacheCycles:       0,
 		cacheMaxCycles:    defaultCacheMaxCycles,
+		MaxCmdlineLength:  defaultMaxCmdlineLength,
 	}
 
 	return newDataScrubber
@@ -105,6 +133,23 @@ func compileStringsToRegex(words []string) []*regexp.Regexp {
 	return compiledRegexps
 }
 
+// compileEnvKeyPatterns compiles the sensitive words into case-insensitive patterns matching
+// them anywhere in the name of an environment variable, e.g. password in DB_PASSWORD. Invalid
+// words are skipped, compileStringsToRegex already warns about them.
+func compileEnvKeyPatterns(words []string) []*regexp.Regexp {
+	patterns := make([]*regexp.Regexp, 0, len(words))
+	forbiddenSymbols := regexp.MustCompile("[^a-zA-Z0-9_*]")
+	for _, word := range words {
+		if word == "" || word == "*" || forbiddenSymbols.MatchString(word) || strings.Contains(word, "**") {
+			continue
+		}
+		if r, err := regexp.Compile("(?i)" + strings.Replace(word, "*", ".*", -1)); err == nil {
+			patterns = append(patterns, r)
+		}
+	}
+	return patterns
+}
+
 // createProcessKey returns an unique identifier for a given process
 func createProcessKey(p *process.FilledProcess) string {
 	var b bytes.Buffer
@@ -141,6 +186,116 @@ func (ds *DataScrubber) ScrubProcessCommand(p *process.FilledProcess) []string {
 	return p.Cmdline
 }
 
+// ScrubEnv returns a copy of the environment variables with the values of the sensitive ones
+// masked: those whose name contains a sensitive word or is in EnvDenylist, unless it is in
+// EnvAllowlist.
+func (ds *DataScrubber) ScrubEnv(env map[string]string) map[string]string {
+	if !ds.Enabled || len(env) == 0 {
+		return env
+	}
+	scrubbed := make(map[string]string, len(env))
+	for k, v := range env {
+		if ds.isSensitiveEnvKey(k) {
+			v = maskedValue
+		}
+		scrubbed[k] = v
+	}
+	return scrubbed
+}
+
+func (ds *DataScrubber) isSensitiveEnvKey(key string) bool {
+	for _, k := range ds.EnvAllowlist {
+		if strings.EqualFold(k, key) {
+			return false
+		}
+	}
+	for _, k := range ds.EnvDenylist {
+		if strings.EqualFold(k, key) {
+			return true
+		}
+	}
+	for _, pattern := range ds.sensitiveEnvKeys {
+		if pattern.MatchString(key) {
+			return true
+		}
+	}
+	return false
+}
+
+// ScrubUsername returns a stable salted hash of the username if username hashing
+// is enabled, the username as is otherwise
+func (ds *DataScrubber) ScrubUsername(name string) string {
+	if !ds.HashUsernames || name == "" {
+		return name
+	}
+	mac := hmac.New(sha256.New, []byte(ds.UsernameSalt))
+	mac.Write([]byte(name))
+	return hex.EncodeToString(mac.Sum(nil))[:hashedUsernameLength]
+}
+
+// TruncateCommand truncates the command line so that its arguments joined by spaces
+// are at most MaxCmdlineLength long, ellipsis included. The cut never falls within a
+// masked value, which would make it look like a shorter or partial password.
+func (ds *DataScrubber) TruncateCommand(cmdline []string) []string {
+	maxLen := ds.MaxCmdlineLength
+	length := len(cmdline) - 1 // The separating spaces
+	for _, arg := range cmdline {
+		length += len(arg)
+	}
+	if maxLen <= 0 || length <= maxLen {
+		return cmdline
+	}
+
+	budget := maxLen - len(cmdlineEllipsis)
+	truncated := make([]string, 0, len(cmdline))
+	used := 0
+	for _, arg := range cmdline {
+		sep := 0
+		if len(truncated) > 0 {
+			sep = 1
+		}
+		if used+sep+len(arg) <= budget {
+			truncated = append(truncated, arg)
+			used += sep + len(arg)
+			continue
+		}
+		if cut := truncateArg(arg, budget-used-sep); cut != "" {
+			truncated = append(truncated, cut)
+		}
+		break
+	}
+
+	if len(truncated) == 0 {
+		return []string{cmdlineEllipsis}
+	}
+	truncated[len(truncated)-1] += cmdlineEllipsis
+	return truncated
+}
+
+// truncateArg returns the first n bytes of arg at most, backing off to the start of a
+// masked value or a UTF-8 character that would otherwise be cut.
+func truncateArg(arg string, n int) string {
+	if n <= 0 {
+		return ""
+	}
+	for i := 0; i < n; {
+		j := strings.Index(arg[i:], maskedValue)
+		if j == -1 || i+j >= n {
+			break
+		}
+		start, end := i+j, i+j+len(maskedValue)
+		if end > n {
+			n = start
+			break
+		}
+		i = end
+	}
+	for n > 0 && !utf8.RuneStart(arg[n]) {
+		n--
+	}
+	return arg[:n]
+}
+
 // IncrementCacheAge increments one cycle of cache memory age. If it reaches
 // cacheMaxCycles, the cache is restarted
 func (ds *DataScrubber) IncrementCacheAge() {
@@ -161,7 +316,7 @@ func (ds *DataScrubber) scrubCommand(cmdline []string) ([]string, bool) {
 	for _, pattern := range ds.SensitivePatterns {
 		if pattern.MatchString(rawCmdline) {
 			changed = true
-			rawCmdline = pattern.ReplaceAllString(rawCmdline, "${key}${delimiter}********")
+			rawCmdline = pattern.ReplaceAllString(rawCmdline, "${key}${delimiter}"+maskedValue)
 		}
 	}
 
@@ -185,4 +340,5 @@ func (ds *DataScrubber) stripArguments(cmdline []string) []string {
 func (ds *DataScrubber) AddCustomSensitiveWords(words []string) {
 	newPatterns := compileStringsToRegex(words)
 	ds.SensitivePatterns = append(ds.SensitivePatterns, newPatterns...)
+	ds.sensitiveEnvKeys = append(ds.sensitiveEnvKeys, compileEnvKeyPatterns(words)...)
 }
@@ -2,6 +2,7 @@ package config
 
 import (
 	"flag"
+	"strings"
 	"testing"
 	"time"
 
@@ -470,3 +471,103 @@ func benchmarkWithoutCache(b *testing.B) {
 	}
 	avoidOptimization = r
 }
+
+func TestScrubUsername(t *testing.T) {
+	assert := assert.New(t)
+	scrubber := NewDefaultDataScrubber()
+
+	// Usernames are left as is by default
+	assert.Equal("root", scrubber.ScrubUsername("root"))
+
+	scrubber.HashUsernames = true
+	scrubber.UsernameSalt = "salt"
+	hashed := scrubber.ScrubUsername("root")
+	assert.NotEqual("root", hashed)
+	assert.Len(hashed, hashedUsernameLength)
+	assert.Equal(hashed, scrubber.ScrubUsername("root"))
+	assert.NotEqual(hashed, scrubber.ScrubUsername("postgres"))
+	assert.Equal("", scrubber.ScrubUsername(""))
+
+	// The salt changes the hash
+	scrubber.UsernameSalt = "pepper"
+	assert.NotEqual(hashed, scrubber.ScrubUsername("root"))
+}
+
+func TestScrubEnv(t *testing.T) {
+	assert := assert.New(t)
+	scrubber := NewDefaultDataScrubber()
+	scrubber.AddCustomSensitiveWords([]string{"consul_token", "*_cert"})
+	env := map[string]string{
+		"API_KEY":          "0123456789abcdef",
+		"DB_PASSWORD":      "hunter2",
+		"aws_secret_key":   "wJalrXUtnFEMI",
+		"CONSUL_TOKEN":     "b1gs33cr3t",
+		"CLIENT_CERT":      "-----BEGIN CERTIFICATE-----",
+		"PATH":             "/usr/local/bin:/usr/bin",
+		"SECRET_SCANNING":  "enabled",
+		"REDIS_URL":        "redis://:hunter2@cache:6379",
+		"DD_APM_ENABLED":   "true",
+		"PGPASSFILE":       "/root/.pgpass",
+		"MYSQL_PWD":        "root",
+		"VAULT_ADDR":       "https://vault:8200",
+		"SESSION_NONSENSE": "",
+	}
+
+	scrubbed := scrubber.ScrubEnv(env)
+	for _, k := range []string{"API_KEY", "DB_PASSWORD", "aws_secret_key", "CONSUL_TOKEN", "CLIENT_CERT", "SECRET_SCANNING", "MYSQL_PWD"} {
+		assert.Equal(maskedValue, scrubbed[k], k)
+	}
+	for _, k := range []string{"PATH", "REDIS_URL", "DD_APM_ENABLED", "PGPASSFILE", "VAULT_ADDR", "SESSION_NONSENSE"} {
+		assert.Equal(env[k], scrubbed[k], k)
+	}
+	// The original environment is left as is
+	assert.Equal("hunter2", env["DB_PASSWORD"])
+
+	scrubber.EnvAllowlist = []string{"secret_scanning"}
+	scrubber.EnvDenylist = []string{"REDIS_URL"}
+	scrubbed = scrubber.ScrubEnv(env)
+	assert.Equal("enabled", scrubbed["SECRET_SCANNING"])
+	assert.Equal(maskedValue, scrubbed["REDIS_URL"])
+	assert.Equal(maskedValue, scrubbed["API_KEY"])
+
+	scrubber.Enabled = false
+	assert.Equal(env, scrubber.ScrubEnv(env))
+}
+
+func TestTruncateCommand(t *testing.T) {
+	scrubber := NewDefaultDataScrubber()
+	assert.Equal(t, defaultMaxCmdlineLength, scrubber.MaxCmdlineLength)
+
+	for _, tc := range []struct {
+		maxLen   int
+		cmdline  []string
+		expected []string
+	}{
+		// Short enough or no limit
+		{20, []string{"java", "-jar", "app.jar"}, []string{"java", "-jar", "app.jar"}},
+		{17, []string{"java", "-jar", "app.jar"}, []string{"java", "-jar", "app.jar"}},
+		{0, []string{"java", "-cp", "a.jar:b.jar:c.jar"}, []string{"java", "-cp", "a.jar:b.jar:c.jar"}},
+		// Cut within an argument
+		{15, []string{"java", "-cp", "a.jar:b.jar:c.jar"}, []string{"java", "-cp", "a.j..."}},
+		// Cut right after an argument or its separator
+		{11, []string{"java", "-cp", "a.jar:b.jar"}, []string{"java", "-cp..."}},
+		{10, []string{"java", "-cp", "a.jar:b.jar"}, []string{"java", "-c..."}},
+		// Too short for anything but the ellipsis
+		{4, []string{"java", "-cp", "a.jar"}, []string{"j..."}},
+		{3, []string{"java", "-cp", "a.jar"}, []string{"..."}},
+		// Masked values are never cut
+		{20, []string{"app", "--password=********", "--verbose"}, []string{"app", "--password=..."}},
+		{27, []string{"app", "--password=********", "--verbose"}, []string{"app", "--password=********..."}},
+		{26, []string{"app", "--password=********"}, []string{"app", "--password=********"}},
+		{18, []string{"app", "-p=********,********"}, []string{"app", "-p=********..."}},
+		// Multi-byte characters are never cut
+		{10, []string{"echo", "héhéhé"}, []string{"echo", "h..."}},
+	} {
+		scrubber.MaxCmdlineLength = tc.maxLen
+		truncated := scrubber.TruncateCommand(tc.cmdline)
+		assert.Equal(t, tc.expected, truncated, "max length %d for %v", tc.maxLen, tc.cmdline)
+		if tc.maxLen > 0 {
+			assert.True(t, len(strings.Join(truncated, " ")) <= tc.maxLen)
+		}
+	}
+}
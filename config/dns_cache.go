@@ -0,0 +1,104 @@
+package config
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+)
+
+type dialContextFunc func(ctx context.Context, network, address string) (net.Conn, error)
+
+type lookupHostFunc func(ctx context.Context, host string) ([]string, error)
+
+// cachingDialer dials through a cache of the IPs of the hosts, so that the endpoint's
+// hostname is resolved once per TTL rather than on every new connection.
+type cachingDialer struct {
+	dial   dialContextFunc
+	lookup lookupHostFunc
+	ttl    time.Duration
+
+	mu      sync.Mutex
+	entries map[string]cachedAddrs
+}
+
+type cachedAddrs struct {
+	addrs   []string
+	expires time.Time
+}
+
+// transportDialer returns the function used by the transport to dial connections.
+func transportDialer(t *http.Transport) dialContextFunc {
+	if t.DialContext != nil {
+		return t.DialContext
+	}
+	if t.Dial != nil {
+		dial := t.Dial
+		return func(ctx context.Context, network, address string) (net.Conn, error) {
+			return dial(network, address)
+		}
+	}
+	return (&net.Dialer{}).DialContext
+}
+
+func newCachingDialer(dial dialContextFunc, ttl time.Duration) *cachingDialer {
+	return &cachingDialer{
+		dial:    dial,
+		lookup:  net.DefaultResolver.LookupHost,
+		ttl:     ttl,
+		entries: make(map[string]cachedAddrs),
+	}
+}
+
+// DialContext connects to the address like net.Dialer.DialContext, trying each of the
+// cached IPs of its host in turn. The host is resolved again on the next dial if none
+// of them could be reached.
+func (d *cachingDialer) DialContext(ctx context.Context, network, address string) (net.Conn, error) {
+	host, port, err := net.SplitHostPort(address)
+	if err != nil || net.ParseIP(host) != nil {
+		return d.dial(ctx, network, address)
+	}
+
+	addrs, err := d.resolve(ctx, host)
+	if err != nil {
+		return nil, err
+	}
+	for _, addr := range addrs {
+		var conn net.Conn
+		if conn, err = d.dial(ctx, network, net.JoinHostPort(addr, port)); err == nil {
+			return conn, nil
+		}
+	}
+	d.forget(host)
+	return nil, err
+}
+
+func (d *cachingDialer) resolve(ctx context.Context, host string) ([]string, error) {
+	d.mu.Lock()
+	e, ok := d.entries[host]
+	d.mu.Unlock()
+	if ok && time.Now().Before(e.expires) {
+		return e.addrs, nil
+	}
+
+	addrs, err := d.lookup(ctx, host)
+	if err != nil {
+		return nil, err
+	}
+	if len(addrs) == 0 {
+		return nil, fmt.Errorf("no addresses found for %s", host)
+	}
+
+	d.mu.Lock()
+	d.entries[host] = cachedAddrs{addrs: addrs, expires: time.Now().Add(d.ttl)}
+	d.mu.Unlock()
+	return addrs, nil
+}
+
+func (d *cachingDialer) forget(host string) {
+	d.mu.Lock()
+	delete(d.entries, host)
+	d.mu.Unlock()
+}
@@ -0,0 +1,82 @@
+package config
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCachingDialer(t *testing.T) {
+	assert := assert.New(t)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+	u, _ := url.Parse(server.URL)
+	_, port, _ := net.SplitHostPort(u.Host)
+
+	var lookups int
+	var dialed []string
+	addrs := []string{"127.0.0.1"}
+	d := newCachingDialer(func(ctx context.Context, network, address string) (net.Conn, error) {
+		dialed = append(dialed, address)
+		return (&net.Dialer{}).DialContext(ctx, network, address)
+	}, 50*time.Millisecond)
+	d.lookup = func(ctx context.Context, host string) ([]string, error) {
+		lookups++
+		assert.Equal("intake.example.com", host)
+		return addrs, nil
+	}
+
+	client := http.Client{Transport: &http.Transport{DialContext: d.DialContext, DisableKeepAlives: true}}
+	get := func() {
+		resp, err := client.Get("http://intake.example.com:" + port + "/")
+		if assert.NoError(err) {
+			resp.Body.Close()
+		}
+	}
+
+	// The host is resolved once within the TTL
+	get()
+	get()
+	assert.Equal(1, lookups)
+	assert.Equal([]string{"127.0.0.1:" + port, "127.0.0.1:" + port}, dialed)
+
+	time.Sleep(60 * time.Millisecond)
+	get()
+	assert.Equal(2, lookups)
+
+	// Unreachable addresses are skipped, and the host is resolved again once none work
+	dialed = nil
+	d.forget("intake.example.com")
+	addrs = []string{"127.0.0.2", "127.0.0.1"}
+	d.dial = func(ctx context.Context, network, address string) (net.Conn, error) {
+		dialed = append(dialed, address)
+		if address == "127.0.0.2:"+port {
+			return nil, errors.New("unreachable")
+		}
+		return (&net.Dialer{}).DialContext(ctx, network, address)
+	}
+	get()
+	assert.Equal([]string{"127.0.0.2:" + port, "127.0.0.1:" + port}, dialed)
+	assert.Equal(3, lookups)
+
+	addrs = []string{"127.0.0.2"}
+	d.forget("intake.example.com")
+	_, err := client.Get("http://intake.example.com:" + port + "/")
+	assert.Error(err)
+	_, ok := d.entries["intake.example.com"]
+	assert.False(ok)
+
+	// IPs are dialed as is
+	lookups = 0
+	resp, err := client.Get(server.URL)
+	assert.NoError(err)
+	resp.Body.Close()
+	assert.Equal(0, lookups)
+}
@@ -0,0 +1,85 @@
+package config
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	log "github.com/cihub/seelog"
+)
+
+var (
+	// ecsMetadataTimeout bounds the queries of the ECS metadata, which is only served on ECS.
+	ecsMetadataTimeout = 500 * time.Millisecond
+
+	// ecsAgentURL is the introspection API of the ECS agent running on the container instance.
+	ecsAgentURL = "http://localhost:51678"
+)
+
+// ecsTaskMetadata holds the fields of the task metadata (v3) and of the ECS agent
+// introspection API we tag the host with.
+type ecsTaskMetadata struct {
+	Cluster string `json:"Cluster"`
+	TaskARN string `json:"TaskARN"`
+	Family  string `json:"Family"`
+}
+
+// ecsTags returns the tags of the ECS task the agent runs in on an ECS container
+// instance, or nil outside of ECS.
+func ecsTags() []string {
+	meta, err := getECSTaskMetadata()
+	if err != nil {
+		log.Debugf("not running on ECS, no ECS tags: %s", err)
+		return nil
+	}
+
+	var tags []string
+	if meta.Cluster != "" {
+		// The cluster is reported as an ARN by the task metadata and as a name by the agent
+		tags = append(tags, "ecs_cluster_name:"+meta.Cluster[strings.LastIndex(meta.Cluster, "/")+1:])
+	}
+	if meta.TaskARN != "" {
+		tags = append(tags, "task_arn:"+meta.TaskARN)
+	}
+	if meta.Family != "" {
+		tags = append(tags, "task_family:"+meta.Family)
+	}
+	return tags
+}
+
+// getECSTaskMetadata reads the metadata of the task from the task metadata endpoint the ECS
+// agent exposes to the containers it runs. Older ECS agents don't, in which case only the
+// cluster is read from the introspection API of the agent.
+func getECSTaskMetadata() (*ecsTaskMetadata, error) {
+	meta := &ecsTaskMetadata{}
+	if uri := os.Getenv("ECS_CONTAINER_METADATA_URI"); uri != "" {
+		return meta, getECSMetadata(strings.TrimRight(uri, "/")+"/task", meta)
+	}
+	return meta, getECSMetadata(ecsAgentURL+"/v1/metadata", meta)
+}
+
+func getECSMetadata(url string, v interface{}) error {
+	ctx, cancel := context.WithTimeout(context.Background(), ecsMetadataTimeout)
+	defer cancel()
+
+	req, err := http.NewRequest("GET", url, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := cloudMetadataClient.Do(req.WithContext(ctx))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status %d from %s", resp.StatusCode, url)
+	}
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		return fmt.Errorf("invalid ECS metadata from %s: %s", url, err)
+	}
+	return nil
+}
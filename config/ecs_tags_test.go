@@ -0,0 +1,58 @@
+package config
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestECSTags(t *testing.T) {
+	assert := assert.New(t)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v3/0a1b2c3d/task":
+			w.Write([]byte(`{
+				"Cluster": "arn:aws:ecs:us-east-1:012345678910:cluster/prod",
+				"TaskARN": "arn:aws:ecs:us-east-1:012345678910:task/9781c248-0edd-4cdb-9a93-f63cb662a5d3",
+				"Family": "datadog-agent",
+				"Revision": "7",
+				"Containers": []
+			}`))
+		case "/v1/metadata":
+			w.Write([]byte(`{"Cluster": "staging", "ContainerInstanceArn": "arn:aws:ecs:us-east-1:012345678910:container-instance/1f73d099", "Version": "Amazon ECS Agent - v1.17.0"}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer srv.Close()
+
+	agentURL := ecsAgentURL
+	defer func() { ecsAgentURL = agentURL }()
+
+	// Task metadata endpoint
+	ecsAgentURL = "http://127.0.0.1:1"
+	os.Setenv("ECS_CONTAINER_METADATA_URI", srv.URL+"/v3/0a1b2c3d")
+	assert.Equal([]string{
+		"ecs_cluster_name:prod",
+		"task_arn:arn:aws:ecs:us-east-1:012345678910:task/9781c248-0edd-4cdb-9a93-f63cb662a5d3",
+		"task_family:datadog-agent",
+	}, ecsTags())
+	os.Unsetenv("ECS_CONTAINER_METADATA_URI")
+
+	// Older agents only expose the cluster through their introspection API
+	ecsAgentURL = srv.URL
+	assert.Equal([]string{"ecs_cluster_name:staging"}, ecsTags())
+
+	cfg, err := NewAgentConfig(nil, nil)
+	assert.NoError(err)
+	assert.Equal([]string{"ecs_cluster_name:staging"}, cfg.Tags)
+
+	// Not on ECS
+	ecsAgentURL = srv.URL + "/missing"
+	assert.Nil(ecsTags())
+	ecsAgentURL = "http://127.0.0.1:1"
+	assert.Nil(ecsTags())
+}
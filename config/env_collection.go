@@ -0,0 +1,38 @@
+package config
+
+import (
+	"regexp"
+
+	"github.com/DataDog/gopsutil/process"
+	log "github.com/cihub/seelog"
+)
+
+// compileEnvCollectionPatterns compiles the patterns of the executables whose environment
+// is collected, ignoring the invalid ones.
+func compileEnvCollectionPatterns(patterns []string) []*regexp.Regexp {
+	compiled := make([]*regexp.Regexp, 0, len(patterns))
+	for _, p := range patterns {
+		r, err := regexp.Compile(p)
+		if err != nil {
+			log.Warnf("Ignoring invalid env_collection_exe_patterns pattern %s: %s", p, err)
+			continue
+		}
+		compiled = append(compiled, r)
+	}
+	return compiled
+}
+
+// CollectsProcessEnv returns true if the environment of the process is collected, i.e. if
+// its executable matches one of the env_collection_exe_patterns. Like for the blacklist, the
+// executable falls back to the first argument of the command line when unknown.
+func (a *AgentConfig) CollectsProcessEnv(fp *process.FilledProcess) bool {
+	if len(a.EnvCollectionExePatterns) == 0 {
+		return false
+	}
+	for _, exe := range blacklistMatchValue(BlacklistMatchExe, fp) {
+		if matchesAny(exe, a.EnvCollectionExePatterns) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,49 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+)
+
+// ErrMissingAPIKey is returned when the agent configuration has no api_key.
+var ErrMissingAPIKey = errors.New("no api_key found in the agent configuration")
+
+// InvalidEndpointError is returned when an intake URL of the configuration can't be used.
+type InvalidEndpointError struct {
+	// Key is the configuration key of the endpoint, e.g. process_dd_url
+	Key   string
+	Value string
+	Err   error
+}
+
+func (e *InvalidEndpointError) Error() string {
+	return fmt.Sprintf("invalid %s '%s': %s", e.Key, e.Value, e.Err)
+}
+
+// InvalidIntervalError is returned when the interval of a check can't be parsed or is negative.
+// The default interval of the check is used instead, so unlike the other errors it comes
+// with a usable configuration.
+type InvalidIntervalError struct {
+	Check string
+	Value string
+}
+
+func (e *InvalidIntervalError) Error() string {
+	return fmt.Sprintf("invalid %s check interval '%s', expected a positive number of seconds", e.Check, e.Value)
+}
+
+// parseEndpoint parses the URL of an intake, which must be absolute.
+func parseEndpoint(key, value string) (*url.URL, error) {
+	u, err := url.Parse(value)
+	if err != nil {
+		if uerr, ok := err.(*url.Error); ok {
+			err = uerr.Err
+		}
+		return nil, &InvalidEndpointError{Key: key, Value: value, Err: err}
+	}
+	if u.Scheme == "" || u.Host == "" {
+		return nil, &InvalidEndpointError{Key: key, Value: value, Err: errors.New("expected an absolute URL like https://host")}
+	}
+	return u, nil
+}
@@ -0,0 +1,78 @@
+package config
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-ini/ini"
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/yaml.v2"
+)
+
+func TestIniConfigErrors(t *testing.T) {
+	load := func(lines ...string) (*AgentConfig, error) {
+		dd, err := ini.Load([]byte(strings.Join(lines, "\n")))
+		assert.NoError(t, err)
+		return NewAgentConfig(&File{instance: dd, Path: "whatever"}, nil)
+	}
+
+	cfg, err := load("[Main]", "log_level = info")
+	assert.Nil(t, cfg)
+	assert.Equal(t, ErrMissingAPIKey, err)
+
+	for _, endpoint := range []string{"process.datadoghq.com", "http://[::1", "/api"} {
+		cfg, err = load("[Main]", "api_key = apikey_12", "[process.config]", "endpoint = "+endpoint)
+		assert.Nil(t, cfg)
+		if assert.IsType(t, &InvalidEndpointError{}, err) {
+			assert.Equal(t, "endpoint", err.(*InvalidEndpointError).Key)
+			assert.Equal(t, endpoint, err.(*InvalidEndpointError).Value)
+		}
+	}
+
+	for _, interval := range []string{"ten", "-5"} {
+		cfg, err = load("[Main]", "api_key = apikey_12", "[process.config]", "process_interval = "+interval, "container_interval = 20")
+		assert.Equal(t, &InvalidIntervalError{Check: "process", Value: interval}, err)
+		// The config is still usable, with the default of the invalid interval
+		if assert.NotNil(t, cfg) {
+			assert.Equal(t, 10*time.Second, cfg.CheckIntervals["process"])
+			assert.Equal(t, 20*time.Second, cfg.CheckIntervals["container"])
+		}
+	}
+}
+
+func TestYamlConfigErrors(t *testing.T) {
+	load := func(lines ...string) (*AgentConfig, error) {
+		var ddy YamlAgentConfig
+		err := yaml.Unmarshal([]byte(strings.Join(append([]string{"api_key: apikey_20", "process_config:"}, lines...), "\n")), &ddy)
+		assert.NoError(t, err)
+		return NewAgentConfig(nil, &ddy)
+	}
+
+	for key, lines := range map[string][]string{
+		"process_dd_url":  {"  process_dd_url: my-process-app.datadoghq.com"},
+		"site":            {"  site: ' '"},
+		"mirror_endpoint": {"  mirror_endpoint: ':staging'", "  mirror_api_key: apikey_mirror"},
+	} {
+		cfg, err := load(lines...)
+		assert.Nil(t, cfg)
+		if assert.IsType(t, &InvalidEndpointError{}, err) {
+			assert.Equal(t, key, err.(*InvalidEndpointError).Key)
+		}
+	}
+
+	cfg, err := load("  intervals:", "    process_realtime: -2", "    container: 20")
+	assert.Equal(t, &InvalidIntervalError{Check: "rtprocess", Value: "-2"}, err)
+	if assert.NotNil(t, cfg) {
+		assert.Equal(t, 2*time.Second, cfg.CheckIntervals["rtprocess"])
+		assert.Equal(t, 20*time.Second, cfg.CheckIntervals["container"])
+	}
+}
+
+func TestConfigErrorMessages(t *testing.T) {
+	_, err := parseEndpoint("process_dd_url", "my-process-app.datadoghq.com")
+	assert.EqualError(t, err, "invalid process_dd_url 'my-process-app.datadoghq.com': expected an absolute URL like https://host")
+
+	err = &InvalidIntervalError{Check: "process", Value: "ten"}
+	assert.EqualError(t, err, "invalid process check interval 'ten', expected a positive number of seconds")
+}
@@ -0,0 +1,40 @@
+package config
+
+import (
+	"errors"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// useForwarder points the submissions to the local forwarder of the infra agent listening
+// on ForwarderAddress, instead of the intake. The forwarder takes care of the proxy, the
+// retries and the backoff of the submissions to the intake, so the process agent connects
+// to it directly, without proxy nor DNS caching.
+func (a *AgentConfig) useForwarder() error {
+	addr := strings.TrimSpace(a.ForwarderAddress)
+	if addr == "" {
+		return errors.New("forwarder_address is not set")
+	}
+	if !strings.Contains(addr, "://") {
+		addr = "http://" + addr
+	}
+	u, err := parseEndpoint("forwarder_address", addr)
+	if err != nil {
+		return err
+	}
+
+	a.APIEndpoint = u
+	a.Transport = &http.Transport{
+		MaxIdleConns:    5,
+		IdleConnTimeout: 90 * time.Second,
+		DialContext: (&net.Dialer{
+			Timeout:   10 * time.Second,
+			KeepAlive: 10 * time.Second,
+		}).DialContext,
+		ResponseHeaderTimeout: 5 * time.Second,
+		ExpectContinueTimeout: 1 * time.Second,
+	}
+	return nil
+}
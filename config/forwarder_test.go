@@ -0,0 +1,45 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/yaml.v2"
+)
+
+func TestUseForwarder(t *testing.T) {
+	assert := assert.New(t)
+
+	var ddy YamlAgentConfig
+	assert.NoError(yaml.Unmarshal([]byte("api_key: apikey_20\nproxy:\n  https: http://proxy:3128\nprocess_config:\n  use_forwarder: true\n  forwarder_address: localhost:5003"), &ddy))
+	agentConfig, err := NewAgentConfig(nil, &ddy)
+	assert.NoError(err)
+	assert.True(agentConfig.UseForwarder)
+	assert.Equal("http://localhost:5003", agentConfig.APIEndpoint.String())
+	// The forwarder is reached directly, it uses the proxy itself
+	assert.Nil(agentConfig.Transport.Proxy)
+
+	// Without address the payloads are submitted to the intake
+	ddy = YamlAgentConfig{}
+	assert.NoError(yaml.Unmarshal([]byte("api_key: apikey_20\nprocess_config:\n  use_forwarder: true"), &ddy))
+	agentConfig, err = NewAgentConfig(nil, &ddy)
+	assert.NoError(err)
+	assert.False(agentConfig.UseForwarder)
+	assert.Equal(defaultEndpoint, agentConfig.APIEndpoint.String())
+}
+
+func TestUseForwarderAddress(t *testing.T) {
+	for addr, expected := range map[string]string{
+		"localhost:5003":        "http://localhost:5003",
+		"https://127.0.0.1:443": "https://127.0.0.1:443",
+	} {
+		cfg := NewDefaultAgentConfig()
+		cfg.ForwarderAddress = addr
+		assert.NoError(t, cfg.useForwarder(), addr)
+		assert.Equal(t, expected, cfg.APIEndpoint.String(), addr)
+	}
+
+	cfg := NewDefaultAgentConfig()
+	cfg.ForwarderAddress = "http://"
+	assert.Error(t, cfg.useForwarder())
+}
@@ -0,0 +1,30 @@
+package config
+
+import (
+	"strings"
+
+	log "github.com/cihub/seelog"
+)
+
+// Seeds of the group IDs correlating the messages of a check run, see AgentConfig.GroupIDSeed.
+// The group ID is incremented for each run from its seed.
+const (
+	// GroupIDSeedRandom seeds the group IDs with a random number.
+	GroupIDSeedRandom = "random"
+	// GroupIDSeedStartTime seeds the group IDs with the start time of the agent, so that they
+	// keep increasing across restarts as long as less than 16 checks run per second on average.
+	GroupIDSeedStartTime = "start_time"
+)
+
+// parseGroupIDSeed returns the group ID seed matching the name, defaulting to random for
+// unknown names.
+func parseGroupIDSeed(name string) string {
+	switch s := strings.ToLower(strings.TrimSpace(name)); s {
+	case GroupIDSeedRandom, GroupIDSeedStartTime:
+		return s
+	default:
+		log.Warnf("Unknown group_id_seed '%s', choose from: %s, %s. Defaulting to %s",
+			name, GroupIDSeedRandom, GroupIDSeedStartTime, GroupIDSeedRandom)
+		return GroupIDSeedRandom
+	}
+}
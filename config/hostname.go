@@ -0,0 +1,88 @@
+package config
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"strings"
+
+	log "github.com/cihub/seelog"
+)
+
+type hostnameProvider struct {
+	name     string
+	hostname func(cfg *AgentConfig) (string, error)
+}
+
+// hostnameProviders are tried in order when no hostname is configured, the first valid
+// hostname wins.
+var hostnameProviders = []hostnameProvider{
+	{name: "cloud metadata", hostname: cloudMetadataHostname},
+	{name: "agent", hostname: agentHostname},
+	{name: "os", hostname: fqdnHostname},
+}
+
+// resolveHostname returns the first valid hostname of the providers. Hostnames like
+// localhost would make all the hosts of a fleet collapse into one, so they are skipped
+// unless allow_localhost_hostname is enabled. If no provider has a valid hostname, the
+// last non-empty one is used.
+func resolveHostname(cfg *AgentConfig) string {
+	var fallback string
+	for _, p := range hostnameProviders {
+		hostname, err := p.hostname(cfg)
+		if err != nil {
+			log.Debugf("no hostname from the %s: %s", p.name, err)
+			continue
+		}
+		if err := validateHostname(hostname, cfg.AllowLocalhostHostname); err != nil {
+			log.Warnf("Ignoring the hostname from the %s: %s", p.name, err)
+			if hostname != "" {
+				fallback = hostname
+			}
+			continue
+		}
+		return hostname
+	}
+	if fallback != "" {
+		log.Errorf("No valid hostname found, using '%s'. Set the hostname in the config, or enable allow_localhost_hostname if it is intended", fallback)
+	}
+	return fallback
+}
+
+// validateHostname returns an error if the hostname doesn't identify the host: it is empty,
+// or it is localhost, e.g. localhost.localdomain, unless allowLocalhost is set.
+func validateHostname(hostname string, allowLocalhost bool) error {
+	if strings.TrimSpace(hostname) == "" {
+		return fmt.Errorf("hostname is empty")
+	}
+	if !allowLocalhost && strings.HasPrefix(strings.ToLower(hostname), "localhost") {
+		return fmt.Errorf("'%s' is shared by all hosts, see allow_localhost_hostname", hostname)
+	}
+	return nil
+}
+
+func cloudMetadataHostname(cfg *AgentConfig) (string, error) {
+	if hostname, ok := cloudHostname(cfg); ok {
+		return hostname, nil
+	}
+	return "", fmt.Errorf("no cloud instance ID")
+}
+
+func agentHostname(cfg *AgentConfig) (string, error) {
+	return getHostname(cfg.DDAgentPy, cfg.DDAgentBin, cfg.DDAgentPyEnv)
+}
+
+// fqdnHostname returns the fully qualified name of the host, or the name reported by the
+// kernel if it can't be resolved.
+func fqdnHostname(cfg *AgentConfig) (string, error) {
+	hostname, err := os.Hostname()
+	if err != nil {
+		return "", err
+	}
+	if cname, err := net.LookupCNAME(hostname); err == nil {
+		if fqdn := strings.TrimSuffix(cname, "."); fqdn != "" {
+			return fqdn, nil
+		}
+	}
+	return hostname, nil
+}
@@ -0,0 +1,87 @@
+package config
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/yaml.v2"
+)
+
+func staticHostname(hostname string, err error) func(cfg *AgentConfig) (string, error) {
+	return func(cfg *AgentConfig) (string, error) { return hostname, err }
+}
+
+func TestValidateHostname(t *testing.T) {
+	assert := assert.New(t)
+	assert.NoError(validateHostname("web-1.example.com", false))
+	assert.NoError(validateHostname("my-localhost", false))
+	for _, h := range []string{"", " ", "localhost", "localhost.localdomain", "LOCALHOST6"} {
+		assert.Error(validateHostname(h, false), h)
+	}
+	assert.NoError(validateHostname("localhost.localdomain", true))
+	assert.Error(validateHostname("", true))
+}
+
+func TestResolveHostname(t *testing.T) {
+	assert := assert.New(t)
+	providers := hostnameProviders
+	defer func() { hostnameProviders = providers }()
+
+	cfg := NewDefaultAgentConfig()
+	for _, tc := range []struct {
+		hostnames []string
+		allow     bool
+		expected  string
+	}{
+		{[]string{"", "web-1", "web-1.example.com"}, false, "web-1"},
+		// localhost falls through to the next provider
+		{[]string{"", "localhost.localdomain", "web-1.example.com"}, false, "web-1.example.com"},
+		{[]string{"localhost", "", "web-1.example.com"}, false, "web-1.example.com"},
+		// Unless it is allowed
+		{[]string{"", "localhost.localdomain", "web-1.example.com"}, true, "localhost.localdomain"},
+		// The last non-empty hostname is used if none is valid
+		{[]string{"", "localhost.localdomain", "localhost"}, false, "localhost"},
+		{[]string{"", "", ""}, false, ""},
+	} {
+		hostnameProviders = nil
+		for i, h := range tc.hostnames {
+			var err error
+			if h == "" {
+				err = fmt.Errorf("no hostname")
+			}
+			hostnameProviders = append(hostnameProviders, hostnameProvider{name: fmt.Sprint(i), hostname: staticHostname(h, err)})
+		}
+		cfg.AllowLocalhostHostname = tc.allow
+		assert.Equal(tc.expected, resolveHostname(cfg), "%v", tc.hostnames)
+	}
+
+	// Providers returning an empty hostname without error are skipped too
+	hostnameProviders = []hostnameProvider{
+		{name: "agent", hostname: staticHostname("", nil)},
+		{name: "os", hostname: staticHostname("web-1", nil)},
+	}
+	assert.Equal("web-1", resolveHostname(cfg))
+}
+
+func TestAllowLocalhostHostname(t *testing.T) {
+	assert := assert.New(t)
+	providers := hostnameProviders
+	defer func() { hostnameProviders = providers }()
+	hostnameProviders = []hostnameProvider{
+		{name: "agent", hostname: staticHostname("localhost", nil)},
+		{name: "os", hostname: staticHostname("web-1.example.com", nil)},
+	}
+
+	agentConfig, err := NewAgentConfig(nil, nil)
+	assert.NoError(err)
+	assert.False(agentConfig.AllowLocalhostHostname)
+	assert.Equal("web-1.example.com", agentConfig.HostName)
+
+	var ddy YamlAgentConfig
+	assert.NoError(yaml.Unmarshal([]byte("api_key: apikey_20\nprocess_config:\n  allow_localhost_hostname: true"), &ddy))
+	agentConfig, err = NewAgentConfig(nil, &ddy)
+	assert.NoError(err)
+	assert.True(agentConfig.AllowLocalhostHostname)
+	assert.Equal("localhost", agentConfig.HostName)
+}
@@ -0,0 +1,22 @@
+package config
+
+import (
+	"crypto/tls"
+	"net/http"
+
+	"golang.org/x/net/http2"
+)
+
+// configureHTTP2 explicitly enables HTTP/2 on the transport, which it otherwise only
+// negotiates without a custom dialer or TLS config, or disables it for the proxies that
+// don't support it.
+func configureHTTP2(t *http.Transport, enabled bool) error {
+	if !enabled {
+		t.ForceAttemptHTTP2 = false
+		// A non-nil empty map prevents the transport from registering h2
+		t.TLSNextProto = make(map[string]func(authority string, c *tls.Conn) http.RoundTripper)
+		return nil
+	}
+	t.ForceAttemptHTTP2 = true
+	return http2.ConfigureTransport(t)
+}
@@ -9,6 +9,7 @@ import (
 	"strings"
 	"time"
 
+	ddconfig "github.com/DataDog/datadog-agent/pkg/config"
 	log "github.com/cihub/seelog"
 )
 
@@ -17,6 +18,9 @@ const (
 	DefaultLogLevel    = "info"
 	DefaultSyslogHost  = "localhost:514"
 	DefaultSyslogLevel = "error"
+
+	// LogLevelAuto is the log level following the log_level of the datadog.yaml.
+	LogLevelAuto = "auto"
 )
 
 var (
@@ -291,6 +295,19 @@ func replaceLogger(cfg *LoggerConfig) error {
 	return log.ReplaceLogger(logger)
 }
 
+// resolveLogLevel returns the log level of the infra agent, read from the datadog.yaml, if
+// the given level is auto. The infra agent level is read again on every (re)load of the
+// config so both stay in sync.
+func resolveLogLevel(level string) string {
+	if !strings.EqualFold(strings.TrimSpace(level), LogLevelAuto) {
+		return level
+	}
+	if l := ddconfig.Datadog.GetString("log_level"); l != "" && !strings.EqualFold(l, LogLevelAuto) {
+		return l
+	}
+	return DefaultLogLevel
+}
+
 // NewLoggerLevel sets the global logger to the given log level.
 func NewLoggerLevel(logLevel, logFile string, logToConsole bool) error {
 	return replaceLogger(&LoggerConfig{
@@ -0,0 +1,146 @@
+package config
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	log "github.com/cihub/seelog"
+)
+
+const (
+	pacFetchTimeout = 10 * time.Second
+	// Larger PAC files are surely not PAC files
+	maxPACSize = 1 << 20
+)
+
+var (
+	pacCommentRegex = regexp.MustCompile(`(?s)/\*.*?\*/|//[^\n]*`)
+	// Matches a FindProxyForURL function returning a string literal, e.g.
+	// function FindProxyForURL(url, host) { return "PROXY proxy.corp:3128; DIRECT"; }
+	staticPACRegex = regexp.MustCompile(`^\s*function\s+FindProxyForURL\s*\(\s*\w+\s*,\s*\w+\s*\)\s*\{\s*return\s*(?:"([^"\\]*)"|'([^'\\]*)')\s*;?\s*\}\s*$`)
+)
+
+// pacProxy picks the proxy of the requests with a proxy auto-config file. Only the PAC
+// files returning a static proxy list are supported, the others would need a JavaScript
+// engine. The file is loaded on the first request so that it doesn't delay the startup.
+type pacProxy struct {
+	pacURL string
+
+	once  sync.Once
+	proxy *url.URL
+}
+
+// newPACProxy returns a proxyFunc using the proxies of the PAC file at pacURL. The
+// requests are made directly if it can't be loaded.
+func newPACProxy(pacURL string) proxyFunc {
+	p := &pacProxy{pacURL: pacURL}
+	return p.proxyFor
+}
+
+func (p *pacProxy) proxyFor(req *http.Request) (*url.URL, error) {
+	p.once.Do(p.load)
+	return p.proxy, nil
+}
+
+func (p *pacProxy) load() {
+	result, err := loadStaticPAC(p.pacURL)
+	if err == nil {
+		p.proxy, err = parsePACResult(result)
+	}
+	if err != nil {
+		log.Warnf("unable to use the proxy auto-config file, connecting directly: %s", err)
+		return
+	}
+	log.Infof("using the proxies of the proxy auto-config file %s", p.pacURL)
+}
+
+// parsePACResult returns the first proxy we can use from the result of FindProxyForURL,
+// e.g. "PROXY proxy.corp:8080; DIRECT", or nil to connect directly.
+func parsePACResult(result string) (*url.URL, error) {
+	for _, entry := range strings.Split(result, ";") {
+		fields := strings.Fields(entry)
+		if len(fields) == 0 {
+			continue
+		}
+		var scheme string
+		switch strings.ToUpper(fields[0]) {
+		case "DIRECT":
+			return nil, nil
+		case "PROXY", "HTTP":
+			scheme = "http"
+		case "HTTPS":
+			scheme = "https"
+		case "SOCKS", "SOCKS5":
+			scheme = "socks5"
+		default:
+			continue
+		}
+		if len(fields) != 2 {
+			return nil, fmt.Errorf("invalid proxy '%s'", strings.TrimSpace(entry))
+		}
+		return parseProxyURL(scheme + "://" + fields[1])
+	}
+	if strings.TrimSpace(result) == "" {
+		return nil, nil
+	}
+	return nil, fmt.Errorf("no supported proxy in '%s'", result)
+}
+
+// parseStaticPAC returns the proxy list returned by a PAC file whose FindProxyForURL
+// always returns the same string, e.g. "PROXY proxy.corp:3128; DIRECT".
+func parseStaticPAC(src string) (string, error) {
+	m := staticPACRegex.FindStringSubmatch(pacCommentRegex.ReplaceAllString(src, ""))
+	if m == nil {
+		return "", fmt.Errorf("only the PAC files whose FindProxyForURL returns a static proxy list are supported")
+	}
+	return m[1] + m[2], nil
+}
+
+// loadStaticPAC downloads the PAC file, which can also be a local file:// URL, and returns
+// its static proxy list.
+func loadStaticPAC(pacURL string) (string, error) {
+	u, err := url.Parse(pacURL)
+	if err != nil {
+		return "", err
+	}
+
+	var body io.ReadCloser
+	if u.Scheme == "file" {
+		f, err := os.Open(u.Path)
+		if err != nil {
+			return "", err
+		}
+		body = f
+	} else {
+		// The PAC file is served from the internal network, not through a proxy
+		client := &http.Client{Timeout: pacFetchTimeout, Transport: &http.Transport{Proxy: nil}}
+		resp, err := client.Get(pacURL)
+		if err != nil {
+			return "", err
+		}
+		if resp.StatusCode != http.StatusOK {
+			resp.Body.Close()
+			return "", fmt.Errorf("unexpected status %d from %s", resp.StatusCode, pacURL)
+		}
+		body = resp.Body
+	}
+	defer body.Close()
+
+	src, err := ioutil.ReadAll(io.LimitReader(body, maxPACSize))
+	if err != nil {
+		return "", err
+	}
+	result, err := parseStaticPAC(string(src))
+	if err != nil {
+		return "", fmt.Errorf("invalid PAC file %s: %s", pacURL, err)
+	}
+	return result, nil
+}
@@ -0,0 +1,115 @@
+package config
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/yaml.v2"
+)
+
+const testPAC = `
+// Proxies of the corp network
+function FindProxyForURL(url, host) {
+	/* Everything goes through the secure proxy */
+	return "HTTPS secure-proxy.corp:443; PROXY proxy.corp:3128; DIRECT";
+}
+`
+
+func TestParseStaticPAC(t *testing.T) {
+	assert := assert.New(t)
+	result, err := parseStaticPAC(testPAC)
+	assert.NoError(err)
+	assert.Equal("HTTPS secure-proxy.corp:443; PROXY proxy.corp:3128; DIRECT", result)
+
+	result, err = parseStaticPAC("function FindProxyForURL(u,h){return 'DIRECT'}")
+	assert.NoError(err)
+	assert.Equal("DIRECT", result)
+
+	for _, src := range []string{
+		"",
+		"function FindProxyForURL(url, host) { return 'DIRECT'",
+		"function FindProxy(url, host) { return 'DIRECT'; }",
+		"function FindProxyForURL(url, host) { return proxy; }",
+		"function FindProxyForURL(url, host) { if (isPlainHostName(host)) return 'DIRECT'; return 'PROXY proxy.corp:3128'; }",
+	} {
+		_, err := parseStaticPAC(src)
+		assert.Error(err, src)
+	}
+}
+
+func TestParsePACResult(t *testing.T) {
+	assert := assert.New(t)
+	for result, expected := range map[string]string{
+		"DIRECT":                                 "",
+		"":                                       "",
+		"PROXY proxy.corp:3128; DIRECT":          "http://proxy.corp:3128",
+		"  proxy proxy.corp:3128":                "http://proxy.corp:3128",
+		"HTTPS proxy.corp:443":                   "https://proxy.corp:443",
+		"SOCKS5 socks.corp:1080":                 "socks5://socks.corp:1080",
+		"QUIC quic.corp:443; PROXY proxy.corp:1": "http://proxy.corp:1",
+	} {
+		u, err := parsePACResult(result)
+		assert.NoError(err, result)
+		if expected == "" {
+			assert.Nil(u, result)
+		} else {
+			assert.Equal(expected, u.String(), result)
+		}
+	}
+
+	_, err := parsePACResult("PROXY")
+	assert.Error(err)
+	_, err = parsePACResult("QUIC quic.corp:443")
+	assert.Error(err)
+}
+
+func TestPACProxy(t *testing.T) {
+	assert := assert.New(t)
+	fetches := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fetches++
+		switch r.URL.Path {
+		case "/proxy.pac":
+			w.Header().Set("Content-Type", "application/x-ns-proxy-autoconfig")
+			w.Write([]byte(testPAC))
+		case "/dynamic.pac":
+			w.Write([]byte("function FindProxyForURL(url, host) { return host == 'a' ? 'DIRECT' : 'PROXY b:1'; }"))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer srv.Close()
+
+	// The PAC file is only fetched on the first request
+	proxy := newPACProxy(srv.URL + "/proxy.pac")
+	assert.Equal(0, fetches)
+	for i := 0; i < 2; i++ {
+		req, _ := http.NewRequest("POST", "https://process.datadoghq.com/api/v1/collector", nil)
+		u, err := proxy(req)
+		assert.NoError(err)
+		assert.Equal(&url.URL{Scheme: "https", Host: "secure-proxy.corp:443"}, u)
+	}
+	assert.Equal(1, fetches)
+
+	// Requests are made directly if the PAC file can't be loaded or isn't static
+	for _, pacURL := range []string{srv.URL + "/missing.pac", srv.URL + "/dynamic.pac", "http://127.0.0.1:1/proxy.pac"} {
+		req, _ := http.NewRequest("GET", "http://example.com/", nil)
+		u, err := newPACProxy(pacURL)(req)
+		assert.NoError(err, pacURL)
+		assert.Nil(u, pacURL)
+	}
+
+	var ddy YamlAgentConfig
+	assert.NoError(yaml.Unmarshal([]byte("api_key: apikey_20\nprocess_config:\n  proxy_pac_url: "+srv.URL+"/proxy.pac"), &ddy))
+	fetches = 0
+	agentConfig, err := NewAgentConfig(nil, &ddy)
+	assert.NoError(err)
+	assert.Equal(0, fetches)
+	req, _ := http.NewRequest("GET", "http://example.com/", nil)
+	u, err := agentConfig.Transport.Proxy(req)
+	assert.NoError(err)
+	assert.Equal("https://secure-proxy.corp:443", u.String())
+}
@@ -0,0 +1,28 @@
+package config
+
+import (
+	"strings"
+
+	log "github.com/cihub/seelog"
+)
+
+// Compression algorithms of the submitted payloads, see AgentConfig.PayloadCompression.
+const (
+	// PayloadCompressionNone sends the protobuf messages as is.
+	PayloadCompressionNone = "none"
+	// PayloadCompressionGzip compresses the whole request body, sent with a gzip Content-Encoding.
+	PayloadCompressionGzip = "gzip"
+	// PayloadCompressionZstd compresses the message bodies, as flagged by their header's encoding.
+	PayloadCompressionZstd = "zstd"
+)
+
+func parsePayloadCompression(name string) string {
+	switch c := strings.ToLower(strings.TrimSpace(name)); c {
+	case PayloadCompressionNone, PayloadCompressionGzip, PayloadCompressionZstd:
+		return c
+	default:
+		log.Warnf("Unknown payload_compression '%s', choose from: %s, %s, %s. Defaulting to %s",
+			name, PayloadCompressionNone, PayloadCompressionGzip, PayloadCompressionZstd, PayloadCompressionZstd)
+		return PayloadCompressionZstd
+	}
+}
@@ -0,0 +1,95 @@
+package config
+
+import (
+	"strings"
+
+	log "github.com/cihub/seelog"
+)
+
+// Process attributes that can be selected with collect_fields. Attributes that
+// aren't selected are left out when formatting the processes, saving their space in
+// the payloads. They are still read along with the processes, as gopsutil fills them
+// all and the blacklist and max_processes rely on some of them, only the extra thread
+// count reads are skipped.
+const (
+	ProcessFieldCmdline     = "cmdline"
+	ProcessFieldUser        = "user"
+	ProcessFieldMemory      = "memory"
+	ProcessFieldCPU         = "cpu"
+	ProcessFieldIO          = "io"
+	ProcessFieldFDs         = "fds"
+	ProcessFieldCtxSwitches = "ctx_switches"
+	ProcessFieldThreads     = "threads"
+	ProcessFieldConnections = "connections"
+)
+
+var allProcessFields = []string{
+	ProcessFieldCmdline,
+	ProcessFieldUser,
+	ProcessFieldMemory,
+	ProcessFieldCPU,
+	ProcessFieldIO,
+	ProcessFieldFDs,
+	ProcessFieldCtxSwitches,
+	ProcessFieldThreads,
+	ProcessFieldConnections,
+}
+
+// parseProcessFields returns the set of process fields with the given names, skipping unknown ones.
+// It returns nil, collecting all the fields, if none of the names is known, e.g. on a typo.
+func parseProcessFields(names []string) map[string]bool {
+	fields := make(map[string]bool, len(names))
+	var rejected []string
+	for _, name := range names {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "" {
+			continue
+		}
+		known := false
+		for _, f := range allProcessFields {
+			if f == name {
+				known = true
+				break
+			}
+		}
+		if !known {
+			log.Warnf("Ignoring unknown process field '%s' in collect_fields, choose from: %v", name, allProcessFields)
+			rejected = append(rejected, name)
+			continue
+		}
+		fields[name] = true
+	}
+	if len(fields) == 0 {
+		log.Warnf("No known process field in collect_fields %v, collecting all of them", rejected)
+		return nil
+	}
+	return fields
+}
+
+// CollectsProcessField returns true if the given process attribute should be collected.
+// All the attributes are collected unless collect_fields is set.
+func (a *AgentConfig) CollectsProcessField(field string) bool {
+	return a.ProcessFields == nil || a.ProcessFields[field]
+}
+
+// parseProcessSamplingRate returns the process_sampling_rate if it is between 0 and 1, the
+// fallback otherwise.
+func parseProcessSamplingRate(rate, fallback float64) float64 {
+	if rate < 0 || rate > 1 {
+		log.Warnf("Ignoring invalid process_sampling_rate %v, it must be between 0 and 1", rate)
+		return fallback
+	}
+	return rate
+}
+
+// parseProcessPriority returns the attribute used to rank the processes when
+// max_processes is reached, which is either cpu or memory.
+func parseProcessPriority(name string) string {
+	switch p := strings.ToLower(strings.TrimSpace(name)); p {
+	case ProcessFieldCPU, ProcessFieldMemory:
+		return p
+	default:
+		log.Warnf("Unknown max_processes_priority '%s', choose from: %s, %s. Defaulting to %s", name, ProcessFieldCPU, ProcessFieldMemory, ProcessFieldCPU)
+		return ProcessFieldCPU
+	}
+}
@@ -0,0 +1,78 @@
+package config
+
+import (
+	"reflect"
+)
+
+// Sources of the settings of an AgentConfig, see AgentConfig.ConfigSources.
+const (
+	SourceDefault = "default"
+	SourceINI     = "ini"
+	SourceYAML    = "yaml"
+	SourceEnv     = "env"
+	// SourceDerived is a default replaced while loading the config, e.g. the hostname
+	// resolved from the system.
+	SourceDerived = "derived"
+)
+
+var configPkgPath = reflect.TypeOf(AgentConfig{}).PkgPath()
+
+// configSnapshot is a copy of the exported settings of an AgentConfig, compared with the
+// config after each source is applied to tell which settings it changed.
+type configSnapshot map[string]interface{}
+
+// snapshotConfig copies the exported settings of the config. The maps and the settings
+// structs of this package are copied too, as they are often updated in place.
+func snapshotConfig(cfg *AgentConfig) configSnapshot {
+	v := reflect.ValueOf(cfg).Elem()
+	t := v.Type()
+	s := make(configSnapshot, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.PkgPath != "" || f.Name == "ConfigSources" {
+			continue
+		}
+		s[f.Name] = copySetting(v.Field(i)).Interface()
+	}
+	return s
+}
+
+func copySetting(v reflect.Value) reflect.Value {
+	switch {
+	case v.Kind() == reflect.Map && !v.IsNil():
+		m := reflect.MakeMapWithSize(v.Type(), v.Len())
+		for _, k := range v.MapKeys() {
+			m.SetMapIndex(k, v.MapIndex(k))
+		}
+		return m
+	case v.Kind() == reflect.Ptr && !v.IsNil() && v.Elem().Kind() == reflect.Struct && v.Elem().Type().PkgPath() == configPkgPath:
+		p := reflect.New(v.Elem().Type())
+		p.Elem().Set(v.Elem())
+		return p
+	}
+	return v
+}
+
+// recordSources attributes the settings changed since the snapshot to the given source.
+// A derived value doesn't replace the source of a setting already set by the user.
+func recordSources(cfg *AgentConfig, before configSnapshot, source string) {
+	after := snapshotConfig(cfg)
+	for name, value := range after {
+		if reflect.DeepEqual(before[name], value) {
+			continue
+		}
+		if source == SourceDerived && cfg.ConfigSources[name] != SourceDefault {
+			continue
+		}
+		cfg.ConfigSources[name] = source
+	}
+}
+
+// defaultSources returns the sources of a default config, where every setting is a default.
+func defaultSources(cfg *AgentConfig) map[string]string {
+	sources := make(map[string]string)
+	for name := range snapshotConfig(cfg) {
+		sources[name] = SourceDefault
+	}
+	return sources
+}
@@ -0,0 +1,122 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	log "github.com/cihub/seelog"
+	"github.com/fsnotify/fsnotify"
+)
+
+// configMapDataLink is the symlink atomically swapped by Kubernetes when a mounted
+// ConfigMap is updated, the files themselves being symlinks through it.
+const configMapDataLink = "..data"
+
+// FileWatcher calls a function when any of the watched files changes. Successive changes
+// within the debounce delay, e.g. a file being written in several steps, trigger a single call.
+type FileWatcher struct {
+	files    map[string]bool
+	dirs     map[string]bool
+	debounce time.Duration
+	onChange func()
+
+	watcher *fsnotify.Watcher
+	mu      sync.Mutex
+	timer   *time.Timer
+	closed  bool
+}
+
+// NewFileWatcher starts watching the given files, or all the files of the given directories,
+// for changes. Paths that don't exist are ignored.
+func NewFileWatcher(paths []string, debounce time.Duration, onChange func()) (*FileWatcher, error) {
+	fw := &FileWatcher{
+		files:    make(map[string]bool),
+		dirs:     make(map[string]bool),
+		debounce: debounce,
+		onChange: onChange,
+	}
+	w, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, err
+	}
+	for _, p := range paths {
+		p = filepath.Clean(p)
+		fi, err := os.Stat(p)
+		if err != nil {
+			continue
+		}
+		dir := p
+		if fi.IsDir() {
+			fw.dirs[p] = true
+		} else {
+			// Watch the parent directory so we still see the file if it is replaced
+			// through a rename, which is what most config management tools do.
+			fw.files[p] = true
+			dir = filepath.Dir(p)
+		}
+		if err := w.Add(dir); err != nil {
+			w.Close()
+			return nil, err
+		}
+	}
+	fw.watcher = w
+	go fw.watch()
+	return fw, nil
+}
+
+// Close stops watching the files. A pending call is cancelled.
+func (fw *FileWatcher) Close() error {
+	fw.mu.Lock()
+	fw.closed = true
+	if fw.timer != nil {
+		fw.timer.Stop()
+	}
+	fw.mu.Unlock()
+	return fw.watcher.Close()
+}
+
+func (fw *FileWatcher) watch() {
+	for {
+		select {
+		case e, ok := <-fw.watcher.Events:
+			if !ok {
+				return
+			}
+			if e.Op&(fsnotify.Write|fsnotify.Create|fsnotify.Rename|fsnotify.Remove) == 0 || !fw.isWatched(e.Name) {
+				continue
+			}
+			log.Debugf("config file %s changed: %s", e.Name, e.Op)
+			fw.changed()
+		case err, ok := <-fw.watcher.Errors:
+			if !ok {
+				return
+			}
+			log.Warnf("error watching the config files: %s", err)
+		}
+	}
+}
+
+// isWatched returns true if the file of the event is watched.
+func (fw *FileWatcher) isWatched(name string) bool {
+	name = filepath.Clean(name)
+	dir := filepath.Dir(name)
+	if fw.files[name] || fw.dirs[dir] {
+		return true
+	}
+	return filepath.Base(name) == configMapDataLink
+}
+
+// changed calls onChange once no other change happened for the debounce delay.
+func (fw *FileWatcher) changed() {
+	fw.mu.Lock()
+	defer fw.mu.Unlock()
+	if fw.closed {
+		return
+	}
+	if fw.timer != nil {
+		fw.timer.Stop()
+	}
+	fw.timer = time.AfterFunc(fw.debounce, fw.onChange)
+}
@@ -0,0 +1,62 @@
+package config
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFileWatcher(t *testing.T) {
+	assert := assert.New(t)
+	dir, err := ioutil.TempDir("", "process-agent-watch")
+	assert.NoError(err)
+	defer os.RemoveAll(dir)
+	confDir := filepath.Join(dir, "conf.d")
+	assert.NoError(os.Mkdir(confDir, 0755))
+	path := filepath.Join(dir, "datadog.yaml")
+	assert.NoError(ioutil.WriteFile(path, []byte("api_key: apikey_20\n"), 0644))
+
+	var calls int64
+	w, err := NewFileWatcher([]string{path, confDir, filepath.Join(dir, "missing.conf")}, 100*time.Millisecond, func() {
+		atomic.AddInt64(&calls, 1)
+	})
+	assert.NoError(err)
+	defer w.Close()
+
+	waitCalls := func(expected int64) {
+		time.Sleep(300 * time.Millisecond)
+		assert.Equal(expected, atomic.LoadInt64(&calls))
+	}
+
+	// Successive writes trigger a single call
+	for i := 0; i < 5; i++ {
+		assert.NoError(ioutil.WriteFile(path, []byte("api_key: apikey_20\nprocess_config:\n  queue_size: 1"), 0644))
+		time.Sleep(10 * time.Millisecond)
+	}
+	waitCalls(1)
+
+	// Other files of the directory are ignored
+	assert.NoError(ioutil.WriteFile(filepath.Join(dir, "other.yaml"), []byte("foo: bar"), 0644))
+	waitCalls(1)
+
+	// The file replaced through a rename
+	tmp := filepath.Join(dir, ".datadog.yaml.tmp")
+	assert.NoError(ioutil.WriteFile(tmp, []byte("api_key: apikey_21\n"), 0644))
+	assert.NoError(os.Rename(tmp, path))
+	waitCalls(2)
+
+	// Any file of a watched directory
+	assert.NoError(ioutil.WriteFile(filepath.Join(confDir, "process.yaml"), []byte("process_config:\n  queue_size: 2"), 0644))
+	waitCalls(3)
+
+	// No call after Close
+	assert.NoError(ioutil.WriteFile(path, []byte("api_key: apikey_22\n"), 0644))
+	time.Sleep(10 * time.Millisecond)
+	assert.NoError(w.Close())
+	waitCalls(3)
+}
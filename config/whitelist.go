@@ -0,0 +1,64 @@
+package config
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/DataDog/gopsutil/process"
+	log "github.com/cihub/seelog"
+)
+
+// Modes of the process collection, see AgentConfig.CollectionMode.
+const (
+	// CollectionModeBlacklist collects all the processes but the blacklisted ones.
+	CollectionModeBlacklist = "blacklist"
+	// CollectionModeWhitelist only collects the processes matching a whitelist pattern,
+	// the blacklisted ones among them being still left out.
+	CollectionModeWhitelist = "whitelist"
+)
+
+// parseCollectionMode returns the collection mode matching the name, defaulting to the
+// blacklist for unknown names.
+func parseCollectionMode(name string) string {
+	switch m := strings.ToLower(strings.TrimSpace(name)); m {
+	case CollectionModeBlacklist, CollectionModeWhitelist:
+		return m
+	default:
+		log.Warnf("Unknown collection_mode '%s', choose from: %s, %s. Defaulting to %s",
+			name, CollectionModeBlacklist, CollectionModeWhitelist, CollectionModeBlacklist)
+		return CollectionModeBlacklist
+	}
+}
+
+// compileWhitelist compiles the whitelist patterns, leaving out the invalid ones.
+func compileWhitelist(patterns []string) []*regexp.Regexp {
+	whitelist := make([]*regexp.Regexp, 0, len(patterns))
+	for _, p := range patterns {
+		r, err := regexp.Compile(p)
+		if err != nil {
+			log.Warnf("Ignoring invalid whitelist pattern %s: %s", p, err)
+			continue
+		}
+		whitelist = append(whitelist, r)
+	}
+	return whitelist
+}
+
+// ShouldCollect returns whether a process with the given command line is collected,
+// according to the collection mode and the blacklist and whitelist patterns.
+func (a *AgentConfig) ShouldCollect(cmdline []string) bool {
+	return a.ShouldCollectProcess(&process.FilledProcess{Cmdline: cmdline})
+}
+
+// ShouldCollectProcess returns whether the process is collected. Blacklisted processes
+// never are, and in whitelist mode only the processes matching a whitelist pattern are.
+// The whitelist patterns are matched against the same field as the blacklist ones.
+func (a *AgentConfig) ShouldCollectProcess(fp *process.FilledProcess) bool {
+	if a.IsProcessBlacklisted(fp) {
+		return false
+	}
+	if a.CollectionMode != CollectionModeWhitelist {
+		return true
+	}
+	return matchesAny(strings.Join(blacklistMatchValue(a.BlacklistMatchField, fp), " "), a.Whitelist)
+}
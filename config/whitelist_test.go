@@ -0,0 +1,92 @@
+package config
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/go-ini/ini"
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/yaml.v2"
+)
+
+func TestShouldCollect(t *testing.T) {
+	cfg := NewDefaultAgentConfig()
+	cfg.Blacklist = []*regexp.Regexp{regexp.MustCompile("--debug")}
+	cfg.Whitelist = []*regexp.Regexp{regexp.MustCompile("^/usr/bin/python "), regexp.MustCompile("^nginx")}
+
+	for _, tc := range []struct {
+		cmdline   string
+		blacklist bool
+		whitelist bool
+	}{
+		{"/usr/bin/python app.py", true, true},
+		{"nginx: worker process", true, true},
+		{"/usr/sbin/sshd -D", true, false},
+		// Blacklisted processes are left out in both modes
+		{"/usr/bin/python app.py --debug", false, false},
+		{"/usr/sbin/sshd -D --debug", false, false},
+	} {
+		cmdline := strings.Split(tc.cmdline, " ")
+		cfg.CollectionMode = CollectionModeBlacklist
+		assert.Equal(t, tc.blacklist, cfg.ShouldCollect(cmdline), "blacklist mode: %s", tc.cmdline)
+		cfg.CollectionMode = CollectionModeWhitelist
+		assert.Equal(t, tc.whitelist, cfg.ShouldCollect(cmdline), "whitelist mode: %s", tc.cmdline)
+	}
+
+	// The whitelist is matched against the blacklist match field
+	cfg.BlacklistMatchField = BlacklistMatchName
+	cfg.Whitelist = []*regexp.Regexp{regexp.MustCompile("^python$")}
+	assert.True(t, cfg.ShouldCollect([]string{"/usr/bin/python", "app.py"}))
+	assert.False(t, cfg.ShouldCollect([]string{"/usr/bin/python3", "app.py"}))
+}
+
+func TestCollectionMode(t *testing.T) {
+	assert := assert.New(t)
+	agentConfig, err := NewAgentConfig(nil, nil)
+	assert.NoError(err)
+	assert.Equal(CollectionModeBlacklist, agentConfig.CollectionMode)
+	assert.Empty(agentConfig.Whitelist)
+
+	var ddy YamlAgentConfig
+	assert.NoError(yaml.Unmarshal([]byte(strings.Join([]string{
+		"api_key: apikey_20",
+		"process_config:",
+		"  collection_mode: Whitelist",
+		"  whitelist_patterns: ['^nginx', '^/usr/bin/python ', '(invalid']",
+		"  blacklist_patterns: ['--debug']",
+	}, "\n")), &ddy))
+	agentConfig, err = NewAgentConfig(nil, &ddy)
+	assert.NoError(err)
+	assert.Equal(CollectionModeWhitelist, agentConfig.CollectionMode)
+	assert.Len(agentConfig.Whitelist, 2)
+	assert.True(agentConfig.ShouldCollect([]string{"nginx:", "master", "process"}))
+	assert.False(agentConfig.ShouldCollect([]string{"nginx:", "master", "process", "--debug"}))
+	assert.False(agentConfig.ShouldCollect([]string{"/usr/sbin/sshd", "-D"}))
+
+	dd, err := ini.Load([]byte(strings.Join([]string{
+		"[Main]",
+		"api_key=apikey_20",
+		"[process.config]",
+		"collection_mode=whitelist",
+		"whitelist=^nginx,^redis-server",
+	}, "\n")))
+	assert.NoError(err)
+	agentConfig, err = NewAgentConfig(&File{instance: dd, Path: "whatever"}, nil)
+	assert.NoError(err)
+	assert.Equal(CollectionModeWhitelist, agentConfig.CollectionMode)
+	assert.Len(agentConfig.Whitelist, 2)
+
+	// Unknown modes and whitelist mode without patterns fall back to the blacklist
+	for _, conf := range []string{
+		"  collection_mode: allowlist\n  whitelist_patterns: ['^nginx']",
+		"  collection_mode: whitelist",
+	} {
+		ddy = YamlAgentConfig{}
+		assert.NoError(yaml.Unmarshal([]byte("api_key: apikey_20\nprocess_config:\n"+conf), &ddy))
+		agentConfig, err = NewAgentConfig(nil, &ddy)
+		assert.NoError(err)
+		assert.Equal(CollectionModeBlacklist, agentConfig.CollectionMode, conf)
+		assert.True(agentConfig.ShouldCollect([]string{"/usr/sbin/sshd", "-D"}), conf)
+	}
+}
@@ -0,0 +1,29 @@
+package config
+
+import (
+	"strings"
+
+	log "github.com/cihub/seelog"
+)
+
+// Memory figures reported as the memory of the processes on Windows, see WindowsConfig.MemoryMetric.
+const (
+	// WindowsMemoryWorkingSet is the physical memory currently used by the process.
+	WindowsMemoryWorkingSet = "working_set"
+	// WindowsMemoryPrivateBytes is the memory committed by the process that can't be
+	// shared with other processes, in physical memory or in the page file.
+	WindowsMemoryPrivateBytes = "private_bytes"
+)
+
+// parseWindowsMemoryMetric returns the windows memory metric matching the name, defaulting to
+// the working set for unknown names.
+func parseWindowsMemoryMetric(name string) string {
+	switch m := strings.ToLower(strings.TrimSpace(name)); m {
+	case WindowsMemoryWorkingSet, WindowsMemoryPrivateBytes:
+		return m
+	default:
+		log.Warnf("Unknown windows memory_metric '%s', choose from: %s, %s. Defaulting to %s",
+			name, WindowsMemoryWorkingSet, WindowsMemoryPrivateBytes, WindowsMemoryWorkingSet)
+		return WindowsMemoryWorkingSet
+	}
+}
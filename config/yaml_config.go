@@ -1,9 +1,13 @@
 package config
 
 import (
+	"bytes"
 	"fmt"
+	"io/ioutil"
 	"net/url"
+	"path/filepath"
 	"regexp"
+	"strconv"
 	"strings"
 	"time"
 
@@ -19,6 +23,12 @@ import (
 // YamlAgentConfig is a structure used for marshaling the datadog.yaml configuration
 // available in Agent versions >= 6
 type YamlAgentConfig struct {
+	// Additional config files, or glob patterns, merged into this one in order. Settings from
+	// later files override earlier ones. Relative paths are relative to the including file.
+	Include yamlIncludes `yaml:"include"`
+	// The files loaded into this config, in the order they were merged
+	files []string
+
 	APIKey string `yaml:"api_key"`
 	// Whether or not the process-agent should output logs to console
 	LogToConsole bool `yaml:"log_to_console"`
@@ -30,6 +40,11 @@ type YamlAgentConfig struct {
 		Enabled string `yaml:"enabled"`
 		// The full path to the file where process-agent logs will be written.
 		LogFile string `yaml:"log_file"`
+		// A list of key:value tags attached to all the processes and containers of this host.
+		Tags []string `yaml:"tags"`
+		// Overrides the location of the host's /proc and /sys, e.g. when mounted in a container.
+		HostProc string `yaml:"host_proc"`
+		HostSys  string `yaml:"host_sys"`
 		// The interval, in seconds, at which we will run each check. If you want consistent
 		// behavior between real-time you may set the Container/ProcessRT intervals to 10.
 		// Defaults to 10s for normal checks and 2s for others.
@@ -39,8 +54,40 @@ type YamlAgentConfig struct {
 			Process           int `yaml:"process"`
 			ProcessRealTime   int `yaml:"process_realtime"`
 		} `yaml:"intervals"`
-		// A list of regex patterns that will exclude a process if matched.
+		// The maximum duration, in seconds, of a run of each check keyed by check name, e.g. connections: 5.
+		// Runs going over their timeout are abandoned and their results discarded. Unbounded by default.
+		CheckTimeouts map[string]int `yaml:"check_timeouts"`
+		// The lowest interval, in seconds, allowed for the real-time checks. Lower intervals are raised to this value.
+		MinRealTimeInterval int `yaml:"min_realtime_interval"`
+		// The maximum delay, in seconds, of the first run of each check after startup. Each check
+		// is delayed by a random duration within this window so that hosts don't submit in sync.
+		CollectionJitter int `yaml:"collection_jitter"`
+		// Only report the processes that are new, exited or changed in the real-time process check.
+		// A full snapshot is still sent on every process check interval.
+		RealTimeDelta struct {
+			Enabled bool `yaml:"enabled"`
+			// CPU usage change, in percentage points, above which a process is reported again.
+			CPUThreshold float32 `yaml:"cpu_threshold"`
+			// RSS change, as a fraction of the last reported value, above which a process is reported again.
+			MemoryThreshold float64 `yaml:"memory_threshold"`
+		} `yaml:"realtime_delta"`
+		// A list of regex patterns that will exclude a process if matched. Processes matching a pattern
+		// prefixed with ! are never excluded, e.g. to exclude all the processes of a directory but one.
 		BlacklistPatterns []string `yaml:"blacklist_patterns"`
+		// A file with additional regex patterns, one per line. The file is reloaded when it changes.
+		BlacklistFile string `yaml:"blacklist_file"`
+		// What the blacklist patterns are matched against: cmdline, the default, for the arguments joined by
+		// spaces, exe for the path of the executable, or name for the process name.
+		BlacklistMatchField string `yaml:"blacklist_match_field"`
+		// A list of regex patterns of the processes to collect when collection_mode is whitelist, matched
+		// against the same field as the blacklist patterns. The blacklisted processes are still left out.
+		WhitelistPatterns []string `yaml:"whitelist_patterns"`
+		// Which processes are collected: blacklist, the default, for all of them but the blacklisted ones,
+		// or whitelist for only those matching the whitelist patterns.
+		CollectionMode string `yaml:"collection_mode"`
+		// Regex patterns of the executables whose environment variables are collected, e.g. only those
+		// of your own applications. No environment is collected by default. The values are scrubbed.
+		EnvCollectionExePatterns []string `yaml:"env_collection_exe_patterns"`
 		// Enable/Disable the DataScrubber to obfuscate process args
 		// XXX: Using a bool pointer to differentiate between empty and set.
 		ScrubArgs *bool `yaml:"scrub_args,omitempty"`
@@ -48,20 +95,185 @@ type YamlAgentConfig struct {
 		CustomSensitiveWords []string `yaml:"custom_sensitive_words"`
 		// Strips all process arguments
 		StripProcessArguments bool `yaml:"strip_proc_arguments"`
+		// Replaces process usernames with a stable hash, salted with username_hash_salt
+		HashUsernames    bool   `yaml:"hash_usernames"`
+		UsernameHashSalt string `yaml:"username_hash_salt"`
+		// Truncates the command lines longer than this many characters, after scrubbing. Defaults to 32768.
+		MaxCmdlineLength int `yaml:"max_cmdline_length"`
+		// Names of the environment variables whose values are never, or always, masked when scrubbing
+		// environments. Others are masked if their name contains a sensitive word.
+		ScrubEnvAllowlist []string `yaml:"scrub_env_allowlist"`
+		ScrubEnvDenylist  []string `yaml:"scrub_env_denylist"`
+		// The process attributes to collect, among cmdline, user, memory, cpu, io, fds, ctx_switches, threads and connections.
+		// All of them are collected by default. The others are left out of the payloads but still read from the system.
+		CollectFields []string `yaml:"collect_fields"`
+		// The maximum number of processes to collect per check run, unlimited by default.
+		MaxProcesses int `yaml:"max_processes"`
+		// Which processes to keep when max_processes is reached: the ones using the most cpu (default) or memory.
+		MaxProcessesPriority string `yaml:"max_processes_priority"`
+		// Processes using less than both min_cpu_percent and min_memory_bytes are left out, only
+		// their count is reported. They are left out of the real-time stats too. Disabled by default.
+		MinCPUPercent  float64 `yaml:"min_cpu_percent"`
+		MinMemoryBytes uint64  `yaml:"min_memory_bytes"`
+		// The fraction, between 0 and 1, of the processes collected per check run, a different sample on each
+		// run. The processes running in a container or using a lot of resources are always collected. The
+		// processes are sampled before max_processes caps their number, and the real-time stats are reported
+		// for the sample of the last run. Defaults to 1, collecting them all.
+		ProcessSamplingRate *float64 `yaml:"process_sampling_rate,omitempty"`
+		// Report the processes running in a container whatever their usage.
+		IncludeContainerProcesses bool `yaml:"include_container_processes"`
+		// Set to true to report the kernel threads, e.g. kworker, which are usually noise and left out.
+		CollectKernelThreads bool `yaml:"collect_kernel_threads"`
 		// How many check results to buffer in memory when POST fails. The default is usually fine.
 		QueueSize int `yaml:"queue_size"`
+		// How long, in seconds, to keep submitting queued check results on shutdown before giving up.
+		DrainTimeout int `yaml:"drain_timeout"`
+		// How long, in seconds, a submission can take overall, including reading the response, before
+		// it is abandoned. Defaults to 30s.
+		SubmissionTimeout int `yaml:"submission_timeout"`
+		// How many times a check result failing to be submitted, e.g. on a backend error, is retried
+		// before being dropped. 0 drops it on the first failure. Defaults to 3.
+		MaxRetries *int `yaml:"max_retries,omitempty"`
+		// How old, in seconds, a queued check result can be before it is dropped instead of submitted, e.g.
+		// after a long outage. By default they are submitted whatever their age.
+		MaxPayloadAge int `yaml:"max_payload_age"`
+		// Set to true to send a manifest before the batches of each check run, with how many batches and
+		// items to expect, so that the backend can detect the missing batches of large collections.
+		BatchManifest bool `yaml:"batch_manifest"`
+		// Set to true to also send the API key in the DD-API-KEY header, like the other intake endpoints
+		// expect it, on top of the X-Dd-APIKey header.
+		APIKeyInHeader bool `yaml:"api_key_in_header"`
+		// Set to false to keep running the checks without submitting their results when no API key is
+		// configured, instead of refusing to start. Defaults to true.
+		RequireAPIKey *bool `yaml:"require_api_key,omitempty"`
+		// Set to true to submit to the local forwarder of the infra agent listening on forwarder_address,
+		// e.g. localhost:5003, which takes care of the proxy and the retries of the submissions to the intake.
+		UseForwarder     bool   `yaml:"use_forwarder"`
+		ForwarderAddress string `yaml:"forwarder_address"`
+		// Set to true to reload the config when the config files change, e.g. when they are mounted
+		// from a Kubernetes ConfigMap. The agent is restarted like on SIGHUP.
+		WatchConfig bool `yaml:"watch_config"`
 		// The maximum number of file descriptors to open when collecting net connections.
 		// Only change if you are running out of file descriptors from the Agent.
 		MaxProcFDs int `yaml:"max_proc_fds"`
+		// Set to false to stop reading the IO counters of the processes, e.g. if the Agent
+		// isn't allowed to read those of other users.
+		CollectProcessIO *bool `yaml:"collect_process_io,omitempty"`
+		// Set to false to report the numeric UID and GID of the processes without resolving
+		// the user names, which can be slow with network backed users (e.g. LDAP).
+		ResolveUserNames *bool `yaml:"resolve_user_names,omitempty"`
 		// The maximum number of processes, connections or containers per message.
 		// Only change if the defaults are causing issues.
 		MaxPerMessage int `yaml:"max_per_message"`
+		// The maximum serialized size, in bytes, of the processes or connections in a single message.
+		// Messages are split early if they would go over this limit.
+		MaxMessageBytes int `yaml:"max_message_bytes"`
+		// Use the instance ID from the EC2, GCE or Azure metadata as hostname instead of the one
+		// of the Agent. Falls back to the latter outside of those clouds.
+		UseCloudHostname bool `yaml:"use_cloud_hostname"`
+		// Set to true to accept localhost, e.g. localhost.localdomain, as hostname when it isn't set in
+		// the config. It is rejected by default as all the hosts of a fleet would collapse into one.
+		AllowLocalhostHostname bool `yaml:"allow_localhost_hostname"`
 		// Overrides the path to the Agent bin used for getting the hostname. The default is usually fine.
 		DDAgentBin string `yaml:"dd_agent_bin"`
 		// Overrides of the environment we pass to fetch the hostname. The default is usually fine.
 		DDAgentEnv []string `yaml:"dd_agent_env"`
+		// URL of a proxy auto-config (PAC) file, e.g. http://wpad.corp/proxy.pac. Only the PAC files whose
+		// FindProxyForURL returns a static proxy list, e.g. "PROXY proxy.corp:3128; DIRECT", are supported.
+		// It is loaded on the first submission and takes precedence over the other proxy settings. Requests
+		// are made directly if it can't be loaded.
+		ProxyPACURL string `yaml:"proxy_pac_url"`
+		// File holding the password of the user of the shared proxy, e.g. http://user@proxy:3128,
+		// to keep the password out of the config.
+		ProxyPasswordFile string `yaml:"proxy_password_file"`
+		// Set to true to always submit over HTTP/2, or to false to never use it, e.g. for proxies that
+		// don't support it. By default it is negotiated with the endpoint.
+		ForceHTTP2 *bool `yaml:"force_http2,omitempty"`
 		// Overrides the submission endpoint URL from the default
 		ProcessDDURL string `yaml:"process_dd_url"`
+		// Prefix of the submission paths, for an API mounted under a path by a proxy, e.g. /intake/process
+		CollectorPath string `yaml:"collector_path"`
+		// The Datadog site to submit to (e.g. datadoghq.eu). Ignored if process_dd_url is set.
+		Site string `yaml:"site"`
+		// A secondary endpoint URL receiving a copy of a sample of the payloads, e.g. to validate a new backend.
+		// Failures to submit to it are ignored. Requires mirror_api_key.
+		MirrorEndpoint string `yaml:"mirror_endpoint"`
+		// The API key used to submit to the mirror endpoint.
+		MirrorAPIKey string `yaml:"mirror_api_key"`
+		// The fraction, between 0 and 1, of the message groups sent to the mirror endpoint. Defaults to 1.
+		MirrorSampleRate *float64 `yaml:"mirror_sample_rate,omitempty"`
+		// For debugging only: a file the collected payloads are appended to as newline-delimited JSON, one
+		// line per payload, to inspect what is collected. They are still submitted, unless there is no API key
+		// and require_api_key is false. The file is never rotated, don't leave it set in production.
+		DebugOutputFile string `yaml:"debug_output_file"`
+		// Resolve the remote addresses of connections to hostnames using reverse DNS. Disabled by default.
+		ConnectionsResolveDNS bool `yaml:"connections_resolve_dns"`
+		// How long, in seconds, to cache the IPs of the endpoint. By default it is resolved on every new connection.
+		DNSCacheTTL int `yaml:"dns_cache_ttl"`
+		// The log level of the process agent, auto (default) follows the log_level of the datadog.yaml.
+		LogLevel string `yaml:"log_level"`
+		// The runtime to collect the containers from, e.g. docker. By default the available runtimes are detected.
+		ContainerRuntime string `yaml:"container_runtime"`
+		// Container labels to report as tags on the containers, mapped to the key of their tag, e.g.
+		// app.kubernetes.io/version: version. No label is reported by default.
+		ContainerLabelTags map[string]string `yaml:"container_label_tags"`
+		// The maximum number of tags made from labels per container. Defaults to 20.
+		ContainerLabelTagsMax int `yaml:"container_label_tags_max"`
+		// Set to false to stop running the container checks, e.g. if the containers are collected
+		// by something else. The process checks keep running.
+		CollectContainers *bool `yaml:"collect_containers,omitempty"`
+		// How long, in seconds, the agent keeps retrying in the background to access the containers when it
+		// can't at startup, e.g. as the container runtime starts slowly. The other checks start right away
+		// and the container checks only once the containers are accessible. Only applies when
+		// process_config.enabled isn't set. By default the agent is disabled right away.
+		ContainerAccessGracePeriod int `yaml:"container_access_grace_period"`
+		// Compression of the submitted payloads: none, gzip or zstd.
+		PayloadCompression string `yaml:"payload_compression"`
+		// A prefix for the names of the internal metrics sent to dogstatsd, e.g. staging to report
+		// staging.datadog.process.agent, so that several agents can share a dogstatsd.
+		StatsdPrefix string `yaml:"statsd_prefix"`
+		// How the IDs correlating the messages of a check run are seeded: random, the default, or
+		// start_time to keep them increasing across restarts of the agent.
+		GroupIDSeed string `yaml:"group_id_seed"`
+		// The interval, in seconds, at which connections are sampled from the tracer. Connections closed
+		// between two flushes are still reported if they were sampled. Defaults to the flush interval.
+		ConnectionsCollectionInterval int `yaml:"connections_collection_interval"`
+		// The maximum number of connections reported per run, e.g. on hosts with many short-lived
+		// connections. Once reached, the long-lived connections transferring the most bytes are
+		// kept along with a random sample of the others. By default there is no limit.
+		ConnectionsMax int `yaml:"connections_max"`
+		// Set to true to report a single connection per process and remote address and port, summing
+		// the bytes of its sockets and counting them, instead of one connection per socket.
+		ConnectionsAggregate bool `yaml:"connections_aggregate"`
+		// Set to true to report the name and scrubbed command line of the process of each connection,
+		// which otherwise only has the pid of the process.
+		ConnectionsProcessName bool `yaml:"connections_process_name"`
+		// Set to true to also report the TCP sockets listening for connections, as connections flagged
+		// as listening without remote address, e.g. to build an inventory of the services of the host.
+		ConnectionsListening bool `yaml:"connections_listening"`
+		// The address ranges, e.g. 10.0.0.0/8 or fd00::/8, of the remote addresses of the connections
+		// not to report, e.g. to ignore the traffic within a cluster or on loopback.
+		ConnectionsExcludeCIDRs []string `yaml:"connections_exclude_cidrs"`
+		// Set to true to also leave out the connections whose local address is in connections_exclude_cidrs.
+		ConnectionsExcludeLocal bool `yaml:"connections_exclude_local"`
+		// Set to true to report the connections to loopback addresses, i.e. 127.0.0.0/8 and ::1, which
+		// are left out by default as they are mostly local traffic between the processes of the host.
+		ConnectionsReportLoopback bool `yaml:"connections_report_loopback"`
+		// Set to true to log the diagnostics of each run of the connections check, e.g. when it collects
+		// nothing: the connections returned by the tracer, how long it took and the connections left out
+		// by each filter. Logged at the debug level, so log_level must be debug too.
+		ConnectionsDebug bool `yaml:"connections_debug"`
+		// The maximum number of entries of each cache of the checks, e.g. of the processes reported with
+		// the connections, the container IDs of the processes, the user names or the resolved addresses.
+		// The least recently used entries are evicted once reached. Defaults to 10000.
+		CacheMaxEntries int `yaml:"cache_max_entries"`
+		// The most connections tracked by the network tracer, the ones beyond it being dropped. Lower it to
+		// bound the work of the connections check on very busy hosts. The eBPF maps of the tracer can't be
+		// resized yet, so values above the default are capped to it. Must be between 1024 and 1048576,
+		// defaults to 65536.
+		ConnectionsMaxTracked int `yaml:"connections_max_tracked"`
+		// The interval, in seconds, at which the connections are submitted. Defaults to 10s.
+		ConnectionsFlushInterval int `yaml:"connections_flush_interval"`
 		// Windows-specific configuration goes in this section.
 		Windows struct {
 			// Sets windows process table refresh rate (in number of check runs)
@@ -69,12 +281,24 @@ type YamlAgentConfig struct {
 			// Controls getting process arguments immediately when a new process is discovered
 			// XXX: Using a bool pointer to differentiate between empty and set.
 			AddNewArgs *bool `yaml:"add_new_args,omitempty"`
+			// Controls reporting the names of the services run by the processes, read from the
+			// service control manager.
+			CollectServices *bool `yaml:"collect_services,omitempty"`
+			// Refresh the arguments of the processes younger than max_age seconds every
+			// refresh_interval check runs, instead of args_refresh_interval.
+			ArgsRefreshAgeBuckets []struct {
+				MaxAge          int `yaml:"max_age"`
+				RefreshInterval int `yaml:"refresh_interval"`
+			} `yaml:"args_refresh_age_buckets"`
+			// The memory reported for the processes: working_set (default), their physical memory,
+			// or private_bytes, the memory they committed.
+			MemoryMetric string `yaml:"memory_metric"`
 		} `yaml:"windows"`
 	} `yaml:"process_config"`
 }
 
-// NewYamlIfExists returns a new YamlAgentConfig if the given configPath is exists.
-func NewYamlIfExists(configPath string) (*YamlAgentConfig, error) {
+// newYamlAgentConfig returns a YamlAgentConfig with the defaults that can't be zero values.
+func newYamlAgentConfig() YamlAgentConfig {
 	var yamlConf YamlAgentConfig
 
 	// Set default values for booleans otherwise it will default to false.
@@ -82,70 +306,250 @@ func NewYamlIfExists(configPath string) (*YamlAgentConfig, error) {
 	yamlConf.Process.ScrubArgs = &defaultScrubArgs
 	defaultNewArgs := true
 	yamlConf.Process.Windows.AddNewArgs = &defaultNewArgs
+	return yamlConf
+}
 
+// NewYamlIfExists returns a new YamlAgentConfig if the given configPath is exists.
+func NewYamlIfExists(configPath string) (*YamlAgentConfig, error) {
+	yamlConf := newYamlAgentConfig()
 	if util.PathExists(configPath) {
-		lines, err := util.ReadLines(configPath)
-		if err != nil {
-			return nil, fmt.Errorf("read error: %s", err)
-		}
-		if err = yaml.Unmarshal([]byte(strings.Join(lines, "\n")), &yamlConf); err != nil {
-			return nil, fmt.Errorf("parse error: %s", err)
+		if err := loadYamlFile(configPath, &yamlConf, nil); err != nil {
+			return nil, err
 		}
 		return &yamlConf, nil
 	}
 	return nil, nil
 }
 
+// NewYamlFromDir returns a new YamlAgentConfig merging the *.yaml files of the given
+// directory in lexical order, later files overriding the values set by earlier ones, like
+// systemd drop-ins. It returns nil if the directory doesn't exist or has no such files.
+func NewYamlFromDir(dir string) (*YamlAgentConfig, error) {
+	files, err := filepath.Glob(filepath.Join(dir, "*.yaml"))
+	if err != nil {
+		return nil, err
+	}
+	if len(files) == 0 {
+		return nil, nil
+	}
+
+	yamlConf := newYamlAgentConfig()
+	// Glob returns the files in lexical order
+	for _, f := range files {
+		log.Debugf("Loading config file %s", f)
+		if err := loadYamlFile(f, &yamlConf, nil); err != nil {
+			return nil, err
+		}
+	}
+	return &yamlConf, nil
+}
+
+// yamlIncludes is the list of files to include, which can also be given as a single path.
+type yamlIncludes []string
+
+func (i *yamlIncludes) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var path string
+	if err := unmarshal(&path); err == nil {
+		*i = yamlIncludes{path}
+		return nil
+	}
+	var paths []string
+	if err := unmarshal(&paths); err != nil {
+		return err
+	}
+	*i = paths
+	return nil
+}
+
+// loadYamlFile merges the config file at path into yamlConf, followed by the files
+// it includes. including holds the files currently being loaded, to detect cycles.
+// Anchors and merge keys are resolved within each file, they can't refer to the
+// anchors of an including or included file.
+func loadYamlFile(path string, yamlConf *YamlAgentConfig, including []string) error {
+	path, err := filepath.Abs(path)
+	if err != nil {
+		return err
+	}
+	for _, p := range including {
+		if p == path {
+			return fmt.Errorf("include cycle: %s -> %s", strings.Join(including, " -> "), path)
+		}
+	}
+
+	// Parsed as is rather than line by line, which would lose the trailing newlines of
+	// literal blocks and fail on very long lines
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("read error in %s: %s", path, err)
+	}
+	yamlConf.Include = nil
+	if err = yaml.Unmarshal(data, yamlConf); err != nil {
+		return fmt.Errorf("parse error in %s: %s", path, err)
+	}
+	yamlConf.files = append(yamlConf.files, path)
+	includes := yamlConf.Include
+	yamlConf.Include = nil
+
+	including = append(append([]string{}, including...), path)
+	for _, pattern := range includes {
+		if !filepath.IsAbs(pattern) {
+			pattern = filepath.Join(filepath.Dir(path), pattern)
+		}
+		matches, err := filepath.Glob(pattern)
+		if err != nil {
+			return fmt.Errorf("invalid include %s in %s: %s", pattern, path, err)
+		}
+		if len(matches) == 0 {
+			log.Warnf("Config include %s in %s matched no files, skipping it", pattern, path)
+			continue
+		}
+		// Glob returns the matches in lexical order, which is the order they are merged in
+		for _, m := range matches {
+			log.Debugf("Including config file %s", m)
+			if err := loadYamlFile(m, yamlConf, including); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
 func mergeYamlConfig(agentConf *AgentConfig, yc *YamlAgentConfig) (*AgentConfig, error) {
 	agentConf.APIKey = yc.APIKey
 
-	if enabled, err := isAffirmative(yc.Process.Enabled); enabled {
-		agentConf.Enabled = true
-		agentConf.EnabledChecks = processChecks
-	} else if strings.ToLower(yc.Process.Enabled) == "disabled" {
-		agentConf.Enabled = false
-	} else if !enabled && err == nil {
-		agentConf.Enabled = true
-		agentConf.EnabledChecks = containerChecks
+	if v := yc.Process.Enabled; v != "" {
+		if strings.ToLower(v) == "disabled" {
+			agentConf.Enabled, agentConf.enabledSet = false, true
+		} else if enabled, err := parseBool(v); err != nil {
+			log.Warnf("Ignoring process_config.enabled: %s", err)
+		} else if enabled {
+			agentConf.Enabled, agentConf.enabledSet = true, true
+			agentConf.EnabledChecks = processChecks
+		} else {
+			agentConf.Enabled, agentConf.enabledSet = true, true
+			agentConf.EnabledChecks = containerChecks
+		}
 	}
 	if yc.Process.ProcessDDURL != "" {
-		u, err := url.Parse(yc.Process.ProcessDDURL)
+		u, err := parseEndpoint("process_dd_url", yc.Process.ProcessDDURL)
+		if err != nil {
+			return nil, err
+		}
+		agentConf.APIEndpoint = u
+		agentConf.endpointOverridden = true
+	} else if yc.Process.Site != "" && !agentConf.endpointOverridden {
+		u, err := siteEndpoint(yc.Process.Site)
 		if err != nil {
-			return nil, fmt.Errorf("invalid process_dd_url: %s", err)
+			return nil, &InvalidEndpointError{Key: "site", Value: yc.Process.Site, Err: err}
 		}
 		agentConf.APIEndpoint = u
 	}
+	if yc.Process.MirrorEndpoint != "" {
+		u, err := parseEndpoint("mirror_endpoint", yc.Process.MirrorEndpoint)
+		if err != nil {
+			return nil, err
+		}
+		if yc.Process.MirrorAPIKey == "" {
+			log.Warn("Ignoring mirror_endpoint because mirror_api_key is not set")
+		} else {
+			agentConf.MirrorEndpoint = u
+			agentConf.MirrorAPIKey = yc.Process.MirrorAPIKey
+		}
+	}
+	if yc.Process.MirrorSampleRate != nil {
+		rate := *yc.Process.MirrorSampleRate
+		if rate < 0 || rate > 1 {
+			log.Warnf("Ignoring invalid mirror_sample_rate %v, it must be between 0 and 1", rate)
+		} else {
+			agentConf.MirrorSampleRate = rate
+		}
+	}
+	if yc.Process.DebugOutputFile != "" {
+		agentConf.DebugOutputFile = yc.Process.DebugOutputFile
+	}
 	if yc.LogToConsole {
 		agentConf.LogToConsole = true
 	}
 	if yc.Process.LogFile != "" {
 		agentConf.LogFile = yc.Process.LogFile
 	}
-	if yc.Process.Intervals.Container != 0 {
-		log.Infof("Overriding container check interval to %ds", yc.Process.Intervals.Container)
-		agentConf.CheckIntervals["container"] = time.Duration(yc.Process.Intervals.Container) * time.Second
+	if len(yc.Process.Tags) > 0 {
+		agentConf.Tags = yc.Process.Tags
+	}
+	if yc.Process.HostProc != "" {
+		agentConf.HostProc = yc.Process.HostProc
+	}
+	if yc.Process.HostSys != "" {
+		agentConf.HostSys = yc.Process.HostSys
+	}
+	// Negative intervals keep their default and are reported once the config is merged
+	var intervalErr error
+	for checkName, seconds := range map[string]int{
+		"container":   yc.Process.Intervals.Container,
+		"rtcontainer": yc.Process.Intervals.ContainerRealTime,
+		"process":     yc.Process.Intervals.Process,
+		"rtprocess":   yc.Process.Intervals.ProcessRealTime,
+	} {
+		if seconds < 0 {
+			log.Warnf("Ignoring the %s check interval of %ds, expected a positive number of seconds", checkName, seconds)
+			intervalErr = &InvalidIntervalError{Check: checkName, Value: strconv.Itoa(seconds)}
+		} else if seconds != 0 {
+			log.Infof("Overriding %s check interval to %ds", checkName, seconds)
+			agentConf.CheckIntervals[checkName] = time.Duration(seconds) * time.Second
+		}
+	}
+	if yc.Process.MinRealTimeInterval != 0 {
+		agentConf.MinRealTimeInterval = time.Duration(yc.Process.MinRealTimeInterval) * time.Second
+	}
+	if yc.Process.CollectionJitter > 0 {
+		agentConf.CollectionJitter = time.Duration(yc.Process.CollectionJitter) * time.Second
 	}
-	if yc.Process.Intervals.ContainerRealTime != 0 {
-		log.Infof("Overriding real-time container check interval to %ds", yc.Process.Intervals.ContainerRealTime)
-		agentConf.CheckIntervals["rtcontainer"] = time.Duration(yc.Process.Intervals.ContainerRealTime) * time.Second
+	if yc.Process.RealTimeDelta.Enabled {
+		agentConf.RealTimeDelta = true
 	}
-	if yc.Process.Intervals.Process != 0 {
-		log.Infof("Overriding process check interval to %ds", yc.Process.Intervals.Process)
-		agentConf.CheckIntervals["process"] = time.Duration(yc.Process.Intervals.Process) * time.Second
+	if yc.Process.RealTimeDelta.CPUThreshold > 0 {
+		agentConf.RealTimeDeltaCPUThreshold = yc.Process.RealTimeDelta.CPUThreshold
 	}
-	if yc.Process.Intervals.ProcessRealTime != 0 {
-		log.Infof("Overriding real-time process check interval to %ds", yc.Process.Intervals.ProcessRealTime)
-		agentConf.CheckIntervals["rtprocess"] = time.Duration(yc.Process.Intervals.Process) * time.Second
+	if yc.Process.RealTimeDelta.MemoryThreshold > 0 {
+		agentConf.RealTimeDeltaMemThreshold = yc.Process.RealTimeDelta.MemoryThreshold
+	}
+	for checkName, timeout := range yc.Process.CheckTimeouts {
+		if timeout > 0 {
+			log.Infof("Setting %s check timeout to %ds", checkName, timeout)
+			agentConf.CheckTimeouts[checkName] = time.Duration(timeout) * time.Second
+		}
 	}
 	blacklist := make([]*regexp.Regexp, 0, len(yc.Process.BlacklistPatterns))
+	var exceptions []*regexp.Regexp
 	for _, b := range yc.Process.BlacklistPatterns {
-		r, err := regexp.Compile(b)
+		r, exception, err := parseBlacklistPattern(b)
 		if err != nil {
-			log.Warnf("Invalid blacklist pattern: %s", b)
+			log.Warnf("Ignoring invalid blacklist pattern %s: %s", b, err)
+			continue
+		}
+		if exception {
+			exceptions = append(exceptions, r)
+		} else {
+			blacklist = append(blacklist, r)
 		}
-		blacklist = append(blacklist, r)
 	}
 	agentConf.Blacklist = blacklist
+	agentConf.BlacklistExceptions = exceptions
+	if yc.Process.BlacklistFile != "" {
+		agentConf.blacklistPath = yc.Process.BlacklistFile
+	}
+	if yc.Process.BlacklistMatchField != "" {
+		agentConf.BlacklistMatchField = parseBlacklistMatchField(yc.Process.BlacklistMatchField)
+	}
+	if len(yc.Process.WhitelistPatterns) > 0 {
+		agentConf.Whitelist = compileWhitelist(yc.Process.WhitelistPatterns)
+	}
+	if yc.Process.CollectionMode != "" {
+		agentConf.CollectionMode = parseCollectionMode(yc.Process.CollectionMode)
+	}
+	if len(yc.Process.EnvCollectionExePatterns) > 0 {
+		agentConf.EnvCollectionExePatterns = compileEnvCollectionPatterns(yc.Process.EnvCollectionExePatterns)
+	}
 
 	// DataScrubber
 	if yc.Process.ScrubArgs != nil {
@@ -155,13 +559,88 @@ func mergeYamlConfig(agentConf *AgentConfig, yc *YamlAgentConfig) (*AgentConfig,
 	if yc.Process.StripProcessArguments {
 		agentConf.Scrubber.StripAllArguments = yc.Process.StripProcessArguments
 	}
+	if yc.Process.HashUsernames {
+		agentConf.Scrubber.HashUsernames = true
+	}
+	if yc.Process.UsernameHashSalt != "" {
+		agentConf.Scrubber.UsernameSalt = yc.Process.UsernameHashSalt
+	}
+	if yc.Process.MaxCmdlineLength > 0 {
+		agentConf.Scrubber.MaxCmdlineLength = yc.Process.MaxCmdlineLength
+	}
+	if len(yc.Process.ScrubEnvAllowlist) > 0 {
+		agentConf.Scrubber.EnvAllowlist = yc.Process.ScrubEnvAllowlist
+	}
+	if len(yc.Process.ScrubEnvDenylist) > 0 {
+		agentConf.Scrubber.EnvDenylist = yc.Process.ScrubEnvDenylist
+	}
+	if len(yc.Process.CollectFields) > 0 {
+		agentConf.ProcessFields = parseProcessFields(yc.Process.CollectFields)
+	}
+	if yc.Process.MaxProcesses > 0 {
+		agentConf.MaxProcesses = yc.Process.MaxProcesses
+	}
+	if yc.Process.MaxProcessesPriority != "" {
+		agentConf.MaxProcessesPriority = parseProcessPriority(yc.Process.MaxProcessesPriority)
+	}
+	if yc.Process.MinCPUPercent > 0 {
+		agentConf.MinCPUPercent = yc.Process.MinCPUPercent
+	}
+	if yc.Process.MinMemoryBytes > 0 {
+		agentConf.MinMemoryBytes = yc.Process.MinMemoryBytes
+	}
+	if yc.Process.ProcessSamplingRate != nil {
+		agentConf.ProcessSamplingRate = parseProcessSamplingRate(*yc.Process.ProcessSamplingRate, agentConf.ProcessSamplingRate)
+	}
+	if yc.Process.IncludeContainerProcesses {
+		agentConf.IncludeContainerProcesses = true
+	}
+	if yc.Process.CollectKernelThreads {
+		agentConf.CollectKernelThreads = true
+	}
 
 	if yc.Process.QueueSize > 0 {
 		agentConf.QueueSize = yc.Process.QueueSize
 	}
+	if yc.Process.DrainTimeout > 0 {
+		agentConf.DrainTimeout = time.Duration(yc.Process.DrainTimeout) * time.Second
+	}
+	if yc.Process.SubmissionTimeout > 0 {
+		agentConf.SubmissionTimeout = time.Duration(yc.Process.SubmissionTimeout) * time.Second
+	}
+	if yc.Process.MaxRetries != nil && *yc.Process.MaxRetries >= 0 {
+		agentConf.MaxRetries = *yc.Process.MaxRetries
+	}
+	if yc.Process.MaxPayloadAge > 0 {
+		agentConf.MaxPayloadAge = time.Duration(yc.Process.MaxPayloadAge) * time.Second
+	}
+	if yc.Process.BatchManifest {
+		agentConf.BatchManifest = true
+	}
+	if yc.Process.APIKeyInHeader {
+		agentConf.APIKeyInHeader = true
+	}
+	if yc.Process.RequireAPIKey != nil {
+		agentConf.RequireAPIKey = *yc.Process.RequireAPIKey
+	}
+	if yc.Process.UseForwarder {
+		agentConf.UseForwarder = true
+	}
+	if yc.Process.ForwarderAddress != "" {
+		agentConf.ForwarderAddress = yc.Process.ForwarderAddress
+	}
+	if yc.Process.WatchConfig {
+		agentConf.WatchConfig = true
+	}
 	if yc.Process.MaxProcFDs > 0 {
 		agentConf.MaxProcFDs = yc.Process.MaxProcFDs
 	}
+	if yc.Process.CollectProcessIO != nil {
+		agentConf.CollectProcessIO = *yc.Process.CollectProcessIO
+	}
+	if yc.Process.ResolveUserNames != nil {
+		agentConf.ResolveUserNames = *yc.Process.ResolveUserNames
+	}
 	if yc.Process.MaxPerMessage > 0 {
 		if yc.Process.MaxPerMessage <= maxMessageBatch {
 			agentConf.MaxPerMessage = yc.Process.MaxPerMessage
@@ -169,6 +648,92 @@ func mergeYamlConfig(agentConf *AgentConfig, yc *YamlAgentConfig) (*AgentConfig,
 			log.Warn("Overriding the configured item count per message limit because it exceeds maximum")
 		}
 	}
+	if yc.Process.MaxMessageBytes > 0 {
+		agentConf.MaxMessageBytes = yc.Process.MaxMessageBytes
+	}
+	if yc.Process.ConnectionsResolveDNS {
+		agentConf.ConnectionsResolveDNS = true
+	}
+	if yc.Process.DNSCacheTTL > 0 {
+		agentConf.DNSCacheTTL = time.Duration(yc.Process.DNSCacheTTL) * time.Second
+	}
+	if yc.Process.ContainerRuntime != "" {
+		agentConf.ContainerRuntime = yc.Process.ContainerRuntime
+	}
+	if len(yc.Process.ContainerLabelTags) > 0 {
+		entries := make([]string, 0, len(yc.Process.ContainerLabelTags))
+		for label, tagKey := range yc.Process.ContainerLabelTags {
+			entries = append(entries, label+":"+tagKey)
+		}
+		agentConf.ContainerLabelTags = parseContainerLabelTags(entries)
+	}
+	if yc.Process.ContainerLabelTagsMax > 0 {
+		agentConf.ContainerLabelTagsMax = yc.Process.ContainerLabelTagsMax
+	}
+	if yc.Process.CollectContainers != nil {
+		agentConf.CollectContainers = *yc.Process.CollectContainers
+	}
+	if yc.Process.ContainerAccessGracePeriod > 0 {
+		agentConf.ContainerAccessGracePeriod = time.Duration(yc.Process.ContainerAccessGracePeriod) * time.Second
+	}
+	if yc.Process.PayloadCompression != "" {
+		agentConf.PayloadCompression = parsePayloadCompression(yc.Process.PayloadCompression)
+	}
+	if yc.Process.StatsdPrefix != "" {
+		agentConf.StatsdPrefix = yc.Process.StatsdPrefix
+	}
+	if yc.Process.GroupIDSeed != "" {
+		agentConf.GroupIDSeed = parseGroupIDSeed(yc.Process.GroupIDSeed)
+	}
+	if yc.Process.ConnectionsFlushInterval > 0 {
+		log.Infof("Overriding connections check interval to %ds", yc.Process.ConnectionsFlushInterval)
+		agentConf.CheckIntervals["connections"] = time.Duration(yc.Process.ConnectionsFlushInterval) * time.Second
+	}
+	if yc.Process.ConnectionsCollectionInterval > 0 {
+		interval := time.Duration(yc.Process.ConnectionsCollectionInterval) * time.Second
+		if interval < agentConf.CheckIntervals["connections"] {
+			agentConf.ConnectionsCollectionInterval = interval
+		} else {
+			log.Warnf("Ignoring connections_collection_interval of %s, it must be shorter than the flush interval of %s",
+				interval, agentConf.CheckIntervals["connections"])
+		}
+	}
+	if yc.Process.ConnectionsMax > 0 {
+		agentConf.MaxConnections = yc.Process.ConnectionsMax
+	}
+	if yc.Process.ConnectionsAggregate {
+		agentConf.ConnectionsAggregate = true
+	}
+	if yc.Process.ConnectionsProcessName {
+		agentConf.ConnectionsProcessName = true
+	}
+	if yc.Process.ConnectionsListening {
+		agentConf.ConnectionsListening = true
+	}
+	if len(yc.Process.ConnectionsExcludeCIDRs) > 0 {
+		agentConf.ConnectionsExcludeCIDRs = parseCIDRs("connections_exclude_cidrs", yc.Process.ConnectionsExcludeCIDRs)
+	}
+	if yc.Process.ConnectionsExcludeLocal {
+		agentConf.ConnectionsExcludeLocal = true
+	}
+	if yc.Process.ConnectionsReportLoopback {
+		agentConf.ConnectionsReportLoopback = true
+	}
+	if yc.Process.ConnectionsDebug {
+		agentConf.ConnectionsDebug = true
+	}
+	if yc.Process.CacheMaxEntries > 0 {
+		agentConf.CacheMaxEntries = yc.Process.CacheMaxEntries
+	}
+	if yc.Process.ConnectionsMaxTracked != 0 {
+		agentConf.ConnectionsMaxTracked = parseConnectionsMaxTracked(yc.Process.ConnectionsMaxTracked, agentConf.ConnectionsMaxTracked)
+	}
+	if yc.Process.UseCloudHostname {
+		agentConf.UseCloudHostname = true
+	}
+	if yc.Process.AllowLocalhostHostname {
+		agentConf.AllowLocalhostHostname = true
+	}
 	agentConf.DDAgentBin = defaultDDAgentBin
 	if yc.Process.DDAgentBin != "" {
 		agentConf.DDAgentBin = yc.Process.DDAgentBin
@@ -180,27 +745,106 @@ func mergeYamlConfig(agentConf *AgentConfig, yc *YamlAgentConfig) (*AgentConfig,
 	if yc.Process.Windows.AddNewArgs != nil {
 		agentConf.Windows.AddNewArgs = *yc.Process.Windows.AddNewArgs
 	}
+	if yc.Process.Windows.CollectServices != nil {
+		agentConf.Windows.CollectServices = *yc.Process.Windows.CollectServices
+	}
+	if yc.Process.Windows.MemoryMetric != "" {
+		agentConf.Windows.MemoryMetric = parseWindowsMemoryMetric(yc.Process.Windows.MemoryMetric)
+	}
+	if len(yc.Process.Windows.ArgsRefreshAgeBuckets) > 0 {
+		buckets := make([]ArgsRefreshBucket, 0, len(yc.Process.Windows.ArgsRefreshAgeBuckets))
+		for _, b := range yc.Process.Windows.ArgsRefreshAgeBuckets {
+			buckets = append(buckets, ArgsRefreshBucket{MaxAge: time.Duration(b.MaxAge) * time.Second, RefreshInterval: b.RefreshInterval})
+		}
+		agentConf.Windows.ArgsRefreshAgeBuckets = sortArgsRefreshBuckets(buckets)
+	}
 
 	// Pull additional parameters from the global config file.
 	agentConf.LogLevel = ddconfig.Datadog.GetString("log_level")
+	if yc.Process.LogLevel != "" {
+		agentConf.LogLevel = yc.Process.LogLevel
+	}
 	agentConf.StatsdPort = ddconfig.Datadog.GetInt("dogstatsd_port")
 	agentConf.Transport = ddutil.CreateHTTPTransport()
 
-	return agentConf, nil
+	if yc.Process.CollectorPath != "" {
+		agentConf.CollectorPath = normalizeCollectorPath(yc.Process.CollectorPath)
+	}
+	if yc.Process.ForceHTTP2 != nil {
+		agentConf.forceHTTP2 = yc.Process.ForceHTTP2
+	}
+	if yc.Process.ProxyPACURL != "" {
+		agentConf.proxyPACURL = yc.Process.ProxyPACURL
+	}
+	// The transport already uses the proxy block shared by the Agent 6 processes, unless a proxy
+	// is configured for the process-agent. Only the password of its proxies is added here.
+	if agentConf.proxy == nil && yc.Process.ProxyPasswordFile != "" {
+		if proxies := ddconfig.GetProxies(); proxies != nil {
+			var err error
+			if agentConf.proxy, err = proxyWithPassword(proxies, yc.Process.ProxyPasswordFile); err != nil {
+				log.Errorf("error parsing proxy settings, not using a proxy: %s", err)
+				agentConf.proxy = nil
+				agentConf.Transport.Proxy = nil
+			}
+		}
+	}
+
+	return agentConf, intervalErr
 }
 
-// SetupDDAgentConfig initializes the datadog-agent config with a YAML file.
-// This is required for configuration to be available for container listeners.
-func SetupDDAgentConfig(configPath string) error {
-	ddconfig.Datadog.AddConfigPath(configPath)
-	// If they set a config file directly, let's try to honor that
-	if strings.HasSuffix(configPath, ".yaml") {
-		ddconfig.Datadog.SetConfigFile(configPath)
+// proxyWithPassword returns the proxyFunc of the shared proxies, the ones with a user
+// authenticating with the password read from passwordFile.
+func proxyWithPassword(proxies *ddconfig.Proxy, passwordFile string) (proxyFunc, error) {
+	password, err := readProxyPassword(passwordFile)
+	if err != nil {
+		return nil, fmt.Errorf("invalid proxy_password_file: %s", err)
+	}
+	p := *proxies
+	for _, v := range []*string{&p.HTTP, &p.HTTPS} {
+		if *v == "" {
+			continue
+		}
+		u, err := parseProxyURL(*v)
+		if err != nil {
+			return nil, fmt.Errorf("invalid proxy %s: %s", *v, err)
+		}
+		if u.User != nil {
+			u.User = url.UserPassword(u.User.Username(), password)
+			*v = u.String()
+		}
+	}
+	return ddutil.GetProxyTransportFunc(&p), nil
+}
+
+func parseProxyURL(v string) (*url.URL, error) {
+	u, err := url.Parse(v)
+	if err != nil {
+		return nil, err
+	}
+	if u.Host == "" {
+		return nil, fmt.Errorf("%s is missing a scheme or host", v)
 	}
+	return u, nil
+}
 
-	// load the configuration
-	if err := ddconfig.Datadog.ReadInConfig(); err != nil {
-		return fmt.Errorf("unable to load Datadog config file: %s", err)
+// SetupDDAgentConfig initializes the datadog-agent config with the YAML files yamlConf
+// was loaded from, merged in the same order, so the config directory drop-ins and the
+// included files also apply to the settings read from it (proxies, tagger, listeners...).
+// This is required for configuration to be available for container listeners.
+func SetupDDAgentConfig(yamlConf *YamlAgentConfig) error {
+	if len(yamlConf.files) == 0 {
+		return nil
+	}
+	ddconfig.Datadog.SetConfigType("yaml")
+	ddconfig.Datadog.SetConfigFile(yamlConf.files[0])
+	for _, f := range yamlConf.files {
+		data, err := ioutil.ReadFile(f)
+		if err == nil {
+			err = ddconfig.Datadog.MergeConfig(bytes.NewReader(data))
+		}
+		if err != nil {
+			return fmt.Errorf("unable to load Datadog config file %s: %s", f, err)
+		}
 	}
 
 	return nil
@@ -34,6 +34,7 @@
 		CPUInfo
 		Host
 		HostTags
+		CollectorManifest
 */
 package model
 
@@ -203,6 +204,57 @@ func (x ConnectionFamily) String() string {
 }
 func (ConnectionFamily) EnumDescriptor() ([]byte, []int) { return fileDescriptorAgent, []int{4} }
 
+type TCPState int32
+
+const (
+	TCPState_unknownTCPState TCPState = 0
+	TCPState_established     TCPState = 1
+	TCPState_synSent         TCPState = 2
+	TCPState_synRecv         TCPState = 3
+	TCPState_finWait1        TCPState = 4
+	TCPState_finWait2        TCPState = 5
+	TCPState_timeWait        TCPState = 6
+	TCPState_close           TCPState = 7
+	TCPState_closeWait       TCPState = 8
+	TCPState_lastAck         TCPState = 9
+	TCPState_listen          TCPState = 10
+	TCPState_closing         TCPState = 11
+)
+
+var TCPState_name = map[int32]string{
+	0:  "unknownTCPState",
+	1:  "established",
+	2:  "synSent",
+	3:  "synRecv",
+	4:  "finWait1",
+	5:  "finWait2",
+	6:  "timeWait",
+	7:  "close",
+	8:  "closeWait",
+	9:  "lastAck",
+	10: "listen",
+	11: "closing",
+}
+var TCPState_value = map[string]int32{
+	"unknownTCPState": 0,
+	"established":     1,
+	"synSent":         2,
+	"synRecv":         3,
+	"finWait1":        4,
+	"finWait2":        5,
+	"timeWait":        6,
+	"close":           7,
+	"closeWait":       8,
+	"lastAck":         9,
+	"listen":          10,
+	"closing":         11,
+}
+
+func (x TCPState) String() string {
+	return proto.EnumName(TCPState_name, int32(x))
+}
+func (TCPState) EnumDescriptor() ([]byte, []int) { return fileDescriptorAgent, []int{5} }
+
 type ResCollector struct {
 	Header  *ResCollector_Header `protobuf:"bytes,1,opt,name=header" json:"header,omitempty"`
 	Message string               `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
@@ -248,9 +300,13 @@ type CollectorProc struct {
 	GroupId   int32       `protobuf:"varint,6,opt,name=groupId,proto3" json:"groupId,omitempty"`
 	GroupSize int32       `protobuf:"varint,7,opt,name=groupSize,proto3" json:"groupSize,omitempty"`
 	// Optional metadata fields
-	Kubernetes *datadog_agentpayload.KubeMetadataPayload `protobuf:"bytes,8,opt,name=kubernetes" json:"kubernetes,omitempty"`
-	Ecs        *datadog_agentpayload.ECSMetadataPayload  `protobuf:"bytes,9,opt,name=ecs" json:"ecs,omitempty"`
-	Containers []*Container                              `protobuf:"bytes,10,rep,name=containers" json:"containers,omitempty"`
+	Kubernetes          *datadog_agentpayload.KubeMetadataPayload `protobuf:"bytes,8,opt,name=kubernetes" json:"kubernetes,omitempty"`
+	Ecs                 *datadog_agentpayload.ECSMetadataPayload  `protobuf:"bytes,9,opt,name=ecs" json:"ecs,omitempty"`
+	Containers          []*Container                              `protobuf:"bytes,10,rep,name=containers" json:"containers,omitempty"`
+	HostTags            []string                                  `protobuf:"bytes,11,rep,name=hostTags" json:"hostTags,omitempty"`
+	TruncatedProcesses  int32                                     `protobuf:"varint,12,opt,name=truncatedProcesses,proto3" json:"truncatedProcesses,omitempty"`
+	FilteredProcesses   int32                                     `protobuf:"varint,13,opt,name=filteredProcesses,proto3" json:"filteredProcesses,omitempty"`
+	SampledOutProcesses int32                                     `protobuf:"varint,14,opt,name=sampledOutProcesses,proto3" json:"sampledOutProcesses,omitempty"`
 }
 
 func (m *CollectorProc) Reset()                    { *m = CollectorProc{} }
@@ -306,8 +362,9 @@ type CollectorConnections struct {
 	// Post-resolved field
 	Host *Host `protobuf:"bytes,4,opt,name=host" json:"host,omitempty"`
 	// Message batching metadata
-	GroupId   int32 `protobuf:"varint,5,opt,name=groupId,proto3" json:"groupId,omitempty"`
-	GroupSize int32 `protobuf:"varint,6,opt,name=groupSize,proto3" json:"groupSize,omitempty"`
+	GroupId              int32 `protobuf:"varint,5,opt,name=groupId,proto3" json:"groupId,omitempty"`
+	GroupSize            int32 `protobuf:"varint,6,opt,name=groupSize,proto3" json:"groupSize,omitempty"`
+	TruncatedConnections int32 `protobuf:"varint,7,opt,name=truncatedConnections,proto3" json:"truncatedConnections,omitempty"`
 }
 
 func (m *CollectorConnections) Reset()                    { *m = CollectorConnections{} }
@@ -340,6 +397,8 @@ type CollectorRealTime struct {
 	NumCpus        int32            `protobuf:"varint,8,opt,name=numCpus,proto3" json:"numCpus,omitempty"`
 	TotalMemory    int64            `protobuf:"varint,9,opt,name=totalMemory,proto3" json:"totalMemory,omitempty"`
 	ContainerStats []*ContainerStat `protobuf:"bytes,10,rep,name=containerStats" json:"containerStats,omitempty"`
+	Exited         []*ProcessStat   `protobuf:"bytes,11,rep,name=exited" json:"exited,omitempty"`
+	Delta          bool             `protobuf:"varint,12,opt,name=delta,proto3" json:"delta,omitempty"`
 }
 
 func (m *CollectorRealTime) Reset()                    { *m = CollectorRealTime{} }
@@ -361,6 +420,13 @@ func (m *CollectorRealTime) GetContainerStats() []*ContainerStat {
 	return nil
 }
 
+func (m *CollectorRealTime) GetExited() []*ProcessStat {
+	if m != nil {
+		return m.Exited
+	}
+	return nil
+}
+
 type CollectorContainer struct {
 	HostName   string       `protobuf:"bytes,1,opt,name=hostName,proto3" json:"hostName,omitempty"`
 	Info       *SystemInfo  `protobuf:"bytes,2,opt,name=info" json:"info,omitempty"`
@@ -371,7 +437,8 @@ type CollectorContainer struct {
 	Kubernetes *datadog_agentpayload.KubeMetadataPayload `protobuf:"bytes,6,opt,name=kubernetes" json:"kubernetes,omitempty"`
 	Ecs        *datadog_agentpayload.ECSMetadataPayload  `protobuf:"bytes,7,opt,name=ecs" json:"ecs,omitempty"`
 	// Post-resolved fields
-	Host *Host `protobuf:"bytes,8,opt,name=host" json:"host,omitempty"`
+	Host     *Host    `protobuf:"bytes,8,opt,name=host" json:"host,omitempty"`
+	HostTags []string `protobuf:"bytes,9,rep,name=hostTags" json:"hostTags,omitempty"`
 }
 
 func (m *CollectorContainer) Reset()                    { *m = CollectorContainer{} }
@@ -477,6 +544,11 @@ type Process struct {
 	InvoluntaryCtxSwitches uint64       `protobuf:"varint,17,opt,name=involuntaryCtxSwitches,proto3" json:"involuntaryCtxSwitches,omitempty"`
 	ByteKey                []byte       `protobuf:"bytes,18,opt,name=byteKey,proto3" json:"byteKey,omitempty"`
 	ContainerByteKey       []byte       `protobuf:"bytes,19,opt,name=containerByteKey,proto3" json:"containerByteKey,omitempty"`
+	NetNs                  uint32       `protobuf:"varint,20,opt,name=netNs,proto3" json:"netNs,omitempty"`
+	Services               []string     `protobuf:"bytes,21,rep,name=services" json:"services,omitempty"`
+	Threads                int32        `protobuf:"varint,22,opt,name=threads,proto3" json:"threads,omitempty"`
+	Env                    []string     `protobuf:"bytes,23,rep,name=env" json:"env,omitempty"`
+	OpenConnections        int32        `protobuf:"varint,24,opt,name=openConnections,proto3" json:"openConnections,omitempty"`
 }
 
 func (m *Process) Reset()                    { *m = Process{} }
@@ -571,25 +643,31 @@ type Container struct {
 	CpuLimit    float32 `protobuf:"fixed32,5,opt,name=cpuLimit,proto3" json:"cpuLimit,omitempty"`
 	MemoryLimit uint64  `protobuf:"varint,6,opt,name=memoryLimit,proto3" json:"memoryLimit,omitempty"`
 	// 7 is removed, do not use.
-	State      ContainerState  `protobuf:"varint,8,opt,name=state,proto3,enum=datadog.process_agent.ContainerState" json:"state,omitempty"`
-	Health     ContainerHealth `protobuf:"varint,9,opt,name=health,proto3,enum=datadog.process_agent.ContainerHealth" json:"health,omitempty"`
-	Created    int64           `protobuf:"varint,10,opt,name=created,proto3" json:"created,omitempty"`
-	Rbps       float32         `protobuf:"fixed32,11,opt,name=rbps,proto3" json:"rbps,omitempty"`
-	Wbps       float32         `protobuf:"fixed32,12,opt,name=wbps,proto3" json:"wbps,omitempty"`
-	Key        uint32          `protobuf:"varint,13,opt,name=key,proto3" json:"key,omitempty"`
-	NetRcvdPs  float32         `protobuf:"fixed32,14,opt,name=netRcvdPs,proto3" json:"netRcvdPs,omitempty"`
-	NetSentPs  float32         `protobuf:"fixed32,15,opt,name=netSentPs,proto3" json:"netSentPs,omitempty"`
-	NetRcvdBps float32         `protobuf:"fixed32,16,opt,name=netRcvdBps,proto3" json:"netRcvdBps,omitempty"`
-	NetSentBps float32         `protobuf:"fixed32,17,opt,name=netSentBps,proto3" json:"netSentBps,omitempty"`
-	UserPct    float32         `protobuf:"fixed32,18,opt,name=userPct,proto3" json:"userPct,omitempty"`
-	SystemPct  float32         `protobuf:"fixed32,19,opt,name=systemPct,proto3" json:"systemPct,omitempty"`
-	TotalPct   float32         `protobuf:"fixed32,20,opt,name=totalPct,proto3" json:"totalPct,omitempty"`
-	MemRss     uint64          `protobuf:"varint,21,opt,name=memRss,proto3" json:"memRss,omitempty"`
-	MemCache   uint64          `protobuf:"varint,22,opt,name=memCache,proto3" json:"memCache,omitempty"`
-	Host       *Host           `protobuf:"bytes,23,opt,name=host" json:"host,omitempty"`
-	Started    int64           `protobuf:"varint,24,opt,name=started,proto3" json:"started,omitempty"`
-	ByteKey    []byte          `protobuf:"bytes,25,opt,name=byteKey,proto3" json:"byteKey,omitempty"`
-	Tags       []string        `protobuf:"bytes,26,rep,name=tags" json:"tags,omitempty"`
+	State            ContainerState  `protobuf:"varint,8,opt,name=state,proto3,enum=datadog.process_agent.ContainerState" json:"state,omitempty"`
+	Health           ContainerHealth `protobuf:"varint,9,opt,name=health,proto3,enum=datadog.process_agent.ContainerHealth" json:"health,omitempty"`
+	Created          int64           `protobuf:"varint,10,opt,name=created,proto3" json:"created,omitempty"`
+	Rbps             float32         `protobuf:"fixed32,11,opt,name=rbps,proto3" json:"rbps,omitempty"`
+	Wbps             float32         `protobuf:"fixed32,12,opt,name=wbps,proto3" json:"wbps,omitempty"`
+	Key              uint32          `protobuf:"varint,13,opt,name=key,proto3" json:"key,omitempty"`
+	NetRcvdPs        float32         `protobuf:"fixed32,14,opt,name=netRcvdPs,proto3" json:"netRcvdPs,omitempty"`
+	NetSentPs        float32         `protobuf:"fixed32,15,opt,name=netSentPs,proto3" json:"netSentPs,omitempty"`
+	NetRcvdBps       float32         `protobuf:"fixed32,16,opt,name=netRcvdBps,proto3" json:"netRcvdBps,omitempty"`
+	NetSentBps       float32         `protobuf:"fixed32,17,opt,name=netSentBps,proto3" json:"netSentBps,omitempty"`
+	UserPct          float32         `protobuf:"fixed32,18,opt,name=userPct,proto3" json:"userPct,omitempty"`
+	SystemPct        float32         `protobuf:"fixed32,19,opt,name=systemPct,proto3" json:"systemPct,omitempty"`
+	TotalPct         float32         `protobuf:"fixed32,20,opt,name=totalPct,proto3" json:"totalPct,omitempty"`
+	MemRss           uint64          `protobuf:"varint,21,opt,name=memRss,proto3" json:"memRss,omitempty"`
+	MemCache         uint64          `protobuf:"varint,22,opt,name=memCache,proto3" json:"memCache,omitempty"`
+	Host             *Host           `protobuf:"bytes,23,opt,name=host" json:"host,omitempty"`
+	Started          int64           `protobuf:"varint,24,opt,name=started,proto3" json:"started,omitempty"`
+	ByteKey          []byte          `protobuf:"bytes,25,opt,name=byteKey,proto3" json:"byteKey,omitempty"`
+	Tags             []string        `protobuf:"bytes,26,rep,name=tags" json:"tags,omitempty"`
+	RestartCount     int32           `protobuf:"varint,27,opt,name=restartCount,proto3" json:"restartCount,omitempty"`
+	OomKilled        bool            `protobuf:"varint,28,opt,name=oomKilled,proto3" json:"oomKilled,omitempty"`
+	ImageDigest      string          `protobuf:"bytes,29,opt,name=imageDigest,proto3" json:"imageDigest,omitempty"`
+	CpuNrPeriods     uint64          `protobuf:"varint,30,opt,name=cpuNrPeriods,proto3" json:"cpuNrPeriods,omitempty"`
+	CpuNrThrottled   uint64          `protobuf:"varint,31,opt,name=cpuNrThrottled,proto3" json:"cpuNrThrottled,omitempty"`
+	CpuThrottledTime uint64          `protobuf:"varint,32,opt,name=cpuThrottledTime,proto3" json:"cpuThrottledTime,omitempty"`
 }
 
 func (m *Container) Reset()                    { *m = Container{} }
@@ -759,11 +837,20 @@ type Connection struct {
 	Laddr *Addr `protobuf:"bytes,5,opt,name=laddr" json:"laddr,omitempty"`
 	Raddr *Addr `protobuf:"bytes,6,opt,name=raddr" json:"raddr,omitempty"`
 	// 7 is deprecated
-	BytesSent     float32          `protobuf:"fixed32,8,opt,name=bytesSent,proto3" json:"bytesSent,omitempty"`
-	BytesRecieved float32          `protobuf:"fixed32,9,opt,name=bytesRecieved,proto3" json:"bytesRecieved,omitempty"`
-	Family        ConnectionFamily `protobuf:"varint,10,opt,name=family,proto3,enum=datadog.process_agent.ConnectionFamily" json:"family,omitempty"`
-	Type          ConnectionType   `protobuf:"varint,11,opt,name=type,proto3,enum=datadog.process_agent.ConnectionType" json:"type,omitempty"`
-	PidCreateTime int64            `protobuf:"varint,12,opt,name=pidCreateTime,proto3" json:"pidCreateTime,omitempty"`
+	BytesSent          float32          `protobuf:"fixed32,8,opt,name=bytesSent,proto3" json:"bytesSent,omitempty"`
+	BytesRecieved      float32          `protobuf:"fixed32,9,opt,name=bytesRecieved,proto3" json:"bytesRecieved,omitempty"`
+	Family             ConnectionFamily `protobuf:"varint,10,opt,name=family,proto3,enum=datadog.process_agent.ConnectionFamily" json:"family,omitempty"`
+	Type               ConnectionType   `protobuf:"varint,11,opt,name=type,proto3,enum=datadog.process_agent.ConnectionType" json:"type,omitempty"`
+	PidCreateTime      int64            `protobuf:"varint,12,opt,name=pidCreateTime,proto3" json:"pidCreateTime,omitempty"`
+	RaddrHost          string           `protobuf:"bytes,13,opt,name=raddrHost,proto3" json:"raddrHost,omitempty"`
+	TotalBytesSent     uint64           `protobuf:"varint,14,opt,name=totalBytesSent,proto3" json:"totalBytesSent,omitempty"`
+	TotalBytesReceived uint64           `protobuf:"varint,15,opt,name=totalBytesReceived,proto3" json:"totalBytesReceived,omitempty"`
+	TcpState           TCPState         `protobuf:"varint,16,opt,name=tcpState,proto3,enum=datadog.process_agent.TCPState" json:"tcpState,omitempty"`
+	NetNs              uint32           `protobuf:"varint,17,opt,name=netNs,proto3" json:"netNs,omitempty"`
+	ConnectionCount    uint32           `protobuf:"varint,18,opt,name=connectionCount,proto3" json:"connectionCount,omitempty"`
+	ProcessName        string           `protobuf:"bytes,19,opt,name=processName,proto3" json:"processName,omitempty"`
+	ProcessCmdline     []string         `protobuf:"bytes,20,rep,name=processCmdline" json:"processCmdline,omitempty"`
+	Listening          bool             `protobuf:"varint,21,opt,name=listening,proto3" json:"listening,omitempty"`
 }
 
 func (m *Connection) Reset()                    { *m = Connection{} }
@@ -902,6 +989,19 @@ func (m *HostTags) String() string            { return proto.CompactTextString(m
 func (*HostTags) ProtoMessage()               {}
 func (*HostTags) Descriptor() ([]byte, []int) { return fileDescriptorAgent, []int{24} }
 
+type CollectorManifest struct {
+	HostName  string `protobuf:"bytes,1,opt,name=hostName,proto3" json:"hostName,omitempty"`
+	GroupId   int32  `protobuf:"varint,2,opt,name=groupId,proto3" json:"groupId,omitempty"`
+	GroupSize int32  `protobuf:"varint,3,opt,name=groupSize,proto3" json:"groupSize,omitempty"`
+	ItemCount int32  `protobuf:"varint,4,opt,name=itemCount,proto3" json:"itemCount,omitempty"`
+	BatchType int32  `protobuf:"varint,5,opt,name=batchType,proto3" json:"batchType,omitempty"`
+}
+
+func (m *CollectorManifest) Reset()                    { *m = CollectorManifest{} }
+func (m *CollectorManifest) String() string            { return proto.CompactTextString(m) }
+func (*CollectorManifest) ProtoMessage()               {}
+func (*CollectorManifest) Descriptor() ([]byte, []int) { return fileDescriptorAgent, []int{25} }
+
 func init() {
 	proto.RegisterType((*ResCollector)(nil), "datadog.process_agent.ResCollector")
 	proto.RegisterType((*ResCollector_Header)(nil), "datadog.process_agent.ResCollector.Header")
@@ -929,11 +1029,13 @@ func init() {
 	proto.RegisterType((*CPUInfo)(nil), "datadog.process_agent.CPUInfo")
 	proto.RegisterType((*Host)(nil), "datadog.process_agent.Host")
 	proto.RegisterType((*HostTags)(nil), "datadog.process_agent.HostTags")
+	proto.RegisterType((*CollectorManifest)(nil), "datadog.process_agent.CollectorManifest")
 	proto.RegisterEnum("datadog.process_agent.ContainerState", ContainerState_name, ContainerState_value)
 	proto.RegisterEnum("datadog.process_agent.ContainerHealth", ContainerHealth_name, ContainerHealth_value)
 	proto.RegisterEnum("datadog.process_agent.ProcessState", ProcessState_name, ProcessState_value)
 	proto.RegisterEnum("datadog.process_agent.ConnectionType", ConnectionType_name, ConnectionType_value)
 	proto.RegisterEnum("datadog.process_agent.ConnectionFamily", ConnectionFamily_name, ConnectionFamily_value)
+	proto.RegisterEnum("datadog.process_agent.TCPState", TCPState_name, TCPState_value)
 }
 func (m *ResCollector) Marshal() (data []byte, err error) {
 	size := m.Size()
@@ -1097,6 +1199,36 @@ func (m *CollectorProc) MarshalTo(data []byte) (int, error) {
 			i += n
 		}
 	}
+	if len(m.HostTags) > 0 {
+		for _, s := range m.HostTags {
+			data[i] = 0x5a
+			i++
+			l = len(s)
+			for l >= 1<<7 {
+				data[i] = uint8(uint64(l)&0x7f | 0x80)
+				l >>= 7
+				i++
+			}
+			data[i] = uint8(l)
+			i++
+			i += copy(data[i:], s)
+		}
+	}
+	if m.TruncatedProcesses != 0 {
+		data[i] = 0x60
+		i++
+		i = encodeVarintAgent(data, i, uint64(m.TruncatedProcesses))
+	}
+	if m.FilteredProcesses != 0 {
+		data[i] = 0x68
+		i++
+		i = encodeVarintAgent(data, i, uint64(m.FilteredProcesses))
+	}
+	if m.SampledOutProcesses != 0 {
+		data[i] = 0x70
+		i++
+		i = encodeVarintAgent(data, i, uint64(m.SampledOutProcesses))
+	}
 	return i, nil
 }
 
@@ -1153,6 +1285,11 @@ func (m *CollectorConnections) MarshalTo(data []byte) (int, error) {
 		i++
 		i = encodeVarintAgent(data, i, uint64(m.GroupSize))
 	}
+	if m.TruncatedConnections != 0 {
+		data[i] = 0x38
+		i++
+		i = encodeVarintAgent(data, i, uint64(m.TruncatedConnections))
+	}
 	return i, nil
 }
 
@@ -1231,6 +1368,28 @@ func (m *CollectorRealTime) MarshalTo(data []byte) (int, error) {
 			i += n
 		}
 	}
+	if len(m.Exited) > 0 {
+		for _, msg := range m.Exited {
+			data[i] = 0x5a
+			i++
+			i = encodeVarintAgent(data, i, uint64(msg.Size()))
+			n, err := msg.MarshalTo(data[i:])
+			if err != nil {
+				return 0, err
+			}
+			i += n
+		}
+	}
+	if m.Delta {
+		data[i] = 0x60
+		i++
+		if m.Delta {
+			data[i] = 1
+		} else {
+			data[i] = 0
+		}
+		i++
+	}
 	return i, nil
 }
 
@@ -1317,6 +1476,21 @@ func (m *CollectorContainer) MarshalTo(data []byte) (int, error) {
 		}
 		i += n11
 	}
+	if len(m.HostTags) > 0 {
+		for _, s := range m.HostTags {
+			data[i] = 0x4a
+			i++
+			l = len(s)
+			for l >= 1<<7 {
+				data[i] = uint8(uint64(l)&0x7f | 0x80)
+				l >>= 7
+				i++
+			}
+			data[i] = uint8(l)
+			i++
+			i += copy(data[i:], s)
+		}
+	}
 	return i, nil
 }
 
@@ -1584,6 +1758,61 @@ func (m *Process) MarshalTo(data []byte) (int, error) {
 		i = encodeVarintAgent(data, i, uint64(len(m.ContainerByteKey)))
 		i += copy(data[i:], m.ContainerByteKey)
 	}
+	if m.NetNs != 0 {
+		data[i] = 0xa0
+		i++
+		data[i] = 0x1
+		i++
+		i = encodeVarintAgent(data, i, uint64(m.NetNs))
+	}
+	if len(m.Services) > 0 {
+		for _, s := range m.Services {
+			data[i] = 0xaa
+			i++
+			data[i] = 0x1
+			i++
+			l = len(s)
+			for l >= 1<<7 {
+				data[i] = uint8(uint64(l)&0x7f | 0x80)
+				l >>= 7
+				i++
+			}
+			data[i] = uint8(l)
+			i++
+			i += copy(data[i:], s)
+		}
+	}
+	if m.Threads != 0 {
+		data[i] = 0xb0
+		i++
+		data[i] = 0x1
+		i++
+		i = encodeVarintAgent(data, i, uint64(m.Threads))
+	}
+	if len(m.Env) > 0 {
+		for _, s := range m.Env {
+			data[i] = 0xba
+			i++
+			data[i] = 0x1
+			i++
+			l = len(s)
+			for l >= 1<<7 {
+				data[i] = uint8(uint64(l)&0x7f | 0x80)
+				l >>= 7
+				i++
+			}
+			data[i] = uint8(l)
+			i++
+			i += copy(data[i:], s)
+		}
+	}
+	if m.OpenConnections != 0 {
+		data[i] = 0xc0
+		i++
+		data[i] = 0x1
+		i++
+		i = encodeVarintAgent(data, i, uint64(m.OpenConnections))
+	}
 	return i, nil
 }
 
@@ -1894,6 +2123,54 @@ func (m *Container) MarshalTo(data []byte) (int, error) {
 			i += copy(data[i:], s)
 		}
 	}
+	if m.RestartCount != 0 {
+		data[i] = 0xd8
+		i++
+		data[i] = 0x1
+		i++
+		i = encodeVarintAgent(data, i, uint64(m.RestartCount))
+	}
+	if m.OomKilled {
+		data[i] = 0xe0
+		i++
+		data[i] = 0x1
+		i++
+		if m.OomKilled {
+			data[i] = 1
+		} else {
+			data[i] = 0
+		}
+		i++
+	}
+	if len(m.ImageDigest) > 0 {
+		data[i] = 0xea
+		i++
+		data[i] = 0x1
+		i++
+		i = encodeVarintAgent(data, i, uint64(len(m.ImageDigest)))
+		i += copy(data[i:], m.ImageDigest)
+	}
+	if m.CpuNrPeriods != 0 {
+		data[i] = 0xf0
+		i++
+		data[i] = 0x1
+		i++
+		i = encodeVarintAgent(data, i, uint64(m.CpuNrPeriods))
+	}
+	if m.CpuNrThrottled != 0 {
+		data[i] = 0xf8
+		i++
+		data[i] = 0x1
+		i++
+		i = encodeVarintAgent(data, i, uint64(m.CpuNrThrottled))
+	}
+	if m.CpuThrottledTime != 0 {
+		data[i] = 0x80
+		i++
+		data[i] = 0x2
+		i++
+		i = encodeVarintAgent(data, i, uint64(m.CpuThrottledTime))
+	}
 	return i, nil
 }
 
@@ -2402,6 +2679,80 @@ func (m *Connection) MarshalTo(data []byte) (int, error) {
 		i++
 		i = encodeVarintAgent(data, i, uint64(m.PidCreateTime))
 	}
+	if len(m.RaddrHost) > 0 {
+		data[i] = 0x6a
+		i++
+		i = encodeVarintAgent(data, i, uint64(len(m.RaddrHost)))
+		i += copy(data[i:], m.RaddrHost)
+	}
+	if m.TotalBytesSent != 0 {
+		data[i] = 0x70
+		i++
+		i = encodeVarintAgent(data, i, uint64(m.TotalBytesSent))
+	}
+	if m.TotalBytesReceived != 0 {
+		data[i] = 0x78
+		i++
+		i = encodeVarintAgent(data, i, uint64(m.TotalBytesReceived))
+	}
+	if m.TcpState != 0 {
+		data[i] = 0x80
+		i++
+		data[i] = 0x1
+		i++
+		i = encodeVarintAgent(data, i, uint64(m.TcpState))
+	}
+	if m.NetNs != 0 {
+		data[i] = 0x88
+		i++
+		data[i] = 0x1
+		i++
+		i = encodeVarintAgent(data, i, uint64(m.NetNs))
+	}
+	if m.ConnectionCount != 0 {
+		data[i] = 0x90
+		i++
+		data[i] = 0x1
+		i++
+		i = encodeVarintAgent(data, i, uint64(m.ConnectionCount))
+	}
+	if len(m.ProcessName) > 0 {
+		data[i] = 0x9a
+		i++
+		data[i] = 0x1
+		i++
+		i = encodeVarintAgent(data, i, uint64(len(m.ProcessName)))
+		i += copy(data[i:], m.ProcessName)
+	}
+	if len(m.ProcessCmdline) > 0 {
+		for _, s := range m.ProcessCmdline {
+			data[i] = 0xa2
+			i++
+			data[i] = 0x1
+			i++
+			l = len(s)
+			for l >= 1<<7 {
+				data[i] = uint8(uint64(l)&0x7f | 0x80)
+				l >>= 7
+				i++
+			}
+			data[i] = uint8(l)
+			i++
+			i += copy(data[i:], s)
+		}
+	}
+	if m.Listening {
+		data[i] = 0xa8
+		i++
+		data[i] = 0x1
+		i++
+		if m.Listening {
+			data[i] = 1
+		} else {
+			data[i] = 0
+		}
+		i++
+	}
 	return i, nil
 }
 
@@ -2779,6 +3130,50 @@ func (m *HostTags) MarshalTo(data []byte) (int, error) {
 	return i, nil
 }
 
+func (m *CollectorManifest) Marshal() (data []byte, err error) {
+	size := m.Size()
+	data = make([]byte, size)
+	n, err := m.MarshalTo(data)
+	if err != nil {
+		return nil, err
+	}
+	return data[:n], nil
+}
+
+func (m *CollectorManifest) MarshalTo(data []byte) (int, error) {
+	var i int
+	_ = i
+	var l int
+	_ = l
+	if len(m.HostName) > 0 {
+		data[i] = 0xa
+		i++
+		i = encodeVarintAgent(data, i, uint64(len(m.HostName)))
+		i += copy(data[i:], m.HostName)
+	}
+	if m.GroupId != 0 {
+		data[i] = 0x10
+		i++
+		i = encodeVarintAgent(data, i, uint64(m.GroupId))
+	}
+	if m.GroupSize != 0 {
+		data[i] = 0x18
+		i++
+		i = encodeVarintAgent(data, i, uint64(m.GroupSize))
+	}
+	if m.ItemCount != 0 {
+		data[i] = 0x20
+		i++
+		i = encodeVarintAgent(data, i, uint64(m.ItemCount))
+	}
+	if m.BatchType != 0 {
+		data[i] = 0x28
+		i++
+		i = encodeVarintAgent(data, i, uint64(m.BatchType))
+	}
+	return i, nil
+}
+
 func encodeFixed64Agent(data []byte, offset int, v uint64) int {
 	data[offset] = uint8(v)
 	data[offset+1] = uint8(v >> 8)
@@ -2874,6 +3269,21 @@ func (m *CollectorProc) Size() (n int) {
 			n += 1 + l + sovAgent(uint64(l))
 		}
 	}
+	if len(m.HostTags) > 0 {
+		for _, s := range m.HostTags {
+			l = len(s)
+			n += 1 + l + sovAgent(uint64(l))
+		}
+	}
+	if m.TruncatedProcesses != 0 {
+		n += 1 + sovAgent(uint64(m.TruncatedProcesses))
+	}
+	if m.FilteredProcesses != 0 {
+		n += 1 + sovAgent(uint64(m.FilteredProcesses))
+	}
+	if m.SampledOutProcesses != 0 {
+		n += 1 + sovAgent(uint64(m.SampledOutProcesses))
+	}
 	return n
 }
 
@@ -2900,6 +3310,9 @@ func (m *CollectorConnections) Size() (n int) {
 	if m.GroupSize != 0 {
 		n += 1 + sovAgent(uint64(m.GroupSize))
 	}
+	if m.TruncatedConnections != 0 {
+		n += 1 + sovAgent(uint64(m.TruncatedConnections))
+	}
 	return n
 }
 
@@ -2940,6 +3353,15 @@ func (m *CollectorRealTime) Size() (n int) {
 			n += 1 + l + sovAgent(uint64(l))
 		}
 	}
+	if len(m.Exited) > 0 {
+		for _, e := range m.Exited {
+			l = e.Size()
+			n += 1 + l + sovAgent(uint64(l))
+		}
+	}
+	if m.Delta {
+		n += 2
+	}
 	return n
 }
 
@@ -2978,6 +3400,12 @@ func (m *CollectorContainer) Size() (n int) {
 		l = m.Host.Size()
 		n += 1 + l + sovAgent(uint64(l))
 	}
+	if len(m.HostTags) > 0 {
+		for _, s := range m.HostTags {
+			l = len(s)
+			n += 1 + l + sovAgent(uint64(l))
+		}
+	}
 	return n
 }
 
@@ -3101,16 +3529,37 @@ func (m *Process) Size() (n int) {
 	if l > 0 {
 		n += 2 + l + sovAgent(uint64(l))
 	}
-	return n
-}
-
-func (m *Command) Size() (n int) {
-	var l int
-	_ = l
-	if len(m.Args) > 0 {
-		for _, s := range m.Args {
+	if m.NetNs != 0 {
+		n += 2 + sovAgent(uint64(m.NetNs))
+	}
+	if len(m.Services) > 0 {
+		for _, s := range m.Services {
 			l = len(s)
-			n += 1 + l + sovAgent(uint64(l))
+			n += 2 + l + sovAgent(uint64(l))
+		}
+	}
+	if m.Threads != 0 {
+		n += 2 + sovAgent(uint64(m.Threads))
+	}
+	if len(m.Env) > 0 {
+		for _, s := range m.Env {
+			l = len(s)
+			n += 2 + l + sovAgent(uint64(l))
+		}
+	}
+	if m.OpenConnections != 0 {
+		n += 2 + sovAgent(uint64(m.OpenConnections))
+	}
+	return n
+}
+
+func (m *Command) Size() (n int) {
+	var l int
+	_ = l
+	if len(m.Args) > 0 {
+		for _, s := range m.Args {
+			l = len(s)
+			n += 1 + l + sovAgent(uint64(l))
 		}
 	}
 	l = len(m.Cwd)
@@ -3252,6 +3701,25 @@ func (m *Container) Size() (n int) {
 			n += 2 + l + sovAgent(uint64(l))
 		}
 	}
+	if m.RestartCount != 0 {
+		n += 2 + sovAgent(uint64(m.RestartCount))
+	}
+	if m.OomKilled {
+		n += 3
+	}
+	l = len(m.ImageDigest)
+	if l > 0 {
+		n += 2 + l + sovAgent(uint64(l))
+	}
+	if m.CpuNrPeriods != 0 {
+		n += 2 + sovAgent(uint64(m.CpuNrPeriods))
+	}
+	if m.CpuNrThrottled != 0 {
+		n += 2 + sovAgent(uint64(m.CpuNrThrottled))
+	}
+	if m.CpuThrottledTime != 0 {
+		n += 2 + sovAgent(uint64(m.CpuThrottledTime))
+	}
 	return n
 }
 
@@ -3500,6 +3968,38 @@ func (m *Connection) Size() (n int) {
 	if m.PidCreateTime != 0 {
 		n += 1 + sovAgent(uint64(m.PidCreateTime))
 	}
+	l = len(m.RaddrHost)
+	if l > 0 {
+		n += 1 + l + sovAgent(uint64(l))
+	}
+	if m.TotalBytesSent != 0 {
+		n += 1 + sovAgent(uint64(m.TotalBytesSent))
+	}
+	if m.TotalBytesReceived != 0 {
+		n += 1 + sovAgent(uint64(m.TotalBytesReceived))
+	}
+	if m.TcpState != 0 {
+		n += 2 + sovAgent(uint64(m.TcpState))
+	}
+	if m.NetNs != 0 {
+		n += 2 + sovAgent(uint64(m.NetNs))
+	}
+	if m.ConnectionCount != 0 {
+		n += 2 + sovAgent(uint64(m.ConnectionCount))
+	}
+	l = len(m.ProcessName)
+	if l > 0 {
+		n += 2 + l + sovAgent(uint64(l))
+	}
+	if len(m.ProcessCmdline) > 0 {
+		for _, s := range m.ProcessCmdline {
+			l = len(s)
+			n += 2 + l + sovAgent(uint64(l))
+		}
+	}
+	if m.Listening {
+		n += 3
+	}
 	return n
 }
 
@@ -3687,6 +4187,28 @@ func (m *HostTags) Size() (n int) {
 	return n
 }
 
+func (m *CollectorManifest) Size() (n int) {
+	var l int
+	_ = l
+	l = len(m.HostName)
+	if l > 0 {
+		n += 1 + l + sovAgent(uint64(l))
+	}
+	if m.GroupId != 0 {
+		n += 1 + sovAgent(uint64(m.GroupId))
+	}
+	if m.GroupSize != 0 {
+		n += 1 + sovAgent(uint64(m.GroupSize))
+	}
+	if m.ItemCount != 0 {
+		n += 1 + sovAgent(uint64(m.ItemCount))
+	}
+	if m.BatchType != 0 {
+		n += 1 + sovAgent(uint64(m.BatchType))
+	}
+	return n
+}
+
 func sovAgent(x uint64) (n int) {
 	for {
 		n++
@@ -4204,6 +4726,92 @@ func (m *CollectorProc) Unmarshal(data []byte) error {
 				return err
 			}
 			iNdEx = postIndex
+		case 11:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field HostTags", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowAgent
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := data[iNdEx]
+				iNdEx++
+				stringLen |= (uint64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthAgent
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.HostTags = append(m.HostTags, string(data[iNdEx:postIndex]))
+			iNdEx = postIndex
+		case 12:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field TruncatedProcesses", wireType)
+			}
+			m.TruncatedProcesses = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowAgent
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := data[iNdEx]
+				iNdEx++
+				m.TruncatedProcesses |= (int32(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 13:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field FilteredProcesses", wireType)
+			}
+			m.FilteredProcesses = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowAgent
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := data[iNdEx]
+				iNdEx++
+				m.FilteredProcesses |= (int32(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 14:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field SampledOutProcesses", wireType)
+			}
+			m.SampledOutProcesses = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowAgent
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := data[iNdEx]
+				iNdEx++
+				m.SampledOutProcesses |= (int32(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
 		default:
 			iNdEx = preIndex
 			skippy, err := skipAgent(data[iNdEx:])
@@ -4385,6 +4993,25 @@ func (m *CollectorConnections) Unmarshal(data []byte) error {
 					break
 				}
 			}
+		case 7:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field TruncatedConnections", wireType)
+			}
+			m.TruncatedConnections = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowAgent
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := data[iNdEx]
+				iNdEx++
+				m.TruncatedConnections |= (int32(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
 		default:
 			iNdEx = preIndex
 			skippy, err := skipAgent(data[iNdEx:])
@@ -4640,6 +5267,57 @@ func (m *CollectorRealTime) Unmarshal(data []byte) error {
 				return err
 			}
 			iNdEx = postIndex
+		case 11:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Exited", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowAgent
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := data[iNdEx]
+				iNdEx++
+				msglen |= (int(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthAgent
+			}
+			postIndex := iNdEx + msglen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Exited = append(m.Exited, &ProcessStat{})
+			if err := m.Exited[len(m.Exited)-1].Unmarshal(data[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		case 12:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Delta", wireType)
+			}
+			var v int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowAgent
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := data[iNdEx]
+				iNdEx++
+				v |= (int(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			m.Delta = bool(v != 0)
 		default:
 			iNdEx = preIndex
 			skippy, err := skipAgent(data[iNdEx:])
@@ -4920,6 +5598,35 @@ func (m *CollectorContainer) Unmarshal(data []byte) error {
 				return err
 			}
 			iNdEx = postIndex
+		case 9:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field HostTags", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowAgent
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := data[iNdEx]
+				iNdEx++
+				stringLen |= (uint64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthAgent
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.HostTags = append(m.HostTags, string(data[iNdEx:postIndex]))
+			iNdEx = postIndex
 		default:
 			iNdEx = preIndex
 			skippy, err := skipAgent(data[iNdEx:])
@@ -5816,24 +6523,139 @@ func (m *Process) Unmarshal(data []byte) error {
 				m.ContainerByteKey = []byte{}
 			}
 			iNdEx = postIndex
-		default:
-			iNdEx = preIndex
-			skippy, err := skipAgent(data[iNdEx:])
-			if err != nil {
-				return err
+		case 20:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field NetNs", wireType)
 			}
-			if skippy < 0 {
-				return ErrInvalidLengthAgent
+			m.NetNs = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowAgent
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := data[iNdEx]
+				iNdEx++
+				m.NetNs |= (uint32(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
 			}
-			if (iNdEx + skippy) > l {
-				return io.ErrUnexpectedEOF
+		case 21:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Services", wireType)
 			}
-			iNdEx += skippy
-		}
-	}
-
-	if iNdEx > l {
-		return io.ErrUnexpectedEOF
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowAgent
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := data[iNdEx]
+				iNdEx++
+				stringLen |= (uint64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthAgent
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Services = append(m.Services, string(data[iNdEx:postIndex]))
+			iNdEx = postIndex
+		case 22:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Threads", wireType)
+			}
+			m.Threads = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowAgent
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := data[iNdEx]
+				iNdEx++
+				m.Threads |= (int32(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 23:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Env", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowAgent
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := data[iNdEx]
+				iNdEx++
+				stringLen |= (uint64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthAgent
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.Env = append(m.Env, string(data[iNdEx:postIndex]))
+			iNdEx = postIndex
+		case 24:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field OpenConnections", wireType)
+			}
+			m.OpenConnections = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowAgent
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := data[iNdEx]
+				iNdEx++
+				m.OpenConnections |= (int32(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		default:
+			iNdEx = preIndex
+			skippy, err := skipAgent(data[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthAgent
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
 	}
 	return nil
 }
@@ -6784,6 +7606,131 @@ func (m *Container) Unmarshal(data []byte) error {
 			}
 			m.Tags = append(m.Tags, string(data[iNdEx:postIndex]))
 			iNdEx = postIndex
+		case 27:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field RestartCount", wireType)
+			}
+			m.RestartCount = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowAgent
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := data[iNdEx]
+				iNdEx++
+				m.RestartCount |= (int32(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 28:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field OomKilled", wireType)
+			}
+			var v int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowAgent
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := data[iNdEx]
+				iNdEx++
+				v |= (int(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			m.OomKilled = bool(v != 0)
+		case 29:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field ImageDigest", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowAgent
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := data[iNdEx]
+				iNdEx++
+				stringLen |= (uint64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthAgent
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.ImageDigest = string(data[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 30:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field CpuNrPeriods", wireType)
+			}
+			m.CpuNrPeriods = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowAgent
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := data[iNdEx]
+				iNdEx++
+				m.CpuNrPeriods |= (uint64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 31:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field CpuNrThrottled", wireType)
+			}
+			m.CpuNrThrottled = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowAgent
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := data[iNdEx]
+				iNdEx++
+				m.CpuNrThrottled |= (uint64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 32:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field CpuThrottledTime", wireType)
+			}
+			m.CpuThrottledTime = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowAgent
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := data[iNdEx]
+				iNdEx++
+				m.CpuThrottledTime |= (uint64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
 		default:
 			iNdEx = preIndex
 			skippy, err := skipAgent(data[iNdEx:])
@@ -8402,38 +9349,240 @@ func (m *Connection) Unmarshal(data []byte) error {
 					break
 				}
 			}
-		default:
-			iNdEx = preIndex
-			skippy, err := skipAgent(data[iNdEx:])
-			if err != nil {
-				return err
+		case 13:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field RaddrHost", wireType)
 			}
-			if skippy < 0 {
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowAgent
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := data[iNdEx]
+				iNdEx++
+				stringLen |= (uint64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
 				return ErrInvalidLengthAgent
 			}
-			if (iNdEx + skippy) > l {
+			postIndex := iNdEx + intStringLen
+			if postIndex > l {
 				return io.ErrUnexpectedEOF
 			}
-			iNdEx += skippy
-		}
-	}
-
-	if iNdEx > l {
-		return io.ErrUnexpectedEOF
-	}
-	return nil
-}
-func (m *Addr) Unmarshal(data []byte) error {
-	l := len(data)
-	iNdEx := 0
-	for iNdEx < l {
-		preIndex := iNdEx
-		var wire uint64
-		for shift := uint(0); ; shift += 7 {
-			if shift >= 64 {
-				return ErrIntOverflowAgent
-			}
-			if iNdEx >= l {
+			m.RaddrHost = string(data[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 14:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field TotalBytesSent", wireType)
+			}
+			m.TotalBytesSent = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowAgent
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := data[iNdEx]
+				iNdEx++
+				m.TotalBytesSent |= (uint64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 15:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field TotalBytesReceived", wireType)
+			}
+			m.TotalBytesReceived = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowAgent
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := data[iNdEx]
+				iNdEx++
+				m.TotalBytesReceived |= (uint64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 16:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field TcpState", wireType)
+			}
+			m.TcpState = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowAgent
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := data[iNdEx]
+				iNdEx++
+				m.TcpState |= (TCPState(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 17:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field NetNs", wireType)
+			}
+			m.NetNs = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowAgent
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := data[iNdEx]
+				iNdEx++
+				m.NetNs |= (uint32(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 18:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field ConnectionCount", wireType)
+			}
+			m.ConnectionCount = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowAgent
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := data[iNdEx]
+				iNdEx++
+				m.ConnectionCount |= (uint32(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 19:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field ProcessName", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowAgent
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := data[iNdEx]
+				iNdEx++
+				stringLen |= (uint64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthAgent
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.ProcessName = string(data[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 20:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field ProcessCmdline", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowAgent
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := data[iNdEx]
+				iNdEx++
+				stringLen |= (uint64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthAgent
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.ProcessCmdline = append(m.ProcessCmdline, string(data[iNdEx:postIndex]))
+			iNdEx = postIndex
+		case 21:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Listening", wireType)
+			}
+			var v int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowAgent
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := data[iNdEx]
+				iNdEx++
+				v |= (int(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			m.Listening = bool(v != 0)
+		default:
+			iNdEx = preIndex
+			skippy, err := skipAgent(data[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthAgent
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+func (m *Addr) Unmarshal(data []byte) error {
+	l := len(data)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowAgent
+			}
+			if iNdEx >= l {
 				return io.ErrUnexpectedEOF
 			}
 			b := data[iNdEx]
@@ -9661,6 +10810,161 @@ func (m *HostTags) Unmarshal(data []byte) error {
 	}
 	return nil
 }
+func (m *CollectorManifest) Unmarshal(data []byte) error {
+	l := len(data)
+	iNdEx := 0
+	for iNdEx < l {
+		preIndex := iNdEx
+		var wire uint64
+		for shift := uint(0); ; shift += 7 {
+			if shift >= 64 {
+				return ErrIntOverflowAgent
+			}
+			if iNdEx >= l {
+				return io.ErrUnexpectedEOF
+			}
+			b := data[iNdEx]
+			iNdEx++
+			wire |= (uint64(b) & 0x7F) << shift
+			if b < 0x80 {
+				break
+			}
+		}
+		fieldNum := int32(wire >> 3)
+		wireType := int(wire & 0x7)
+		if wireType == 4 {
+			return fmt.Errorf("proto: CollectorManifest: wiretype end group for non-group")
+		}
+		if fieldNum <= 0 {
+			return fmt.Errorf("proto: CollectorManifest: illegal tag %d (wire type %d)", fieldNum, wire)
+		}
+		switch fieldNum {
+		case 1:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field HostName", wireType)
+			}
+			var stringLen uint64
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowAgent
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := data[iNdEx]
+				iNdEx++
+				stringLen |= (uint64(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			intStringLen := int(stringLen)
+			if intStringLen < 0 {
+				return ErrInvalidLengthAgent
+			}
+			postIndex := iNdEx + intStringLen
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			m.HostName = string(data[iNdEx:postIndex])
+			iNdEx = postIndex
+		case 2:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field GroupId", wireType)
+			}
+			m.GroupId = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowAgent
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := data[iNdEx]
+				iNdEx++
+				m.GroupId |= (int32(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 3:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field GroupSize", wireType)
+			}
+			m.GroupSize = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowAgent
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := data[iNdEx]
+				iNdEx++
+				m.GroupSize |= (int32(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 4:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field ItemCount", wireType)
+			}
+			m.ItemCount = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowAgent
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := data[iNdEx]
+				iNdEx++
+				m.ItemCount |= (int32(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		case 5:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field BatchType", wireType)
+			}
+			m.BatchType = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowAgent
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := data[iNdEx]
+				iNdEx++
+				m.BatchType |= (int32(b) & 0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+		default:
+			iNdEx = preIndex
+			skippy, err := skipAgent(data[iNdEx:])
+			if err != nil {
+				return err
+			}
+			if skippy < 0 {
+				return ErrInvalidLengthAgent
+			}
+			if (iNdEx + skippy) > l {
+				return io.ErrUnexpectedEOF
+			}
+			iNdEx += skippy
+		}
+	}
+
+	if iNdEx > l {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
 func skipAgent(data []byte) (n int, err error) {
 	l := len(data)
 	iNdEx := 0
@@ -9769,163 +11073,195 @@ var (
 func init() { proto.RegisterFile("agent.proto", fileDescriptorAgent) }
 
 var fileDescriptorAgent = []byte{
-	// 2515 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xac, 0x59, 0xcd, 0x73, 0x1d, 0x47,
-	0x11, 0xd7, 0xee, 0xdb, 0xf7, 0xd5, 0xd2, 0x93, 0xd7, 0x63, 0xc5, 0xd9, 0x28, 0x41, 0x28, 0x4b,
-	0x08, 0x42, 0x55, 0x96, 0x8d, 0x13, 0x5c, 0x76, 0xa0, 0x9c, 0x60, 0x19, 0x63, 0x55, 0x62, 0x47,
-	0x35, 0x92, 0x09, 0x15, 0x0e, 0xa9, 0xd5, 0xee, 0xf8, 0x69, 0xcb, 0x6f, 0x3f, 0xd8, 0x0f, 0xc9,
-	0x2f, 0x27, 0xfe, 0x84, 0x5c, 0x38, 0xe4, 0xc8, 0x81, 0x03, 0x55, 0xdc, 0xf9, 0x0f, 0x28, 0x0a,
-	0x2e, 0x14, 0x37, 0x6e, 0x29, 0x53, 0xb9, 0xf0, 0x57, 0x50, 0xdd, 0x33, 0xfb, 0xf5, 0xbe, 0xf4,
-	0x01, 0xa7, 0xd7, 0xdd, 0xd3, 0x3d, 0x33, 0x3b, 0xdd, 0xbf, 0xee, 0x9e, 0x79, 0xb0, 0xec, 0x0c,
-	0x45, 0x98, 0xed, 0xc4, 0x49, 0x94, 0x45, 0xec, 0x35, 0xcf, 0xc9, 0x1c, 0x2f, 0x1a, 0x22, 0xeb,
-	0x8a, 0x34, 0xfd, 0x82, 0x06, 0xd7, 0xdf, 0x1f, 0xfa, 0xd9, 0x71, 0x7e, 0xb4, 0xe3, 0x46, 0xc1,
-	0xcd, 0x87, 0x4e, 0xe6, 0x3c, 0x8c, 0x86, 0x37, 0x69, 0xe4, 0x46, 0xec, 0x8c, 0x47, 0x91, 0xe3,
-	0x49, 0xee, 0x0b, 0xc5, 0xc9, 0xc9, 0xec, 0xbf, 0x69, 0xb0, 0xc2, 0x45, 0xba, 0x1b, 0x8d, 0x46,
-	0xc2, 0xcd, 0xa2, 0x84, 0x3d, 0x80, 0xce, 0xb1, 0x70, 0x3c, 0x91, 0x58, 0xda, 0xa6, 0xb6, 0xb5,
-	0x7c, 0x7b, 0x7b, 0x67, 0xe6, 0x72, 0x3b, 0x75, 0xa3, 0x9d, 0xc7, 0x64, 0xc1, 0x95, 0x25, 0xb3,
-	0xa0, 0x1b, 0x88, 0x34, 0x75, 0x86, 0xc2, 0xd2, 0x37, 0xb5, 0xad, 0x3e, 0x2f, 0x58, 0x76, 0x1f,
-	0x3a, 0x69, 0xe6, 0x64, 0x79, 0x6a, 0xb5, 0x68, 0xf6, 0x77, 0xe7, 0xcc, 0x5e, 0x4e, 0x7d, 0x40,
-	0xda, 0x5c, 0x59, 0xad, 0xbf, 0x05, 0x1d, 0xb9, 0x16, 0x63, 0x60, 0x64, 0xe3, 0x58, 0x58, 0xc6,
-	0xa6, 0xb6, 0xd5, 0xe6, 0x44, 0xdb, 0xff, 0x6c, 0xc1, 0xa0, 0xb4, 0xdc, 0x4f, 0x22, 0x97, 0xad,
-	0x43, 0xef, 0x38, 0x4a, 0xb3, 0xa7, 0x4e, 0x50, 0x6c, 0xa5, 0xe4, 0xd9, 0x4f, 0xa1, 0xaf, 0x16,
-	0x15, 0xb8, 0x9d, 0xd6, 0xd6, 0xf2, 0xed, 0x8d, 0x39, 0xdb, 0xd9, 0x97, 0x1c, 0xaf, 0x0c, 0xd8,
-	0x4d, 0x30, 0x70, 0x26, 0x5a, 0x7f, 0xf9, 0xf6, 0x9b, 0x73, 0x0c, 0x1f, 0x47, 0x69, 0xc6, 0x49,
-	0x91, 0xfd, 0x18, 0x0c, 0x3f, 0x7c, 0x1e, 0x59, 0x6d, 0x32, 0x78, 0x7b, 0x8e, 0xc1, 0xc1, 0x38,
-	0xcd, 0x44, 0xb0, 0x17, 0x3e, 0x8f, 0x38, 0xa9, 0xe3, 0x59, 0x0e, 0x93, 0x28, 0x8f, 0xf7, 0x3c,
-	0xab, 0x43, 0x9f, 0x5a, 0xb0, 0xec, 0x2d, 0xe8, 0x13, 0x79, 0xe0, 0x7f, 0x29, 0xac, 0x2e, 0x8d,
-	0x55, 0x02, 0xb6, 0x07, 0xf0, 0x22, 0x3f, 0x12, 0x49, 0x28, 0x32, 0x91, 0x5a, 0x3d, 0x5a, 0xf4,
-	0x87, 0xe5, 0xa2, 0xb4, 0x58, 0x11, 0x09, 0x1f, 0xe7, 0x47, 0xe2, 0x89, 0xc8, 0x1c, 0x1c, 0xdc,
-	0x97, 0x32, 0x5e, 0x33, 0x66, 0x1f, 0x40, 0x4b, 0xb8, 0xa9, 0xd5, 0xa7, 0x39, 0xb6, 0x66, 0xcf,
-	0xf1, 0xf3, 0xdd, 0x83, 0xc9, 0x29, 0xd0, 0x88, 0x7d, 0x04, 0xe0, 0x46, 0x61, 0xe6, 0xf8, 0xa1,
-	0x48, 0x52, 0x0b, 0xe8, 0x94, 0x37, 0xe7, 0x3a, 0x5d, 0x29, 0xf2, 0x9a, 0x8d, 0xfd, 0x8d, 0x06,
-	0x6b, 0xa5, 0x53, 0x77, 0xa3, 0x30, 0x14, 0x6e, 0xe6, 0x47, 0x61, 0xba, 0xd0, 0xb7, 0xbb, 0xb0,
-	0xec, 0x56, 0xaa, 0xca, 0xbb, 0x6f, 0xcf, 0x5f, 0x57, 0x69, 0xf2, 0xba, 0xd5, 0xc5, 0x5d, 0x5c,
-	0xf3, 0x55, 0x7b, 0x81, 0xaf, 0x3a, 0x13, 0xbe, 0xb2, 0xff, 0xa5, 0xc3, 0xd5, 0xf2, 0x13, 0xb9,
-	0x70, 0x46, 0x87, 0x7e, 0x20, 0x16, 0x7e, 0xdf, 0x5d, 0x68, 0x23, 0x22, 0x8a, 0x2f, 0xb3, 0x17,
-	0xc7, 0x2d, 0x82, 0x88, 0x4b, 0x03, 0x76, 0x1d, 0x3a, 0x38, 0xcb, 0x9e, 0xa7, 0x90, 0xa3, 0x38,
-	0xb6, 0x06, 0xed, 0x28, 0x19, 0x96, 0x3b, 0x97, 0xcc, 0xa5, 0xa3, 0xcf, 0x82, 0x6e, 0x98, 0x07,
-	0xbb, 0x71, 0x2e, 0x43, 0xaf, 0xcd, 0x0b, 0x96, 0x6d, 0xc2, 0x72, 0x16, 0x65, 0xce, 0xe8, 0x89,
-	0x08, 0xa2, 0x64, 0x4c, 0x41, 0xd5, 0xe2, 0x75, 0x11, 0xfb, 0x04, 0x56, 0x4b, 0xf7, 0x1f, 0xd0,
-	0x47, 0xca, 0xb0, 0x79, 0xe7, 0xac, 0xb0, 0xa1, 0xcf, 0x9c, 0xb0, 0xb5, 0xbf, 0x6e, 0x01, 0xab,
-	0x87, 0x8f, 0x1c, 0x6b, 0x1c, 0xae, 0x36, 0x71, 0xb8, 0x05, 0x52, 0xf5, 0x8b, 0x21, 0xb5, 0x19,
-	0xea, 0xad, 0x8b, 0x87, 0x7a, 0xfd, 0xb4, 0x8d, 0x05, 0xa7, 0xdd, 0x5e, 0x8c, 0xf5, 0xce, 0xff,
-	0x01, 0xeb, 0xdd, 0xcb, 0x60, 0xbd, 0xc0, 0x4b, 0xef, 0x9c, 0x78, 0xb1, 0x7f, 0xab, 0xc3, 0xfa,
-	0xb4, 0x6f, 0x66, 0x02, 0x60, 0xd2, 0x47, 0x1f, 0x14, 0x00, 0xd0, 0x2f, 0x10, 0x1b, 0x0a, 0x02,
-	0xb5, 0xe0, 0x6c, 0x2d, 0x0c, 0x4e, 0x63, 0x3a, 0x38, 0x2b, 0xf8, 0xb4, 0x1b, 0xf0, 0xb9, 0x24,
-	0x50, 0xec, 0x5b, 0xb5, 0xe8, 0xe4, 0xe2, 0x37, 0xb2, 0xdc, 0x2d, 0x82, 0xbe, 0x7d, 0x00, 0x57,
-	0x26, 0xaa, 0x23, 0x7b, 0x07, 0x06, 0x8e, 0x9b, 0xf9, 0x27, 0x62, 0x77, 0xe4, 0x8b, 0x30, 0x4b,
-	0xe9, 0xb4, 0xda, 0xbc, 0x29, 0xc4, 0x49, 0xfd, 0x30, 0x13, 0xc9, 0x89, 0x33, 0xa2, 0x49, 0xdb,
-	0xbc, 0xe4, 0xed, 0x3f, 0x76, 0xa0, 0xab, 0x92, 0x05, 0x33, 0xa1, 0xf5, 0x42, 0x8c, 0x69, 0x8e,
-	0x01, 0x47, 0x12, 0x25, 0xb1, 0xef, 0x29, 0x23, 0x24, 0x4b, 0x57, 0xb7, 0xce, 0x9b, 0x1a, 0xef,
-	0x42, 0xd7, 0x8d, 0x82, 0xc0, 0x09, 0x3d, 0x95, 0x4e, 0x37, 0xe6, 0x7a, 0x8c, 0xb4, 0x78, 0xa1,
-	0xce, 0xee, 0x80, 0x91, 0xa7, 0x22, 0x51, 0x75, 0xf3, 0x8c, 0x4c, 0xf7, 0x2c, 0x15, 0x09, 0x27,
-	0x7d, 0x76, 0x0f, 0x3a, 0x81, 0x74, 0x63, 0x77, 0x21, 0x8e, 0xa5, 0x63, 0x29, 0x3e, 0x94, 0x01,
-	0xbb, 0x05, 0x2d, 0x37, 0xce, 0x55, 0x1c, 0xcf, 0xdd, 0xe8, 0xfe, 0x33, 0x32, 0x42, 0x55, 0xb6,
-	0x01, 0xe0, 0x26, 0xc2, 0xc9, 0x04, 0x06, 0xae, 0x4a, 0x6a, 0x35, 0x09, 0xbb, 0x0f, 0xfd, 0x12,
-	0xe7, 0x16, 0xd0, 0xbc, 0x67, 0xa7, 0x86, 0xca, 0x04, 0x03, 0x33, 0x8a, 0x45, 0xf8, 0xc8, 0xdb,
-	0x8d, 0xf2, 0x30, 0xb3, 0x96, 0xc9, 0x13, 0x75, 0x11, 0xbb, 0x27, 0x01, 0x21, 0xac, 0x95, 0x4d,
-	0x6d, 0x6b, 0xf5, 0xf6, 0xf7, 0xce, 0xae, 0x08, 0x42, 0xe2, 0x01, 0xf3, 0x5d, 0xc7, 0x8f, 0x50,
-	0x62, 0x0d, 0x68, 0x67, 0xdf, 0x99, 0x63, 0xbb, 0xf7, 0xa9, 0x3c, 0x25, 0xa9, 0x8c, 0x7b, 0x2a,
-	0x37, 0xb8, 0xe7, 0x59, 0xab, 0x14, 0xa7, 0x75, 0x11, 0xb3, 0x61, 0xa5, 0x64, 0x3f, 0x16, 0x63,
-	0xeb, 0x0a, 0x85, 0x54, 0x43, 0xc6, 0x6e, 0xc3, 0xda, 0x49, 0x34, 0xca, 0xc3, 0xcc, 0x49, 0xc6,
-	0xbb, 0xd9, 0xcb, 0x83, 0x53, 0x3f, 0x73, 0x8f, 0x45, 0x6a, 0x99, 0x9b, 0xda, 0x96, 0xc1, 0x67,
-	0x8e, 0xb1, 0x3b, 0x70, 0xdd, 0x0f, 0x67, 0x5a, 0x5d, 0x25, 0xab, 0x39, 0xa3, 0x08, 0xd2, 0xa3,
-	0x71, 0x26, 0x70, 0x2b, 0x6c, 0x53, 0xdb, 0x5a, 0xe1, 0x05, 0xcb, 0xb6, 0xc1, 0x2c, 0x77, 0xf5,
-	0x40, 0xa9, 0x5c, 0x23, 0x95, 0x29, 0xb9, 0xfd, 0xb5, 0x06, 0x5d, 0x15, 0xa5, 0xd8, 0x85, 0x3a,
-	0xc9, 0x10, 0x01, 0xd7, 0xda, 0xea, 0x73, 0xa2, 0x11, 0x2d, 0xee, 0xa9, 0x47, 0xd0, 0xe8, 0x73,
-	0x24, 0x51, 0x2b, 0x89, 0x22, 0xd9, 0x48, 0xf4, 0x39, 0xd1, 0x98, 0x48, 0xa2, 0xf0, 0xa1, 0x9f,
-	0xbe, 0xa0, 0xc0, 0xee, 0x71, 0xc5, 0xa1, 0x6e, 0x8c, 0x60, 0x93, 0x59, 0x84, 0x68, 0xd4, 0x8d,
-	0x29, 0x65, 0xa8, 0xfc, 0xa1, 0x38, 0x5c, 0x49, 0xbc, 0x14, 0x14, 0xa7, 0x7d, 0x8e, 0xa4, 0xfd,
-	0x3b, 0x0d, 0x96, 0x6b, 0x50, 0xc0, 0xd9, 0xc2, 0x2a, 0x7d, 0x12, 0x8d, 0x56, 0x79, 0x85, 0xe6,
-	0xdc, 0xf7, 0x50, 0x32, 0xf4, 0x3d, 0x95, 0x0c, 0x91, 0x44, 0x3b, 0x81, 0x4a, 0xaa, 0xbb, 0x46,
-	0x9a, 0x64, 0xa8, 0xd6, 0x56, 0x32, 0xa5, 0x97, 0xe6, 0xd5, 0x6e, 0x53, 0xa5, 0x97, 0xa2, 0x5e,
-	0x57, 0xc9, 0x86, 0xbe, 0x67, 0x7f, 0xdb, 0x86, 0x7e, 0x55, 0x7c, 0x8b, 0xde, 0x5d, 0xed, 0x0a,
-	0x69, 0xb6, 0x0a, 0xba, 0xda, 0x54, 0x9f, 0xeb, 0x72, 0x16, 0xda, 0x79, 0xab, 0xb6, 0xf3, 0x35,
-	0x68, 0xfb, 0x01, 0xde, 0x2a, 0xe4, 0x41, 0x4a, 0x06, 0xf3, 0x9a, 0x1b, 0xe7, 0x9f, 0xf8, 0x81,
-	0x9f, 0xd1, 0xde, 0x74, 0x5e, 0xf2, 0x18, 0xa3, 0x12, 0xd3, 0x72, 0xb8, 0x43, 0xe1, 0x51, 0x17,
-	0xb1, 0x9f, 0x14, 0xb8, 0xe9, 0x11, 0x6e, 0xbe, 0x7f, 0x9e, 0x42, 0x52, 0x22, 0xe7, 0x3e, 0x5d,
-	0x96, 0x46, 0xd9, 0x31, 0x41, 0x7e, 0x75, 0xc1, 0x75, 0x46, 0x59, 0x3f, 0x26, 0x6d, 0xae, 0xac,
-	0x30, 0x20, 0x65, 0x92, 0xf0, 0x28, 0x29, 0xb4, 0x78, 0xc1, 0x52, 0xc8, 0x1c, 0xc5, 0x29, 0x21,
-	0x5d, 0xe7, 0x44, 0xa3, 0xec, 0x14, 0x65, 0x2b, 0x52, 0x86, 0x74, 0x91, 0xac, 0x07, 0x55, 0xb2,
-	0x7e, 0x0b, 0xfa, 0xa1, 0xc8, 0xb8, 0x7b, 0xe2, 0xed, 0xa7, 0x04, 0x4a, 0x9d, 0x57, 0x02, 0x35,
-	0x7a, 0x20, 0xc2, 0x6c, 0x3f, 0x25, 0x3c, 0xca, 0x51, 0x29, 0xc0, 0x34, 0xa6, 0x54, 0x1f, 0xc4,
-	0x12, 0x82, 0x3a, 0xaf, 0x49, 0xd4, 0x38, 0x2a, 0xe3, 0xf8, 0xd5, 0x72, 0x5c, 0x49, 0xf0, 0x7b,
-	0x30, 0xf7, 0xee, 0xbb, 0x19, 0x01, 0x4c, 0xe7, 0x05, 0x8b, 0xeb, 0xa6, 0xd4, 0x30, 0xe1, 0xd8,
-	0x35, 0xb9, 0x6e, 0x29, 0x40, 0x17, 0x52, 0x91, 0xc5, 0xc1, 0x35, 0xe9, 0xc2, 0x82, 0xc7, 0xe0,
-	0x0f, 0x44, 0xc0, 0xd3, 0xd4, 0x7a, 0x8d, 0xbc, 0xa7, 0x38, 0xb4, 0x09, 0x44, 0xb0, 0xeb, 0xb8,
-	0xc7, 0xc2, 0xba, 0x4e, 0x23, 0x25, 0x5f, 0x96, 0xa7, 0xd7, 0x2f, 0xd0, 0xb9, 0xa7, 0x99, 0x93,
-	0xa0, 0x23, 0x2c, 0xe9, 0x08, 0xc5, 0xd6, 0x73, 0xc6, 0x1b, 0xcd, 0x9c, 0x81, 0x51, 0xec, 0x0c,
-	0x53, 0x6b, 0x5d, 0x62, 0x1f, 0x69, 0xfb, 0xcf, 0xbd, 0x12, 0x7f, 0x94, 0x23, 0x55, 0xe5, 0xd4,
-	0xaa, 0xca, 0xd9, 0xac, 0x14, 0xfa, 0x54, 0xa5, 0xa8, 0xca, 0x56, 0xeb, 0x92, 0x65, 0xcb, 0x38,
-	0x7f, 0xd9, 0x42, 0x90, 0xf9, 0x6e, 0xd1, 0x51, 0x12, 0x8d, 0x1f, 0x9c, 0x1d, 0x27, 0xc2, 0xf1,
-	0x52, 0x85, 0xe0, 0x82, 0x9d, 0x2c, 0x42, 0xbd, 0xe9, 0x22, 0xa4, 0xa2, 0xb1, 0x5f, 0x45, 0xe3,
-	0x44, 0x91, 0x80, 0xe9, 0x22, 0xf1, 0x64, 0xa2, 0xdd, 0x17, 0x14, 0xf3, 0xe7, 0x46, 0xe2, 0x84,
-	0x31, 0xfb, 0x05, 0xac, 0xc4, 0xb5, 0x1a, 0x77, 0x91, 0x72, 0xd8, 0x30, 0x64, 0xfb, 0x70, 0xc5,
-	0x6d, 0xc2, 0x96, 0xf0, 0x72, 0x7e, 0x90, 0x4f, 0x9a, 0x63, 0x9b, 0x56, 0x8a, 0xf8, 0x51, 0x09,
-	0xb0, 0xa6, 0xb0, 0xa1, 0xf5, 0xd9, 0x51, 0x09, 0xb3, 0xa6, 0x70, 0xaa, 0xb4, 0xb2, 0x19, 0xa5,
-	0xb5, 0xaa, 0xeb, 0xd7, 0x2e, 0x52, 0xd7, 0x77, 0x80, 0x95, 0xd3, 0x3c, 0x2d, 0x33, 0x89, 0x84,
-	0xe5, 0x8c, 0x91, 0x49, 0x7d, 0x95, 0x5b, 0x5e, 0x9b, 0xd6, 0x57, 0x49, 0xe6, 0x16, 0x5c, 0x9b,
-	0x9c, 0x05, 0xb3, 0xc9, 0x75, 0x32, 0x98, 0x35, 0x34, 0x69, 0x51, 0xe4, 0x9f, 0xd7, 0xa7, 0x2d,
-	0x8a, 0x44, 0x34, 0xaf, 0xab, 0xb0, 0x2e, 0xd5, 0x55, 0xbc, 0x71, 0xde, 0xae, 0x62, 0xfd, 0xec,
-	0xae, 0xe2, 0xcd, 0x39, 0x5d, 0xc5, 0x5f, 0x0c, 0x18, 0x34, 0x42, 0x5b, 0x55, 0x44, 0xad, 0xac,
-	0x88, 0xb5, 0xe4, 0xaa, 0x2f, 0x48, 0xae, 0xad, 0x45, 0xc9, 0xd5, 0x98, 0x48, 0xae, 0x8b, 0x6a,
-	0x67, 0x95, 0x78, 0x3b, 0x73, 0x13, 0x6f, 0x77, 0x22, 0xf1, 0xca, 0x31, 0x39, 0x5f, 0xaf, 0x1c,
-	0x93, 0xf3, 0x15, 0x25, 0xad, 0x3f, 0xa3, 0xa4, 0x41, 0xad, 0xa4, 0x35, 0x0a, 0xd8, 0xf2, 0xc2,
-	0x02, 0xb6, 0xb2, 0xb8, 0x80, 0x0d, 0xce, 0x28, 0x60, 0xab, 0x53, 0x05, 0xac, 0xec, 0x06, 0xae,
-	0xfc, 0x4f, 0xdd, 0x80, 0x79, 0xa9, 0x6e, 0x40, 0x65, 0xcf, 0xab, 0x55, 0xf6, 0xac, 0x95, 0x25,
-	0x36, 0xb7, 0x2c, 0x5d, 0x6b, 0x04, 0x9d, 0xfd, 0x07, 0x0d, 0xa0, 0x7a, 0x9b, 0xc0, 0x13, 0xce,
-	0xf3, 0x32, 0x8e, 0x88, 0x66, 0x37, 0x40, 0x8f, 0x52, 0xf5, 0xbc, 0x31, 0x2f, 0x29, 0x7c, 0x7a,
-	0x40, 0x4f, 0x1b, 0x7a, 0x84, 0x60, 0x32, 0x5c, 0x79, 0x59, 0x6e, 0x2d, 0x2e, 0x2c, 0xf2, 0x31,
-	0xc4, 0x9d, 0x71, 0x93, 0x6e, 0x4f, 0xdd, 0xa4, 0xed, 0xaf, 0x34, 0xe8, 0xc8, 0x45, 0x66, 0x76,
-	0xa9, 0xeb, 0xd0, 0x8b, 0x47, 0x4e, 0xf6, 0x3c, 0x4a, 0x82, 0xe2, 0x0a, 0x5c, 0xf0, 0x18, 0x99,
-	0xcf, 0x9d, 0xc0, 0x1f, 0x8d, 0x55, 0x77, 0xa8, 0x38, 0x3c, 0x94, 0x13, 0x91, 0xa4, 0x7e, 0x14,
-	0xaa, 0x0e, 0xb1, 0x60, 0x31, 0xa9, 0xbe, 0x10, 0x49, 0x28, 0x46, 0xbf, 0x54, 0xe3, 0x6d, 0x1a,
-	0x6f, 0x0a, 0x69, 0x4b, 0x32, 0x19, 0xe2, 0xf2, 0x58, 0xf4, 0x38, 0xc6, 0x82, 0x26, 0x81, 0x51,
-	0xf0, 0x18, 0x82, 0xa7, 0x89, 0x9f, 0x09, 0x1a, 0x94, 0x50, 0xac, 0x04, 0xb8, 0x14, 0x6a, 0x22,
-	0xae, 0x53, 0xd2, 0x90, 0x80, 0x6c, 0x0a, 0xd9, 0xbb, 0xb0, 0x4a, 0x26, 0x95, 0x9a, 0x84, 0xe6,
-	0x84, 0xd4, 0xfe, 0x8f, 0x0e, 0x50, 0xbd, 0x4f, 0xce, 0xe8, 0x27, 0x7e, 0x04, 0xed, 0x91, 0xe3,
-	0x79, 0xc5, 0xfd, 0x78, 0x5e, 0xaf, 0xf3, 0x33, 0xcf, 0x4b, 0xb8, 0xd4, 0x44, 0x93, 0x84, 0x4c,
-	0x3a, 0xe7, 0x30, 0x21, 0x4d, 0xfc, 0x64, 0x8c, 0xaf, 0x14, 0x71, 0x42, 0xc0, 0xd6, 0x79, 0x25,
-	0xc0, 0x4f, 0x26, 0x86, 0x0b, 0xd7, 0x17, 0x27, 0xc2, 0x53, 0x10, 0x6f, 0x0a, 0xd9, 0x87, 0xa5,
-	0xd7, 0x80, 0xe0, 0xf1, 0x83, 0x33, 0x9f, 0x63, 0x1f, 0x91, 0x7a, 0xe9, 0xde, 0x7b, 0xea, 0xda,
-	0x70, 0x66, 0x7f, 0xa0, 0xcc, 0x0f, 0xc7, 0xb1, 0x50, 0xb7, 0x8b, 0x77, 0x60, 0x10, 0xfb, 0xde,
-	0x6e, 0xd5, 0x78, 0xad, 0x50, 0x40, 0x36, 0x85, 0xf6, 0xaf, 0xc1, 0xc0, 0x8f, 0x2e, 0xdb, 0x47,
-	0xed, 0xbc, 0xed, 0x23, 0xa6, 0xea, 0xb8, 0xbc, 0xbc, 0xc4, 0x74, 0x89, 0x8b, 0x92, 0x4c, 0xdd,
-	0xa8, 0x88, 0xb6, 0xff, 0xa4, 0x01, 0x54, 0x4d, 0x1b, 0x7a, 0x32, 0x49, 0xe5, 0x4b, 0x8d, 0xc1,
-	0x91, 0x44, 0xc9, 0x49, 0x20, 0x61, 0x69, 0x70, 0x24, 0xe9, 0x26, 0x75, 0xea, 0xc4, 0x34, 0x8d,
-	0xc1, 0x89, 0xc6, 0xd8, 0x4f, 0x8f, 0x9d, 0x44, 0xc8, 0xbb, 0x99, 0xc1, 0x15, 0x47, 0xdd, 0xa8,
-	0x78, 0x29, 0xb3, 0xb8, 0xc1, 0x89, 0xc6, 0x19, 0x47, 0xfe, 0x91, 0x4a, 0xdf, 0x48, 0xa2, 0x16,
-	0x7e, 0x8c, 0xca, 0xdb, 0x44, 0xe3, 0xad, 0xca, 0xf3, 0x93, 0x6c, 0xac, 0x12, 0xb6, 0x64, 0xec,
-	0xdf, 0xeb, 0xd0, 0x55, 0xbd, 0x22, 0xe2, 0x6a, 0xe4, 0xa4, 0xd9, 0x6e, 0x9c, 0x2b, 0x88, 0x16,
-	0x6c, 0xa3, 0xb6, 0xe8, 0x13, 0xb5, 0xa5, 0x56, 0xaf, 0x5a, 0x0b, 0xea, 0x95, 0x31, 0x59, 0xaf,
-	0x30, 0x47, 0xe7, 0xc1, 0xa1, 0xea, 0x41, 0x65, 0x6b, 0x5a, 0x93, 0xb0, 0xbb, 0x2a, 0x1d, 0x75,
-	0x16, 0xbe, 0xfc, 0x1d, 0xf8, 0xe1, 0x70, 0x24, 0x8a, 0x6e, 0x57, 0x26, 0xa5, 0xa2, 0xdd, 0xed,
-	0xd6, 0xda, 0xdd, 0x75, 0xe8, 0xe1, 0xb6, 0x28, 0x28, 0x7a, 0x14, 0x14, 0x25, 0x8f, 0x3b, 0x91,
-	0xdb, 0xaa, 0xbf, 0xea, 0x54, 0x12, 0xfb, 0x43, 0x18, 0x34, 0x96, 0x99, 0x97, 0xc8, 0xe6, 0x1d,
-	0x91, 0xfd, 0xad, 0x46, 0x87, 0x4c, 0x49, 0xf0, 0x3a, 0x74, 0xc2, 0x3c, 0x38, 0x52, 0x7f, 0xbc,
-	0xb5, 0xb9, 0xe2, 0x50, 0x7e, 0x22, 0x42, 0x2f, 0x4a, 0x54, 0x7c, 0x29, 0x6e, 0x6e, 0x12, 0x5c,
-	0x83, 0x76, 0x10, 0x79, 0x62, 0x54, 0x5c, 0x92, 0x89, 0xc1, 0x4f, 0x89, 0x8f, 0xc7, 0xa9, 0xef,
-	0x3a, 0x23, 0xf5, 0x76, 0xd9, 0xe7, 0x35, 0x09, 0xce, 0xe6, 0x46, 0x89, 0x50, 0xcf, 0x97, 0x7d,
-	0xae, 0x38, 0x9c, 0x0d, 0xa9, 0xe2, 0x2e, 0x20, 0x19, 0x0c, 0xac, 0xe0, 0xf8, 0x4b, 0x75, 0x5e,
-	0x48, 0xa2, 0x4b, 0x5d, 0xec, 0x00, 0xe8, 0x95, 0xb3, 0x2f, 0x5f, 0x39, 0x4b, 0x81, 0xfd, 0x77,
-	0x0d, 0x8c, 0xc7, 0x05, 0x50, 0x8a, 0xf4, 0x85, 0x3d, 0x4d, 0xf9, 0xaf, 0x83, 0x5e, 0xff, 0xd7,
-	0x61, 0xd6, 0xdd, 0xff, 0x3d, 0x75, 0xdb, 0x32, 0xc8, 0xeb, 0xdf, 0x5d, 0x80, 0xc9, 0x43, 0x67,
-	0x98, 0xca, 0xeb, 0x18, 0x86, 0xa0, 0x33, 0x1a, 0xa1, 0x80, 0xa2, 0xa5, 0xcf, 0x0b, 0xb6, 0xfe,
-	0x06, 0xdc, 0x5d, 0xf8, 0x06, 0xdc, 0x9b, 0xae, 0x5c, 0xf7, 0xa1, 0x57, 0xac, 0x43, 0x21, 0x12,
-	0xe5, 0x89, 0x2b, 0x0e, 0x8b, 0x07, 0x8d, 0x01, 0xaf, 0x49, 0xca, 0x4b, 0xa2, 0x5e, 0x5d, 0x12,
-	0xb7, 0x7d, 0x58, 0x6d, 0x36, 0x10, 0x6c, 0x19, 0xba, 0x79, 0xf8, 0x22, 0x8c, 0x4e, 0x43, 0x73,
-	0x09, 0x19, 0xf5, 0x0a, 0x60, 0x6a, 0x6c, 0x15, 0x20, 0x11, 0x54, 0xf4, 0xfd, 0x70, 0x68, 0xea,
-	0x38, 0x98, 0xe4, 0x61, 0x88, 0x4c, 0x8b, 0x01, 0x74, 0x62, 0x27, 0x4f, 0x85, 0x67, 0x1a, 0x48,
-	0x8b, 0x97, 0x3e, 0x1a, 0xb5, 0x59, 0x0f, 0x0c, 0x4f, 0x38, 0x9e, 0xd9, 0xd9, 0x7e, 0x0a, 0x57,
-	0x26, 0xba, 0x0d, 0x76, 0x15, 0x06, 0x6a, 0x2d, 0x29, 0x30, 0x97, 0xd8, 0x0a, 0xf4, 0xca, 0x25,
-	0x34, 0x5c, 0x42, 0x36, 0x24, 0x63, 0x53, 0x67, 0x03, 0xe8, 0xe7, 0x61, 0xc1, 0xb6, 0xb6, 0x1f,
-	0xc1, 0x4a, 0xfd, 0xca, 0xc4, 0xda, 0xa0, 0x3d, 0x33, 0x97, 0xf0, 0xe7, 0xa1, 0xa9, 0xe1, 0x0f,
-	0x37, 0x75, 0xfc, 0x39, 0x30, 0x5b, 0xf8, 0x73, 0x68, 0x1a, 0xf8, 0xf3, 0x99, 0xd9, 0xc6, 0x9f,
-	0x5f, 0x99, 0x1d, 0xfc, 0xf9, 0xdc, 0xec, 0x6e, 0xdb, 0x74, 0x04, 0xb5, 0x3c, 0xcd, 0xba, 0xd0,
-	0xca, 0xdc, 0xd8, 0x5c, 0x42, 0x22, 0xf7, 0x62, 0x53, 0xdb, 0xb6, 0xc1, 0x9c, 0x2c, 0x05, 0xac,
-	0x03, 0xfa, 0xc9, 0xfb, 0xe6, 0x12, 0xfd, 0xde, 0x31, 0xb5, 0x07, 0x1f, 0xfd, 0xf5, 0xd5, 0x86,
-	0xf6, 0x8f, 0x57, 0x1b, 0xda, 0x37, 0xaf, 0x36, 0xb4, 0xaf, 0xfe, 0xbd, 0xb1, 0xf4, 0xf9, 0xce,
-	0x8c, 0xbf, 0xc1, 0x55, 0xac, 0xdc, 0x50, 0xb1, 0x72, 0x83, 0x62, 0xe5, 0x26, 0x01, 0xe3, 0xa8,
-	0x43, 0xff, 0x83, 0xbf, 0xf7, 0xdf, 0x00, 0x00, 0x00, 0xff, 0xff, 0x8a, 0xbe, 0x31, 0xc8, 0x63,
-	0x1f, 0x00, 0x00,
+	// 3027 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xac, 0x5a, 0xdb, 0x6f, 0x25, 0x47,
+	0xd1, 0xdf, 0x99, 0x73, 0xaf, 0xe3, 0xcb, 0x6c, 0xdb, 0xd9, 0x4c, 0x9c, 0x8d, 0xe3, 0x9c, 0x2f,
+	0x5f, 0x3e, 0x7f, 0x16, 0xeb, 0x4d, 0x9c, 0x10, 0xe5, 0x82, 0x36, 0xc9, 0x7a, 0x09, 0xbb, 0x4a,
+	0x76, 0xd7, 0x6a, 0x3b, 0x04, 0x85, 0x87, 0x68, 0x3c, 0xd3, 0x3e, 0x1e, 0xed, 0xdc, 0x98, 0xe9,
+	0xf1, 0xee, 0xc9, 0x13, 0x7f, 0x42, 0x1e, 0xe0, 0x81, 0x47, 0x84, 0x78, 0x82, 0x17, 0x24, 0x10,
+	0xcf, 0x3c, 0x80, 0x10, 0xbc, 0xf0, 0x27, 0xa0, 0x20, 0xfe, 0x0d, 0x84, 0xaa, 0xba, 0xe7, 0x72,
+	0xae, 0xbe, 0xc0, 0xd3, 0xe9, 0xaa, 0xae, 0xea, 0x4b, 0x75, 0xd5, 0xaf, 0xaa, 0x7b, 0x0e, 0xf4,
+	0x9d, 0xa1, 0x88, 0xe4, 0x6e, 0x92, 0xc6, 0x32, 0x66, 0xcf, 0x79, 0x8e, 0x74, 0xbc, 0x78, 0x88,
+	0xa4, 0x2b, 0xb2, 0xec, 0x4b, 0xea, 0xdc, 0x78, 0x6b, 0xe8, 0xcb, 0xd3, 0xfc, 0x78, 0xd7, 0x8d,
+	0xc3, 0xdb, 0xf7, 0x1c, 0xe9, 0xdc, 0x8b, 0x87, 0xb7, 0xa9, 0xe7, 0x56, 0xe2, 0x8c, 0x82, 0xd8,
+	0xf1, 0x14, 0xf5, 0xa5, 0xa6, 0xd4, 0x60, 0x83, 0xbf, 0x18, 0xb0, 0xc4, 0x45, 0xb6, 0x1f, 0x07,
+	0x81, 0x70, 0x65, 0x9c, 0xb2, 0xbb, 0xd0, 0x3e, 0x15, 0x8e, 0x27, 0x52, 0xdb, 0xd8, 0x32, 0xb6,
+	0xfb, 0x7b, 0x3b, 0xbb, 0x33, 0xa7, 0xdb, 0xad, 0x2b, 0xed, 0xde, 0x27, 0x0d, 0xae, 0x35, 0x99,
+	0x0d, 0x9d, 0x50, 0x64, 0x99, 0x33, 0x14, 0xb6, 0xb9, 0x65, 0x6c, 0xf7, 0x78, 0x41, 0xb2, 0x3b,
+	0xd0, 0xce, 0xa4, 0x23, 0xf3, 0xcc, 0x6e, 0xd0, 0xe8, 0xaf, 0xcd, 0x19, 0xbd, 0x1c, 0xfa, 0x90,
+	0xa4, 0xb9, 0xd6, 0xda, 0xb8, 0x09, 0x6d, 0x35, 0x17, 0x63, 0xd0, 0x94, 0xa3, 0x44, 0xd8, 0xcd,
+	0x2d, 0x63, 0xbb, 0xc5, 0xa9, 0x3d, 0xf8, 0x57, 0x13, 0x96, 0x4b, 0xcd, 0x83, 0x34, 0x76, 0xd9,
+	0x06, 0x74, 0x4f, 0xe3, 0x4c, 0x3e, 0x72, 0xc2, 0x62, 0x29, 0x25, 0xcd, 0xbe, 0x03, 0x3d, 0x3d,
+	0xa9, 0xc0, 0xe5, 0x34, 0xb6, 0xfb, 0x7b, 0x9b, 0x73, 0x96, 0x73, 0xa0, 0x28, 0x5e, 0x29, 0xb0,
+	0xdb, 0xd0, 0xc4, 0x91, 0x68, 0xfe, 0xfe, 0xde, 0x8b, 0x73, 0x14, 0xef, 0xc7, 0x99, 0xe4, 0x24,
+	0xc8, 0xbe, 0x0d, 0x4d, 0x3f, 0x3a, 0x89, 0xed, 0x16, 0x29, 0xbc, 0x32, 0x47, 0xe1, 0x70, 0x94,
+	0x49, 0x11, 0x3e, 0x88, 0x4e, 0x62, 0x4e, 0xe2, 0x68, 0xcb, 0x61, 0x1a, 0xe7, 0xc9, 0x03, 0xcf,
+	0x6e, 0xd3, 0x56, 0x0b, 0x92, 0xdd, 0x84, 0x1e, 0x35, 0x0f, 0xfd, 0xaf, 0x84, 0xdd, 0xa1, 0xbe,
+	0x8a, 0xc1, 0x1e, 0x00, 0x3c, 0xc9, 0x8f, 0x45, 0x1a, 0x09, 0x29, 0x32, 0xbb, 0x4b, 0x93, 0xfe,
+	0x7f, 0x39, 0x29, 0x4d, 0x56, 0x78, 0xc2, 0x27, 0xf9, 0xb1, 0x78, 0x28, 0xa4, 0x83, 0x9d, 0x07,
+	0x8a, 0xc7, 0x6b, 0xca, 0xec, 0x3d, 0x68, 0x08, 0x37, 0xb3, 0x7b, 0x34, 0xc6, 0xf6, 0xec, 0x31,
+	0xbe, 0xbb, 0x7f, 0x38, 0x39, 0x04, 0x2a, 0xb1, 0x0f, 0x01, 0xdc, 0x38, 0x92, 0x8e, 0x1f, 0x89,
+	0x34, 0xb3, 0x81, 0xac, 0xbc, 0x35, 0xf7, 0xd0, 0xb5, 0x20, 0xaf, 0xe9, 0x14, 0x47, 0x78, 0xe4,
+	0x0c, 0x33, 0xbb, 0xbf, 0xd5, 0x28, 0x8e, 0x10, 0x69, 0xb6, 0x0b, 0x4c, 0xa6, 0x79, 0xe4, 0x3a,
+	0x52, 0x78, 0x07, 0xe5, 0x59, 0x2e, 0x91, 0x2d, 0x66, 0xf4, 0xb0, 0x6f, 0xc1, 0xf5, 0x13, 0x3f,
+	0x90, 0x22, 0xad, 0x8b, 0x2f, 0x93, 0xf8, 0x74, 0x07, 0x7b, 0x1d, 0xd6, 0x32, 0x27, 0x4c, 0x02,
+	0xe1, 0x3d, 0xce, 0x65, 0x25, 0xbf, 0x42, 0xf2, 0xb3, 0xba, 0x06, 0x3f, 0x31, 0x61, 0xbd, 0x74,
+	0xc0, 0xfd, 0x38, 0x8a, 0x84, 0x2b, 0xfd, 0x38, 0xca, 0x16, 0xfa, 0xe1, 0x3e, 0xf4, 0xdd, 0x4a,
+	0x54, 0x7b, 0xe2, 0x2b, 0xf3, 0x6d, 0xa4, 0x25, 0x79, 0x5d, 0xeb, 0xf2, 0xee, 0x58, 0xf3, 0xab,
+	0xd6, 0x02, 0xbf, 0x6a, 0x4f, 0xfa, 0xd5, 0x1e, 0xac, 0x97, 0x86, 0xad, 0xed, 0x50, 0x3b, 0xe0,
+	0xcc, 0xbe, 0xc1, 0xaf, 0x1a, 0x70, 0xbd, 0x34, 0x0b, 0x17, 0x4e, 0x70, 0xe4, 0x87, 0x62, 0xa1,
+	0x4d, 0xde, 0x81, 0x16, 0x46, 0x7c, 0x61, 0x8d, 0xc1, 0xe2, 0xb8, 0x44, 0x90, 0xe0, 0x4a, 0x81,
+	0xdd, 0x80, 0x36, 0x8e, 0xf2, 0xc0, 0xd3, 0xc8, 0xa0, 0x29, 0xb6, 0x0e, 0xad, 0x38, 0x1d, 0x96,
+	0xbb, 0x55, 0xc4, 0x95, 0xa3, 0xcb, 0x86, 0x4e, 0x94, 0x87, 0xfb, 0x49, 0xae, 0x42, 0xab, 0xc5,
+	0x0b, 0x92, 0x6d, 0x41, 0x5f, 0xc6, 0xd2, 0x09, 0x1e, 0x8a, 0x30, 0x4e, 0x47, 0x14, 0x34, 0x0d,
+	0x5e, 0x67, 0xb1, 0x4f, 0x61, 0xa5, 0x74, 0xef, 0x43, 0xda, 0xa4, 0x0a, 0x8b, 0x57, 0xcf, 0x0b,
+	0x0b, 0xda, 0xe6, 0x84, 0x2e, 0x7b, 0x0f, 0xda, 0xe2, 0x99, 0x2f, 0x85, 0x67, 0xf7, 0x2f, 0x6c,
+	0x2a, 0xad, 0x81, 0x36, 0xf1, 0x44, 0x20, 0x1d, 0x8a, 0x98, 0x2e, 0x57, 0xc4, 0xe0, 0x77, 0x0d,
+	0x60, 0x75, 0x27, 0x56, 0xb3, 0x8d, 0x1d, 0x97, 0x31, 0x71, 0x5c, 0x05, 0xb6, 0x99, 0x97, 0xc3,
+	0xb6, 0x71, 0x70, 0x68, 0x5c, 0x01, 0x1c, 0x6a, 0xe7, 0xd7, 0x5c, 0x70, 0x7e, 0xad, 0xc5, 0xe8,
+	0xd8, 0xfe, 0x2f, 0xa0, 0x63, 0xe7, 0x2a, 0xe8, 0x58, 0x44, 0x6d, 0xf7, 0xa2, 0x51, 0x5b, 0x07,
+	0xc3, 0xde, 0x38, 0x18, 0x0e, 0x7e, 0x6c, 0xc2, 0xc6, 0xf4, 0xb9, 0xcd, 0x0c, 0xb7, 0xc9, 0xf3,
+	0x7b, 0xaf, 0x08, 0x37, 0xf3, 0x12, 0x9e, 0xa8, 0x03, 0xae, 0x16, 0x0a, 0x8d, 0x85, 0xa1, 0xd0,
+	0x9c, 0x0e, 0x85, 0x2a, 0x58, 0x5b, 0x63, 0xc1, 0x7a, 0xc5, 0xb0, 0x1c, 0xbc, 0x5e, 0xf3, 0x5c,
+	0x2e, 0x7e, 0xa4, 0x8a, 0x87, 0x45, 0x40, 0x33, 0x38, 0x84, 0xd5, 0x89, 0x5a, 0x83, 0xbd, 0x0a,
+	0xcb, 0x8e, 0x2b, 0xfd, 0x33, 0xb1, 0x1f, 0xf8, 0x22, 0x92, 0x19, 0x59, 0xab, 0xc5, 0xc7, 0x99,
+	0x38, 0xa8, 0x1f, 0x49, 0x91, 0x9e, 0x39, 0x01, 0x0d, 0xda, 0xe2, 0x25, 0x3d, 0xf8, 0x4d, 0x07,
+	0x3a, 0x3a, 0xde, 0x98, 0x05, 0x8d, 0x27, 0x62, 0x44, 0x63, 0x2c, 0x73, 0x6c, 0x22, 0x27, 0xf1,
+	0x3d, 0xad, 0x84, 0xcd, 0xd2, 0x0d, 0x1a, 0x17, 0x75, 0x83, 0x77, 0xa0, 0xe3, 0xc6, 0x61, 0xe8,
+	0x44, 0x9e, 0x06, 0xfc, 0xcd, 0xb9, 0x27, 0x46, 0x52, 0xbc, 0x10, 0x67, 0x6f, 0x43, 0x33, 0xcf,
+	0x44, 0xaa, 0xab, 0x90, 0x73, 0xc0, 0xe2, 0xb3, 0x4c, 0xa4, 0x9c, 0xe4, 0xd9, 0xbb, 0xd0, 0x0e,
+	0xd5, 0x31, 0x76, 0x16, 0xc6, 0xb8, 0x3a, 0x58, 0x85, 0x32, 0x4a, 0x81, 0xbd, 0x0e, 0x0d, 0x37,
+	0xc9, 0xed, 0xee, 0xe2, 0x85, 0x1e, 0x7c, 0x46, 0x4a, 0x28, 0xca, 0x36, 0x01, 0xdc, 0x54, 0x38,
+	0x52, 0xa0, 0xe3, 0x6a, 0x08, 0xad, 0x71, 0xd8, 0x1d, 0xe8, 0x95, 0x18, 0x60, 0xc3, 0x96, 0x71,
+	0x21, 0xd8, 0xa8, 0x54, 0xd0, 0x31, 0xe3, 0x44, 0x44, 0x1f, 0x7b, 0xfb, 0x71, 0x1e, 0x49, 0xbb,
+	0x4f, 0x27, 0x51, 0x67, 0xb1, 0x77, 0x55, 0x40, 0x08, 0x42, 0xc6, 0x95, 0xbd, 0xff, 0x39, 0x1f,
+	0x54, 0x85, 0x8a, 0x07, 0xc4, 0xc2, 0xb6, 0x1f, 0x23, 0x87, 0x0a, 0x8b, 0xfe, 0xde, 0x4b, 0x73,
+	0x74, 0x1f, 0x3c, 0x56, 0x56, 0x52, 0xc2, 0xb8, 0xa6, 0x72, 0x81, 0x0f, 0x3c, 0x2a, 0x32, 0x7a,
+	0xbc, 0xce, 0x62, 0x03, 0x58, 0x2a, 0xc9, 0x4f, 0xc4, 0xc8, 0x5e, 0x25, 0x97, 0x1a, 0xe3, 0x61,
+	0x76, 0x3e, 0x8b, 0x83, 0x3c, 0x92, 0x4e, 0x3a, 0xda, 0x97, 0xcf, 0x0e, 0x9f, 0xfa, 0xd2, 0x3d,
+	0x15, 0x99, 0x6d, 0x6d, 0x19, 0xdb, 0x4d, 0x3e, 0xb3, 0x8f, 0xbd, 0x0d, 0x37, 0xfc, 0x68, 0xa6,
+	0xd6, 0x75, 0xd2, 0x9a, 0xd3, 0x8b, 0x41, 0x7a, 0x3c, 0x92, 0x02, 0x97, 0xc2, 0xb6, 0x8c, 0xed,
+	0x25, 0x5e, 0x90, 0x6c, 0x07, 0xac, 0x72, 0x55, 0x77, 0xb5, 0xc8, 0x1a, 0x89, 0x4c, 0xf1, 0x31,
+	0x07, 0x45, 0x42, 0x3e, 0xca, 0xec, 0x75, 0xda, 0x8e, 0x22, 0x30, 0xba, 0x32, 0x91, 0x9e, 0xf9,
+	0xae, 0xc8, 0xec, 0xe7, 0x14, 0xce, 0x15, 0x34, 0xce, 0x2b, 0x4f, 0x53, 0xe1, 0x78, 0x99, 0x7d,
+	0x43, 0x81, 0x83, 0x26, 0x31, 0xb2, 0x44, 0x74, 0x66, 0x3f, 0x4f, 0x0a, 0xd8, 0x64, 0xdb, 0xb0,
+	0x8a, 0xc7, 0x5a, 0x2f, 0x54, 0x6c, 0xd2, 0x99, 0x64, 0x0f, 0x7e, 0x66, 0x40, 0x47, 0x47, 0x0b,
+	0xde, 0x2d, 0x9c, 0x74, 0x88, 0x81, 0x8f, 0x03, 0x51, 0x1b, 0xc7, 0x76, 0x9f, 0x7a, 0x14, 0xa2,
+	0x3d, 0x8e, 0x4d, 0x94, 0x4a, 0xe3, 0x58, 0x95, 0x5c, 0x3d, 0x4e, 0x6d, 0x04, 0xb4, 0x38, 0xba,
+	0xe7, 0x67, 0x4f, 0x28, 0xc0, 0xba, 0x5c, 0x53, 0x28, 0x9b, 0x24, 0x7e, 0x81, 0x66, 0xd4, 0x46,
+	0xd9, 0x84, 0xa0, 0x4b, 0xe3, 0x98, 0xa6, 0x68, 0x17, 0xcf, 0x04, 0xc5, 0x0b, 0xee, 0xe2, 0x99,
+	0x18, 0xfc, 0xd4, 0x80, 0x7e, 0x2d, 0x24, 0x71, 0xb4, 0xa8, 0x82, 0x71, 0x6a, 0xa3, 0x56, 0x5e,
+	0xa1, 0x4a, 0xee, 0x7b, 0xc8, 0x19, 0xfa, 0x9e, 0x06, 0x65, 0x6c, 0xa2, 0x9e, 0x40, 0x21, 0x7d,
+	0x67, 0x12, 0xb9, 0xe6, 0xa1, 0x58, 0x4b, 0xf3, 0xb4, 0x5c, 0x96, 0x57, 0xab, 0xcd, 0xb4, 0x5c,
+	0x86, 0x72, 0x1d, 0xcd, 0x1b, 0xfa, 0xde, 0xe0, 0x0f, 0x1d, 0xe8, 0x55, 0x05, 0x42, 0x71, 0x23,
+	0xd3, 0xab, 0xc2, 0x36, 0x5b, 0x01, 0x53, 0x2f, 0xaa, 0xc7, 0x4d, 0x35, 0x0a, 0xad, 0xbc, 0x51,
+	0x5b, 0xf9, 0x3a, 0xb4, 0xfc, 0x10, 0xef, 0x8a, 0xca, 0x90, 0x8a, 0x40, 0x0f, 0x70, 0x93, 0xfc,
+	0x53, 0x3f, 0xf4, 0x25, 0xad, 0xcd, 0xe4, 0x25, 0x8d, 0xb1, 0xa2, 0xb0, 0x45, 0x75, 0xb7, 0xc9,
+	0x4d, 0xeb, 0x2c, 0xf6, 0x7e, 0x11, 0xbf, 0x5d, 0x8a, 0xdf, 0xff, 0xbd, 0x48, 0x42, 0x2b, 0x23,
+	0xf8, 0x0e, 0x5d, 0x81, 0x03, 0x79, 0x4a, 0xd0, 0xb3, 0xb2, 0xf7, 0xda, 0x79, 0xda, 0xf7, 0x49,
+	0x9a, 0x6b, 0x2d, 0x74, 0x50, 0x05, 0x56, 0x1e, 0x81, 0x53, 0x83, 0x17, 0x24, 0xb9, 0xcc, 0x71,
+	0x92, 0x11, 0xe2, 0x98, 0x9c, 0xda, 0xc8, 0x7b, 0x8a, 0xbc, 0x25, 0xc5, 0xc3, 0x76, 0x91, 0x34,
+	0x96, 0xab, 0xa4, 0x71, 0x13, 0x7a, 0x91, 0x90, 0xdc, 0x3d, 0xf3, 0x0e, 0xd4, 0x0d, 0xc4, 0xe4,
+	0x15, 0x43, 0xf7, 0x1e, 0x8a, 0x48, 0x1e, 0x64, 0xf6, 0x6a, 0xd9, 0xab, 0x18, 0x08, 0xa7, 0x5a,
+	0xf4, 0x6e, 0xa2, 0xa0, 0xc0, 0xe4, 0x35, 0x8e, 0xee, 0x47, 0xe1, 0xbb, 0x89, 0x0a, 0x7a, 0x93,
+	0xd7, 0x38, 0xb8, 0x1f, 0xcc, 0x01, 0x07, 0xae, 0xa4, 0x40, 0x37, 0x79, 0x41, 0xe2, 0xbc, 0x19,
+	0x15, 0x75, 0xd8, 0xb7, 0xa6, 0xe6, 0x2d, 0x19, 0x78, 0x84, 0x94, 0xec, 0xb1, 0x73, 0x5d, 0x1d,
+	0x61, 0x41, 0xa3, 0xf3, 0x87, 0x22, 0xe4, 0x19, 0x86, 0x37, 0x9e, 0x9e, 0xa6, 0x50, 0x27, 0x14,
+	0xe1, 0xbe, 0xe3, 0x9e, 0x0a, 0x8a, 0xee, 0x26, 0x2f, 0xe9, 0x32, 0x4d, 0x3e, 0x7f, 0x89, 0x3b,
+	0x4e, 0x26, 0x9d, 0x14, 0x0f, 0xc2, 0x56, 0x07, 0xa1, 0xc9, 0x3a, 0x76, 0xbd, 0x30, 0x8e, 0x5d,
+	0xe8, 0xc5, 0x58, 0x5d, 0x6d, 0xa8, 0xd8, 0xc7, 0x36, 0x22, 0x6f, 0x2a, 0x48, 0x55, 0x25, 0x8c,
+	0x17, 0x29, 0x06, 0xc6, 0x78, 0x68, 0x8a, 0x38, 0x0e, 0x3f, 0xf1, 0x83, 0x40, 0x78, 0xf6, 0x4d,
+	0x0a, 0xfe, 0x8a, 0x81, 0x1e, 0x4b, 0x6e, 0x7d, 0xcf, 0x1f, 0x8a, 0x4c, 0xda, 0x2f, 0x29, 0x74,
+	0xaf, 0xb1, 0x08, 0xdd, 0x93, 0xfc, 0x51, 0x7a, 0x20, 0x52, 0x3f, 0xf6, 0x32, 0x7b, 0x93, 0x36,
+	0x3f, 0xc6, 0x63, 0xaf, 0xc1, 0x0a, 0xd1, 0x47, 0xa7, 0x69, 0x2c, 0x25, 0x4e, 0xf4, 0x32, 0x49,
+	0x4d, 0x70, 0x09, 0x7f, 0x93, 0xbc, 0xa4, 0x29, 0x8b, 0x6e, 0x91, 0xe4, 0x14, 0x7f, 0xf0, 0xfb,
+	0x6e, 0x89, 0x2d, 0x94, 0x87, 0x74, 0x75, 0x62, 0x54, 0xd5, 0xc9, 0x78, 0x36, 0x36, 0xa7, 0xb2,
+	0x71, 0x55, 0x1a, 0x34, 0xae, 0x58, 0x1a, 0x34, 0x2f, 0x5e, 0x1a, 0x20, 0x80, 0xf8, 0x6e, 0x51,
+	0xd1, 0x53, 0xbb, 0x9e, 0x10, 0x3a, 0xe3, 0x09, 0x61, 0x22, 0xd1, 0x77, 0xa7, 0x13, 0xbd, 0x8e,
+	0xb4, 0x5e, 0x15, 0x69, 0x13, 0x89, 0x18, 0xa6, 0x13, 0xf1, 0xc3, 0x89, 0x0b, 0x9c, 0xb0, 0xfb,
+	0x97, 0x41, 0x99, 0x09, 0x65, 0xf6, 0x3d, 0x58, 0x4a, 0xaa, 0x03, 0xb8, 0x54, 0xc9, 0x31, 0xa6,
+	0xc8, 0x0e, 0x60, 0xd5, 0x1d, 0x87, 0x24, 0x7b, 0xf5, 0x52, 0x00, 0x36, 0xa9, 0x8e, 0xa5, 0x70,
+	0xc9, 0xe2, 0xc7, 0x25, 0x78, 0x8c, 0x33, 0xc7, 0xa4, 0x3e, 0x3f, 0x2e, 0x21, 0x64, 0x9c, 0x39,
+	0x55, 0xbe, 0xb0, 0x19, 0xe5, 0x4b, 0x55, 0x3b, 0xad, 0x5d, 0xa6, 0x76, 0xda, 0x05, 0x56, 0x0e,
+	0xf3, 0xa8, 0x44, 0x49, 0x05, 0x39, 0x33, 0x7a, 0x26, 0xe5, 0x35, 0x6e, 0x3e, 0x37, 0x2d, 0xaf,
+	0x7a, 0xf0, 0x21, 0x68, 0x72, 0x14, 0x44, 0xca, 0x1b, 0xa4, 0x30, 0xab, 0x6b, 0x52, 0xa3, 0xc0,
+	0xd6, 0xe7, 0xa7, 0x35, 0x74, 0xd7, 0xdc, 0xca, 0xcd, 0xbe, 0x52, 0xe5, 0xf6, 0xc2, 0x45, 0x2b,
+	0xb7, 0x8d, 0xf3, 0x2b, 0xb7, 0x17, 0x67, 0x57, 0x6e, 0x83, 0x3f, 0xd1, 0x6b, 0x6b, 0xcd, 0x95,
+	0x75, 0xb6, 0x37, 0xca, 0x6c, 0x5f, 0x4b, 0x1c, 0xe6, 0x82, 0xc4, 0xd1, 0x58, 0x94, 0x38, 0x9a,
+	0x13, 0x89, 0x63, 0x51, 0x5d, 0x50, 0x25, 0x95, 0xf6, 0xdc, 0xa4, 0xd2, 0x99, 0x48, 0x2a, 0xaa,
+	0x4f, 0x8d, 0xd7, 0x2d, 0xfb, 0xd4, 0x78, 0x45, 0xba, 0xee, 0xcd, 0x48, 0xd7, 0x50, 0x4b, 0xd7,
+	0x63, 0xc9, 0xb9, 0xbf, 0x30, 0x39, 0x2f, 0x2d, 0x4e, 0xce, 0xcb, 0xe7, 0x24, 0xe7, 0x95, 0xa9,
+	0xe4, 0x5c, 0x56, 0x3a, 0xab, 0xff, 0x51, 0xa5, 0x63, 0x5d, 0xa9, 0xd2, 0xd1, 0xe8, 0x79, 0xbd,
+	0x42, 0xcf, 0x5a, 0xca, 0x65, 0x73, 0x53, 0xee, 0xda, 0x98, 0xd3, 0x0d, 0x7e, 0x69, 0x00, 0x54,
+	0x6f, 0x43, 0x68, 0xe1, 0x3c, 0x2f, 0xfd, 0x88, 0xda, 0xec, 0x16, 0x98, 0x71, 0x66, 0x9b, 0x0b,
+	0x41, 0xe1, 0xf1, 0x21, 0xaa, 0x73, 0x33, 0xc6, 0x60, 0x6a, 0xba, 0xea, 0x41, 0xa2, 0xb1, 0x38,
+	0xb1, 0x90, 0x06, 0xc9, 0x4e, 0xbe, 0x56, 0xb4, 0xa6, 0x5e, 0x2b, 0x06, 0x5f, 0x1b, 0xd0, 0x7e,
+	0x7c, 0x58, 0xac, 0x71, 0xaa, 0x02, 0xdf, 0x80, 0x6e, 0x12, 0x38, 0xf2, 0x24, 0x4e, 0xc3, 0xe2,
+	0x99, 0xa1, 0xa0, 0xd1, 0x33, 0x4f, 0x9c, 0xd0, 0x0f, 0x46, 0xba, 0xf2, 0xd5, 0x14, 0x1a, 0xe5,
+	0x4c, 0xa4, 0x99, 0x1f, 0x47, 0xba, 0xfa, 0x2d, 0x48, 0x04, 0xd5, 0x27, 0x22, 0x8d, 0x44, 0xf0,
+	0x7d, 0xdd, 0xdf, 0xa2, 0xfe, 0x71, 0x26, 0x2d, 0x49, 0x81, 0x21, 0x4e, 0x8f, 0x49, 0x8f, 0x3b,
+	0x52, 0x2d, 0xcb, 0xe4, 0x25, 0x8d, 0x2e, 0xf8, 0x34, 0xf5, 0xa5, 0xa0, 0x4e, 0x15, 0x8a, 0x15,
+	0x03, 0xa7, 0x42, 0x49, 0x8c, 0xeb, 0x8c, 0x24, 0x54, 0x40, 0x8e, 0x33, 0xb1, 0xf8, 0x20, 0x95,
+	0x4a, 0x4c, 0x85, 0xe6, 0x04, 0x77, 0xf0, 0xc7, 0x16, 0x40, 0x75, 0xb1, 0x9a, 0x51, 0x4f, 0xbc,
+	0x01, 0xad, 0xc0, 0xf1, 0xbc, 0xe2, 0x0d, 0x62, 0x5e, 0x1d, 0xf7, 0x91, 0xe7, 0xa5, 0x5c, 0x49,
+	0xa2, 0x4a, 0x4a, 0x2a, 0xed, 0x0b, 0xa8, 0x90, 0x24, 0x6e, 0x19, 0xfd, 0x2b, 0xc3, 0x38, 0xa1,
+	0xc0, 0x36, 0x79, 0xc5, 0xc0, 0x2d, 0x13, 0xc1, 0x85, 0xeb, 0x8b, 0x33, 0xe1, 0xe9, 0x10, 0x1f,
+	0x67, 0xb2, 0x0f, 0xca, 0x53, 0x03, 0x0a, 0x8f, 0xff, 0x3b, 0xf7, 0x51, 0xfe, 0x63, 0x12, 0x2f,
+	0x8f, 0xf7, 0x5d, 0x7d, 0x25, 0x3a, 0xb7, 0x3e, 0xd0, 0xea, 0x47, 0xa3, 0x44, 0xe8, 0x9b, 0xd3,
+	0xab, 0xb0, 0x9c, 0xf8, 0xde, 0x7e, 0x55, 0x78, 0x2d, 0x91, 0x43, 0x8e, 0x33, 0x71, 0x97, 0xb4,
+	0x5d, 0x2c, 0x7a, 0x09, 0x3c, 0x7a, 0xbc, 0x62, 0xe0, 0x91, 0x91, 0xff, 0xde, 0x2d, 0x0d, 0xb1,
+	0xa2, 0xea, 0xc5, 0x71, 0x2e, 0x7d, 0x46, 0x29, 0x39, 0x5c, 0xb8, 0xc2, 0x47, 0x93, 0xac, 0x92,
+	0xec, 0x8c, 0x1e, 0xf6, 0x3e, 0x74, 0xa5, 0x9b, 0xa8, 0x6a, 0x45, 0x01, 0xc7, 0xcb, 0x73, 0xb6,
+	0x76, 0xb4, 0x7f, 0x40, 0x62, 0xbc, 0x54, 0xa8, 0x2e, 0xfc, 0xd7, 0xeb, 0x17, 0xfe, 0x6d, 0xaa,
+	0x5d, 0xb4, 0x19, 0x54, 0xb5, 0xa6, 0x0a, 0x84, 0x49, 0x36, 0xc6, 0xa9, 0x9e, 0x83, 0x1e, 0xf4,
+	0xd6, 0x54, 0x7d, 0x56, 0x63, 0xe1, 0xb6, 0x35, 0xb9, 0x1f, 0x7a, 0x81, 0x1f, 0x09, 0x7b, 0x9d,
+	0x8a, 0xf9, 0x09, 0x2e, 0x1a, 0x2f, 0xf0, 0x33, 0x29, 0x22, 0x3f, 0x1a, 0x52, 0xf6, 0xef, 0xf2,
+	0x8a, 0x31, 0xf8, 0x21, 0x34, 0xd1, 0x9f, 0xca, 0x5b, 0x87, 0x71, 0xd1, 0x5b, 0x07, 0x66, 0xc1,
+	0xa4, 0xbc, 0xf3, 0x26, 0x74, 0xf7, 0x8f, 0x53, 0xa9, 0x2f, 0xe2, 0xd4, 0x1e, 0xfc, 0xda, 0x00,
+	0xa8, 0xea, 0x61, 0x0c, 0x92, 0x34, 0x53, 0x0f, 0x8d, 0x4d, 0x8e, 0x4d, 0xe4, 0x9c, 0x85, 0x0a,
+	0xf1, 0x9a, 0x1c, 0x9b, 0x38, 0x4c, 0xf6, 0xd4, 0x49, 0x68, 0x98, 0x26, 0xa7, 0x36, 0xc2, 0x4a,
+	0x76, 0xea, 0xa4, 0x42, 0x5d, 0xe9, 0x9b, 0x5c, 0x53, 0x28, 0x2b, 0xc5, 0x33, 0x95, 0x20, 0x9b,
+	0x9c, 0xda, 0x38, 0x62, 0xe0, 0x1f, 0xeb, 0xcc, 0x88, 0x4d, 0x94, 0xc2, 0xcd, 0xe8, 0x94, 0x48,
+	0x6d, 0xfa, 0x24, 0xe0, 0xa7, 0x72, 0xa4, 0x73, 0xa1, 0x22, 0x06, 0x3f, 0x37, 0xa1, 0xa3, 0xcb,
+	0x70, 0x84, 0xac, 0xc0, 0xc9, 0xe4, 0x7e, 0x92, 0x6b, 0xf4, 0x2b, 0xc8, 0xb1, 0xb4, 0x6d, 0x4e,
+	0xa4, 0xed, 0x5a, 0x29, 0xd0, 0x58, 0x50, 0x0a, 0x34, 0x27, 0x4b, 0x01, 0x4c, 0x7f, 0x79, 0x78,
+	0xa4, 0xcb, 0x7b, 0x55, 0xf5, 0xd7, 0x38, 0xec, 0x1d, 0x8d, 0xf4, 0xed, 0x85, 0x0f, 0xd7, 0x87,
+	0x7e, 0x34, 0x0c, 0x44, 0x71, 0x91, 0x20, 0x8d, 0xf2, 0x26, 0xd1, 0xa9, 0xdd, 0x24, 0x36, 0xa0,
+	0x8b, 0xcb, 0xa2, 0x78, 0xeb, 0x52, 0xbc, 0x95, 0x34, 0xae, 0x44, 0x2d, 0xab, 0xfe, 0x28, 0x59,
+	0x71, 0x06, 0x1f, 0xc0, 0xf2, 0xd8, 0x34, 0xf3, 0x72, 0xc4, 0x3c, 0x13, 0x0d, 0xfe, 0x69, 0x90,
+	0x91, 0x29, 0xbf, 0xdc, 0x80, 0x76, 0x94, 0x87, 0xc7, 0xfa, 0x2b, 0x7c, 0x8b, 0x6b, 0x0a, 0xf9,
+	0x67, 0x22, 0xf2, 0xe2, 0x54, 0xfb, 0x97, 0xa6, 0xe6, 0xe6, 0x97, 0x75, 0x68, 0x85, 0xb1, 0x27,
+	0x82, 0xe2, 0x6d, 0x85, 0x08, 0xdc, 0x4a, 0x72, 0x3a, 0xca, 0x7c, 0xd7, 0x09, 0xf4, 0xd3, 0x7b,
+	0x8f, 0xd7, 0x38, 0x38, 0x9a, 0x1b, 0xa7, 0x42, 0xbf, 0xbe, 0xf7, 0xb8, 0xa6, 0x70, 0x34, 0x6c,
+	0x15, 0xd7, 0x2c, 0x45, 0xa0, 0x63, 0x85, 0xa7, 0x5f, 0x69, 0x7b, 0x61, 0x13, 0x8f, 0xd4, 0xc5,
+	0xe2, 0x8a, 0x1e, 0xe9, 0x7b, 0x24, 0x5b, 0x31, 0x06, 0x7f, 0x35, 0xa0, 0x79, 0xbf, 0x08, 0x94,
+	0x22, 0x33, 0x98, 0x7e, 0xed, 0x13, 0x9d, 0x59, 0xff, 0x44, 0x37, 0xeb, 0xc9, 0xe8, 0x4d, 0x7d,
+	0x49, 0x6f, 0xd2, 0xa9, 0xbf, 0xbc, 0x20, 0x26, 0xf1, 0xcb, 0x88, 0xbe, 0xc5, 0xdb, 0xd0, 0x71,
+	0x82, 0x00, 0x19, 0xe4, 0x2d, 0x3d, 0x5e, 0x90, 0xf5, 0x4f, 0x18, 0x9d, 0x85, 0x9f, 0x30, 0xba,
+	0xd3, 0x45, 0xc1, 0x1d, 0xe8, 0x16, 0xf3, 0x90, 0x8b, 0xc4, 0x79, 0xea, 0x8a, 0xa3, 0xe2, 0x1d,
+	0x6c, 0x99, 0xd7, 0x38, 0xe5, 0xdb, 0x82, 0x59, 0xbd, 0x2d, 0x0c, 0x7e, 0x61, 0xd4, 0xbe, 0x8d,
+	0x3e, 0x74, 0x22, 0xff, 0x44, 0x54, 0xdf, 0x79, 0x66, 0x7e, 0xac, 0xa9, 0x7d, 0x1c, 0x31, 0x17,
+	0x7c, 0x1c, 0x69, 0x4c, 0x7e, 0xf3, 0xba, 0x09, 0x3d, 0x5f, 0x8a, 0x50, 0x81, 0xab, 0x7a, 0x02,
+	0xac, 0x18, 0x94, 0x2f, 0x1d, 0xe9, 0x9e, 0xd2, 0xd2, 0xf5, 0xf7, 0xb2, 0x92, 0xb1, 0xe3, 0xc3,
+	0xca, 0x78, 0x05, 0xc9, 0xfa, 0xd0, 0xc9, 0xa3, 0x27, 0x51, 0xfc, 0x34, 0xb2, 0xae, 0x21, 0xa1,
+	0x9f, 0xb8, 0x2c, 0x83, 0xad, 0x00, 0xe8, 0x97, 0x11, 0x3f, 0x1a, 0x5a, 0x26, 0x76, 0xa6, 0x79,
+	0x84, 0x98, 0x6a, 0x35, 0x18, 0x40, 0x3b, 0x71, 0xf2, 0x4c, 0x78, 0x56, 0x13, 0xdb, 0xea, 0x43,
+	0xa4, 0xd5, 0x62, 0x5d, 0x68, 0x7a, 0xc2, 0xf1, 0xac, 0xf6, 0xce, 0x23, 0x58, 0x2d, 0xa7, 0xd2,
+	0xd7, 0xd0, 0xeb, 0xb0, 0xac, 0xe7, 0x52, 0x0c, 0xeb, 0x1a, 0x5b, 0x82, 0x6e, 0x39, 0x85, 0x81,
+	0x53, 0xa8, 0x8a, 0x74, 0x64, 0x99, 0x6c, 0x19, 0x7a, 0x79, 0x54, 0x90, 0x8d, 0x9d, 0x8f, 0x61,
+	0xa9, 0x7e, 0x67, 0x66, 0x2d, 0x30, 0x3e, 0xb3, 0xae, 0xe1, 0xcf, 0x3d, 0xcb, 0xc0, 0x1f, 0x6e,
+	0x99, 0xf8, 0x73, 0x68, 0x35, 0xf0, 0xe7, 0xc8, 0x6a, 0xe2, 0xcf, 0xe7, 0x56, 0x0b, 0x7f, 0x7e,
+	0x60, 0xb5, 0xf1, 0xe7, 0x0b, 0xab, 0xb3, 0x33, 0x20, 0x13, 0xd4, 0x12, 0x35, 0xeb, 0x40, 0x43,
+	0xba, 0x89, 0x75, 0x0d, 0x1b, 0xb9, 0x97, 0x58, 0xc6, 0xce, 0x00, 0xac, 0xc9, 0x5a, 0x80, 0xb5,
+	0xc1, 0x3c, 0x7b, 0xcb, 0xba, 0x46, 0xbf, 0x6f, 0x5b, 0xc6, 0xce, 0x6f, 0x0d, 0xe8, 0x16, 0x69,
+	0x91, 0xad, 0xc1, 0xaa, 0xde, 0x59, 0xc1, 0xb2, 0xae, 0xb1, 0x55, 0xe8, 0xa3, 0xfd, 0x8e, 0x03,
+	0x3f, 0x3b, 0x25, 0x8b, 0xf6, 0xa1, 0x93, 0x8d, 0x22, 0x4c, 0xd5, 0xca, 0x9c, 0xd9, 0x28, 0xe2,
+	0xc2, 0x3d, 0xb3, 0x1a, 0x68, 0x86, 0x13, 0x3f, 0xfa, 0xdc, 0xf1, 0xe5, 0x1b, 0x56, 0xb3, 0x46,
+	0xed, 0x59, 0x2d, 0xa4, 0xa4, 0x1f, 0x0a, 0x24, 0xad, 0x36, 0xeb, 0x41, 0xcb, 0x0d, 0xe2, 0x4c,
+	0x58, 0x1d, 0x34, 0x10, 0x35, 0xa9, 0xa7, 0x8b, 0x03, 0x22, 0x82, 0x7f, 0xe4, 0x3e, 0xb1, 0x7a,
+	0x78, 0x26, 0x2a, 0x05, 0x5a, 0x40, 0xa7, 0x1a, 0xc4, 0x19, 0x9a, 0xb8, 0x7f, 0xf7, 0xc3, 0x3f,
+	0x7f, 0xb3, 0x69, 0xfc, 0xed, 0x9b, 0x4d, 0xe3, 0xef, 0xdf, 0x6c, 0x1a, 0x5f, 0xff, 0x63, 0xf3,
+	0xda, 0x17, 0xbb, 0x33, 0xfe, 0x70, 0xa4, 0x03, 0xf1, 0x96, 0x0e, 0xc4, 0x5b, 0x14, 0x88, 0xb7,
+	0x09, 0x75, 0x8e, 0xdb, 0xf4, 0x8f, 0xa3, 0x37, 0xff, 0x3d, 0x00, 0xd5, 0x9f, 0x41, 0xa2, 0xcd,
+	0x24, 0x00, 0x00,
 }
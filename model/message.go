@@ -81,6 +81,7 @@ const (
 	TypeCollectorRealTime          = 27
 	TypeCollectorContainer         = 39
 	TypeCollectorContainerRealTime = 40
+	TypeCollectorManifest          = 41
 )
 
 // Message is a generic type for all messages with a Header and Body.
@@ -119,6 +120,8 @@ func DecodeMessage(data []byte) (Message, error) {
 		m = &CollectorContainer{}
 	case TypeCollectorContainerRealTime:
 		m = &CollectorContainerRealTime{}
+	case TypeCollectorManifest:
+		m = &CollectorManifest{}
 	default:
 		return Message{}, fmt.Errorf("unhandled message type: %d", header.Type)
 	}
@@ -144,6 +147,8 @@ func DetectMessageType(b MessageBody) (MessageType, error) {
 		t = TypeCollectorContainer
 	case *CollectorContainerRealTime:
 		t = TypeCollectorContainerRealTime
+	case *CollectorManifest:
+		t = TypeCollectorManifest
 	default:
 		return 0, fmt.Errorf("unknown message body type: %s", reflect.TypeOf(b))
 	}
@@ -203,6 +208,8 @@ func ReadHeader(data []byte) (MessageHeader, int, error) {
 		return readHeaderV1(data)
 	case MessageV2:
 		return readHeaderV2(data)
+	case MessageV3:
+		return readHeaderV3(data)
 	default:
 		return MessageHeader{}, 0, fmt.Errorf("invalid message version: %d", uint8(data[0]))
 	}
@@ -2,6 +2,7 @@ package statsd
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/DataDog/datadog-go/statsd"
 	"github.com/DataDog/datadog-process-agent/config"
@@ -12,12 +13,24 @@ import (
 var Client *statsd.Client
 
 // Configure creates a statsd client from a dogweb.ini style config file and set it to the global Statsd.
+// The names of all the metrics it sends are prefixed with the statsd_prefix, if any.
 func Configure(cfg *config.AgentConfig) error {
 	client, err := statsd.New(fmt.Sprintf("%s:%d", cfg.StatsdHost, cfg.StatsdPort))
 	if err != nil {
 		return err
 	}
+	client.Namespace = namespace(cfg.StatsdPrefix)
 
 	Client = client
 	return nil
 }
+
+// namespace returns the namespace of the metric names for the given prefix, which is
+// separated from the names by a dot.
+func namespace(prefix string) string {
+	prefix = strings.Trim(strings.TrimSpace(prefix), ".")
+	if prefix == "" {
+		return ""
+	}
+	return prefix + "."
+}
@@ -0,0 +1,57 @@
+package statsd
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"github.com/DataDog/datadog-process-agent/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNamespace(t *testing.T) {
+	for prefix, expected := range map[string]string{
+		"":          "",
+		" ":         "",
+		"staging":   "staging.",
+		"staging.":  "staging.",
+		"eu.prod":   "eu.prod.",
+		" .team. ":  "team.",
+		"a.b.c....": "a.b.c.",
+	} {
+		assert.Equal(t, expected, namespace(prefix), prefix)
+	}
+}
+
+func TestConfigurePrefix(t *testing.T) {
+	assert := assert.New(t)
+	conn, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1)})
+	assert.NoError(err)
+	defer conn.Close()
+
+	client := Client
+	defer func() { Client = client }()
+
+	read := func() string {
+		b := make([]byte, 1024)
+		conn.SetReadDeadline(time.Now().Add(time.Second))
+		n, err := conn.Read(b)
+		assert.NoError(err)
+		return string(b[:n])
+	}
+
+	cfg := config.NewDefaultAgentConfig()
+	cfg.StatsdHost = "127.0.0.1"
+	cfg.StatsdPort = conn.LocalAddr().(*net.UDPAddr).Port
+
+	assert.NoError(Configure(cfg))
+	assert.NoError(Client.Gauge("datadog.process.agent", 1, nil, 1))
+	assert.Equal("datadog.process.agent:1.000000|g", read())
+
+	cfg.StatsdPrefix = "staging"
+	assert.NoError(Configure(cfg))
+	assert.NoError(Client.Gauge("datadog.process.agent", 1, []string{"version:1"}, 1))
+	assert.Equal("staging.datadog.process.agent:1.000000|g|#version:1", read())
+	assert.NoError(Client.Count("datadog.process.payloads.dropped", 1, nil, 1))
+	assert.Equal("staging.datadog.process.payloads.dropped:1|c", read())
+}
@@ -0,0 +1,102 @@
+package cache
+
+import (
+	"container/list"
+	"sync"
+	"time"
+)
+
+// LRU is a thread-safe in-memory cache holding at most maxEntries entries, evicting the
+// least recently used one when full. Entries also expire ttl after they were last added,
+// and are removed on lookup or by Sweep, e.g. the ones of processes that have exited.
+type LRU struct {
+	maxEntries int
+	ttl        time.Duration
+
+	mu      sync.Mutex
+	entries map[interface{}]*list.Element
+	order   *list.List // Front is the most recently used
+}
+
+type lruEntry struct {
+	key     interface{}
+	val     interface{}
+	expires time.Time
+}
+
+// NewLRU returns an empty LRU cache. A maxEntries of 0 or less doesn't limit the number
+// of entries, a ttl of 0 or less doesn't expire them.
+func NewLRU(maxEntries int, ttl time.Duration) *LRU {
+	return &LRU{
+		maxEntries: maxEntries,
+		ttl:        ttl,
+		entries:    make(map[interface{}]*list.Element),
+		order:      list.New(),
+	}
+}
+
+// Get returns the value of the key if it is cached and not expired at the given time.
+func (c *LRU) Get(key interface{}, now time.Time) (interface{}, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	e, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+	entry := e.Value.(*lruEntry)
+	if c.expired(entry, now) {
+		c.remove(e)
+		return nil, false
+	}
+	c.order.MoveToFront(e)
+	return entry.val, true
+}
+
+// Add stores the value of the key, expiring ttl after the given time. The least
+// recently used entry is evicted if the cache is full.
+func (c *LRU) Add(key, val interface{}, now time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if e, ok := c.entries[key]; ok {
+		entry := e.Value.(*lruEntry)
+		entry.val, entry.expires = val, now.Add(c.ttl)
+		c.order.MoveToFront(e)
+		return
+	}
+	c.entries[key] = c.order.PushFront(&lruEntry{key: key, val: val, expires: now.Add(c.ttl)})
+	if c.maxEntries > 0 && c.order.Len() > c.maxEntries {
+		c.remove(c.order.Back())
+	}
+}
+
+// Sweep removes the entries expired at the given time, returning how many were removed.
+func (c *LRU) Sweep(now time.Time) int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	removed := 0
+	for e := c.order.Front(); e != nil; {
+		next := e.Next()
+		if c.expired(e.Value.(*lruEntry), now) {
+			c.remove(e)
+			removed++
+		}
+		e = next
+	}
+	return removed
+}
+
+// Len returns the number of cached entries, including the expired ones not swept yet.
+func (c *LRU) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.order.Len()
+}
+
+func (c *LRU) expired(entry *lruEntry, now time.Time) bool {
+	return c.ttl > 0 && !now.Before(entry.expires)
+}
+
+func (c *LRU) remove(e *list.Element) {
+	c.order.Remove(e)
+	delete(c.entries, e.Value.(*lruEntry).key)
+}
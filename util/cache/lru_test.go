@@ -0,0 +1,63 @@
+package cache
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLRUExpiry(t *testing.T) {
+	assert := assert.New(t)
+	now := time.Now()
+	c := NewLRU(0, time.Minute)
+
+	c.Add(int32(1), "nginx", now)
+	c.Add(int32(2), "redis-server", now.Add(30*time.Second))
+	v, ok := c.Get(int32(1), now.Add(59*time.Second))
+	assert.True(ok)
+	assert.Equal("nginx", v)
+
+	// Expired entries aren't returned, and are removed on lookup
+	_, ok = c.Get(int32(1), now.Add(time.Minute))
+	assert.False(ok)
+	assert.Equal(1, c.Len())
+
+	// Adding an entry again extends its lifetime
+	c.Add(int32(2), "redis-server", now.Add(time.Minute))
+	c.Add(int32(3), "sshd", now.Add(time.Minute))
+	assert.Equal(0, c.Sweep(now.Add(time.Minute+59*time.Second)))
+	assert.Equal(2, c.Sweep(now.Add(2*time.Minute)))
+	assert.Equal(0, c.Len())
+
+	// Entries don't expire without a TTL
+	c = NewLRU(0, 0)
+	c.Add("key", "value", now)
+	assert.Equal(0, c.Sweep(now.Add(24*time.Hour)))
+	_, ok = c.Get("key", now.Add(24*time.Hour))
+	assert.True(ok)
+}
+
+func TestLRUMaxEntries(t *testing.T) {
+	assert := assert.New(t)
+	now := time.Now()
+	c := NewLRU(2, time.Minute)
+
+	c.Add(1, "a", now)
+	c.Add(2, "b", now)
+	// 1 is now more recently used than 2, which is evicted
+	_, ok := c.Get(1, now)
+	assert.True(ok)
+	c.Add(3, "c", now)
+	assert.Equal(2, c.Len())
+	_, ok = c.Get(2, now)
+	assert.False(ok)
+
+	// Updating an entry doesn't evict anything
+	c.Add(3, "d", now)
+	assert.Equal(2, c.Len())
+	v, _ := c.Get(3, now)
+	assert.Equal("d", v)
+	_, ok = c.Get(1, now)
+	assert.True(ok)
+}
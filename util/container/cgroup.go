@@ -0,0 +1,286 @@
+package container
+
+import (
+	"bufio"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	log "github.com/cihub/seelog"
+
+	"github.com/DataDog/datadog-agent/pkg/util/docker"
+
+	"github.com/DataDog/datadog-process-agent/util"
+)
+
+// userHZ is the clock tick rate used by cgroup v1 cpuacct.stat. The v2 cpu.stat
+// reports microseconds so we convert to ticks to keep the same units for both.
+const userHZ = 100
+
+// IsCgroupV2 returns true if the host is using the cgroup v2 unified hierarchy.
+func IsCgroupV2() bool {
+	return isCgroupV2(util.HostSys("fs", "cgroup"))
+}
+
+func isCgroupV2(cgroupRoot string) bool {
+	return util.PathExists(filepath.Join(cgroupRoot, "cgroup.controllers"))
+}
+
+// fillCgroupV2Stats populates the CPU and memory stats of the given containers
+// from the unified hierarchy. The docker utilities only understand the v1
+// layout, so this is needed on hosts that default to cgroup v2.
+func fillCgroupV2Stats(procRoot, cgroupRoot string, containers []*docker.Container) {
+	for _, ctr := range containers {
+		if len(ctr.Pids) == 0 {
+			continue
+		}
+		path, err := cgroupV2Path(procRoot, ctr.Pids[0])
+		if err != nil {
+			log.Debugf("unable to find cgroup v2 path for container %s: %s", ctr.ID, err)
+			continue
+		}
+		dir := filepath.Join(cgroupRoot, path)
+
+		if cpu, err := readCgroupV2CPU(dir); err == nil {
+			cpu.ContainerID = ctr.ID
+			if ctr.CPU != nil {
+				cpu.SystemUsage = ctr.CPU.SystemUsage
+			}
+			ctr.CPU = cpu
+		} else {
+			log.Debugf("unable to read cgroup v2 cpu stats for container %s: %s", ctr.ID, err)
+		}
+
+		if mem, limit, err := readCgroupV2Memory(dir); err == nil {
+			mem.ContainerID = ctr.ID
+			ctr.Memory = mem
+			if limit > 0 {
+				ctr.MemLimit = limit
+			}
+		} else {
+			log.Debugf("unable to read cgroup v2 memory stats for container %s: %s", ctr.ID, err)
+		}
+	}
+}
+
+// cgroupV2Path returns the unified hierarchy path of the given pid, read from
+// the "0::<path>" entry of /proc/<pid>/cgroup.
+func cgroupV2Path(procRoot string, pid int32) (string, error) {
+	lines, err := util.ReadLines(filepath.Join(procRoot, strconv.Itoa(int(pid)), "cgroup"))
+	if err != nil {
+		return "", err
+	}
+	for _, l := range lines {
+		if strings.HasPrefix(l, "0::") {
+			return strings.TrimPrefix(l, "0::"), nil
+		}
+	}
+	return "", fmt.Errorf("no unified hierarchy entry for pid %d", pid)
+}
+
+// readOOMKilled returns true if the kernel killed a process of the cgroup of the given
+// pid for going over its memory limit. An error is returned if the kernel doesn't report
+// OOM kills, which requires Linux 4.13 or later with cgroup v1.
+func readOOMKilled(procRoot, cgroupRoot string, pid int32) (bool, error) {
+	var eventsFile string
+	if isCgroupV2(cgroupRoot) {
+		path, err := cgroupV2Path(procRoot, pid)
+		if err != nil {
+			return false, err
+		}
+		eventsFile = filepath.Join(cgroupRoot, path, "memory.events")
+	} else {
+		path, err := cgroupV1Path(procRoot, pid, "memory")
+		if err != nil {
+			return false, err
+		}
+		eventsFile = filepath.Join(cgroupRoot, "memory", path, "memory.oom_control")
+	}
+
+	events, err := readCgroupV2KeyValues(eventsFile)
+	if err != nil {
+		return false, err
+	}
+	kills, ok := events["oom_kill"]
+	if !ok {
+		return false, fmt.Errorf("no oom_kill count in %s", eventsFile)
+	}
+	return kills > 0, nil
+}
+
+// cgroupV1Path returns the path of the given pid in the hierarchy of the given controller,
+// read from the "<id>:<controllers>:<path>" entries of /proc/<pid>/cgroup.
+func cgroupV1Path(procRoot string, pid int32, controller string) (string, error) {
+	lines, err := util.ReadLines(filepath.Join(procRoot, strconv.Itoa(int(pid)), "cgroup"))
+	if err != nil {
+		return "", err
+	}
+	for _, l := range lines {
+		parts := strings.SplitN(l, ":", 3)
+		if len(parts) != 3 {
+			continue
+		}
+		for _, c := range strings.Split(parts[1], ",") {
+			if c == controller {
+				return parts[2], nil
+			}
+		}
+	}
+	return "", fmt.Errorf("no %s hierarchy entry for pid %d", controller, pid)
+}
+
+// cgroupV1MemoryUnlimited is the lowest memory.limit_in_bytes reported for unlimited
+// cgroups, which is the max int64 rounded down to the page size.
+const cgroupV1MemoryUnlimited = uint64(1) << 62
+
+// fillCgroupV1Stats populates the CPU and memory stats of the given containers from
+// their cpuacct and memory hierarchies, for runtimes that don't report them.
+func fillCgroupV1Stats(procRoot, cgroupRoot string, containers []*docker.Container) {
+	for _, ctr := range containers {
+		if len(ctr.Pids) == 0 {
+			continue
+		}
+
+		if path, err := cgroupV1Path(procRoot, ctr.Pids[0], "cpuacct"); err == nil {
+			dir := filepath.Join(cgroupRoot, "cpuacct", path)
+			if stats, err := readCgroupV2KeyValues(filepath.Join(dir, "cpuacct.stat")); err == nil {
+				cpu := &docker.CgroupTimesStat{ContainerID: ctr.ID, User: stats["user"], System: stats["system"]}
+				if usage, err := readCgroupV2Value(filepath.Join(dir, "cpuacct.usage")); err == nil {
+					cpu.UsageTotal = float64(usage)
+				}
+				ctr.CPU = cpu
+			} else {
+				log.Debugf("unable to read cgroup v1 cpu stats for container %s: %s", ctr.ID, err)
+			}
+		}
+
+		if path, err := cgroupV1Path(procRoot, ctr.Pids[0], "memory"); err == nil {
+			dir := filepath.Join(cgroupRoot, "memory", path)
+			if stats, err := readCgroupV2KeyValues(filepath.Join(dir, "memory.stat")); err == nil {
+				ctr.Memory = &docker.CgroupMemStat{
+					ContainerID: ctr.ID,
+					Cache:       stats["cache"],
+					RSS:         stats["rss"],
+					RSSHuge:     stats["rss_huge"],
+					MappedFile:  stats["mapped_file"],
+					Swap:        stats["swap"],
+				}
+			} else {
+				log.Debugf("unable to read cgroup v1 memory stats for container %s: %s", ctr.ID, err)
+			}
+			if limit, err := readCgroupV2Value(filepath.Join(dir, "memory.limit_in_bytes")); err == nil && limit < cgroupV1MemoryUnlimited {
+				ctr.MemLimit = limit
+			}
+		}
+	}
+}
+
+// readCgroupPids returns the pids of the processes of the cgroup of the given pid,
+// read from the cgroup.procs file of its memory or unified hierarchy.
+func readCgroupPids(procRoot, cgroupRoot string, pid int32) ([]int32, error) {
+	var procsFile string
+	if isCgroupV2(cgroupRoot) {
+		path, err := cgroupV2Path(procRoot, pid)
+		if err != nil {
+			return nil, err
+		}
+		procsFile = filepath.Join(cgroupRoot, path, "cgroup.procs")
+	} else {
+		path, err := cgroupV1Path(procRoot, pid, "memory")
+		if err != nil {
+			return nil, err
+		}
+		procsFile = filepath.Join(cgroupRoot, "memory", path, "cgroup.procs")
+	}
+
+	lines, err := util.ReadLines(procsFile)
+	if err != nil {
+		return nil, err
+	}
+	pids := make([]int32, 0, len(lines))
+	for _, l := range lines {
+		if p, err := strconv.ParseInt(strings.TrimSpace(l), 10, 32); err == nil {
+			pids = append(pids, int32(p))
+		}
+	}
+	return pids, nil
+}
+
+// readCgroupV2CPU reads the user and system time from cpu.stat.
+func readCgroupV2CPU(dir string) (*docker.CgroupTimesStat, error) {
+	stats, err := readCgroupV2KeyValues(filepath.Join(dir, "cpu.stat"))
+	if err != nil {
+		return nil, err
+	}
+	usecPerTick := uint64(1000000 / userHZ)
+	return &docker.CgroupTimesStat{
+		User:       stats["user_usec"] / usecPerTick,
+		System:     stats["system_usec"] / usecPerTick,
+		UsageTotal: float64(stats["usage_usec"] * 1000),
+	}, nil
+}
+
+// readCgroupV2Memory reads the memory usage breakdown from memory.stat along with
+// the limit from memory.max, which is 0 if the cgroup is unlimited.
+func readCgroupV2Memory(dir string) (*docker.CgroupMemStat, uint64, error) {
+	stats, err := readCgroupV2KeyValues(filepath.Join(dir, "memory.stat"))
+	if err != nil {
+		return nil, 0, err
+	}
+	mem := &docker.CgroupMemStat{
+		Cache:      stats["file"],
+		RSS:        stats["anon"],
+		RSSHuge:    stats["anon_thp"],
+		MappedFile: stats["file_mapped"],
+	}
+	if v, err := readCgroupV2Value(filepath.Join(dir, "memory.swap.current")); err == nil {
+		mem.Swap = v
+	}
+
+	var limit uint64
+	if v, err := readCgroupV2Value(filepath.Join(dir, "memory.max")); err == nil {
+		limit = v
+	}
+	return mem, limit, nil
+}
+
+// readCgroupV2KeyValues parses a flat keyed file such as cpu.stat or memory.stat.
+// The v1 memory.oom_control file uses the same format.
+func readCgroupV2KeyValues(path string) (map[string]uint64, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	values := make(map[string]uint64)
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) != 2 {
+			continue
+		}
+		v, err := strconv.ParseUint(fields[1], 10, 64)
+		if err != nil {
+			continue
+		}
+		values[fields[0]] = v
+	}
+	return values, scanner.Err()
+}
+
+// readCgroupV2Value parses a single value file. "max" is reported as 0.
+func readCgroupV2Value(path string) (uint64, error) {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return 0, err
+	}
+	v := strings.TrimSpace(string(b))
+	if v == "max" {
+		return 0, nil
+	}
+	return strconv.ParseUint(v, 10, 64)
+}
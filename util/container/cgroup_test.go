@@ -0,0 +1,87 @@
+package container
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/DataDog/datadog-agent/pkg/util/docker"
+	"github.com/stretchr/testify/assert"
+)
+
+// writeFixture creates a file with the given contents under root, creating parent directories.
+func writeFixture(t *testing.T, root, path, contents string) {
+	p := filepath.Join(root, path)
+	assert.NoError(t, os.MkdirAll(filepath.Dir(p), 0755))
+	assert.NoError(t, ioutil.WriteFile(p, []byte(contents), 0644))
+}
+
+func TestIsCgroupV2(t *testing.T) {
+	root, err := ioutil.TempDir("", "cgroup")
+	assert.NoError(t, err)
+	defer os.RemoveAll(root)
+
+	// cgroup v1 has one hierarchy per controller and no cgroup.controllers at the root
+	v1 := filepath.Join(root, "v1")
+	writeFixture(t, v1, "memory/docker/abc/memory.usage_in_bytes", "1024\n")
+	writeFixture(t, v1, "cpuacct/docker/abc/cpuacct.stat", "user 10\nsystem 5\n")
+	assert.False(t, isCgroupV2(v1))
+
+	v2 := filepath.Join(root, "v2")
+	writeFixture(t, v2, "cgroup.controllers", "cpuset cpu io memory pids\n")
+	assert.True(t, isCgroupV2(v2))
+}
+
+func TestFillCgroupV2Stats(t *testing.T) {
+	assert := assert.New(t)
+	root, err := ioutil.TempDir("", "cgroup")
+	assert.NoError(err)
+	defer os.RemoveAll(root)
+
+	procRoot := filepath.Join(root, "proc")
+	cgroupRoot := filepath.Join(root, "cgroup")
+	scope := "/system.slice/docker-abc.scope"
+
+	writeFixture(t, procRoot, "42/cgroup", "0::"+scope+"\n")
+	writeFixture(t, procRoot, "43/cgroup", "12:memory:/docker/def\n")
+	writeFixture(t, cgroupRoot, "cgroup.controllers", "cpuset cpu io memory pids\n")
+	writeFixture(t, cgroupRoot, scope+"/cpu.stat", "usage_usec 3500000\nuser_usec 2500000\nsystem_usec 1000000\n")
+	writeFixture(t, cgroupRoot, scope+"/memory.stat", "anon 4096\nfile 8192\nanon_thp 0\nfile_mapped 1024\n")
+	writeFixture(t, cgroupRoot, scope+"/memory.swap.current", "512\n")
+	writeFixture(t, cgroupRoot, scope+"/memory.max", "268435456\n")
+
+	ctrs := []*docker.Container{
+		{ID: "abc", Pids: []int32{42}, CPU: &docker.CgroupTimesStat{SystemUsage: 99}},
+		{ID: "def", Pids: []int32{43}},
+		{ID: "none"},
+	}
+	fillCgroupV2Stats(procRoot, cgroupRoot, ctrs)
+
+	assert.Equal(&docker.CgroupTimesStat{
+		ContainerID: "abc",
+		User:        250,
+		System:      100,
+		UsageTotal:  3500000000,
+		SystemUsage: 99,
+	}, ctrs[0].CPU)
+	assert.Equal(&docker.CgroupMemStat{
+		ContainerID: "abc",
+		RSS:         4096,
+		Cache:       8192,
+		MappedFile:  1024,
+		Swap:        512,
+	}, ctrs[0].Memory)
+	assert.Equal(uint64(268435456), ctrs[0].MemLimit)
+
+	// Containers without a unified hierarchy entry are left untouched
+	assert.Nil(ctrs[1].CPU)
+	assert.Nil(ctrs[1].Memory)
+	assert.Nil(ctrs[2].CPU)
+
+	// An unlimited cgroup keeps the limit reported by the runtime
+	writeFixture(t, cgroupRoot, scope+"/memory.max", "max\n")
+	ctrs[0].MemLimit = 1
+	fillCgroupV2Stats(procRoot, cgroupRoot, ctrs[:1])
+	assert.Equal(uint64(1), ctrs[0].MemLimit)
+}
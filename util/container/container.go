@@ -5,25 +5,39 @@ package container
 import (
 	"errors"
 	"fmt"
+	"io/ioutil"
+	"strconv"
 	"strings"
-
-	log "github.com/cihub/seelog"
+	"time"
 
 	"github.com/DataDog/datadog-agent/pkg/config"
 	"github.com/DataDog/datadog-agent/pkg/util/docker"
 	"github.com/DataDog/datadog-agent/pkg/util/ecs"
+	log "github.com/cihub/seelog"
+	"github.com/docker/docker/api/types"
+
+	"github.com/DataDog/datadog-process-agent/util"
+	"github.com/DataDog/datadog-process-agent/util/cache"
 )
 
-var (
-	listeners []config.Listeners
-	// hasFatalError stores whether a listener has fatally error'd, to see if we should keep accessing its containers
-	hasFatalError map[string]bool
+// restartCountCacheDuration is how long the restart counts from the runtime are cached
+// to avoid inspecting every container on every check run.
+const restartCountCacheDuration = 30 * time.Second
+
+// labelsCacheDuration is how long the labels from the runtime are cached. They can't change
+// during the life of a container, the cache only has to let go of the exited ones.
+const labelsCacheDuration = 5 * time.Minute
+
+// Names of the container runtimes supported with the docker build tag.
+const (
+	RuntimeDocker     = "docker"
+	RuntimeECSFargate = "ecs_fargate"
 )
 
-// Unmarshal the listeners once and store the result
-func initContainerListeners() {
-	listeners = GetDefaultListeners()
-	hasFatalError = make(map[string]bool)
+func init() {
+	registerProvider(RuntimeDocker, func() bool { return true }, newDockerProvider)
+	registerProvider(RuntimeECSFargate, ecs.IsFargateInstance, func() ContainerProvider { return ecsFargateProvider{} })
+	registerProvider(RuntimeContainerd, detectContainerd, newContainerdProvider)
 }
 
 // GetDefaultListeners returns the default auto-discovery listeners, for use in container retrieval
@@ -36,60 +50,237 @@ func GetDefaultListeners() []config.Listeners {
 	return l
 }
 
-// GetContainers is the unique method that returns all containers on the host (or in the task)
-// and that other agents can consume so that we don't have to convert all containers to the format.
+// dockerClient is the subset of the docker util used by the dockerProvider.
+type dockerClient interface {
+	Containers(cfg *docker.ContainerListConfig) ([]*docker.Container, error)
+	RawContainerList(options types.ContainerListOptions) ([]types.Container, error)
+}
+
+// dockerProvider lists the containers from the docker daemon.
 // NOTE: This is a modified copy of datadog-agent/pkg/util/container to prevent noisy logging
-func GetContainers() ([]*docker.Container, error) {
-	if listeners == nil {
-		initContainerListeners()
+type dockerProvider struct {
+	// hasFatalError stores whether connecting to docker permanently failed, to stop trying
+	hasFatalError bool
+	// getClient returns the docker util, replaced in tests
+	getClient func() (dockerClient, error)
+
+	procRoot   string
+	cgroupRoot string
+	// A pid of each of the containers listed without the network stats, to find their cgroups
+	pids map[string]int32
+}
+
+func newDockerProvider() ContainerProvider {
+	return &dockerProvider{
+		getClient:  func() (dockerClient, error) { return docker.GetDockerUtil() },
+		procRoot:   util.HostProc(),
+		cgroupRoot: util.HostSys("fs", "cgroup"),
+		pids:       make(map[string]int32),
 	}
+}
 
-	containers := make([]*docker.Container, 0)
-	errs := make([]error, 0)
-	ctrListConfig := docker.ContainerListConfig{
+func (p *dockerProvider) GetContainers() ([]*docker.Container, error) {
+	if p.hasFatalError {
+		return nil, errors.New("unable to connect to docker")
+	}
+	du, err := p.getClient()
+	if err != nil {
+		// If connecting permanently fails, we should skip further attempts (and its subsequent logging)
+		if strings.HasPrefix(err.Error(), "permanent failure") {
+			p.hasFatalError = true
+		}
+		return nil, fmt.Errorf("unable to connect to docker - %s", err)
+	}
+	if !collectsNetwork() {
+		return p.listContainers(du)
+	}
+	ctrs, err := du.Containers(&docker.ContainerListConfig{
 		IncludeExited: false,
 		FlagExcluded:  false,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get container list from docker - %s", err)
 	}
-	succeeded := false
+	if isCgroupV2(p.cgroupRoot) {
+		fillCgroupV2Stats(p.procRoot, p.cgroupRoot, ctrs)
+	}
+	return ctrs, nil
+}
 
-	for _, l := range listeners {
-		if hasFatalError[l.Name] {
+// listContainers returns the running containers without their network stats. The docker
+// util inspects every new container to find its networks, so the containers are listed
+// with a single call to docker instead and their stats are read from their cgroups.
+func (p *dockerProvider) listContainers(du dockerClient) ([]*docker.Container, error) {
+	list, err := du.RawContainerList(types.ContainerListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get container list from docker - %s", err)
+	}
+
+	pids := p.containerPids(list)
+	containers := make([]*docker.Container, 0, len(list))
+	for _, c := range list {
+		pid, ok := pids[c.ID]
+		if !ok {
+			// Exited since it was listed
 			continue
 		}
+		ctrPids, err := readCgroupPids(p.procRoot, p.cgroupRoot, pid)
+		if err != nil || len(ctrPids) == 0 {
+			ctrPids = []int32{pid}
+		}
+		var name string
+		if len(c.Names) > 0 {
+			name = strings.TrimPrefix(c.Names[0], "/")
+		}
+		containers = append(containers, &docker.Container{
+			Type:     "Docker",
+			ID:       c.ID,
+			EntityID: docker.ContainerIDToEntityName(c.ID),
+			Name:     name,
+			Image:    c.Image,
+			ImageID:  c.ImageID,
+			Created:  c.Created,
+			State:    c.State,
+			Health:   parseContainerHealth(c.Status),
+			Pids:     ctrPids,
+			CPU:      &docker.CgroupTimesStat{ContainerID: c.ID},
+			Memory:   &docker.CgroupMemStat{ContainerID: c.ID},
+			IO:       &docker.CgroupIOStat{ContainerID: c.ID},
+		})
+	}
 
-		switch l.Name {
-		case "docker":
-			if du, err := docker.GetDockerUtil(); err == nil {
-				if ctrs, err := du.Containers(&ctrListConfig); err == nil {
-					succeeded = true
-					containers = append(containers, ctrs...)
-					continue
-				}
-				errs = append(errs, fmt.Errorf("failed to get container list from docker - %s", err))
-			} else {
-				// If connecting permanently fails, we should skip further attempts (and its subsequent logging)
-				if strings.HasPrefix(err.Error(), "permanent failure") {
-					hasFatalError[l.Name] = true
-				}
-				errs = append(errs, fmt.Errorf("unable to connect to docker - %s", err))
+	if isCgroupV2(p.cgroupRoot) {
+		fillCgroupV2Stats(p.procRoot, p.cgroupRoot, containers)
+	} else {
+		fillCgroupV1Stats(p.procRoot, p.cgroupRoot, containers)
+	}
+	return containers, nil
+}
+
+// containerPids returns a pid of each of the given containers, read from the cgroups of the
+// processes. The pids found are checked again on the next runs, the processes are only
+// scanned when a container isn't found this way, e.g. when it just started.
+func (p *dockerProvider) containerPids(list []types.Container) map[string]int32 {
+	found := make(map[string]int32, len(list))
+	missing := make(map[string]bool)
+	for _, c := range list {
+		if pid, ok := p.pids[c.ID]; ok {
+			if id, _ := readPidContainerID(p.procRoot, pid); id == c.ID {
+				found[c.ID] = pid
+				continue
 			}
-		case "ecs": // Fargate containers
-			if ctrs, err := ecs.GetContainers(); err != nil {
-				errs = append(errs, fmt.Errorf("failed to get container list from fargate - %s", err))
-			} else {
-				succeeded = true
-				containers = append(containers, ctrs...)
+		}
+		missing[c.ID] = true
+	}
+
+	if len(missing) > 0 {
+		entries, err := ioutil.ReadDir(p.procRoot)
+		if err != nil {
+			log.Debugf("unable to list the processes: %s", err)
+		}
+		for _, e := range entries {
+			pid, err := strconv.ParseInt(e.Name(), 10, 32)
+			if err != nil {
+				continue
+			}
+			if id, _ := readPidContainerID(p.procRoot, int32(pid)); missing[id] {
+				found[id] = int32(pid)
+				delete(missing, id)
+				if len(missing) == 0 {
+					break
+				}
 			}
 		}
 	}
+	// Only the listed containers are kept so that the exited ones don't accumulate
+	p.pids = found
+	return found
+}
+
+// parseContainerHealth returns the health of a container from its status as listed by
+// docker, e.g. "Up 5 minutes (healthy)", or an empty string if it has no health check.
+func parseContainerHealth(status string) string {
+	switch {
+	case strings.HasSuffix(status, "(healthy)"):
+		return "healthy"
+	case strings.HasSuffix(status, "(unhealthy)"):
+		return "unhealthy"
+	case strings.HasSuffix(status, "(health: starting)"):
+		return "starting"
+	}
+	return ""
+}
+
+// ecsFargateProvider lists the containers of the ECS Fargate task.
+type ecsFargateProvider struct{}
 
-	if succeeded { // Some container access method succeeded so drop errors from other access methods
-		return containers, nil
+func (ecsFargateProvider) GetContainers() ([]*docker.Container, error) {
+	ctrs, err := ecs.GetContainers()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get container list from fargate - %s", err)
+	}
+	if !collectsNetwork() {
+		for _, ctr := range ctrs {
+			ctr.Network = nil
+		}
 	}
+	return ctrs, nil
+}
+
+// GetCPUThrottling returns the CPU throttling stats of the given containers, keyed by container ID.
+func GetCPUThrottling(containers []*docker.Container) map[string]CPUThrottling {
+	return getCPUThrottling(util.HostProc(), util.HostSys("fs", "cgroup"), containers)
+}
 
-	for _, e := range errs {
-		log.Debug(e)
+// GetLifecycles returns the lifecycle signals of the given containers, keyed by container ID.
+func GetLifecycles(containers []*docker.Container) map[string]Lifecycle {
+	du, err := docker.GetDockerUtil()
+	if err != nil {
+		// Without access to the runtime only the cgroup signals are available
+		return getLifecycles(util.HostProc(), util.HostSys("fs", "cgroup"), containers, nil)
 	}
+	return getLifecycles(util.HostProc(), util.HostSys("fs", "cgroup"), containers, func(id string) (int32, error) {
+		cacheKey := "container_restart_count:" + id
+		if n, ok := cache.Get(cacheKey); ok {
+			return n.(int32), nil
+		}
+		info, err := du.Inspect(id, false)
+		if err != nil {
+			return 0, err
+		}
+		if info.ContainerJSONBase == nil {
+			return 0, fmt.Errorf("no inspect information for container %s", id)
+		}
+		n := int32(info.RestartCount)
+		cache.SetWithTTL(cacheKey, n, restartCountCacheDuration)
+		return n, nil
+	})
+}
 
-	return containers, errors.New("failed to get containers from any source")
+// GetLabelTags returns the tags made from the labels of the given containers, keyed by
+// container ID, see getLabelTags.
+func GetLabelTags(containers []*docker.Container, labelTags map[string]string, maxTags int) map[string][]string {
+	if len(labelTags) == 0 {
+		return nil
+	}
+	du, err := docker.GetDockerUtil()
+	if err != nil {
+		return nil
+	}
+	return getLabelTags(containers, labelTags, maxTags, func(id string) (map[string]string, error) {
+		cacheKey := "container_labels:" + id
+		if labels, ok := cache.Get(cacheKey); ok {
+			return labels.(map[string]string), nil
+		}
+		info, err := du.Inspect(id, false)
+		if err != nil {
+			return nil, err
+		}
+		var labels map[string]string
+		if info.Config != nil {
+			labels = info.Config.Labels
+		}
+		cache.SetWithTTL(cacheKey, labels, labelsCacheDuration)
+		return labels, nil
+	})
 }
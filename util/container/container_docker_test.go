@@ -0,0 +1,103 @@
+// +build docker
+
+package container
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/DataDog/datadog-agent/pkg/util/docker"
+	"github.com/docker/docker/api/types"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeDockerClient returns fixed containers and counts the calls made to it.
+type fakeDockerClient struct {
+	containers     []*docker.Container
+	list           []types.Container
+	containerCalls int
+	listCalls      int
+}
+
+func (c *fakeDockerClient) Containers(cfg *docker.ContainerListConfig) ([]*docker.Container, error) {
+	c.containerCalls++
+	return c.containers, nil
+}
+
+func (c *fakeDockerClient) RawContainerList(options types.ContainerListOptions) ([]types.Container, error) {
+	c.listCalls++
+	return c.list, nil
+}
+
+func TestDockerProviderCollectNetwork(t *testing.T) {
+	assert := assert.New(t)
+	root, err := ioutil.TempDir("", "docker")
+	assert.NoError(err)
+	defer os.RemoveAll(root)
+	defer SetCollectNetwork(true)
+
+	id := strings.Repeat("ab", 32)
+	procRoot := filepath.Join(root, "proc")
+	cgroupRoot := filepath.Join(root, "cgroup")
+	path := "/docker/" + id
+	writeFixture(t, procRoot, "1/cgroup", "4:memory:/\n")
+	writeFixture(t, procRoot, "42/cgroup", "5:cpu,cpuacct:"+path+"\n4:memory:"+path+"\n")
+	writeFixture(t, cgroupRoot, "memory"+path+"/cgroup.procs", "42\n45\n")
+	writeFixture(t, cgroupRoot, "memory"+path+"/memory.stat", "cache 8192\nrss 4096\n")
+	writeFixture(t, cgroupRoot, "cpuacct"+path+"/cpuacct.stat", "user 250\nsystem 100\n")
+
+	client := &fakeDockerClient{
+		containers: []*docker.Container{{ID: id, Network: docker.ContainerNetStats{{NetworkName: "bridge"}}}},
+		list: []types.Container{
+			{ID: id, Names: []string{"/web"}, Image: "nginx:1.15", Created: 1500000000, State: "running", Status: "Up 5 minutes (healthy)"},
+			{ID: strings.Repeat("cd", 32), Names: []string{"/exited"}, State: "running"},
+		},
+	}
+	p := &dockerProvider{
+		getClient:  func() (dockerClient, error) { return client, nil },
+		procRoot:   procRoot,
+		cgroupRoot: cgroupRoot,
+		pids:       make(map[string]int32),
+	}
+
+	ctrs, err := p.GetContainers()
+	assert.NoError(err)
+	assert.Equal(client.containers, ctrs)
+	assert.Equal(1, client.containerCalls)
+	assert.Equal(0, client.listCalls)
+
+	// Without the network stats the docker util, which inspects the containers, isn't used
+	SetCollectNetwork(false)
+	for i := 0; i < 2; i++ {
+		ctrs, err = p.GetContainers()
+		assert.NoError(err)
+		assert.Equal([]*docker.Container{{
+			Type:     "Docker",
+			ID:       id,
+			EntityID: "docker://" + id,
+			Name:     "web",
+			Image:    "nginx:1.15",
+			Created:  1500000000,
+			State:    "running",
+			Health:   "healthy",
+			Pids:     []int32{42, 45},
+			CPU:      &docker.CgroupTimesStat{ContainerID: id, User: 250, System: 100},
+			Memory:   &docker.CgroupMemStat{ContainerID: id, Cache: 8192, RSS: 4096},
+			IO:       &docker.CgroupIOStat{ContainerID: id},
+		}}, ctrs)
+	}
+	assert.Equal(1, client.containerCalls)
+	assert.Equal(2, client.listCalls)
+	assert.Equal(map[string]int32{id: 42}, p.pids)
+}
+
+func TestParseContainerHealth(t *testing.T) {
+	assert := assert.New(t)
+	assert.Equal("healthy", parseContainerHealth("Up 5 minutes (healthy)"))
+	assert.Equal("unhealthy", parseContainerHealth("Up 2 hours (unhealthy)"))
+	assert.Equal("starting", parseContainerHealth("Up 3 seconds (health: starting)"))
+	assert.Equal("", parseContainerHealth("Up 5 minutes"))
+}
@@ -0,0 +1,200 @@
+package container
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	log "github.com/cihub/seelog"
+
+	"github.com/DataDog/datadog-agent/pkg/util/docker"
+
+	"github.com/DataDog/datadog-process-agent/util"
+)
+
+// RuntimeContainerd is the name of the containerd runtime, see SetContainerRuntime.
+const RuntimeContainerd = "containerd"
+
+// Annotations set by the containerd CRI plugin on the containers of Kubernetes pods.
+const (
+	criContainerTypeAnnotation = "io.kubernetes.cri.container-type"
+	criContainerNameAnnotation = "io.kubernetes.cri.container-name"
+	criImageNameAnnotation     = "io.kubernetes.cri.image-name"
+)
+
+// containerdRuntimeDirs are the state directories of the containerd runtime shims,
+// each holding a directory per namespace with a bundle directory per task.
+var containerdRuntimeDirs = []string{"io.containerd.runtime.v2.task", "io.containerd.runtime.v1.linux"}
+
+// containerdTask is a container run by containerd.
+type containerdTask struct {
+	ID          string
+	Namespace   string
+	Pid         int32
+	Created     time.Time
+	Annotations map[string]string
+}
+
+// containerdClient lists the tasks run by containerd.
+type containerdClient interface {
+	Tasks() ([]containerdTask, error)
+}
+
+// containerdStateClient reads the tasks from the bundles containerd keeps in its state
+// directory, next to its socket. Unlike the containerd API this needs no client
+// library and works with the directory mounted read-only in the agent container.
+type containerdStateClient struct {
+	root string
+}
+
+// Tasks returns the tasks of all the namespaces and runtime shims.
+func (c containerdStateClient) Tasks() ([]containerdTask, error) {
+	var tasks []containerdTask
+	found := false
+	for _, runtime := range containerdRuntimeDirs {
+		namespaces, err := ioutil.ReadDir(filepath.Join(c.root, runtime))
+		if err != nil {
+			continue
+		}
+		found = true
+		for _, ns := range namespaces {
+			if !ns.IsDir() {
+				continue
+			}
+			bundles, err := ioutil.ReadDir(filepath.Join(c.root, runtime, ns.Name()))
+			if err != nil {
+				log.Debugf("unable to list containerd namespace %s: %s", ns.Name(), err)
+				continue
+			}
+			for _, b := range bundles {
+				if !b.IsDir() {
+					continue
+				}
+				task, err := readContainerdTask(filepath.Join(c.root, runtime, ns.Name(), b.Name()), ns.Name())
+				if err != nil {
+					log.Debugf("unable to read containerd task %s: %s", b.Name(), err)
+					continue
+				}
+				tasks = append(tasks, task)
+			}
+		}
+	}
+	if !found {
+		return nil, fmt.Errorf("no containerd runtime state in %s", c.root)
+	}
+	return tasks, nil
+}
+
+// readContainerdTask reads a task from its bundle: the pid of its init process from
+// init.pid, and its annotations from the OCI spec in config.json.
+func readContainerdTask(bundle, namespace string) (containerdTask, error) {
+	pidFile := filepath.Join(bundle, "init.pid")
+	b, err := ioutil.ReadFile(pidFile)
+	if err != nil {
+		return containerdTask{}, err
+	}
+	pid, err := strconv.ParseInt(strings.TrimSpace(string(b)), 10, 32)
+	if err != nil {
+		return containerdTask{}, fmt.Errorf("invalid init pid: %s", err)
+	}
+	task := containerdTask{ID: filepath.Base(bundle), Namespace: namespace, Pid: int32(pid)}
+	// The pid file is written once the init process started
+	if fi, err := os.Stat(pidFile); err == nil {
+		task.Created = fi.ModTime()
+	}
+
+	var spec struct {
+		Annotations map[string]string `json:"annotations"`
+	}
+	if b, err := ioutil.ReadFile(filepath.Join(bundle, "config.json")); err == nil {
+		if err := json.Unmarshal(b, &spec); err != nil {
+			log.Debugf("unable to parse the OCI spec of containerd task %s: %s", task.ID, err)
+		}
+	}
+	task.Annotations = spec.Annotations
+	return task, nil
+}
+
+// containerdRoot returns the directory of the containerd socket and state.
+func containerdRoot() string {
+	return util.GetEnv("CONTAINERD_STATE_PATH", "/run/containerd")
+}
+
+// detectContainerd returns true if containerd runs on the host without docker, which
+// otherwise already reports the containers it runs through containerd.
+func detectContainerd() bool {
+	if _, err := util.GetDockerSocketPath(); err == nil {
+		return false
+	}
+	return util.PathExists(filepath.Join(containerdRoot(), "containerd.sock"))
+}
+
+func newContainerdProvider() ContainerProvider {
+	return &containerdProvider{
+		client:     containerdStateClient{root: containerdRoot()},
+		procRoot:   util.HostProc(),
+		cgroupRoot: util.HostSys("fs", "cgroup"),
+	}
+}
+
+// containerdProvider lists the running containers of containerd, with their stats read
+// from their cgroups.
+type containerdProvider struct {
+	client     containerdClient
+	procRoot   string
+	cgroupRoot string
+}
+
+func (p *containerdProvider) GetContainers() ([]*docker.Container, error) {
+	tasks, err := p.client.Tasks()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get container list from containerd - %s", err)
+	}
+
+	containers := make([]*docker.Container, 0, len(tasks))
+	for _, t := range tasks {
+		// Pod sandboxes only hold the namespaces shared by the containers of the pod
+		if t.Annotations[criContainerTypeAnnotation] == "sandbox" {
+			continue
+		}
+		// The bundle of an exited task is kept until it is deleted
+		if !util.PathExists(filepath.Join(p.procRoot, strconv.Itoa(int(t.Pid)))) {
+			continue
+		}
+
+		var created int64
+		if !t.Created.IsZero() {
+			created = t.Created.Unix()
+		}
+		pids, err := readCgroupPids(p.procRoot, p.cgroupRoot, t.Pid)
+		if err != nil || len(pids) == 0 {
+			pids = []int32{t.Pid}
+		}
+		containers = append(containers, &docker.Container{
+			Type:      RuntimeContainerd,
+			ID:        t.ID,
+			EntityID:  RuntimeContainerd + "://" + t.ID,
+			Name:      t.Annotations[criContainerNameAnnotation],
+			Image:     t.Annotations[criImageNameAnnotation],
+			Created:   created,
+			StartedAt: created,
+			State:     "running",
+			Pids:      pids,
+			CPU:       &docker.CgroupTimesStat{ContainerID: t.ID},
+			Memory:    &docker.CgroupMemStat{ContainerID: t.ID},
+			IO:        &docker.CgroupIOStat{ContainerID: t.ID},
+		})
+	}
+
+	if isCgroupV2(p.cgroupRoot) {
+		fillCgroupV2Stats(p.procRoot, p.cgroupRoot, containers)
+	} else {
+		fillCgroupV1Stats(p.procRoot, p.cgroupRoot, containers)
+	}
+	return containers, nil
+}
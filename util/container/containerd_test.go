@@ -0,0 +1,108 @@
+package container
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/DataDog/datadog-agent/pkg/util/docker"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeContainerdClient returns a fixed list of tasks or error.
+type fakeContainerdClient struct {
+	tasks []containerdTask
+	err   error
+}
+
+func (c fakeContainerdClient) Tasks() ([]containerdTask, error) {
+	return c.tasks, c.err
+}
+
+func TestContainerdStateClient(t *testing.T) {
+	assert := assert.New(t)
+	root, err := ioutil.TempDir("", "containerd")
+	assert.NoError(err)
+	defer os.RemoveAll(root)
+
+	_, err = containerdStateClient{root: root}.Tasks()
+	assert.Error(err)
+
+	writeFixture(t, root, "io.containerd.runtime.v2.task/k8s.io/abc/init.pid", "42\n")
+	writeFixture(t, root, "io.containerd.runtime.v2.task/k8s.io/abc/config.json",
+		`{"ociVersion":"1.0.1","annotations":{"io.kubernetes.cri.container-name":"web"}}`)
+	writeFixture(t, root, "io.containerd.runtime.v2.task/moby/def/init.pid", "43")
+	// Starting tasks have no pid yet
+	writeFixture(t, root, "io.containerd.runtime.v2.task/moby/ghi/config.json", "{}")
+	writeFixture(t, root, "io.containerd.runtime.v1.linux/default/jkl/init.pid", "44")
+
+	tasks, err := containerdStateClient{root: root}.Tasks()
+	assert.NoError(err)
+	sort.Slice(tasks, func(i, j int) bool { return tasks[i].ID < tasks[j].ID })
+	assert.Len(tasks, 3)
+	for _, task := range tasks {
+		assert.False(task.Created.IsZero())
+	}
+	assert.Equal(containerdTask{ID: "abc", Namespace: "k8s.io", Pid: 42, Created: tasks[0].Created,
+		Annotations: map[string]string{criContainerNameAnnotation: "web"}}, tasks[0])
+	assert.Equal(containerdTask{ID: "def", Namespace: "moby", Pid: 43, Created: tasks[1].Created}, tasks[1])
+	assert.Equal(containerdTask{ID: "jkl", Namespace: "default", Pid: 44, Created: tasks[2].Created}, tasks[2])
+}
+
+func TestContainerdProvider(t *testing.T) {
+	assert := assert.New(t)
+	root, err := ioutil.TempDir("", "containerd")
+	assert.NoError(err)
+	defer os.RemoveAll(root)
+
+	procRoot := filepath.Join(root, "proc")
+	cgroupRoot := filepath.Join(root, "cgroup")
+	path := "/kubepods/besteffort/pod1/abc"
+	writeFixture(t, procRoot, "42/cgroup", "5:cpu,cpuacct:"+path+"\n4:memory:"+path+"\n")
+	writeFixture(t, procRoot, "50/cgroup", "0::/\n")
+	writeFixture(t, cgroupRoot, "memory"+path+"/cgroup.procs", "42\n45\n")
+	writeFixture(t, cgroupRoot, "memory"+path+"/memory.stat", "cache 8192\nrss 4096\nrss_huge 0\nmapped_file 1024\nswap 512\n")
+	writeFixture(t, cgroupRoot, "memory"+path+"/memory.limit_in_bytes", "268435456\n")
+	writeFixture(t, cgroupRoot, "cpuacct"+path+"/cpuacct.stat", "user 250\nsystem 100\n")
+	writeFixture(t, cgroupRoot, "cpuacct"+path+"/cpuacct.usage", "3500000000\n")
+
+	created := time.Unix(1500000000, 0)
+	p := &containerdProvider{
+		client: fakeContainerdClient{tasks: []containerdTask{
+			{ID: "abc", Pid: 42, Created: created, Annotations: map[string]string{
+				criContainerNameAnnotation: "web",
+				criImageNameAnnotation:     "nginx:1.15",
+			}},
+			{ID: "exited", Pid: 43, Created: created},
+			{ID: "sandbox", Pid: 50, Created: created, Annotations: map[string]string{criContainerTypeAnnotation: "sandbox"}},
+		}},
+		procRoot:   procRoot,
+		cgroupRoot: cgroupRoot,
+	}
+
+	ctrs, err := p.GetContainers()
+	assert.NoError(err)
+	assert.Equal([]*docker.Container{{
+		Type:      "containerd",
+		ID:        "abc",
+		EntityID:  "containerd://abc",
+		Name:      "web",
+		Image:     "nginx:1.15",
+		Created:   created.Unix(),
+		StartedAt: created.Unix(),
+		State:     "running",
+		Pids:      []int32{42, 45},
+		MemLimit:  268435456,
+		CPU:       &docker.CgroupTimesStat{ContainerID: "abc", User: 250, System: 100, UsageTotal: 3500000000},
+		Memory:    &docker.CgroupMemStat{ContainerID: "abc", Cache: 8192, RSS: 4096, MappedFile: 1024, Swap: 512},
+		IO:        &docker.CgroupIOStat{ContainerID: "abc"},
+	}}, ctrs)
+
+	p.client = fakeContainerdClient{err: errors.New("unreachable")}
+	_, err = p.GetContainers()
+	assert.Error(err)
+}
@@ -12,8 +12,17 @@ func GetDefaultListeners() []config.Listeners {
 	return nil
 }
 
-// GetContainers is the unique method that returns all containers on the host (or in the task)
-// and that other agents can consume so that we don't have to convert all containers to the format.
-func GetContainers() ([]*docker.Container, error) {
-	return make([]*docker.Container, 0), docker.ErrNotImplemented
+// GetLifecycles returns the lifecycle signals of the given containers, keyed by container ID.
+func GetLifecycles(containers []*docker.Container) map[string]Lifecycle {
+	return nil
+}
+
+// GetCPUThrottling returns the CPU throttling stats of the given containers, keyed by container ID.
+func GetCPUThrottling(containers []*docker.Container) map[string]CPUThrottling {
+	return nil
+}
+
+// GetLabelTags returns the tags made from the labels of the given containers, keyed by container ID.
+func GetLabelTags(containers []*docker.Container, labelTags map[string]string, maxTags int) map[string][]string {
+	return nil
 }
@@ -0,0 +1,132 @@
+package container
+
+import (
+	"regexp"
+	"strings"
+
+	log "github.com/cihub/seelog"
+
+	"github.com/DataDog/datadog-agent/pkg/util/docker"
+)
+
+// Attributes of the containers that the patterns of a Filter can match.
+const (
+	filterName   = "name"
+	filterImage  = "image"
+	filterDigest = "digest"
+)
+
+// Filter excludes the containers matching a blacklist pattern unless they also match a
+// whitelist pattern. Patterns are regular expressions prefixed by the attribute they
+// match: name:<regexp>, image:<regexp> or digest:<regexp>, the latter matching the
+// image digest in its sha256:<hex> form.
+type Filter struct {
+	blacklist []filterPattern
+	whitelist []filterPattern
+}
+
+type filterPattern struct {
+	attribute string
+	re        *regexp.Regexp
+}
+
+// NewFilter returns a Filter from the blacklist and whitelist patterns, skipping the
+// invalid ones.
+func NewFilter(blacklist, whitelist []string) *Filter {
+	return &Filter{
+		blacklist: parseFilterPatterns(blacklist),
+		whitelist: parseFilterPatterns(whitelist),
+	}
+}
+
+func parseFilterPatterns(patterns []string) []filterPattern {
+	parsed := make([]filterPattern, 0, len(patterns))
+	for _, p := range patterns {
+		p = strings.TrimSpace(p)
+		if p == "" {
+			continue
+		}
+		parts := strings.SplitN(p, ":", 2)
+		if len(parts) != 2 || (parts[0] != filterName && parts[0] != filterImage && parts[0] != filterDigest) {
+			log.Warnf("Ignoring container filter %s, expected name:, image: or digest: prefix", p)
+			continue
+		}
+		re, err := regexp.Compile(parts[1])
+		if err != nil {
+			log.Warnf("Ignoring invalid container filter %s: %s", p, err)
+			continue
+		}
+		parsed = append(parsed, filterPattern{attribute: parts[0], re: re})
+	}
+	return parsed
+}
+
+// IsExcluded returns whether the container is left out by the filter. A nil Filter
+// excludes nothing.
+func (f *Filter) IsExcluded(ctr *docker.Container) bool {
+	if f == nil {
+		return false
+	}
+	return matchesAny(f.blacklist, ctr) && !matchesAny(f.whitelist, ctr)
+}
+
+// Filter returns the containers that aren't excluded.
+func (f *Filter) Filter(containers []*docker.Container) []*docker.Container {
+	if f == nil || len(f.blacklist) == 0 {
+		return containers
+	}
+	kept := make([]*docker.Container, 0, len(containers))
+	for _, ctr := range containers {
+		if !f.IsExcluded(ctr) {
+			kept = append(kept, ctr)
+		}
+	}
+	return kept
+}
+
+func matchesAny(patterns []filterPattern, ctr *docker.Container) bool {
+	for _, p := range patterns {
+		var value string
+		switch p.attribute {
+		case filterName:
+			value = ctr.Name
+		case filterImage:
+			value = ctr.Image
+		case filterDigest:
+			value = ImageDigest(ctr)
+		}
+		if value != "" && p.re.MatchString(value) {
+			return true
+		}
+	}
+	return false
+}
+
+// ImageDigest returns the content digest of the image of the container in its
+// sha256:<hex> form, or "" if the runtime doesn't expose it.
+func ImageDigest(ctr *docker.Container) string {
+	id := ctr.ImageID
+	// Image references can be pinned by digest, e.g. nginx@sha256:<hex>
+	if i := strings.LastIndex(id, "@"); i != -1 {
+		id = id[i+1:]
+	}
+	if strings.HasPrefix(id, "sha256:") {
+		return id
+	}
+	if isSHA256(id) {
+		return "sha256:" + id
+	}
+	return ""
+}
+
+func isSHA256(s string) bool {
+	if len(s) != 64 {
+		return false
+	}
+	for _, c := range s {
+		if (c < '0' || c > '9') && (c < 'a' || c > 'f') {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,59 @@
+package container
+
+import (
+	"testing"
+
+	"github.com/DataDog/datadog-agent/pkg/util/docker"
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	nginxDigest = "sha256:4f5e6a2d1c8b9a0f7e3d2c1b0a9f8e7d6c5b4a3f2e1d0c9b8a7f6e5d4c3b2a10"
+	redisDigest = "sha256:9a8b7c6d5e4f3a2b1c0d9e8f7a6b5c4d3e2f1a0b9c8d7e6f5a4b3c2d1e0f9a8b"
+)
+
+func TestImageDigest(t *testing.T) {
+	for imageID, digest := range map[string]string{
+		nginxDigest:                              nginxDigest,
+		nginxDigest[len("sha256:"):]:             nginxDigest,
+		"docker.io/library/nginx@" + nginxDigest: nginxDigest,
+		"nginx:1.15":                             "",
+		"":                                       "",
+	} {
+		assert.Equal(t, digest, ImageDigest(&docker.Container{ImageID: imageID}), imageID)
+	}
+}
+
+func TestFilterDigest(t *testing.T) {
+	assert := assert.New(t)
+	nginx := &docker.Container{ID: "a", Name: "web", Image: "nginx:1.15", ImageID: nginxDigest}
+	redis := &docker.Container{ID: "b", Name: "cache", Image: "redis:5", ImageID: redisDigest[len("sha256:"):]}
+	unknown := &docker.Container{ID: "c", Name: "job", Image: "busybox"}
+
+	// Full and abbreviated digests match
+	f := NewFilter([]string{"digest:" + nginxDigest, "digest:^sha256:9a8b7c6d"}, nil)
+	assert.True(f.IsExcluded(nginx))
+	assert.True(f.IsExcluded(redis))
+	assert.False(f.IsExcluded(unknown))
+	assert.Equal([]*docker.Container{unknown}, f.Filter([]*docker.Container{nginx, redis, unknown}))
+
+	// The whitelist wins over the blacklist
+	f = NewFilter([]string{"image:.*"}, []string{"digest:" + redisDigest, "name:^job$"})
+	assert.True(f.IsExcluded(nginx))
+	assert.False(f.IsExcluded(redis))
+	assert.False(f.IsExcluded(unknown))
+}
+
+func TestFilterPatterns(t *testing.T) {
+	assert := assert.New(t)
+	ctr := &docker.Container{Name: "web", Image: "gcr.io/google_containers/pause-amd64:3.0"}
+
+	// Invalid patterns are skipped
+	f := NewFilter([]string{"web", "tag:web", "name:[", "", "image:gcr.io/google_containers/pause.*"}, nil)
+	assert.Len(f.blacklist, 1)
+	assert.True(f.IsExcluded(ctr))
+
+	var nilFilter *Filter
+	assert.False(nilFilter.IsExcluded(ctr))
+	assert.Equal([]*docker.Container{ctr}, nilFilter.Filter([]*docker.Container{ctr}))
+}
@@ -0,0 +1,46 @@
+package container
+
+import (
+	"sort"
+
+	log "github.com/cihub/seelog"
+
+	"github.com/DataDog/datadog-agent/pkg/util/docker"
+)
+
+// labelsFunc returns the labels of the given container.
+type labelsFunc func(id string) (map[string]string, error)
+
+// getLabelTags returns the tags made from the labels of the containers, keyed by container
+// ID. Only the labels in labelTags are reported, as tags named after the tag key they map
+// to, e.g. the app.kubernetes.io/version label as version:1.2. At most maxTags tags are
+// reported per container, in the order of their tag key, 0 doesn't limit them.
+func getLabelTags(containers []*docker.Container, labelTags map[string]string, maxTags int, labels labelsFunc) map[string][]string {
+	if len(labelTags) == 0 {
+		return nil
+	}
+	tags := make(map[string][]string, len(containers))
+	for _, ctr := range containers {
+		ctrLabels, err := labels(ctr.ID)
+		if err != nil {
+			log.Debugf("unable to get labels for container %s: %s", ctr.ID, err)
+			continue
+		}
+		var ctrTags []string
+		for label, tagKey := range labelTags {
+			if v, ok := ctrLabels[label]; ok && v != "" {
+				ctrTags = append(ctrTags, tagKey+":"+v)
+			}
+		}
+		if len(ctrTags) == 0 {
+			continue
+		}
+		sort.Strings(ctrTags)
+		if maxTags > 0 && len(ctrTags) > maxTags {
+			log.Debugf("container %s has %d label tags, only reporting the first %d", ctr.ID, len(ctrTags), maxTags)
+			ctrTags = ctrTags[:maxTags]
+		}
+		tags[ctr.ID] = ctrTags
+	}
+	return tags
+}
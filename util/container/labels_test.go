@@ -0,0 +1,43 @@
+package container
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/DataDog/datadog-agent/pkg/util/docker"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetLabelTags(t *testing.T) {
+	assert := assert.New(t)
+	containers := []*docker.Container{{ID: "foo"}, {ID: "bar"}, {ID: "baz"}}
+	labels := func(id string) (map[string]string, error) {
+		switch id {
+		case "foo":
+			return map[string]string{
+				"app.kubernetes.io/version": "1.2",
+				"com.example.team":          "core",
+				"com.example.owner":         "",
+				"unmapped":                  "value",
+			}, nil
+		case "bar":
+			return map[string]string{"unmapped": "value"}, nil
+		}
+		return nil, fmt.Errorf("no such container: %s", id)
+	}
+	labelTags := map[string]string{
+		"app.kubernetes.io/version": "version",
+		"com.example.team":          "team",
+		"com.example.owner":         "owner",
+	}
+
+	tags := getLabelTags(containers, labelTags, 0, labels)
+	assert.Equal(map[string][]string{"foo": {"team:core", "version:1.2"}}, tags)
+
+	// Only the first tags in the order of their key are reported past the cap
+	tags = getLabelTags(containers, labelTags, 1, labels)
+	assert.Equal(map[string][]string{"foo": {"team:core"}}, tags)
+
+	// No label is reported by default
+	assert.Nil(getLabelTags(containers, nil, 0, labels))
+}
@@ -0,0 +1,44 @@
+package container
+
+import (
+	log "github.com/cihub/seelog"
+
+	"github.com/DataDog/datadog-agent/pkg/util/docker"
+)
+
+// Lifecycle holds the lifecycle signals of a container, as opposed to its resource usage.
+// Signals that the runtime or the kernel don't expose are left to their zero value.
+type Lifecycle struct {
+	// Number of times the runtime restarted the container
+	RestartCount int32
+	// Whether a process of the container was killed for going over its memory limit
+	OOMKilled bool
+}
+
+// restartCountFunc returns the number of times the runtime restarted the given container.
+type restartCountFunc func(id string) (int32, error)
+
+// getLifecycles returns the lifecycle signals of the containers keyed by container ID,
+// using the runtime for the restart counts and the memory cgroups for OOM kills.
+func getLifecycles(procRoot, cgroupRoot string, containers []*docker.Container, restartCount restartCountFunc) map[string]Lifecycle {
+	lifecycles := make(map[string]Lifecycle, len(containers))
+	for _, ctr := range containers {
+		var l Lifecycle
+		if restartCount != nil {
+			if n, err := restartCount(ctr.ID); err == nil {
+				l.RestartCount = n
+			} else {
+				log.Debugf("unable to get restart count for container %s: %s", ctr.ID, err)
+			}
+		}
+		if len(ctr.Pids) > 0 {
+			if killed, err := readOOMKilled(procRoot, cgroupRoot, ctr.Pids[0]); err == nil {
+				l.OOMKilled = killed
+			} else {
+				log.Debugf("unable to get OOM kills for container %s: %s", ctr.ID, err)
+			}
+		}
+		lifecycles[ctr.ID] = l
+	}
+	return lifecycles
+}
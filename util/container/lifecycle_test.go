@@ -0,0 +1,62 @@
+package container
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/DataDog/datadog-agent/pkg/util/docker"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetLifecycles(t *testing.T) {
+	assert := assert.New(t)
+	root, err := ioutil.TempDir("", "cgroup")
+	assert.NoError(err)
+	defer os.RemoveAll(root)
+
+	// cgroup v1, the "old" kernel doesn't report OOM kills in memory.oom_control
+	procRoot := filepath.Join(root, "proc")
+	cgroupRoot := filepath.Join(root, "cgroup")
+	writeFixture(t, procRoot, "42/cgroup", "5:cpu,cpuacct:/docker/abc\n4:memory:/docker/abc\n")
+	writeFixture(t, procRoot, "43/cgroup", "4:memory:/docker/def\n")
+	writeFixture(t, procRoot, "44/cgroup", "4:memory:/docker/old\n")
+	writeFixture(t, cgroupRoot, "memory/docker/abc/memory.oom_control", "oom_kill_disable 0\nunder_oom 0\noom_kill 2\n")
+	writeFixture(t, cgroupRoot, "memory/docker/def/memory.oom_control", "oom_kill_disable 0\nunder_oom 0\noom_kill 0\n")
+	writeFixture(t, cgroupRoot, "memory/docker/old/memory.oom_control", "oom_kill_disable 0\nunder_oom 0\n")
+
+	restarts := map[string]int32{"abc": 3, "def": 0}
+	fakeRuntime := func(id string) (int32, error) {
+		if n, ok := restarts[id]; ok {
+			return n, nil
+		}
+		return 0, fmt.Errorf("unknown container %s", id)
+	}
+
+	ctrs := []*docker.Container{
+		{ID: "abc", Pids: []int32{42}},
+		{ID: "def", Pids: []int32{43}},
+		{ID: "old", Pids: []int32{44}},
+		{ID: "none"},
+	}
+	assert.Equal(map[string]Lifecycle{
+		"abc":  {RestartCount: 3, OOMKilled: true},
+		"def":  {},
+		"old":  {},
+		"none": {},
+	}, getLifecycles(procRoot, cgroupRoot, ctrs, fakeRuntime))
+
+	// Without a runtime only the OOM kills are reported
+	assert.Equal(Lifecycle{OOMKilled: true}, getLifecycles(procRoot, cgroupRoot, ctrs[:1], nil)["abc"])
+
+	// cgroup v2 reports OOM kills in memory.events
+	v2Root := filepath.Join(root, "cgroup2")
+	scope := "/system.slice/docker-abc.scope"
+	writeFixture(t, procRoot, "45/cgroup", "0::"+scope+"\n")
+	writeFixture(t, v2Root, "cgroup.controllers", "cpuset cpu io memory pids\n")
+	writeFixture(t, v2Root, scope+"/memory.events", "low 0\nhigh 0\nmax 4\noom 1\noom_kill 1\n")
+	lifecycles := getLifecycles(procRoot, v2Root, []*docker.Container{{ID: "abc", Pids: []int32{45}}}, fakeRuntime)
+	assert.Equal(Lifecycle{RestartCount: 3, OOMKilled: true}, lifecycles["abc"])
+}
@@ -0,0 +1,89 @@
+package container
+
+import (
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/DataDog/datadog-process-agent/util"
+	"github.com/DataDog/datadog-process-agent/util/cache"
+)
+
+// containerIDPattern matches the container ID at the end of a cgroup path, such as
+// /docker/<id>, /kubepods/burstable/pod<uid>/<id> or the systemd scopes
+// /system.slice/docker-<id>.scope and cri-containerd-<id>.scope.
+var containerIDPattern = regexp.MustCompile(`(?:^|[/-])([0-9a-f]{64})(?:\.scope)?$`)
+
+var globalPidCache = &pidContainerCache{}
+
+// GetPidContainerID returns the ID of the container the given process runs in, read from
+// its /proc/<pid>/cgroup, or an empty string if it doesn't run in a container. This covers
+// processes of containers the runtime didn't list, e.g. with containerd or CRI-O. The
+// result is cached for ttl, keyed by pid and create time as pids can be reused, and at
+// most maxEntries processes are cached.
+func GetPidContainerID(pid int32, createTime int64, ttl time.Duration, maxEntries int) string {
+	return globalPidCache.get(util.HostProc(), pid, createTime, ttl, maxEntries, time.Now())
+}
+
+type pidKey struct {
+	pid        int32
+	createTime int64
+}
+
+// pidContainerCache caches the container IDs of the processes. Expired entries are swept
+// at most once per TTL so that exited processes don't accumulate.
+type pidContainerCache struct {
+	sync.Mutex
+	ttl        time.Duration
+	maxEntries int
+	entries    *cache.LRU
+	lastSweep  time.Time
+}
+
+func (c *pidContainerCache) get(procRoot string, pid int32, createTime int64, ttl time.Duration, maxEntries int, now time.Time) string {
+	c.Lock()
+	defer c.Unlock()
+
+	// The cache is created on first use, and again if the config changed
+	if c.entries == nil || c.ttl != ttl || c.maxEntries != maxEntries {
+		c.entries = cache.NewLRU(maxEntries, ttl)
+		c.ttl, c.maxEntries = ttl, maxEntries
+	}
+	if now.Sub(c.lastSweep) >= ttl {
+		c.entries.Sweep(now)
+		c.lastSweep = now
+	}
+
+	key := pidKey{pid: pid, createTime: createTime}
+	if id, ok := c.entries.Get(key, now); ok {
+		return id.(string)
+	}
+	// Processes that aren't in a container, or that exited since they were collected,
+	// are cached too so their cgroup isn't read again on every run.
+	id, _ := readPidContainerID(procRoot, pid)
+	c.entries.Add(key, id, now)
+	return id
+}
+
+// readPidContainerID returns the container ID found in the cgroup paths of the given pid,
+// or an empty string if the process is in the root cgroup or a non-container cgroup such
+// as a systemd slice.
+func readPidContainerID(procRoot string, pid int32) (string, error) {
+	lines, err := util.ReadLines(filepath.Join(procRoot, strconv.Itoa(int(pid)), "cgroup"))
+	if err != nil {
+		return "", err
+	}
+	for _, l := range lines {
+		parts := strings.SplitN(l, ":", 3)
+		if len(parts) != 3 {
+			continue
+		}
+		if m := containerIDPattern.FindStringSubmatch(parts[2]); m != nil {
+			return m[1], nil
+		}
+	}
+	return "", nil
+}
@@ -0,0 +1,72 @@
+package container
+
+import (
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReadPidContainerID(t *testing.T) {
+	assert := assert.New(t)
+	procRoot, err := ioutil.TempDir("", "proc")
+	assert.NoError(err)
+	defer os.RemoveAll(procRoot)
+
+	id := strings.Repeat("0123456789abcdef", 4)
+	writeFixture(t, procRoot, "1/cgroup", "4:memory:/\n3:cpu,cpuacct:/\n0::/\n")
+	writeFixture(t, procRoot, "2/cgroup", "4:memory:/docker/"+id+"\n3:cpu,cpuacct:/docker/"+id+"\n")
+	writeFixture(t, procRoot, "3/cgroup", "4:memory:/kubepods/burstable/pod5f0a7c3e-8b4d-11e8-9a2b-0a580a040105/"+id+"\n")
+	writeFixture(t, procRoot, "4/cgroup", "0::/system.slice/docker-"+id+".scope\n")
+	writeFixture(t, procRoot, "5/cgroup", "0::/kubepods.slice/kubepods-pod1.slice/cri-containerd-"+id+".scope\n")
+	writeFixture(t, procRoot, "6/cgroup", "1:name=systemd:/user.slice/user-1000.slice/session-2.scope\n0::/user.slice\n")
+
+	for pid, expected := range map[int32]string{1: "", 2: id, 3: id, 4: id, 5: id, 6: ""} {
+		actual, err := readPidContainerID(procRoot, pid)
+		assert.NoError(err)
+		assert.Equal(expected, actual, "pid %d", pid)
+	}
+
+	// The process exited since it was collected
+	actual, err := readPidContainerID(procRoot, 7)
+	assert.Error(err)
+	assert.Equal("", actual)
+}
+
+func TestPidContainerCache(t *testing.T) {
+	assert := assert.New(t)
+	procRoot, err := ioutil.TempDir("", "proc")
+	assert.NoError(err)
+	defer os.RemoveAll(procRoot)
+
+	first, second := strings.Repeat("a", 64), strings.Repeat("b", 64)
+	writeFixture(t, procRoot, "42/cgroup", "4:memory:/docker/"+first+"\n")
+
+	c := &pidContainerCache{}
+	now := time.Now()
+	ttl := 10 * time.Second
+	assert.Equal(first, c.get(procRoot, 42, 100, ttl, 10, now))
+
+	// The mapping is cached for the TTL
+	writeFixture(t, procRoot, "42/cgroup", "4:memory:/docker/"+second+"\n")
+	assert.Equal(first, c.get(procRoot, 42, 100, ttl, 10, now.Add(time.Second)))
+
+	// A reused pid is a different process
+	assert.Equal(second, c.get(procRoot, 42, 200, ttl, 10, now.Add(time.Second)))
+
+	assert.Equal(second, c.get(procRoot, 42, 100, ttl, 10, now.Add(ttl)))
+
+	// Entries of exited processes are purged
+	assert.Equal("", c.get(procRoot, 43, 100, ttl, 10, now.Add(ttl)))
+	c.get(procRoot, 42, 100, ttl, 10, now.Add(3*ttl))
+	assert.Equal(1, c.entries.Len())
+
+	// At most maxEntries processes are cached
+	for pid := int32(100); pid < 105; pid++ {
+		c.get(procRoot, pid, 100, ttl, 3, now.Add(3*ttl))
+	}
+	assert.Equal(3, c.entries.Len())
+}
@@ -0,0 +1,146 @@
+package container
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	log "github.com/cihub/seelog"
+
+	"github.com/DataDog/datadog-agent/pkg/util/docker"
+)
+
+// RuntimeAuto selects the providers of all the container runtimes detected on the host.
+const RuntimeAuto = "auto"
+
+// ContainerProvider is a source of the containers running on the host, such as a
+// container runtime. The containers use the docker model whatever their runtime.
+type ContainerProvider interface {
+	// GetContainers returns the running containers.
+	GetContainers() ([]*docker.Container, error)
+}
+
+// providerFactory creates the provider of a container runtime.
+type providerFactory struct {
+	name string
+	// detect returns true if the runtime is available on the host
+	detect func() bool
+	new    func() ContainerProvider
+}
+
+var (
+	// providerFactories holds the runtimes supported by this build, in detection order.
+	providerFactories []providerFactory
+
+	providerMu sync.Mutex
+	provider   ContainerProvider
+	// collectNetwork is whether the providers collect the network stats of the containers
+	collectNetwork = true
+)
+
+// registerProvider adds a container runtime to the ones that can be selected.
+func registerProvider(name string, detect func() bool, newProvider func() ContainerProvider) {
+	providerFactories = append(providerFactories, providerFactory{name: name, detect: detect, new: newProvider})
+}
+
+// SetContainerRuntime selects the provider of the containers returned by GetContainers.
+// Empty or "auto" combines the providers of all the runtimes detected on the host.
+func SetContainerRuntime(runtime string) error {
+	p, err := selectProvider(runtime, providerFactories)
+	if err != nil {
+		return err
+	}
+	providerMu.Lock()
+	provider = p
+	providerMu.Unlock()
+	return nil
+}
+
+// SetCollectNetwork sets whether the providers collect the network stats of the containers
+// returned by GetContainers, which can take extra calls to the runtime.
+func SetCollectNetwork(enabled bool) {
+	providerMu.Lock()
+	collectNetwork = enabled
+	providerMu.Unlock()
+}
+
+func collectsNetwork() bool {
+	providerMu.Lock()
+	defer providerMu.Unlock()
+	return collectNetwork
+}
+
+// GetContainers is the unique method that returns all containers on the host (or in the task)
+// and that other agents can consume so that we don't have to convert all containers to the format.
+// The runtimes are detected on first use unless one was selected with SetContainerRuntime.
+func GetContainers() ([]*docker.Container, error) {
+	providerMu.Lock()
+	if provider == nil {
+		provider, _ = selectProvider(RuntimeAuto, providerFactories)
+	}
+	p := provider
+	providerMu.Unlock()
+
+	if p == nil {
+		return make([]*docker.Container, 0), docker.ErrNotImplemented
+	}
+	return p.GetContainers()
+}
+
+func selectProvider(runtime string, factories []providerFactory) (ContainerProvider, error) {
+	runtime = strings.ToLower(strings.TrimSpace(runtime))
+	if runtime != "" && runtime != RuntimeAuto {
+		names := make([]string, 0, len(factories))
+		for _, f := range factories {
+			if f.name == runtime {
+				return f.new(), nil
+			}
+			names = append(names, f.name)
+		}
+		sort.Strings(names)
+		return nil, fmt.Errorf("unsupported container runtime '%s', choose from: %s", runtime, strings.Join(append(names, RuntimeAuto), ", "))
+	}
+
+	var detected multiProvider
+	for _, f := range factories {
+		if f.detect() {
+			detected = append(detected, f.new())
+		}
+	}
+	if len(detected) == 0 {
+		return nil, errors.New("no container runtime detected")
+	}
+	return detected, nil
+}
+
+// multiProvider returns the containers of several providers, e.g. docker and ECS Fargate.
+type multiProvider []ContainerProvider
+
+// GetContainers returns the containers of all the providers. It only fails if none of
+// them succeeded.
+func (m multiProvider) GetContainers() ([]*docker.Container, error) {
+	containers := make([]*docker.Container, 0)
+	errs := make([]error, 0)
+	succeeded := false
+	for _, p := range m {
+		ctrs, err := p.GetContainers()
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		succeeded = true
+		containers = append(containers, ctrs...)
+	}
+
+	if succeeded { // Some container access method succeeded so drop errors from other access methods
+		return containers, nil
+	}
+
+	for _, e := range errs {
+		log.Debug(e)
+	}
+
+	return containers, errors.New("failed to get containers from any source")
+}
@@ -0,0 +1,70 @@
+package container
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/DataDog/datadog-agent/pkg/util/docker"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeProvider returns a fixed list of containers or error.
+type fakeProvider struct {
+	containers []*docker.Container
+	err        error
+}
+
+func (p *fakeProvider) GetContainers() ([]*docker.Container, error) {
+	return p.containers, p.err
+}
+
+func fakeFactory(name string, detected bool, p *fakeProvider) providerFactory {
+	return providerFactory{
+		name:   name,
+		detect: func() bool { return detected },
+		new:    func() ContainerProvider { return p },
+	}
+}
+
+func TestSelectProvider(t *testing.T) {
+	assert := assert.New(t)
+	first := &fakeProvider{containers: []*docker.Container{{ID: "first"}}}
+	second := &fakeProvider{containers: []*docker.Container{{ID: "second"}}}
+	factories := []providerFactory{
+		fakeFactory("first", true, first),
+		fakeFactory("second", false, second),
+	}
+
+	// An explicit runtime is used even if it wasn't detected
+	p, err := selectProvider(" Second ", factories)
+	assert.NoError(err)
+	assert.Equal(second, p)
+
+	_, err = selectProvider("cri-o", factories)
+	assert.EqualError(err, "unsupported container runtime 'cri-o', choose from: first, second, auto")
+
+	// Otherwise only the detected runtimes are used
+	for _, runtime := range []string{"", RuntimeAuto} {
+		p, err = selectProvider(runtime, factories)
+		assert.NoError(err)
+		assert.Equal(multiProvider{first}, p)
+	}
+
+	_, err = selectProvider(RuntimeAuto, factories[1:])
+	assert.Error(err)
+}
+
+func TestMultiProvider(t *testing.T) {
+	assert := assert.New(t)
+	ok := &fakeProvider{containers: []*docker.Container{{ID: "abc"}, {ID: "def"}}}
+	failed := &fakeProvider{err: errors.New("unreachable")}
+
+	// A failing provider doesn't prevent collecting the containers of the others
+	ctrs, err := multiProvider{failed, ok}.GetContainers()
+	assert.NoError(err)
+	assert.Equal(ok.containers, ctrs)
+
+	ctrs, err = multiProvider{failed, failed}.GetContainers()
+	assert.Error(err)
+	assert.Empty(ctrs)
+}
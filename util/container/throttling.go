@@ -0,0 +1,82 @@
+package container
+
+import (
+	"path/filepath"
+
+	log "github.com/cihub/seelog"
+
+	"github.com/DataDog/datadog-agent/pkg/util/docker"
+)
+
+// CPUThrottling holds the cumulative CPU throttling stats of a container, which only
+// increase for containers with a CPU limit.
+type CPUThrottling struct {
+	// Number of enforcement periods of the CPU limit
+	NrPeriods uint64
+	// Number of periods the container used up its quota in
+	NrThrottled uint64
+	// Total time the container was throttled for, in nanoseconds
+	ThrottledTime uint64
+}
+
+// getCPUThrottling returns the CPU throttling stats of the containers keyed by container ID,
+// from the cpu.stat file of their cpu or unified hierarchy. Containers whose cgroup can't be
+// read are left out.
+func getCPUThrottling(procRoot, cgroupRoot string, containers []*docker.Container) map[string]CPUThrottling {
+	v2 := isCgroupV2(cgroupRoot)
+	throttling := make(map[string]CPUThrottling, len(containers))
+	for _, ctr := range containers {
+		if len(ctr.Pids) == 0 {
+			continue
+		}
+		var t CPUThrottling
+		var err error
+		if v2 {
+			t, err = readCgroupV2Throttling(procRoot, cgroupRoot, ctr.Pids[0])
+		} else {
+			t, err = readCgroupV1Throttling(procRoot, cgroupRoot, ctr.Pids[0])
+		}
+		if err != nil {
+			log.Debugf("unable to read cpu throttling stats for container %s: %s", ctr.ID, err)
+			continue
+		}
+		throttling[ctr.ID] = t
+	}
+	return throttling
+}
+
+// readCgroupV1Throttling reads the cpu.stat file of the cpu hierarchy, which reports the
+// throttled time in nanoseconds.
+func readCgroupV1Throttling(procRoot, cgroupRoot string, pid int32) (CPUThrottling, error) {
+	path, err := cgroupV1Path(procRoot, pid, "cpu")
+	if err != nil {
+		return CPUThrottling{}, err
+	}
+	stats, err := readCgroupV2KeyValues(filepath.Join(cgroupRoot, "cpu", path, "cpu.stat"))
+	if err != nil {
+		return CPUThrottling{}, err
+	}
+	return CPUThrottling{
+		NrPeriods:     stats["nr_periods"],
+		NrThrottled:   stats["nr_throttled"],
+		ThrottledTime: stats["throttled_time"],
+	}, nil
+}
+
+// readCgroupV2Throttling reads the cpu.stat file of the unified hierarchy, which reports the
+// throttled time in microseconds.
+func readCgroupV2Throttling(procRoot, cgroupRoot string, pid int32) (CPUThrottling, error) {
+	path, err := cgroupV2Path(procRoot, pid)
+	if err != nil {
+		return CPUThrottling{}, err
+	}
+	stats, err := readCgroupV2KeyValues(filepath.Join(cgroupRoot, path, "cpu.stat"))
+	if err != nil {
+		return CPUThrottling{}, err
+	}
+	return CPUThrottling{
+		NrPeriods:     stats["nr_periods"],
+		NrThrottled:   stats["nr_throttled"],
+		ThrottledTime: stats["throttled_usec"] * 1000,
+	}, nil
+}
@@ -0,0 +1,49 @@
+package container
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/DataDog/datadog-agent/pkg/util/docker"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetCPUThrottling(t *testing.T) {
+	assert := assert.New(t)
+	root, err := ioutil.TempDir("", "cgroup")
+	assert.NoError(err)
+	defer os.RemoveAll(root)
+
+	// cgroup v1, with the throttled time in nanoseconds. def has no CPU limit.
+	procRoot := filepath.Join(root, "proc")
+	cgroupRoot := filepath.Join(root, "cgroup")
+	writeFixture(t, procRoot, "42/cgroup", "5:cpu,cpuacct:/docker/abc\n4:memory:/docker/abc\n")
+	writeFixture(t, procRoot, "43/cgroup", "5:cpu,cpuacct:/docker/def\n4:memory:/docker/def\n")
+	writeFixture(t, procRoot, "44/cgroup", "4:memory:/docker/nocpu\n")
+	writeFixture(t, cgroupRoot, "cpu/docker/abc/cpu.stat", "nr_periods 1200\nnr_throttled 300\nthrottled_time 45000000000\n")
+	writeFixture(t, cgroupRoot, "cpu/docker/def/cpu.stat", "nr_periods 0\nnr_throttled 0\nthrottled_time 0\n")
+
+	ctrs := []*docker.Container{
+		{ID: "abc", Pids: []int32{42}},
+		{ID: "def", Pids: []int32{43}},
+		{ID: "nocpu", Pids: []int32{44}},
+		{ID: "none"},
+	}
+	assert.Equal(map[string]CPUThrottling{
+		"abc": {NrPeriods: 1200, NrThrottled: 300, ThrottledTime: 45000000000},
+		"def": {},
+	}, getCPUThrottling(procRoot, cgroupRoot, ctrs))
+
+	// cgroup v2, with the throttled time in microseconds
+	v2Root := filepath.Join(root, "cgroup2")
+	scope := "/system.slice/docker-abc.scope"
+	writeFixture(t, procRoot, "45/cgroup", "0::"+scope+"\n")
+	writeFixture(t, v2Root, "cgroup.controllers", "cpuset cpu io memory pids\n")
+	writeFixture(t, v2Root, scope+"/cpu.stat", "usage_usec 90000000\nuser_usec 60000000\nsystem_usec 30000000\n"+
+		"nr_periods 1200\nnr_throttled 300\nthrottled_usec 45000000\n")
+	assert.Equal(map[string]CPUThrottling{
+		"abc": {NrPeriods: 1200, NrThrottled: 300, ThrottledTime: 45000000000},
+	}, getCPUThrottling(procRoot, v2Root, []*docker.Container{{ID: "abc", Pids: []int32{45}}, {ID: "gone", Pids: []int32{46}}}))
+}
@@ -0,0 +1,42 @@
+package container
+
+import (
+	"github.com/DataDog/datadog-agent/pkg/util/docker"
+)
+
+// Thresholds above which a timestamp is taken to be in milliseconds, microseconds or
+// nanoseconds rather than in seconds. In seconds they would be after the year 33658.
+const (
+	maxEpochSeconds      = 1e12
+	maxEpochMilliseconds = 1e15
+	maxEpochMicroseconds = 1e18
+)
+
+// Timestamps returns when the container was created and started, in seconds since the
+// epoch, or 0 if unknown. The runtimes don't agree on the unit of their timestamps, and
+// some of them only provide one: a container started without a creation time is reported
+// as created when it started, as it can't have been created later.
+func Timestamps(ctr *docker.Container) (created, started int64) {
+	created, started = toEpochSeconds(ctr.Created), toEpochSeconds(ctr.StartedAt)
+	if created == 0 {
+		created = started
+	}
+	return created, started
+}
+
+// toEpochSeconds normalizes a timestamp since the epoch to seconds. Negative timestamps,
+// e.g. the zero time.Time of a container that never started, are unknown.
+func toEpochSeconds(ts int64) int64 {
+	switch {
+	case ts <= 0:
+		return 0
+	case ts < maxEpochSeconds:
+		return ts
+	case ts < maxEpochMilliseconds:
+		return ts / 1e3
+	case ts < maxEpochMicroseconds:
+		return ts / 1e6
+	default:
+		return ts / 1e9
+	}
+}
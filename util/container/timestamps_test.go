@@ -0,0 +1,59 @@
+package container
+
+import (
+	"testing"
+
+	"github.com/DataDog/datadog-agent/pkg/util/docker"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTimestamps(t *testing.T) {
+	for _, tc := range []struct {
+		name             string
+		ctr              *docker.Container
+		created, started int64
+	}{
+		{
+			name:    "both in seconds",
+			ctr:     &docker.Container{Created: 1536000000, StartedAt: 1536000060},
+			created: 1536000000,
+			started: 1536000060,
+		},
+		{
+			name:    "created only",
+			ctr:     &docker.Container{Created: 1536000000},
+			created: 1536000000,
+		},
+		{
+			name:    "started only",
+			ctr:     &docker.Container{StartedAt: 1536000060},
+			created: 1536000060,
+			started: 1536000060,
+		},
+		{
+			name: "neither",
+			ctr:  &docker.Container{},
+		},
+		{
+			name:    "never started",
+			ctr:     &docker.Container{Created: 1536000000, StartedAt: -62135596800},
+			created: 1536000000,
+		},
+		{
+			name:    "milliseconds",
+			ctr:     &docker.Container{Created: 1536000000123, StartedAt: 1536000060456},
+			created: 1536000000,
+			started: 1536000060,
+		},
+		{
+			name:    "microseconds and nanoseconds",
+			ctr:     &docker.Container{Created: 1536000000123456, StartedAt: 1536000060123456789},
+			created: 1536000000,
+			started: 1536000060,
+		},
+	} {
+		created, started := Timestamps(tc.ctr)
+		assert.Equal(t, tc.created, created, tc.name)
+		assert.Equal(t, tc.started, started, tc.name)
+	}
+}
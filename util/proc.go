@@ -0,0 +1,257 @@
+package util
+
+import (
+	"encoding/hex"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// clockTicks is the USER_HZ unit of the times in /proc/<pid>/stat, which is 100 on
+// all the architectures we support.
+const clockTicks = 100
+
+// ReadBootTime returns the host boot time in seconds since the epoch, from the btime
+// line of <procRoot>/stat.
+func ReadBootTime(procRoot string) (int64, error) {
+	path := filepath.Join(procRoot, "stat")
+	lines, err := ReadLines(path)
+	if err != nil {
+		return 0, err
+	}
+	for _, l := range lines {
+		fields := strings.Fields(l)
+		if len(fields) == 2 && fields[0] == "btime" {
+			return strconv.ParseInt(fields[1], 10, 64)
+		}
+	}
+	return 0, fmt.Errorf("no btime in %s", path)
+}
+
+// ReadProcessStartOffset returns how long after the host boot the given process
+// started, from the starttime field of <procRoot>/<pid>/stat.
+func ReadProcessStartOffset(procRoot string, pid int32) (time.Duration, error) {
+	path := filepath.Join(procRoot, strconv.Itoa(int(pid)), "stat")
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return 0, err
+	}
+
+	// The command name can contain spaces and parentheses, so the fields are
+	// counted from the last closing parenthesis, which is followed by the state.
+	stat := string(b)
+	i := strings.LastIndex(stat, ")")
+	if i == -1 {
+		return 0, fmt.Errorf("invalid format in %s", path)
+	}
+	fields := strings.Fields(stat[i+1:])
+	// starttime is the 22nd field, the state being the 3rd
+	if len(fields) < 20 {
+		return 0, fmt.Errorf("missing starttime in %s", path)
+	}
+	ticks, err := strconv.ParseUint(fields[19], 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid starttime in %s: %s", path, err)
+	}
+	return time.Duration(ticks) * time.Second / clockTicks, nil
+}
+
+// ReadNetNamespace returns the inode of the network namespace of the given process, from
+// the target of the <procRoot>/<pid>/ns/net link, e.g. net:[4026531992].
+func ReadNetNamespace(procRoot string, pid int32) (uint32, error) {
+	link, err := os.Readlink(filepath.Join(procRoot, strconv.Itoa(int(pid)), "ns", "net"))
+	if err != nil {
+		return 0, err
+	}
+	if !strings.HasPrefix(link, "net:[") || !strings.HasSuffix(link, "]") {
+		return 0, fmt.Errorf("invalid network namespace link %s", link)
+	}
+	ino, err := strconv.ParseUint(link[len("net:["):len(link)-1], 10, 32)
+	if err != nil {
+		return 0, fmt.Errorf("invalid network namespace link %s: %s", link, err)
+	}
+	return uint32(ino), nil
+}
+
+// TCPSocketKey identifies a TCP socket by its local and remote address
+type TCPSocketKey struct {
+	LocalIP    string
+	LocalPort  uint16
+	RemoteIP   string
+	RemotePort uint16
+}
+
+// ReadTCPStates returns the state of the TCP sockets listed in a net/tcp or net/tcp6
+// file of procfs, numbered like in the kernel. The IPs are in their canonical form.
+func ReadTCPStates(path string) (map[TCPSocketKey]uint8, error) {
+	lines, err := ReadLines(path)
+	if err != nil {
+		return nil, err
+	}
+
+	states := make(map[TCPSocketKey]uint8, len(lines))
+	// The first line is the header
+	for i := 1; i < len(lines); i++ {
+		// sl local_address rem_address st ...
+		fields := strings.Fields(lines[i])
+		if len(fields) < 4 {
+			continue
+		}
+		lip, lport, err := parseProcNetAddr(fields[1])
+		if err != nil {
+			continue
+		}
+		rip, rport, err := parseProcNetAddr(fields[2])
+		if err != nil {
+			continue
+		}
+		st, err := strconv.ParseUint(fields[3], 16, 8)
+		if err != nil {
+			continue
+		}
+		states[TCPSocketKey{lip, lport, rip, rport}] = uint8(st)
+	}
+	return states, nil
+}
+
+// parseProcNetAddr parses an address of /proc/net/tcp, e.g. 0100007F:0CEA. The IP is
+// printed as 32 bit words in host byte order, which we assume to be little endian.
+func parseProcNetAddr(s string) (string, uint16, error) {
+	parts := strings.Split(s, ":")
+	if len(parts) != 2 || (len(parts[0]) != 8 && len(parts[0]) != 32) {
+		return "", 0, fmt.Errorf("invalid address %s", s)
+	}
+	raw, err := hex.DecodeString(parts[0])
+	if err != nil {
+		return "", 0, err
+	}
+	port, err := strconv.ParseUint(parts[1], 16, 16)
+	if err != nil {
+		return "", 0, err
+	}
+
+	ip := make(net.IP, len(raw))
+	for w := 0; w < len(raw); w += 4 {
+		for b := 0; b < 4; b++ {
+			ip[w+b] = raw[w+3-b]
+		}
+	}
+	return ip.String(), uint16(port), nil
+}
+
+// tcpListen is the kernel state of the TCP sockets listening for connections
+const tcpListen = 0x0A
+
+// ListeningSocket is a TCP socket listening for connections
+type ListeningSocket struct {
+	IP    string
+	Port  uint16
+	Inode uint64
+}
+
+// ReadListeningSockets returns the TCP sockets in the LISTEN state listed in a net/tcp
+// or net/tcp6 file of procfs. The IPs are in their canonical form.
+func ReadListeningSockets(path string) ([]ListeningSocket, error) {
+	lines, err := ReadLines(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var socks []ListeningSocket
+	// The first line is the header
+	for i := 1; i < len(lines); i++ {
+		// sl local_address rem_address st tx_queue:rx_queue tr:tm->when retrnsmt uid timeout inode ...
+		fields := strings.Fields(lines[i])
+		if len(fields) < 10 {
+			continue
+		}
+		if st, err := strconv.ParseUint(fields[3], 16, 8); err != nil || st != tcpListen {
+			continue
+		}
+		ip, port, err := parseProcNetAddr(fields[1])
+		if err != nil {
+			continue
+		}
+		inode, err := strconv.ParseUint(fields[9], 10, 64)
+		if err != nil || inode == 0 {
+			continue
+		}
+		socks = append(socks, ListeningSocket{IP: ip, Port: port, Inode: inode})
+	}
+	return socks, nil
+}
+
+// ReadConnectionInodes returns the inodes of the sockets listed in a net/tcp, net/tcp6,
+// net/udp or net/udp6 file of procfs, except the TCP sockets in the LISTEN state.
+func ReadConnectionInodes(path string) ([]uint64, error) {
+	lines, err := ReadLines(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var inodes []uint64
+	// The first line is the header
+	for i := 1; i < len(lines); i++ {
+		// sl local_address rem_address st tx_queue:rx_queue tr:tm->when retrnsmt uid timeout inode ...
+		fields := strings.Fields(lines[i])
+		if len(fields) < 10 {
+			continue
+		}
+		if st, err := strconv.ParseUint(fields[3], 16, 8); err != nil || st == tcpListen {
+			continue
+		}
+		inode, err := strconv.ParseUint(fields[9], 10, 64)
+		if err != nil || inode == 0 {
+			continue
+		}
+		inodes = append(inodes, inode)
+	}
+	return inodes, nil
+}
+
+// ReadSocketInodes returns the inodes of the sockets opened by the given process, from
+// the targets of the <procRoot>/<pid>/fd links, e.g. socket:[12345].
+func ReadSocketInodes(procRoot string, pid int32) ([]uint64, error) {
+	fdDir := filepath.Join(procRoot, strconv.Itoa(int(pid)), "fd")
+	fds, err := ioutil.ReadDir(fdDir)
+	if err != nil {
+		return nil, err
+	}
+
+	var inodes []uint64
+	for _, fd := range fds {
+		link, err := os.Readlink(filepath.Join(fdDir, fd.Name()))
+		if err != nil || !strings.HasPrefix(link, "socket:[") || !strings.HasSuffix(link, "]") {
+			continue
+		}
+		inode, err := strconv.ParseUint(link[len("socket:["):len(link)-1], 10, 64)
+		if err != nil {
+			continue
+		}
+		inodes = append(inodes, inode)
+	}
+	return inodes, nil
+}
+
+// ReadProcessEnv returns the environment variables the given process was started with,
+// from the NUL-separated KEY=VALUE entries of <procRoot>/<pid>/environ.
+func ReadProcessEnv(procRoot string, pid int32) (map[string]string, error) {
+	b, err := ioutil.ReadFile(filepath.Join(procRoot, strconv.Itoa(int(pid)), "environ"))
+	if err != nil {
+		return nil, err
+	}
+	env := make(map[string]string)
+	for _, e := range strings.Split(string(b), "\x00") {
+		i := strings.IndexByte(e, '=')
+		if i <= 0 {
+			continue
+		}
+		env[e[:i]] = e[i+1:]
+	}
+	return env, nil
+}